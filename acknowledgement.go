@@ -0,0 +1,106 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"context"
+	"fmt"
+)
+
+// acknowledgedLabel is the text emitted prior to the acknowledgement
+// details.
+const acknowledgedLabel string = "Acknowledged"
+
+// Acknowledgement describes an existing acknowledgement of a problem with
+// the host or service being checked.
+type Acknowledgement struct {
+	// Author is the user who acknowledged the problem.
+	Author string
+
+	// Comment is the reason given with the acknowledgement.
+	Comment string
+}
+
+// String provides a summary of the acknowledgement (e.g., "Acknowledged by
+// jdoe: vendor ticket 1234 opened").
+func (a Acknowledgement) String() string {
+	summary := acknowledgedLabel
+
+	if a.Author != "" {
+		summary = fmt.Sprintf("%s by %s", summary, a.Author)
+	}
+
+	if a.Comment != "" {
+		summary = fmt.Sprintf("%s: %s", summary, a.Comment)
+	}
+
+	return summary
+}
+
+// AcknowledgementSource reports an existing acknowledgement for the host or
+// service being checked. An implementation is provided in the livestatus
+// package.
+type AcknowledgementSource interface {
+	// Acknowledgement returns the existing acknowledgement or nil if the
+	// problem has not been acknowledged.
+	Acknowledgement(ctx context.Context) (*Acknowledgement, error)
+}
+
+// AcknowledgementSourceFunc is an adapter allowing an ordinary function to
+// be used as an AcknowledgementSource.
+type AcknowledgementSourceFunc func(ctx context.Context) (*Acknowledgement, error)
+
+// Acknowledgement calls f(ctx).
+func (f AcknowledgementSourceFunc) Acknowledgement(ctx context.Context) (*Acknowledgement, error) {
+	return f(ctx)
+}
+
+// WithAcknowledgementSource is an Option used to include existing
+// acknowledgement details in LongServiceOutput. See also
+// SetAcknowledgementSource.
+func WithAcknowledgementSource(source AcknowledgementSource) Option {
+	return func(p *Plugin) {
+		p.SetAcknowledgementSource(source)
+	}
+}
+
+// SetAcknowledgementSource causes the given source to be consulted for an
+// existing acknowledgement when non-OK check results are returned. If
+// found, the acknowledgement (e.g., "Acknowledged by jdoe: vendor ticket
+// 1234 opened") is included in LongServiceOutput, giving on-call responders
+// context directly in the check output.
+//
+// Failure to look up an acknowledgement does not affect plugin output; the
+// failure is logged instead (see SetLogger).
+func (p *Plugin) SetAcknowledgementSource(source AcknowledgementSource) {
+	p.acknowledgementSource = source
+}
+
+// applyAcknowledgement includes existing acknowledgement details (if
+// enabled) in LongServiceOutput.
+func (p *Plugin) applyAcknowledgement() {
+	if p.acknowledgementSource == nil || p.ExitStatusCode == StateOKExitCode {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusLookupTimeout)
+	defer cancel()
+
+	ack, err := p.acknowledgementSource.Acknowledgement(ctx)
+	switch {
+	case err != nil:
+		p.Logger().Warn("failed to look up acknowledgement", "error", err)
+		return
+	case ack == nil:
+		return
+	}
+
+	// Acknowledgement details are provided by a remote system and may
+	// contain the performance data separator.
+	p.WithDetail(EscapePipes(ack.String(), p.pipeReplacement))
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestAcknowledgementIncludedForProblems asserts that existing
+// acknowledgement details are included in LongServiceOutput for non-OK
+// results only.
+func TestAcknowledgementIncludedForProblems(t *testing.T) {
+	t.Parallel()
+
+	source := nagios.AcknowledgementSourceFunc(func(context.Context) (*nagios.Acknowledgement, error) {
+		return &nagios.Acknowledgement{Author: "jdoe", Comment: "vendor ticket 1234 opened"}, nil
+	})
+
+	run := func(exitCode int) string {
+		plugin := nagios.Plugin{}
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetAcknowledgementSource(source)
+		plugin.SetState(exitCode)
+		plugin.SetSummary("summary")
+		plugin.ReturnCheckResults()
+
+		return outputBuffer.String()
+	}
+
+	want := "Acknowledged by jdoe: vendor ticket 1234 opened"
+
+	if got := run(nagios.StateCRITICALExitCode); !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got %q", want, got)
+	}
+
+	if got := run(nagios.StateOKExitCode); strings.Contains(got, "Acknowledged") {
+		t.Errorf("want no acknowledgement for OK results, got %q", got)
+	}
+}
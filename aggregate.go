@@ -0,0 +1,146 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Expression is a node in a business-process style aggregation expression.
+// Sub-results are combined using the And, Or and AtLeast functions to
+// derive an overall state.
+type Expression interface {
+	// State returns the evaluated state (exit code) of the expression.
+	State() int
+
+	// String returns the evaluated expression in a human readable format,
+	// including the state of each node.
+	String() string
+}
+
+// SubResult is the result of an individual component (e.g., a single
+// replica or cluster member) used as input to an aggregation expression.
+type SubResult struct {
+	// Name identifies the component.
+	Name string
+
+	// ExitCode is the state of the component.
+	ExitCode int
+}
+
+// State returns the state (exit code) of the sub-result.
+func (sr SubResult) State() int {
+	return sr.ExitCode
+}
+
+// String returns the name and state label of the sub-result.
+func (sr SubResult) String() string {
+	return fmt.Sprintf("%s [%s]", sr.Name, stateLabel(sr.ExitCode))
+}
+
+// aggregateExpression combines sub-expressions by selecting the k-th best
+// state. AND (worst state), OR (best state) and "k of n" expressions are
+// all special cases of this.
+type aggregateExpression struct {
+	name  string
+	k     int
+	nodes []Expression
+}
+
+// And returns an expression which evaluates to the worst state of the given
+// sub-expressions; the expression is only OK if all sub-expressions are OK.
+func And(nodes ...Expression) Expression {
+	return aggregateExpression{name: "AND", k: len(nodes), nodes: nodes}
+}
+
+// Or returns an expression which evaluates to the best state of the given
+// sub-expressions; the expression is OK if any sub-expression is OK.
+func Or(nodes ...Expression) Expression {
+	return aggregateExpression{name: "OR", k: 1, nodes: nodes}
+}
+
+// AtLeast returns a "k of n" expression which evaluates to the k-th best
+// state of the given sub-expressions; the expression is OK as long as at
+// least k sub-expressions are OK. For example, AtLeast(2, ...) with three
+// replicas is only CRITICAL once two of the three replicas are CRITICAL.
+//
+// Values of k less than 1 are treated as 1 and values greater than the
+// number of sub-expressions are treated as the number of sub-expressions.
+func AtLeast(k int, nodes ...Expression) Expression {
+	return aggregateExpression{
+		name:  fmt.Sprintf("%d of %d", k, len(nodes)),
+		k:     k,
+		nodes: nodes,
+	}
+}
+
+// State returns the evaluated state (exit code) of the expression. An
+// expression without sub-expressions evaluates to UNKNOWN.
+func (ae aggregateExpression) State() int {
+	if len(ae.nodes) == 0 {
+		return StateUNKNOWNExitCode
+	}
+
+	states := make([]int, 0, len(ae.nodes))
+	for _, node := range ae.nodes {
+		states = append(states, node.State())
+	}
+
+	// Order from best to worst state.
+	sort.SliceStable(states, func(i, j int) bool {
+		return stateSeverity(states[i]) < stateSeverity(states[j])
+	})
+
+	k := ae.k
+	switch {
+	case k < 1:
+		k = 1
+	case k > len(states):
+		k = len(states)
+	}
+
+	return states[k-1]
+}
+
+// String returns the evaluated expression in a human readable format.
+//
+//	AND (web [OK], 2 of 3 (db01 [CRITICAL], db02 [OK], db03 [OK]) [OK]) [OK]
+func (ae aggregateExpression) String() string {
+	nodes := make([]string, 0, len(ae.nodes))
+	for _, node := range ae.nodes {
+		nodes = append(nodes, node.String())
+	}
+
+	return fmt.Sprintf(
+		"%s (%s) [%s]",
+		ae.name,
+		strings.Join(nodes, ", "),
+		stateLabel(ae.State()),
+	)
+}
+
+// Aggregate sets the plugin state to the evaluated state of the given
+// expression and the one-line summary (ServiceOutput) to the given summary
+// prefixed with the matching state label. The evaluated expression is
+// appended to LongServiceOutput so that it is clear how the final state was
+// derived. The receiver is returned to allow chaining further calls.
+//
+//	replicas := nagios.AtLeast(2,
+//		nagios.SubResult{Name: "db01", ExitCode: db01State},
+//		nagios.SubResult{Name: "db02", ExitCode: db02State},
+//		nagios.SubResult{Name: "db03", ExitCode: db03State},
+//	)
+//	plugin.Aggregate("database replica availability", replicas)
+func (p *Plugin) Aggregate(summary string, expr Expression) *Plugin {
+	state := expr.State()
+
+	return p.setResult(state, stateLabel(state), summary).
+		WithDetail("Evaluated expression: " + expr.String())
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestAggregateExpressionStates asserts that AND, OR and "k of n"
+// expressions evaluate to the expected states.
+func TestAggregateExpressionStates(t *testing.T) {
+	t.Parallel()
+
+	ok := nagios.SubResult{Name: "a", ExitCode: nagios.StateOKExitCode}
+	warning := nagios.SubResult{Name: "b", ExitCode: nagios.StateWARNINGExitCode}
+	critical := nagios.SubResult{Name: "c", ExitCode: nagios.StateCRITICALExitCode}
+
+	tests := map[string]struct {
+		expr nagios.Expression
+		want int
+	}{
+		"and worst":              {expr: nagios.And(ok, warning, critical), want: nagios.StateCRITICALExitCode},
+		"or best":                {expr: nagios.Or(ok, warning, critical), want: nagios.StateOKExitCode},
+		"2 of 3 with one down":   {expr: nagios.AtLeast(2, ok, ok, critical), want: nagios.StateOKExitCode},
+		"2 of 3 with two down":   {expr: nagios.AtLeast(2, ok, critical, critical), want: nagios.StateCRITICALExitCode},
+		"2 of 3 with one warn":   {expr: nagios.AtLeast(2, ok, warning, critical), want: nagios.StateWARNINGExitCode},
+		"nested":                 {expr: nagios.And(ok, nagios.Or(critical, warning)), want: nagios.StateWARNINGExitCode},
+		"empty":                  {expr: nagios.And(), want: nagios.StateUNKNOWNExitCode},
+		"k larger than children": {expr: nagios.AtLeast(5, ok, warning), want: nagios.StateWARNINGExitCode},
+	}
+
+	for name, tt := range tests {
+		if got := tt.expr.State(); got != tt.want {
+			t.Errorf("%s: want state %d, got %d", name, tt.want, got)
+		}
+	}
+}
+
+// TestAggregateShowsEvaluatedExpression asserts that the aggregated state
+// is used as the plugin state and that the evaluated expression is shown in
+// LongServiceOutput.
+func TestAggregateShowsEvaluatedExpression(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Aggregate(
+		"database replica availability",
+		nagios.AtLeast(2,
+			nagios.SubResult{Name: "db01", ExitCode: nagios.StateCRITICALExitCode},
+			nagios.SubResult{Name: "db02", ExitCode: nagios.StateOKExitCode},
+			nagios.SubResult{Name: "db03", ExitCode: nagios.StateCRITICALExitCode},
+		),
+	)
+
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	want := "CRITICAL: database replica availability" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"Evaluated expression: 2 of 3 (db01 [CRITICAL], db02 [OK], db03 [CRITICAL]) [CRITICAL]" +
+		nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultBaselineMinSamples is the minimum number of previous weeks with
+// samples recorded during a time slot required before deviation from the
+// baseline is evaluated.
+const DefaultBaselineMinSamples int = 3
+
+// baselineDateLayout identifies the date (and so the week) a sample was
+// recorded in for a given weekday.
+const baselineDateLayout string = "2006-01-02"
+
+// ErrInsufficientBaseline indicates that too few historical samples were
+// found to evaluate deviation from the baseline.
+var ErrInsufficientBaseline = errors.New("insufficient history for baseline")
+
+// BaselineResult is the result of comparing a value against the baseline
+// for the current weekday and hour.
+type BaselineResult struct {
+	// Weekday is the weekday of the time slot used for the baseline.
+	Weekday time.Weekday
+
+	// Hour is the hour of the time slot used for the baseline.
+	Hour int
+
+	// Baseline is the average of the historical values recorded during the
+	// time slot.
+	Baseline float64
+
+	// Samples is the number of historical values used for the baseline.
+	Samples int
+
+	// Weeks is the number of distinct previous weeks the historical values
+	// were recorded in.
+	Weeks int
+
+	// Deviation is the percentage by which the value differs from the
+	// baseline. Negative values indicate that the value is below the
+	// baseline.
+	Deviation float64
+}
+
+// String provides a summary of the baseline comparison (e.g., "+45.2% from
+// baseline 82.8 (Tue 14:00, 6 samples over 3 weeks)").
+func (br BaselineResult) String() string {
+	return fmt.Sprintf(
+		"%+.1f%% from baseline %s (%s %02d:00, %d samples over %d weeks)",
+		br.Deviation,
+		formatRangeBoundary(math.Round(br.Baseline*100)/100),
+		br.Weekday.String()[:3],
+		br.Hour,
+		br.Samples,
+		br.Weeks,
+	)
+}
+
+// EvaluateBaseline compares the given value of the performance data metric
+// with the given label against a baseline built from the values recorded
+// during the same weekday and hour of previous weeks in the run history
+// (see SetHistoryStore). Values recorded during the current time slot are
+// excluded so that the baseline is not skewed towards the value being
+// compared. The matching exit status code is returned if the
+// deviation from the baseline (in either direction) meets or exceeds the
+// critical or warning percentage; a percentage of zero disables that
+// threshold. This is intended for metrics where static thresholds never fit
+// (e.g., traffic, queue depth).
+//
+// A summary of the comparison is included in LongServiceOutput. The plugin
+// must emit the performance data metric with the given label on each run so
+// that it is recorded in the run history.
+//
+// StateOKExitCode is returned along with an error wrapping
+// ErrInsufficientBaseline if values were recorded during the time slot in
+// fewer than DefaultBaselineMinSamples previous weeks or wrapping ErrHistoryStoreNotEnabled
+// if a history store is not enabled.
+func (p *Plugin) EvaluateBaseline(label string, value float64, warningPct float64, criticalPct float64) (int, BaselineResult, error) {
+	if p.historyStore == nil {
+		return StateOKExitCode, BaselineResult{}, ErrHistoryStoreNotEnabled
+	}
+
+	records, err := p.historyStore.Records(time.Time{})
+	if err != nil {
+		return StateOKExitCode, BaselineResult{}, err
+	}
+
+	result := baselineFor(label, records, time.Now())
+
+	if result.Weeks < DefaultBaselineMinSamples {
+		return StateOKExitCode, result, fmt.Errorf(
+			"%w: samples recorded in %d of %d weeks for %s (%s %02d:00)",
+			ErrInsufficientBaseline,
+			result.Weeks,
+			DefaultBaselineMinSamples,
+			label,
+			result.Weekday.String()[:3],
+			result.Hour,
+		)
+	}
+
+	result.Deviation = deviationPercent(value, result.Baseline)
+
+	p.WithDetail(fmt.Sprintf("%s: %s is %s", label, formatRangeBoundary(value), result))
+
+	deviation := math.Abs(result.Deviation)
+
+	switch {
+	case criticalPct > 0 && deviation >= criticalPct:
+		return StateCRITICALExitCode, result, nil
+	case warningPct > 0 && deviation >= warningPct:
+		return StateWARNINGExitCode, result, nil
+	default:
+		return StateOKExitCode, result, nil
+	}
+}
+
+// baselineFor averages the values of the given label recorded during the
+// same weekday and hour as now in previous weeks. Values recorded today
+// (i.e., during the current time slot) are excluded.
+func baselineFor(label string, records []HistoryRecord, now time.Time) BaselineResult {
+	now = now.Local()
+	today := now.Format(baselineDateLayout)
+
+	result := BaselineResult{
+		Weekday: now.Weekday(),
+		Hour:    now.Hour(),
+	}
+
+	weeks := make(map[string]struct{})
+
+	var sum float64
+	for _, record := range records {
+		recorded := record.Time.Local()
+		if recorded.Weekday() != result.Weekday || recorded.Hour() != result.Hour {
+			continue
+		}
+
+		date := recorded.Format(baselineDateLayout)
+		if date >= today {
+			continue
+		}
+
+		value, ok := record.Values[label]
+		if !ok {
+			continue
+		}
+
+		sum += value
+		result.Samples++
+		weeks[date] = struct{}{}
+	}
+
+	result.Weeks = len(weeks)
+
+	if result.Samples > 0 {
+		result.Baseline = sum / float64(result.Samples)
+	}
+
+	return result
+}
+
+// deviationPercent returns the percentage by which value differs from
+// baseline. Any non-zero value differs from a zero baseline by an infinite
+// percentage.
+func deviationPercent(value float64, baseline float64) float64 {
+	if baseline == 0 {
+		switch {
+		case value > 0:
+			return math.Inf(1)
+		case value < 0:
+			return math.Inf(-1)
+		default:
+			return 0
+		}
+	}
+
+	return (value - baseline) / math.Abs(baseline) * 100
+}
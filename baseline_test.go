@@ -0,0 +1,95 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestEvaluateBaselineAlertsOnDeviation asserts that values are compared
+// against the average of values recorded during the same weekday and hour.
+func TestEvaluateBaselineAlertsOnDeviation(t *testing.T) {
+	t.Parallel()
+
+	store := nagios.NewJSONLinesHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	plugin := nagios.Plugin{}
+	plugin.SetHistoryStore(store)
+
+	if _, _, err := plugin.EvaluateBaseline("queue", 100, 25, 50); !errors.Is(err, nagios.ErrInsufficientBaseline) {
+		t.Fatalf("want error wrapping %v, got %v", nagios.ErrInsufficientBaseline, err)
+	}
+
+	now := time.Now()
+	week := 7 * 24 * time.Hour
+
+	// Values recorded during the current time slot are excluded, even if
+	// they alone would meet the minimum number of samples.
+	for i := 0; i < nagios.DefaultBaselineMinSamples; i++ {
+		if err := store.Append(nagios.HistoryRecord{Time: now, Values: map[string]float64{"queue": 1000}}); err != nil {
+			t.Fatalf("failed to append record: %v", err)
+		}
+	}
+
+	if _, _, err := plugin.EvaluateBaseline("queue", 100, 25, 50); !errors.Is(err, nagios.ErrInsufficientBaseline) {
+		t.Fatalf("want error wrapping %v, got %v", nagios.ErrInsufficientBaseline, err)
+	}
+
+	for i, queue := range []float64{90, 100, 110} {
+		record := nagios.HistoryRecord{
+			Time:   now.Add(-time.Duration(i+1) * week),
+			Values: map[string]float64{"queue": queue},
+		}
+
+		if err := store.Append(record); err != nil {
+			t.Fatalf("failed to append record: %v", err)
+		}
+
+		// Values recorded during other time slots are ignored.
+		record.Time = record.Time.Add(-3 * time.Hour)
+		record.Values = map[string]float64{"queue": 1000}
+
+		if err := store.Append(record); err != nil {
+			t.Fatalf("failed to append record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		queue float64
+		want  int
+	}{
+		{queue: 110, want: nagios.StateOKExitCode},
+		{queue: 130, want: nagios.StateWARNINGExitCode},
+		{queue: 40, want: nagios.StateCRITICALExitCode},
+	}
+
+	for _, tt := range tests {
+		got, result, err := plugin.EvaluateBaseline("queue", tt.queue, 25, 50)
+		if err != nil {
+			t.Fatalf("failed to evaluate baseline: %v", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("queue %v: want state %d, got %d (%s)", tt.queue, tt.want, got, result)
+		}
+
+		if result.Baseline != 100 || result.Samples != 3 || result.Weeks != 3 {
+			t.Errorf("want baseline 100 from 3 samples over 3 weeks, got %v from %d over %d", result.Baseline, result.Samples, result.Weeks)
+		}
+	}
+
+	if !strings.Contains(plugin.LongServiceOutput, "queue: 130 is +30.0% from baseline 100") {
+		t.Errorf("want baseline summary in LongServiceOutput, got %q", plugin.LongServiceOutput)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Abbreviated threshold names used when describing threshold breaches.
+const (
+	breachWarningLabel  string = "warn"
+	breachCriticalLabel string = "crit"
+)
+
+var (
+	// ErrWarningThresholdCrossed indicates that a metric triggered its
+	// warning threshold range.
+	ErrWarningThresholdCrossed = errors.New("warning threshold crossed")
+
+	// ErrCriticalThresholdCrossed indicates that a metric triggered its
+	// critical threshold range.
+	ErrCriticalThresholdCrossed = errors.New("critical threshold crossed")
+)
+
+// ThresholdBreach describes a metric which triggered a threshold range
+// during evaluation (see EvaluateThresholdSet).
+//
+// ThresholdBreach implements the error interface, wrapping either
+// ErrWarningThresholdCrossed or ErrCriticalThresholdCrossed, so that client
+// code and tests may branch on exactly which threshold fired (see
+// ThresholdError).
+type ThresholdBreach struct {
+	// Label is the performance data label of the metric.
+	Label string
+
+	// Value is the value of the metric.
+	Value float64
+
+	// UnitOfMeasurement is the unit of measurement of the metric.
+	UnitOfMeasurement string
+
+	// State is the resulting state (WARNING or CRITICAL).
+	State int
+
+	// Range is the threshold range which was triggered.
+	Range Range
+}
+
+// String describes the breach including the value and the side of the
+// threshold range which was crossed (e.g., "used_space 92% (crit >90%)").
+func (tb ThresholdBreach) String() string {
+	name := breachWarningLabel
+	if tb.State == StateCRITICALExitCode {
+		name = breachCriticalLabel
+	}
+
+	format := func(value float64) string {
+		return formatRangeBoundary(value) + tb.UnitOfMeasurement
+	}
+
+	var crossed string
+
+	switch {
+	case tb.Range.AlertInside:
+		crossed = fmt.Sprintf("within %s..%s", format(tb.Range.Start), format(tb.Range.End))
+	case tb.Value < tb.Range.Start:
+		crossed = "<" + format(tb.Range.Start)
+	default:
+		crossed = ">" + format(tb.Range.End)
+	}
+
+	return fmt.Sprintf("%s %s (%s %s)", tb.Label, format(tb.Value), name, crossed)
+}
+
+// Error describes the breach, prefixed by the wrapped sentinel error.
+func (tb ThresholdBreach) Error() string {
+	return fmt.Sprintf("%v: %s", tb.Unwrap(), tb.String())
+}
+
+// Unwrap returns ErrCriticalThresholdCrossed for a CRITICAL breach and
+// ErrWarningThresholdCrossed otherwise.
+func (tb ThresholdBreach) Unwrap() error {
+	if tb.State == StateCRITICALExitCode {
+		return ErrCriticalThresholdCrossed
+	}
+
+	return ErrWarningThresholdCrossed
+}
+
+// ThresholdError returns the recorded threshold breaches (see
+// ThresholdBreaches) as a single error, or nil if no thresholds were
+// breached. The result may be inspected using errors.Is (e.g., with
+// ErrCriticalThresholdCrossed) and errors.As (with a ThresholdBreach
+// target) to determine which threshold fired for which metric.
+func (p Plugin) ThresholdError() error {
+	breaches := p.ThresholdBreaches()
+	if len(breaches) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(breaches))
+	for i, breach := range breaches {
+		errs[i] = breach
+	}
+
+	return errors.Join(errs...)
+}
+
+// ThresholdBreaches returns the threshold breaches recorded during
+// evaluation, most severe first.
+func (p Plugin) ThresholdBreaches() []ThresholdBreach {
+	breaches := make([]ThresholdBreach, 0, len(p.thresholdBreaches))
+
+	for _, state := range []int{StateCRITICALExitCode, StateWARNINGExitCode} {
+		for _, breach := range p.thresholdBreaches {
+			if breach.State == state {
+				breaches = append(breaches, breach)
+			}
+		}
+	}
+
+	return breaches
+}
+
+// BreachSummary composes a one-line summary fragment listing each recorded
+// threshold breach, most severe first, prefixed by the most severe state:
+//
+//	CRITICAL: used_space 92% (crit >90%), inodes 81% (warn >80%)
+//
+// This is suitable for use as ServiceOutput (see SetSummary) when threshold
+// evaluation results in a WARNING or CRITICAL state. An empty string is
+// returned if no thresholds were breached.
+func (p Plugin) BreachSummary() string {
+	breaches := p.ThresholdBreaches()
+	if len(breaches) == 0 {
+		return ""
+	}
+
+	descriptions := make([]string, len(breaches))
+	for i, breach := range breaches {
+		descriptions[i] = breach.String()
+	}
+
+	return fmt.Sprintf("%s: %s", stateLabel(breaches[0].State), strings.Join(descriptions, ", "))
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestBreachSummary asserts that breached metrics are listed with their
+// value and threshold, most severe first.
+func TestBreachSummary(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	if got := plugin.BreachSummary(); got != "" {
+		t.Errorf("want empty summary without breaches, got %q", got)
+	}
+
+	if err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "inodes", Value: "81", UnitOfMeasurement: "%"},
+		nagios.PerformanceData{Label: "used_space", Value: "92", UnitOfMeasurement: "%"},
+		nagios.PerformanceData{Label: "free_space", Value: "2", UnitOfMeasurement: "GB"},
+		nagios.PerformanceData{Label: "temp", Value: "24"},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	set, err := nagios.ParseThresholdSet(
+		"inodes=80/90",
+		"used_space=80/90",
+		"free_space=5:/1:",
+		"temp=@20:25/",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := plugin.EvaluateThresholdSet(set); got != nagios.StateCRITICALExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateCRITICALExitCode, got)
+	}
+
+	want := "CRITICAL: used_space 92% (crit >90%), inodes 81% (warn >80%), " +
+		"free_space 2GB (warn <5GB), temp 24 (warn within 20..25)"
+
+	if got := plugin.BreachSummary(); got != want {
+		t.Errorf("want summary\n%q\ngot\n%q", want, got)
+	}
+}
+
+// TestThresholdErrorWrapsSentinels asserts that threshold breaches are
+// reported as wrapped sentinel errors carrying the breach details.
+func TestThresholdErrorWrapsSentinels(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	if err := plugin.ThresholdError(); err != nil {
+		t.Errorf("want nil error without breaches, got %v", err)
+	}
+
+	if err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "load1", Value: "12"},
+		nagios.PerformanceData{Label: "load5", Value: "4"},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	set, _ := nagios.ParseThresholdSet("load1=5/10", "load5=5/10")
+	plugin.EvaluateThresholdSet(set)
+
+	err := plugin.ThresholdError()
+
+	if !errors.Is(err, nagios.ErrCriticalThresholdCrossed) {
+		t.Errorf("want critical threshold error, got %v", err)
+	}
+
+	if errors.Is(err, nagios.ErrWarningThresholdCrossed) {
+		t.Errorf("want no warning threshold error, got %v", err)
+	}
+
+	var breach nagios.ThresholdBreach
+	if !errors.As(err, &breach) || breach.Label != "load1" || breach.Value != 12 || breach.Range.End != 10 {
+		t.Errorf("want load1 breach details, got %+v", breach)
+	}
+
+	if got, want := err.Error(), "critical threshold crossed: load1 12 (crit >10)"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
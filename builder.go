@@ -0,0 +1,127 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"strings"
+)
+
+// OK sets the plugin state to OK and the one-line summary (ServiceOutput) to
+// the given summary prefixed with the OK state label. The receiver is
+// returned to allow chaining further calls.
+//
+//	plugin.OK("all mailboxes within quota").WithDetail("checked 10 mailboxes")
+func (p *Plugin) OK(summary string) *Plugin {
+	return p.setResult(StateOKExitCode, StateOKLabel, summary)
+}
+
+// Warning sets the plugin state to WARNING and the one-line summary
+// (ServiceOutput) to the given summary prefixed with the WARNING state label.
+// The receiver is returned to allow chaining further calls.
+func (p *Plugin) Warning(summary string) *Plugin {
+	return p.setResult(StateWARNINGExitCode, StateWARNINGLabel, summary)
+}
+
+// Critical sets the plugin state to CRITICAL and the one-line summary
+// (ServiceOutput) to the given summary prefixed with the CRITICAL state
+// label. The receiver is returned to allow chaining further calls.
+func (p *Plugin) Critical(summary string) *Plugin {
+	return p.setResult(StateCRITICALExitCode, StateCRITICALLabel, summary)
+}
+
+// Unknown sets the plugin state to UNKNOWN and the one-line summary
+// (ServiceOutput) to the given summary prefixed with the UNKNOWN state
+// label. The receiver is returned to allow chaining further calls.
+func (p *Plugin) Unknown(summary string) *Plugin {
+	return p.setResult(StateUNKNOWNExitCode, StateUNKNOWNLabel, summary)
+}
+
+// WarningWithError sets the plugin state to WARNING, sets the one-line
+// summary (ServiceOutput) to the given summary prefixed with the WARNING
+// state label and records the given error in the Errors collection. Nil
+// errors are ignored. The receiver is returned to allow chaining further
+// calls.
+func (p *Plugin) WarningWithError(summary string, err error) *Plugin {
+	return p.Warning(summary).withNonNilError(err)
+}
+
+// CriticalWithError sets the plugin state to CRITICAL, sets the one-line
+// summary (ServiceOutput) to the given summary prefixed with the CRITICAL
+// state label and records the given error in the Errors collection. Nil
+// errors are ignored. The receiver is returned to allow chaining further
+// calls.
+//
+// This replaces the common pattern of separately recording the error,
+// summary and exit status code:
+//
+//	if err != nil {
+//		plugin.CriticalWithError("failed to connect to mail server", err)
+//		return
+//	}
+func (p *Plugin) CriticalWithError(summary string, err error) *Plugin {
+	return p.Critical(summary).withNonNilError(err)
+}
+
+// UnknownWithError sets the plugin state to UNKNOWN, sets the one-line
+// summary (ServiceOutput) to the given summary prefixed with the UNKNOWN
+// state label and records the given error in the Errors collection. Nil
+// errors are ignored. The receiver is returned to allow chaining further
+// calls.
+func (p *Plugin) UnknownWithError(summary string, err error) *Plugin {
+	return p.Unknown(summary).withNonNilError(err)
+}
+
+// WithPerfData adds the given performance data metrics to the collection
+// after validating them. Because this method does not return an error, any
+// validation failure is recorded in the Errors collection instead. The
+// receiver is returned to allow chaining further calls.
+func (p *Plugin) WithPerfData(perfData ...PerformanceData) *Plugin {
+	if err := p.AddPerfData(false, perfData...); err != nil {
+		p.AddError(err)
+	}
+
+	return p
+}
+
+// WithDetail appends the given text as a new line of LongServiceOutput. The
+// receiver is returned to allow chaining further calls.
+func (p *Plugin) WithDetail(detail string) *Plugin {
+	if p.LongServiceOutput != "" && !strings.HasSuffix(p.LongServiceOutput, CheckOutputEOL) {
+		p.LongServiceOutput += CheckOutputEOL
+	}
+
+	p.LongServiceOutput += detail
+
+	return p
+}
+
+// WithError appends the given errors to the collection. The receiver is
+// returned to allow chaining further calls.
+func (p *Plugin) WithError(err ...error) *Plugin {
+	p.AddError(err...)
+
+	return p
+}
+
+// withNonNilError is a helper method used to record the given error if it
+// is not nil.
+func (p *Plugin) withNonNilError(err error) *Plugin {
+	if err != nil {
+		p.AddError(err)
+	}
+
+	return p
+}
+
+// setResult is a helper method used to record the given state and summary.
+func (p *Plugin) setResult(exitCode int, label string, summary string) *Plugin {
+	p.ExitStatusCode = exitCode
+	p.ServiceOutput = label + ": " + summary
+
+	return p
+}
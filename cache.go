@@ -0,0 +1,210 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const (
+	// cacheAgeLabelSuffix is appended to the cache name to form the label of
+	// the cache age performance data metric.
+	cacheAgeLabelSuffix string = "_cache_age"
+
+	// cacheLabel is the text emitted prior to the cache freshness summary.
+	cacheLabel string = "Cache"
+)
+
+// ErrCacheCollectionFailed indicates that collecting fresh data for a disk
+// cache failed.
+var ErrCacheCollectionFailed = errors.New("failed to collect data for cache")
+
+// DiskCache caches the result of expensive data collection (e.g., a full SAN
+// inventory) in a local file for reuse across plugin runs until the TTL
+// expires.
+//
+// Cache files are written atomically, so concurrent plugin runs never
+// observe a partially written cache. If the cache expires while multiple
+// runs are in progress, each of those runs collects fresh data.
+type DiskCache struct {
+	name string
+	path string
+	ttl  time.Duration
+}
+
+// CacheInfo describes the cached data returned by a DiskCache.
+type CacheInfo struct {
+	// CollectedAt is when the data was collected.
+	CollectedAt time.Time
+
+	// Age is the time elapsed since the data was collected.
+	Age time.Duration
+
+	// Refreshed indicates whether the data was collected during this call
+	// (e.g., because the cache was missing or expired).
+	Refreshed bool
+
+	// WriteErr is the error encountered while writing refreshed data to the
+	// cache file (e.g., a read-only or full file system), if any. The
+	// refreshed data is returned regardless.
+	WriteErr error
+}
+
+// cacheEntry is the content of a cache file.
+type cacheEntry struct {
+	CollectedAt time.Time       `json:"collected_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// NewDiskCache returns a DiskCache which caches data in the file at the given
+// path for the given TTL. The name identifies the cache in plugin output and
+// performance data (e.g., "inventory").
+func NewDiskCache(name string, path string, ttl time.Duration) *DiskCache {
+	return &DiskCache{
+		name: name,
+		path: path,
+		ttl:  ttl,
+	}
+}
+
+// Name returns the name of the cache.
+func (c *DiskCache) Name() string {
+	return c.name
+}
+
+// Path returns the path of the cache file.
+func (c *DiskCache) Path() string {
+	return c.path
+}
+
+// Fetch decodes the cached data into v (a pointer, as used with
+// json.Unmarshal) if the cache is present and has not expired. Otherwise
+// collect is called to obtain fresh data, which is cached and then decoded
+// into v. Collected data must be encodable as JSON. Failure to write the
+// cache file does not prevent the fresh data from being returned; the
+// failure is reported via the WriteErr field of the returned CacheInfo.
+//
+// An error wrapping ErrCacheCollectionFailed is returned if collect fails.
+func (c *DiskCache) Fetch(v interface{}, collect func() (interface{}, error)) (CacheInfo, error) {
+	now := time.Now()
+
+	entry, err := c.read()
+	if err != nil {
+		return CacheInfo{}, err
+	}
+
+	if entry != nil && now.Sub(entry.CollectedAt) < c.ttl {
+		if err := json.Unmarshal(entry.Data, v); err == nil {
+			return CacheInfo{
+				CollectedAt: entry.CollectedAt,
+				Age:         now.Sub(entry.CollectedAt),
+			}, nil
+		}
+	}
+
+	collected, err := collect()
+	if err != nil {
+		return CacheInfo{}, fmt.Errorf("%w %q: %v", ErrCacheCollectionFailed, c.name, err)
+	}
+
+	data, err := json.Marshal(collected)
+	if err != nil {
+		return CacheInfo{}, fmt.Errorf("failed to encode cache data: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return CacheInfo{}, fmt.Errorf("failed to decode cache data: %w", err)
+	}
+
+	return CacheInfo{
+		CollectedAt: now,
+		Refreshed:   true,
+		WriteErr:    c.write(cacheEntry{CollectedAt: now, Data: data}),
+	}, nil
+}
+
+// read returns the decoded cache file or nil if the cache file does not
+// exist or cannot be decoded.
+func (c *DiskCache) read() (*cacheEntry, error) {
+	data, err := os.ReadFile(c.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil
+	}
+
+	return &entry, nil
+}
+
+// write atomically writes the cache file.
+func (c *DiskCache) write(entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache file: %w", err)
+	}
+
+	return writeFileAtomic(c.path, data)
+}
+
+// FetchCached retrieves data via the given DiskCache (see DiskCache.Fetch)
+// and reports cache freshness. A summary (e.g., "Cache (inventory): data
+// collected 4m12s ago, TTL 10m0s") is included in LongServiceOutput and the
+// cache age is recorded as a performance data metric labeled with the cache
+// name and a "_cache_age" suffix. Failure to write the cache file is logged
+// (see SetLogger).
+func (p *Plugin) FetchCached(cache *DiskCache, v interface{}, collect func() (interface{}, error)) error {
+	info, err := cache.Fetch(v, collect)
+	if err != nil {
+		return err
+	}
+
+	if info.WriteErr != nil {
+		p.Logger().Warn("failed to write cache file", "cache", cache.name, "error", info.WriteErr)
+	}
+
+	p.WithDetail(cacheFreshnessText(cache, info))
+
+	ageMetric := PerformanceData{
+		Label:             cache.name + cacheAgeLabelSuffix,
+		Value:             fmt.Sprintf("%d", int64(info.Age/time.Second)),
+		UnitOfMeasurement: "s",
+		Min:               "0",
+	}
+
+	if err := p.AddPerfData(false, ageMetric); err != nil {
+		p.Logger().Warn("failed to record cache age metric", "cache", cache.name, "error", err)
+	}
+
+	return nil
+}
+
+// cacheFreshnessText summarizes the freshness of cached data.
+func cacheFreshnessText(cache *DiskCache, info CacheInfo) string {
+	if info.Refreshed {
+		return fmt.Sprintf("%s (%s): data refreshed this run, TTL %s", cacheLabel, cache.name, cache.ttl)
+	}
+
+	return fmt.Sprintf(
+		"%s (%s): data collected %s ago, TTL %s",
+		cacheLabel,
+		cache.name,
+		formatAge(info.Age),
+		cache.ttl,
+	)
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestDiskCacheReusesFreshData asserts that cached data is reused until the
+// TTL expires and that collection errors are reported.
+func TestDiskCacheReusesFreshData(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var calls int
+	collect := func() (interface{}, error) {
+		calls++
+		return []string{"lun1", "lun2"}, nil
+	}
+
+	cache := nagios.NewDiskCache("inventory", filepath.Join(dir, "inventory.json"), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		var luns []string
+		info, err := cache.Fetch(&luns, collect)
+		if err != nil {
+			t.Fatalf("fetch %d failed: %v", i, err)
+		}
+
+		if want := i == 0; info.Refreshed != want {
+			t.Errorf("fetch %d: want refreshed %t, got %t", i, want, info.Refreshed)
+		}
+
+		if d := cmp.Diff([]string{"lun1", "lun2"}, luns); d != "" {
+			t.Errorf("(-want, +got)\n:%s", d)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("want 1 collection, got %d", calls)
+	}
+
+	expired := nagios.NewDiskCache("inventory", filepath.Join(dir, "inventory.json"), 0)
+
+	var luns []string
+	_, err := expired.Fetch(&luns, func() (interface{}, error) {
+		return nil, errors.New("array unreachable")
+	})
+	if !errors.Is(err, nagios.ErrCacheCollectionFailed) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrCacheCollectionFailed, err)
+	}
+}
+
+// TestDiskCacheReturnsDataIfWriteFails asserts that freshly collected data
+// is returned (and the write failure reported) if the cache file cannot be
+// written.
+func TestDiskCacheReturnsDataIfWriteFails(t *testing.T) {
+	t.Parallel()
+
+	// The cache directory does not exist and is not created.
+	cache := nagios.NewDiskCache("inventory", filepath.Join(t.TempDir(), "missing", "inventory.json"), time.Hour)
+
+	var luns []string
+	info, err := cache.Fetch(&luns, func() (interface{}, error) {
+		return []string{"lun1"}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !info.Refreshed || info.WriteErr == nil {
+		t.Errorf("want refreshed data with write error, got %+v", info)
+	}
+
+	if d := cmp.Diff([]string{"lun1"}, luns); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestPluginFetchCachedReportsFreshness asserts that cache freshness is
+// reported in LongServiceOutput and as a performance data metric.
+func TestPluginFetchCachedReportsFreshness(t *testing.T) {
+	t.Parallel()
+
+	cache := nagios.NewDiskCache("inventory", filepath.Join(t.TempDir(), "inventory.json"), 10*time.Minute)
+
+	var outputBuffer bytes.Buffer
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.ServiceOutput = "OK: 2 LUNs found"
+
+	var luns []string
+	err := plugin.FetchCached(cache, &luns, func() (interface{}, error) {
+		return []string{"lun1", "lun2"}, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to fetch cached data: %v", err)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"Cache (inventory): data refreshed this run, TTL 10m0s",
+		"'inventory_cache_age'=0s;;;0;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package checkrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/checkrpc"
+	"github.com/atc0005/go-nagios/resultpb"
+)
+
+// newTestClient starts an HTTP/2 test server hosting the given server and
+// returns a client for it.
+func newTestClient(t *testing.T, server *checkrpc.Server) *checkrpc.Client {
+	t.Helper()
+
+	ts := httptest.NewUnstartedServer(server)
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	return checkrpc.NewClient(ts.URL, checkrpc.WithHTTPClient(ts.Client()))
+}
+
+// newTestServer returns a server hosting sample checks.
+func newTestServer() *checkrpc.Server {
+	server := checkrpc.NewServer(checkrpc.WithHostName("web01"))
+
+	server.Register("disk", func(_ context.Context, p *nagios.Plugin, args []string) {
+		p.Critical("disk " + strings.Join(args, " ") + " is 97% full").
+			WithPerfData(nagios.PerformanceData{Label: "used", Value: "97", UnitOfMeasurement: "%"})
+	})
+
+	server.Register("load", func(_ context.Context, p *nagios.Plugin, _ []string) {
+		p.OK("load is 0.42")
+	})
+
+	server.Register("broken", func(context.Context, *nagios.Plugin, []string) {
+		panic("nil map")
+	})
+
+	return server
+}
+
+// TestExecuteCheck asserts that a registered check is run with the given
+// arguments and its result returned.
+func TestExecuteCheck(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, newTestServer())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cr, err := client.ExecuteCheck(ctx, "disk", "/var")
+	if err != nil {
+		t.Fatalf("failed to execute check: %v", err)
+	}
+
+	if cr.HostName != "web01" || cr.ServiceDescription != "disk" {
+		t.Errorf("want result for web01/disk, got %s/%s", cr.HostName, cr.ServiceDescription)
+	}
+
+	if cr.Result.ExitCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateCRITICALExitCode, cr.Result.ExitCode)
+	}
+
+	if want := "CRITICAL: disk /var is 97% full"; cr.Result.ServiceOutput != want {
+		t.Errorf("want summary %q, got %q", want, cr.Result.ServiceOutput)
+	}
+
+	if len(cr.Result.PerfData) != 1 || cr.Result.PerfData[0].Label != "used" {
+		t.Errorf("want used performance data metric, got %v", cr.Result.PerfData)
+	}
+}
+
+// TestExecuteCheckReportsPanicAsUnknown asserts that a panicking check is
+// reported as UNKNOWN rather than failing the call.
+func TestExecuteCheckReportsPanicAsUnknown(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, newTestServer())
+
+	cr, err := client.ExecuteCheck(context.Background(), "broken")
+	if err != nil {
+		t.Fatalf("failed to execute check: %v", err)
+	}
+
+	if cr.Result.ExitCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateUNKNOWNExitCode, cr.Result.ExitCode)
+	}
+
+	if len(cr.Result.Errors) != 1 || !strings.Contains(cr.Result.Errors[0], "nil map") {
+		t.Errorf("want panic recorded as error, got %v", cr.Result.Errors)
+	}
+}
+
+// TestExecuteCheckNotFound asserts that calling an unregistered check
+// returns a NotFound status.
+func TestExecuteCheckNotFound(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, newTestServer())
+
+	_, err := client.ExecuteCheck(context.Background(), "missing")
+
+	var statusErr *checkrpc.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != checkrpc.CodeNotFound {
+		t.Fatalf("want NotFound status error, got %v", err)
+	}
+
+	if !errors.Is(err, checkrpc.ErrCallFailed) {
+		t.Errorf("want error wrapping %v, got %v", checkrpc.ErrCallFailed, err)
+	}
+
+	if !strings.Contains(statusErr.Message, `"missing"`) {
+		t.Errorf("want status message naming the check, got %q", statusErr.Message)
+	}
+}
+
+// TestStreamResultsOnce asserts that all registered checks are streamed
+// once if no interval is requested.
+func TestStreamResultsOnce(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, newTestServer())
+
+	var got []string
+
+	err := client.StreamResults(
+		context.Background(),
+		resultpb.StreamResultsRequest{Names: []string{"load", "disk"}, Args: []string{"/"}},
+		func(cr resultpb.CheckResult) error {
+			got = append(got, cr.ServiceDescription+"="+cr.Result.State)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to stream results: %v", err)
+	}
+
+	if want := "load=OK,disk=CRITICAL"; strings.Join(got, ",") != want {
+		t.Errorf("want results %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+// TestStreamResultsRepeats asserts that results are streamed repeatedly at
+// the requested interval until the call is cancelled.
+func TestStreamResultsRepeats(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, newTestServer())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received int
+
+	err := client.StreamResults(
+		ctx,
+		resultpb.StreamResultsRequest{Names: []string{"load"}, Interval: 10 * time.Millisecond},
+		func(resultpb.CheckResult) error {
+			received++
+			if received == 3 {
+				cancel()
+			}
+			return nil
+		},
+	)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+
+	if received < 3 {
+		t.Errorf("want at least 3 results, got %d", received)
+	}
+}
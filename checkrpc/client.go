@@ -0,0 +1,157 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package checkrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-nagios/resultpb"
+)
+
+// Client calls the CheckService of a remote Server.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// ClientOption is a functional option used to configure a Client value
+// when constructed via NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient is a ClientOption used to specify the HTTP client used to
+// call the server. The HTTP client must support HTTP/2 (e.g., an
+// http.Client using an *http.Transport with a TLS configuration). If not
+// specified, http.DefaultClient is used.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// NewClient returns a Client which calls the server at the given base URL
+// (e.g., "https://web01.example.com:5667"). Default settings are used
+// unless overridden by the given options (e.g., WithHTTPClient).
+func NewClient(baseURL string, options ...ClientOption) *Client {
+	c := Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return &c
+}
+
+// ExecuteCheck runs the check registered with the given name on the server
+// and returns its result. The deadline of ctx (if any) is sent to the
+// server and applies to the check. A *StatusError is returned if the server
+// reports a non-OK status (e.g., CodeNotFound for unregistered checks).
+func (c *Client) ExecuteCheck(ctx context.Context, name string, args ...string) (resultpb.CheckResult, error) {
+	req := resultpb.ExecuteCheckRequest{Name: name, Args: args}
+
+	var result resultpb.CheckResult
+	var received bool
+
+	err := c.call(ctx, ExecuteCheckPath, resultpb.MarshalExecuteCheckRequest(req), func(cr resultpb.CheckResult) error {
+		result, received = cr, true
+		return nil
+	})
+
+	switch {
+	case err != nil:
+		return resultpb.CheckResult{}, err
+	case !received:
+		return resultpb.CheckResult{}, &StatusError{Code: CodeInternal, Message: "response did not include a result"}
+	}
+
+	return result, nil
+}
+
+// StreamResults runs the requested checks on the server and calls fn with
+// each result as it is received. If the request specifies an interval the
+// server repeats the checks until ctx is cancelled; the resulting
+// context.Canceled error is returned as-is. Streaming stops and the error is
+// returned if fn returns an error.
+func (c *Client) StreamResults(ctx context.Context, req resultpb.StreamResultsRequest, fn func(resultpb.CheckResult) error) error {
+	return c.call(ctx, StreamResultsPath, resultpb.MarshalStreamResultsRequest(req), fn)
+}
+
+// call sends the request message to the given method and calls fn with each
+// check result received.
+func (c *Client) call(ctx context.Context, path string, message []byte, fn func(resultpb.CheckResult) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var body bytes.Buffer
+	if err := writeFrame(&body, message); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(headerContentType, grpcContentType)
+	req.Header.Set(headerTE, "trailers")
+	req.Header.Set(headerGRPCAcceptEnc, identityEncoding)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set(headerGRPCTimeout, encodeTimeout(time.Until(deadline)))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Code: CodeUnavailable, Message: fmt.Sprintf("unexpected HTTP status %s", resp.Status)}
+	}
+
+	if !strings.HasPrefix(resp.Header.Get(headerContentType), grpcContentType) {
+		return &StatusError{
+			Code:    CodeInternal,
+			Message: fmt.Sprintf("unexpected content type %q", resp.Header.Get(headerContentType)),
+		}
+	}
+
+	for {
+		frame, err := readFrame(resp.Body)
+		switch {
+		case errors.Is(err, io.EOF):
+			return responseStatus(resp)
+		case err != nil:
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+
+		result, err := resultpb.Unmarshal(frame)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+}
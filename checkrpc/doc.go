@@ -0,0 +1,47 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package checkrpc provides a gRPC service used to run Go checks on remote
+agents, a modern alternative to NRPE.
+
+# OVERVIEW
+
+The CheckService (see resultpb/service.proto) offers two methods:
+ExecuteCheck runs a single check and returns its result, while
+StreamResults runs a set of checks (optionally repeating them at an
+interval) and streams their results. Results use the resultpb CheckResult
+message and carry the complete plugin result.
+
+The Server type is a reference server hosting checks registered as Go
+functions. It implements the gRPC protocol (message framing, status
+trailers and deadlines) by hand on top of the net/http HTTP/2 support so
+that the module remains free of third-party runtime dependencies. Only
+uncompressed messages are supported.
+
+# FEATURES
+
+  - Server type used to host registered checks (CheckFunc), reporting
+    panicking checks as UNKNOWN and honoring the grpc-timeout of calls
+  - Client type used to call ExecuteCheck and StreamResults
+  - StatusError type reporting the gRPC status code of failed calls
+
+# LIMITATIONS
+
+The net/http server only negotiates HTTP/2 (required by gRPC) for TLS
+connections. Serve the Server using ListenAndServeTLS (or an http.Server
+with a TLS configuration, e.g. from the transport package TLSConfig type)
+and use an http.Client trusting the server certificate. Cleartext HTTP/2
+(h2c), as used by gRPC clients configured with insecure credentials, is
+not supported by either the Server or the Client.
+
+The Server and Client are tested against each other only; they have not
+been tested with other gRPC implementations (e.g., grpc-go or clients
+generated from the schema for other languages). Confirm interoperability
+before replacing an existing deployment.
+*/
+package checkrpc
@@ -0,0 +1,247 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package checkrpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-nagios/resultpb"
+)
+
+// Method paths of the CheckService (see resultpb/service.proto).
+const (
+	ExecuteCheckPath  string = "/gonagios.result.v1.CheckService/ExecuteCheck"
+	StreamResultsPath string = "/gonagios.result.v1.CheckService/StreamResults"
+)
+
+// gRPC protocol details.
+const (
+	grpcContentType string = "application/grpc"
+
+	headerContentType    string = "Content-Type"
+	headerGRPCStatus     string = "Grpc-Status"
+	headerGRPCMessage    string = "Grpc-Message"
+	headerGRPCTimeout    string = "Grpc-Timeout"
+	headerGRPCEncoding   string = "Grpc-Encoding"
+	headerGRPCAcceptEnc  string = "Grpc-Accept-Encoding"
+	headerTE             string = "Te"
+	identityEncoding     string = "identity"
+	frameHeaderSize      int    = 5
+	frameCompressedFlag  byte   = 1
+	maxGRPCTimeoutDigits int    = 8
+)
+
+// Code is a gRPC status code.
+type Code int
+
+// gRPC status codes used by the CheckService.
+//
+// https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+const (
+	CodeOK               Code = 0
+	CodeCanceled         Code = 1
+	CodeInvalidArgument  Code = 3
+	CodeDeadlineExceeded Code = 4
+	CodeNotFound         Code = 5
+	CodeUnimplemented    Code = 12
+	CodeInternal         Code = 13
+	CodeUnavailable      Code = 14
+)
+
+// ErrCallFailed indicates that a CheckService call did not complete
+// successfully. See also StatusError.
+var ErrCallFailed = errors.New("check service call failed")
+
+// StatusError is returned by Client methods if the server reports a non-OK
+// gRPC status. It wraps ErrCallFailed.
+type StatusError struct {
+	// Code is the gRPC status code.
+	Code Code
+
+	// Message is the status message.
+	Message string
+}
+
+// Error provides the status code and message.
+func (se *StatusError) Error() string {
+	return fmt.Sprintf("%s: code %d: %s", ErrCallFailed, se.Code, se.Message)
+}
+
+// Unwrap returns ErrCallFailed.
+func (se *StatusError) Unwrap() error {
+	return ErrCallFailed
+}
+
+// writeFrame writes a length prefixed (uncompressed) gRPC message.
+func writeFrame(w io.Writer, message []byte) error {
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	frame = append(frame, message...)
+
+	_, err := w.Write(frame)
+
+	return err
+}
+
+// readFrame reads a length prefixed gRPC message. io.EOF is returned if no
+// further messages are available.
+func readFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("%w: truncated frame header: %v", resultpb.ErrInvalidMessage, err)
+	}
+
+	if header[0]&frameCompressedFlag != 0 {
+		return nil, &StatusError{Code: CodeUnimplemented, Message: "compressed messages are not supported"}
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if uint64(size) > uint64(resultpb.MaxMessageSize) {
+		return nil, fmt.Errorf(
+			"%w: message size %d exceeds %d bytes", resultpb.ErrInvalidMessage, size, resultpb.MaxMessageSize,
+		)
+	}
+
+	message := make([]byte, size)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return nil, fmt.Errorf("%w: truncated message: %v", resultpb.ErrInvalidMessage, err)
+	}
+
+	return message, nil
+}
+
+// encodeTimeout formats the given timeout as a grpc-timeout header value.
+func encodeTimeout(timeout time.Duration) string {
+	if timeout <= 0 {
+		// Already expired; request the smallest possible timeout.
+		return "1n"
+	}
+
+	maxValue := int64(1)
+	for i := 0; i < maxGRPCTimeoutDigits; i++ {
+		maxValue *= 10
+	}
+
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{suffix: "n", size: time.Nanosecond},
+		{suffix: "u", size: time.Microsecond},
+		{suffix: "m", size: time.Millisecond},
+		{suffix: "S", size: time.Second},
+		{suffix: "M", size: time.Minute},
+	} {
+		// Round up so that the server does not give up early.
+		value := (int64(timeout) + int64(unit.size) - 1) / int64(unit.size)
+		if value < maxValue {
+			return strconv.FormatInt(value, 10) + unit.suffix
+		}
+	}
+
+	return strconv.FormatInt(int64(timeout/time.Hour)+1, 10) + "H"
+}
+
+// parseTimeout parses a grpc-timeout header value (e.g., "10S").
+func parseTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > maxGRPCTimeoutDigits+1 {
+		return 0, fmt.Errorf("invalid timeout %q", value)
+	}
+
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid timeout unit in %q", value)
+	}
+
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid timeout %q", value)
+	}
+
+	return time.Duration(amount) * unit, nil
+}
+
+// encodeStatusMessage percent-encodes a grpc-message header value.
+func encodeStatusMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// decodeStatusMessage decodes a percent-encoded grpc-message header value.
+// Invalid escapes are retained as-is.
+func decodeStatusMessage(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) {
+			if c, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+
+	return b.String()
+}
+
+// responseStatus returns the gRPC status reported by a response, reading
+// the trailers (or the headers of a trailers-only response).
+func responseStatus(resp *http.Response) error {
+	status := resp.Trailer.Get(headerGRPCStatus)
+	message := resp.Trailer.Get(headerGRPCMessage)
+
+	if status == "" {
+		status = resp.Header.Get(headerGRPCStatus)
+		message = resp.Header.Get(headerGRPCMessage)
+	}
+
+	if status == "" {
+		return &StatusError{Code: CodeInternal, Message: "response did not include a status"}
+	}
+
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return &StatusError{Code: CodeInternal, Message: fmt.Sprintf("invalid status %q", status)}
+	}
+
+	if Code(code) == CodeOK {
+		return nil
+	}
+
+	return &StatusError{Code: Code(code), Message: decodeStatusMessage(message)}
+}
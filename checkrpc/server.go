@@ -0,0 +1,283 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package checkrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/resultpb"
+)
+
+// CheckFunc is a check hosted by a Server. The check records its result
+// using the given plugin (e.g., via the Critical or AddPerfData methods)
+// and must return once ctx is done.
+type CheckFunc func(ctx context.Context, p *nagios.Plugin, args []string)
+
+// Server hosts registered checks and runs them on request via the
+// CheckService gRPC protocol. Server implements http.Handler; gRPC requires
+// HTTP/2, which the net/http server provides for TLS connections:
+//
+//	server := checkrpc.NewServer()
+//	server.Register("disk", checkDisk)
+//
+//	log.Fatal(http.ListenAndServeTLS(":5667", certFile, keyFile, server))
+type Server struct {
+	hostName string
+
+	mu     sync.RWMutex
+	checks map[string]CheckFunc
+}
+
+// ServerOption is a functional option used to configure a Server value
+// when constructed via NewServer.
+type ServerOption func(*Server)
+
+// WithHostName is a ServerOption used to specify the host name reported in
+// check results. If not specified (or empty), the host name reported by the
+// operating system is used.
+func WithHostName(hostName string) ServerOption {
+	return func(s *Server) {
+		if hostName != "" {
+			s.hostName = hostName
+		}
+	}
+}
+
+// NewServer returns a Server without registered checks. Default settings
+// are used unless overridden by the given options (e.g., WithHostName).
+func NewServer(options ...ServerOption) *Server {
+	s := Server{
+		checks: make(map[string]CheckFunc),
+	}
+
+	s.hostName, _ = os.Hostname()
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s
+}
+
+// Register hosts the given check under the given name, replacing any check
+// previously registered with the same name. The name is reported as the
+// service description of the check results.
+func (s *Server) Register(name string, check CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checks[name] = check
+}
+
+// ServeHTTP handles CheckService calls.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get(headerContentType), grpcContentType) {
+		http.Error(w, "unsupported request", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set(headerContentType, grpcContentType)
+	w.Header().Set(headerGRPCAcceptEnc, identityEncoding)
+
+	if encoding := r.Header.Get(headerGRPCEncoding); encoding != "" && encoding != identityEncoding {
+		s.writeStatus(w, CodeUnimplemented, fmt.Sprintf("unsupported message encoding %q", encoding))
+		return
+	}
+
+	ctx := r.Context()
+	if value := r.Header.Get(headerGRPCTimeout); value != "" {
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			s.writeStatus(w, CodeInvalidArgument, err.Error())
+			return
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	message, err := readFrame(r.Body)
+	if err != nil {
+		s.writeStatus(w, CodeInvalidArgument, fmt.Sprintf("failed to read request: %v", err))
+		return
+	}
+
+	var code Code
+	var statusMessage string
+
+	switch r.URL.Path {
+	case ExecuteCheckPath:
+		code, statusMessage = s.executeCheck(ctx, w, message)
+	case StreamResultsPath:
+		code, statusMessage = s.streamResults(ctx, w, message)
+	default:
+		code, statusMessage = CodeUnimplemented, fmt.Sprintf("unknown method %s", r.URL.Path)
+	}
+
+	s.writeStatus(w, code, statusMessage)
+}
+
+// executeCheck handles ExecuteCheck calls.
+func (s *Server) executeCheck(ctx context.Context, w http.ResponseWriter, message []byte) (Code, string) {
+	req, err := resultpb.UnmarshalExecuteCheckRequest(message)
+	if err != nil {
+		return CodeInvalidArgument, err.Error()
+	}
+
+	check, ok := s.check(req.Name)
+	if !ok {
+		return CodeNotFound, fmt.Sprintf("check %q is not registered", req.Name)
+	}
+
+	result := s.run(ctx, req.Name, check, req.Args)
+
+	if err := ctx.Err(); err != nil {
+		return contextCode(err), err.Error()
+	}
+
+	if err := writeFrame(w, resultpb.Marshal(result)); err != nil {
+		return CodeUnavailable, err.Error()
+	}
+
+	return CodeOK, ""
+}
+
+// streamResults handles StreamResults calls.
+func (s *Server) streamResults(ctx context.Context, w http.ResponseWriter, message []byte) (Code, string) {
+	req, err := resultpb.UnmarshalStreamResultsRequest(message)
+	if err != nil {
+		return CodeInvalidArgument, err.Error()
+	}
+
+	names := req.Names
+	if len(names) == 0 {
+		names = s.names()
+	}
+
+	checks := make([]CheckFunc, 0, len(names))
+	for _, name := range names {
+		check, ok := s.check(name)
+		if !ok {
+			return CodeNotFound, fmt.Sprintf("check %q is not registered", name)
+		}
+		checks = append(checks, check)
+	}
+
+	flusher, _ := w.(http.Flusher)
+
+	for {
+		for i, check := range checks {
+			result := s.run(ctx, names[i], check, req.Args)
+
+			if err := ctx.Err(); err != nil {
+				return contextCode(err), err.Error()
+			}
+
+			if err := writeFrame(w, resultpb.Marshal(result)); err != nil {
+				return CodeUnavailable, err.Error()
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		if req.Interval <= 0 {
+			return CodeOK, ""
+		}
+
+		timer := time.NewTimer(req.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contextCode(ctx.Err()), ctx.Err().Error()
+		case <-timer.C:
+		}
+	}
+}
+
+// run runs the given check and returns its result. A panicking check is
+// reported as UNKNOWN.
+func (s *Server) run(ctx context.Context, name string, check CheckFunc, args []string) resultpb.CheckResult {
+	plugin := nagios.NewPlugin()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				plugin.Unknown(fmt.Sprintf("check %s panicked", name)).
+					WithError(fmt.Errorf("%w: %v", nagios.ErrPanicDetected, r))
+			}
+		}()
+
+		check(ctx, plugin, args)
+	}()
+
+	return resultpb.CheckResult{
+		HostName:           s.hostName,
+		ServiceDescription: name,
+		CheckTime:          time.Now(),
+		Result:             plugin.Result(),
+	}
+}
+
+// check returns the check registered with the given name.
+func (s *Server) check(name string) (CheckFunc, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	check, ok := s.checks[name]
+
+	return check, ok
+}
+
+// names returns the names of all registered checks, sorted.
+func (s *Server) names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.checks))
+	for name := range s.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// writeStatus records the gRPC status of the call as trailers.
+func (s *Server) writeStatus(w http.ResponseWriter, code Code, message string) {
+	w.Header().Set(http.TrailerPrefix+headerGRPCStatus, strconv.Itoa(int(code)))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+headerGRPCMessage, encodeStatusMessage(message))
+	}
+
+	// Ensure the response is sent with a 200 status (as required by gRPC)
+	// even if no message was written.
+	_, _ = io.WriteString(w, "")
+}
+
+// contextCode returns the gRPC status code for the given context error.
+func contextCode(err error) Code {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CodeDeadlineExceeded
+	}
+
+	return CodeCanceled
+}
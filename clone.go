@@ -0,0 +1,73 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns an independent copy of the current plugin state. Changes to
+// the result recorded by the copy (e.g., state, summary, errors,
+// performance data, thresholds and sections) do not affect the receiver and
+// vice versa. This allows snapshots of a result for intermediate reporting
+// and "what-if" evaluations (e.g., applying alternative thresholds) without
+// modifying the live result.
+//
+// Collaborators such as the output target, logger, stores and tracer are
+// shared with the copy. A running Progress reporter (see StartProgress) and
+// the root tracing span are not carried over so that they are only stopped
+// or ended once, by the receiver.
+//
+// Clone does not synchronize access to the receiver; concurrent writers
+// must serialize calls to Clone with their own changes.
+func (p *Plugin) Clone() *Plugin {
+	clone := *p
+
+	clone.Errors = slices.Clone(p.Errors)
+	clone.perfData = maps.Clone(p.perfData)
+	clone.WarningRange = cloneRange(p.WarningRange)
+	clone.CriticalRange = cloneRange(p.CriticalRange)
+	clone.warningRecoveryRange = cloneRange(p.warningRecoveryRange)
+	clone.criticalRecoveryRange = cloneRange(p.criticalRecoveryRange)
+	clone.warningRangeSet = slices.Clone(p.warningRangeSet)
+	clone.criticalRangeSet = slices.Clone(p.criticalRangeSet)
+	clone.thresholdWindows = slices.Clone(p.thresholdWindows)
+	clone.thresholdBreaches = slices.Clone(p.thresholdBreaches)
+	clone.diagnostics = slices.Clone(p.diagnostics)
+	clone.links = slices.Clone(p.links)
+	clone.emitHooks = slices.Clone(p.emitHooks)
+	clone.sinks = slices.Clone(p.sinks)
+	clone.beforeEmitHooks = slices.Clone(p.beforeEmitHooks)
+	clone.afterEmitHooks = slices.Clone(p.afterEmitHooks)
+	clone.outputFilters = slices.Clone(p.outputFilters)
+	clone.stateRemapping = maps.Clone(p.stateRemapping)
+	clone.environmentAllowlist = slices.Clone(p.environmentAllowlist)
+
+	if p.outputTrace != nil {
+		trace := *p.outputTrace
+		clone.outputTrace = &trace
+	}
+
+	clone.progress = nil
+	clone.rootSpan = nil
+
+	return &clone
+}
+
+// cloneRange returns a copy of the given threshold range, or nil if r is
+// nil.
+func cloneRange(r *Range) *Range {
+	if r == nil {
+		return nil
+	}
+
+	clone := *r
+
+	return &clone
+}
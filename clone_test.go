@@ -0,0 +1,75 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestCloneIsIndependent asserts that changes to a cloned plugin do not
+// affect the original plugin and vice versa.
+func TestCloneIsIndependent(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin()
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Warning("queue backlog").WithError(errors.New("consumer lagging"))
+	if err := plugin.AddPerfData(false, nagios.PerformanceData{Label: "queued", Value: "120"}); err != nil {
+		t.Fatalf("failed to add performance data: %v", err)
+	}
+
+	if err := plugin.SetWarningThreshold("100"); err != nil {
+		t.Fatalf("failed to set warning threshold: %v", err)
+	}
+
+	snapshot := plugin.Clone()
+
+	// What-if evaluation on the copy.
+	snapshot.Critical("what-if result").WithError(errors.New("what-if error"))
+	snapshot.WarningRange.End = 500
+	if err := snapshot.AddPerfData(false, nagios.PerformanceData{Label: "what_if", Value: "1"}); err != nil {
+		t.Fatalf("failed to add performance data: %v", err)
+	}
+
+	// Further changes to the live result.
+	plugin.WithError(errors.New("live error"))
+
+	if len(snapshot.Errors) != 2 {
+		t.Errorf("want 2 errors recorded by the copy, got %d: %v", len(snapshot.Errors), snapshot.Errors)
+	}
+
+	if plugin.WarningRange.End != 100 {
+		t.Errorf("want original warning range end 100, got %v", plugin.WarningRange.End)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{"WARNING: queue backlog", "consumer lagging", "live error", "'queued'=120"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	for _, unwanted := range []string{"what-if", "what_if"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("want output to not contain %q, got:\n%s", unwanted, got)
+		}
+	}
+}
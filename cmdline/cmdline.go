@@ -0,0 +1,266 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package cmdline
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// Flag names registered by RegisterFlags.
+const (
+	FlagDebug        string = "debug"
+	FlagLogFile      string = "log-file"
+	FlagVersion      string = "version"
+	FlagVersionShort string = "V"
+	FlagOKAs         string = "ok-as"
+	FlagWarningAs    string = "warning-as"
+	FlagCriticalAs   string = "critical-as"
+	FlagUnknownAs    string = "unknown-as"
+	FlagSink         string = "sink"
+)
+
+// DefaultLogLevel is the level at which diagnostics are emitted when debug
+// output is not enabled.
+const DefaultLogLevel slog.Level = slog.LevelWarn
+
+// Config holds the values of the shared command-line flags.
+type Config struct {
+	// Debug indicates whether debug level diagnostics are enabled.
+	Debug bool
+
+	// LogFile is the optional path to a file where diagnostics are written.
+	// If not set, diagnostics are written to stderr.
+	LogFile string
+
+	// Version indicates whether plugin version details were requested.
+	Version bool
+
+	// OKAs, WarningAs, CriticalAs and UnknownAs are the optional states
+	// (labels or exit codes) reported in place of the OK, WARNING,
+	// CRITICAL and UNKNOWN plugin states.
+	OKAs       string
+	WarningAs  string
+	CriticalAs string
+	UnknownAs  string
+
+	// Sinks are the optional result sink specifications (see
+	// nagios.ParseSink) the final result is emitted to instead of plugin
+	// output.
+	Sinks []string
+
+	// logFile is the opened diagnostics file (if any).
+	logFile *os.File
+}
+
+// RegisterFlags registers the shared flags with the given flag set. This
+// should be called before the flag set is parsed.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(
+		&c.Debug,
+		FlagDebug,
+		false,
+		"Enable debug diagnostics (written to stderr or the --"+FlagLogFile+" file, never to plugin output).",
+	)
+	fs.StringVar(
+		&c.LogFile,
+		FlagLogFile,
+		"",
+		"Write diagnostics to this file (appended) instead of stderr.",
+	)
+	fs.BoolVar(
+		&c.Version,
+		FlagVersion,
+		false,
+		"Print plugin name, version and build details and exit.",
+	)
+	fs.BoolVar(
+		&c.Version,
+		FlagVersionShort,
+		false,
+		"Print plugin name, version and build details and exit (shorthand).",
+	)
+
+	for _, remap := range c.stateRemapFlags() {
+		fs.StringVar(
+			remap.value,
+			remap.name,
+			"",
+			fmt.Sprintf("Report the %s state as this state (e.g., critical) instead.", remap.label),
+		)
+	}
+
+	fs.Func(
+		FlagSink,
+		"Emit the result to this sink (e.g., stdout: or nrdp+https://host/nrdp/?token=T&service=S) instead "+
+			"of plugin output. May be repeated.",
+		func(spec string) error {
+			c.Sinks = append(c.Sinks, spec)
+			return nil
+		},
+	)
+}
+
+// stateRemapFlag associates a state remapping flag with its value.
+type stateRemapFlag struct {
+	name     string
+	label    string
+	exitCode int
+	value    *string
+}
+
+// stateRemapFlags returns the state remapping flags.
+func (c *Config) stateRemapFlags() []stateRemapFlag {
+	return []stateRemapFlag{
+		{name: FlagOKAs, label: nagios.StateOKLabel, exitCode: nagios.StateOKExitCode, value: &c.OKAs},
+		{name: FlagWarningAs, label: nagios.StateWARNINGLabel, exitCode: nagios.StateWARNINGExitCode, value: &c.WarningAs},
+		{name: FlagCriticalAs, label: nagios.StateCRITICALLabel, exitCode: nagios.StateCRITICALExitCode, value: &c.CriticalAs},
+		{name: FlagUnknownAs, label: nagios.StateUNKNOWNLabel, exitCode: nagios.StateUNKNOWNExitCode, value: &c.UnknownAs},
+	}
+}
+
+// StateMapping returns the state remapping requested via the --ok-as,
+// --warning-as, --critical-as and --unknown-as flags. An error wrapping
+// nagios.ErrUnknownState is returned if a flag value is not a recognized
+// state.
+func (c *Config) StateMapping() (nagios.StateMapping, error) {
+	mapping := make(nagios.StateMapping)
+
+	for _, remap := range c.stateRemapFlags() {
+		if *remap.value == "" {
+			continue
+		}
+
+		exitCode, err := nagios.ParseState(*remap.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s value: %w", remap.name, err)
+		}
+
+		mapping[remap.exitCode] = exitCode
+	}
+
+	return mapping, nil
+}
+
+// PrintVersion writes the version details of the plugin with the given name
+// and semantic version (see nagios.ReadVersionInfo) to w if requested via
+// the -V (--version) flag. The returned value indicates whether the details
+// were written, in which case the plugin should exit without performing
+// checks. Like the reference plugins, the plugin should exit with the
+// UNKNOWN exit code.
+func (c *Config) PrintVersion(w io.Writer, name string, version string) bool {
+	if !c.Version {
+		return false
+	}
+
+	fmt.Fprintln(w, nagios.ReadVersionInfo(name, version))
+
+	return true
+}
+
+// LogLevel returns the diagnostics level selected by the flag values.
+func (c *Config) LogLevel() slog.Level {
+	if c.Debug {
+		return slog.LevelDebug
+	}
+
+	return DefaultLogLevel
+}
+
+// Logger returns a logger which writes diagnostics at the selected level to
+// the selected destination. The Close method should be called once the
+// logger is no longer needed if a log file was specified.
+func (c *Config) Logger() (*slog.Logger, error) {
+	if c.LogFile == "" {
+		return slog.New(nagios.NewLogHandler(os.Stderr, c.LogLevel())), nil
+	}
+
+	if c.logFile == nil {
+		f, err := os.OpenFile(c.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		c.logFile = f
+	}
+
+	return slog.New(nagios.NewLogHandler(c.logFile, c.LogLevel())), nil
+}
+
+// ResultSinks returns the result sinks requested via the --sink flag. The
+// sink schemes provided by the passive and icinga2 packages are available
+// if those packages are imported by the plugin.
+func (c *Config) ResultSinks() ([]nagios.Sink, error) {
+	sinks := make([]nagios.Sink, 0, len(c.Sinks))
+
+	for _, spec := range c.Sinks {
+		sink, err := nagios.ParseSink(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s value: %w", FlagSink, err)
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+// Apply applies the flag values to the given Plugin. The logger returned
+// by Logger is assigned to the plugin, the requested state remapping (see
+// StateMapping) and result sinks (see ResultSinks) are applied and, if
+// debug output is enabled, the diagnostics section is enabled. The Close
+// method should be called once the plugin has finished if a log file was
+// specified.
+func (c *Config) Apply(plugin *nagios.Plugin) error {
+	mapping, err := c.StateMapping()
+	if err != nil {
+		return err
+	}
+
+	sinks, err := c.ResultSinks()
+	if err != nil {
+		return err
+	}
+
+	logger, err := c.Logger()
+	if err != nil {
+		return err
+	}
+
+	if len(mapping) > 0 {
+		plugin.SetStateRemapping(mapping)
+	}
+
+	if len(sinks) > 0 {
+		plugin.SetSinks(sinks...)
+	}
+
+	plugin.SetLogger(logger)
+
+	if c.Debug {
+		plugin.EnableDiagnostics()
+	}
+
+	return nil
+}
+
+// Close closes the diagnostics log file (if opened).
+func (c *Config) Close() error {
+	if c.logFile == nil {
+		return nil
+	}
+
+	err := c.logFile.Close()
+	c.logFile = nil
+
+	return err
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package cmdline_test
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/cmdline"
+
+	// Register the passive result sink schemes (e.g., cmdfile).
+	_ "github.com/atc0005/go-nagios/passive"
+)
+
+// TestDebugFlagWritesDiagnosticsToLogFile asserts that the --debug flag
+// enables library diagnostics and that they are written to the log file
+// rather than plugin output.
+func TestDebugFlagWritesDiagnosticsToLogFile(t *testing.T) {
+	t.Parallel()
+
+	logFile := filepath.Join(t.TempDir(), "plugin.log")
+
+	var cfg cmdline.Config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg.RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"--debug", "--log-file", logFile}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	logger, err := cfg.Logger()
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(nagios.WithLogger(logger), nagios.WithOutputTarget(&outputBuffer))
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.OK("all good")
+	plugin.ReturnCheckResults()
+
+	if err := cfg.Close(); err != nil {
+		t.Fatalf("failed to close log file: %v", err)
+	}
+
+	logOutput, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+
+	if !strings.Contains(string(logOutput), `msg="emitting check results" state=OK`) {
+		t.Errorf("want debug diagnostics in log file, got %q", logOutput)
+	}
+
+	if strings.Contains(outputBuffer.String(), "emitting check results") {
+		t.Errorf("diagnostics found in plugin output: %q", outputBuffer.String())
+	}
+}
+
+// TestLogLevelWithoutDebug asserts that debug diagnostics are disabled by
+// default.
+func TestLogLevelWithoutDebug(t *testing.T) {
+	t.Parallel()
+
+	var cfg cmdline.Config
+	if got := cfg.LogLevel(); got != cmdline.DefaultLogLevel {
+		t.Errorf("want level %v, got %v", cmdline.DefaultLogLevel, got)
+	}
+}
+
+// TestApplyEnablesDiagnosticsWithDebug asserts that applying the flag
+// values with --debug set enables the diagnostics section.
+func TestApplyEnablesDiagnosticsWithDebug(t *testing.T) {
+	t.Parallel()
+
+	cfg := cmdline.Config{Debug: true, LogFile: filepath.Join(t.TempDir(), "plugin.log")}
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(nagios.WithOutputTarget(&outputBuffer))
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := cfg.Apply(plugin); err != nil {
+		t.Fatalf("failed to apply flag values: %v", err)
+	}
+	defer cfg.Close()
+
+	plugin.OK("all good")
+	plugin.ReturnCheckResults()
+
+	if !strings.Contains(outputBuffer.String(), "**DIAGNOSTICS**") {
+		t.Errorf("want diagnostics section in output, got %q", outputBuffer.String())
+	}
+}
+
+// TestVersionFlagPrintsVersion asserts that both the -V and --version flags
+// request version details.
+func TestVersionFlagPrintsVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, arg := range []string{"-V", "--version"} {
+		var cfg cmdline.Config
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		cfg.RegisterFlags(fs)
+
+		if err := fs.Parse([]string{arg}); err != nil {
+			t.Fatalf("%s: failed to parse flags: %v", arg, err)
+		}
+
+		var output strings.Builder
+		if !cfg.PrintVersion(&output, "check_foo", "1.2.3") {
+			t.Fatalf("%s: want version details to be printed", arg)
+		}
+
+		if want := "check_foo v1.2.3 ("; !strings.HasPrefix(output.String(), want) {
+			t.Errorf("%s: want prefix %q, got %q", arg, want, output.String())
+		}
+	}
+
+	var cfg cmdline.Config
+	if cfg.PrintVersion(io.Discard, "check_foo", "1.2.3") {
+		t.Error("want no version details without flag")
+	}
+}
+
+// TestStateRemapFlagsRemapState asserts that the state remapping flags are
+// applied to the plugin.
+func TestStateRemapFlagsRemapState(t *testing.T) {
+	t.Parallel()
+
+	var cfg cmdline.Config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg.RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"--unknown-as=critical", "--warning-as", "0"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(nagios.WithOutputTarget(&outputBuffer), nagios.WithSkipOSExit())
+
+	if err := cfg.Apply(plugin); err != nil {
+		t.Fatalf("failed to apply flags: %v", err)
+	}
+
+	plugin.Warning("certificate expires in 20 days")
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateOKExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateOKExitCode, plugin.ExitStatusCode)
+	}
+
+	if want := "OK: certificate expires in 20 days (state remapped from WARNING)"; !strings.HasPrefix(outputBuffer.String(), want) {
+		t.Errorf("want output to start with %q, got:\n%s", want, outputBuffer.String())
+	}
+}
+
+// TestStateRemapFlagsRejectUnknownStates asserts that unrecognized states
+// are rejected.
+func TestStateRemapFlagsRejectUnknownStates(t *testing.T) {
+	t.Parallel()
+
+	cfg := cmdline.Config{UnknownAs: "fatal"}
+
+	if err := cfg.Apply(nagios.NewPlugin()); !errors.Is(err, nagios.ErrUnknownState) {
+		t.Errorf("want error %v, got %v", nagios.ErrUnknownState, err)
+	}
+}
+
+// TestSinkFlagEmitsToSinks asserts that results are emitted to the sinks
+// given via the --sink flag instead of plugin output.
+func TestSinkFlagEmitsToSinks(t *testing.T) {
+	t.Parallel()
+
+	// The external command file is created by Nagios.
+	commandFile := filepath.Join(t.TempDir(), "nagios.cmd")
+	if err := os.WriteFile(commandFile, nil, 0o600); err != nil {
+		t.Fatalf("failed to create command file: %v", err)
+	}
+
+	var cfg cmdline.Config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg.RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"--sink", "cmdfile://" + commandFile + "?host=web01&service=disk"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(nagios.WithOutputTarget(&outputBuffer), nagios.WithSkipOSExit())
+
+	if err := cfg.Apply(plugin); err != nil {
+		t.Fatalf("failed to apply flags: %v", err)
+	}
+
+	plugin.Critical("disk full")
+	plugin.ReturnCheckResults()
+
+	if outputBuffer.Len() != 0 {
+		t.Errorf("want no plugin output, got:\n%s", outputBuffer.String())
+	}
+
+	got, err := os.ReadFile(commandFile)
+	if err != nil {
+		t.Fatalf("failed to read command file: %v", err)
+	}
+
+	if want := "PROCESS_SERVICE_CHECK_RESULT;web01;disk;2;CRITICAL: disk full"; !strings.Contains(string(got), want) {
+		t.Errorf("want command file to contain %q, got %q", want, got)
+	}
+}
+
+// TestSinkFlagRejectsUnknownSchemes asserts that unregistered sink schemes
+// are rejected.
+func TestSinkFlagRejectsUnknownSchemes(t *testing.T) {
+	t.Parallel()
+
+	cfg := cmdline.Config{Sinks: []string{"carrier-pigeon://loft"}}
+
+	if err := cfg.Apply(nagios.NewPlugin()); !errors.Is(err, nagios.ErrUnknownSinkScheme) {
+		t.Errorf("want error %v, got %v", nagios.ErrUnknownSinkScheme, err)
+	}
+}
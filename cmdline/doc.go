@@ -0,0 +1,63 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package cmdline provides common command-line flag conventions for Nagios
+plugins written using the nagios package.
+
+# OVERVIEW
+
+Plugins built on this library should behave consistently when run by
+operators. This package registers the shared flags with a flag.FlagSet and
+translates their values into library settings so that each plugin does not
+need to reimplement them.
+
+# FEATURES
+
+  - --debug flag used to enable debug level diagnostics and the
+    diagnostics section (Go runtime memory, GC and build details) in plugin
+    output
+  - --log-file flag used to write diagnostics to a file instead of stderr;
+    diagnostics are never written to stdout as Nagios would interpret them
+    as plugin output
+  - -V (--version) flag used to print the plugin name, semantic version
+    and VCS/build details embedded by the Go toolchain in the format
+    recommended by the plugin development guidelines
+  - --ok-as, --warning-as, --critical-as and --unknown-as flags used to
+    remap the final plugin state (e.g., --unknown-as=critical) per
+    environment; remapped results note the original state in the one-line
+    summary
+  - --sink flag (repeatable) used to emit the final result to result sinks
+    (e.g., NRDP or the Icinga 2 API) instead of plugin output so that the
+    same plugin runs in active or passive mode by configuration only
+
+# HOW TO USE
+
+	// version is set at build time via -ldflags "-X main.version=1.2.3".
+	var version string
+
+	func main() {
+		var cfg cmdline.Config
+		cfg.RegisterFlags(flag.CommandLine)
+		flag.Parse()
+
+		if cfg.PrintVersion(os.Stdout, "check_foo", version) {
+			os.Exit(nagios.StateUNKNOWNExitCode)
+		}
+
+		plugin := nagios.NewPlugin()
+		defer plugin.ReturnCheckResults()
+
+		if err := cfg.Apply(plugin); err != nil {
+			// handle error
+		}
+		defer cfg.Close()
+
+		// check logic here
+	}
+*/
+package cmdline
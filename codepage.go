@@ -0,0 +1,167 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CodePage identifies a Windows code page used to encode plugin output.
+type CodePage int
+
+// Supported CodePage values.
+const (
+	// CodePageUTF8 emits plugin output as UTF-8 (the default).
+	CodePageUTF8 CodePage = 65001
+
+	// CodePage437 is the OEM code page used by consoles in the United
+	// States.
+	CodePage437 CodePage = 437
+
+	// CodePage850 is the OEM code page used by consoles in Western Europe.
+	CodePage850 CodePage = 850
+
+	// CodePage1252 is the ANSI code page used in the Americas and Western
+	// Europe.
+	CodePage1252 CodePage = 1252
+)
+
+// ErrUnsupportedCodePage indicates that a code page other than
+// CodePageUTF8, CodePage437, CodePage850 or CodePage1252 was requested.
+var ErrUnsupportedCodePage = errors.New("unsupported code page")
+
+// codePage437 lists the characters of bytes 0x80 through 0xFF of code page
+// 437.
+const codePage437 string = "ÇüéâäàåçêëèïîìÄÅ" +
+	"ÉæÆôöòûùÿÖÜ¢£¥₧ƒ" +
+	"áíóúñÑªº¿⌐¬½¼¡«»" +
+	"░▒▓│┤╡╢╖╕╣║╗╝╜╛┐" +
+	"└┴┬├─┼╞╟╚╔╩╦╠═╬╧" +
+	"╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+	"αßΓπΣσµτΦΘΩδ∞φε∩" +
+	"≡±≥≤⌠⌡÷≈°∙·√ⁿ²■\u00a0"
+
+// codePage850 lists the characters of bytes 0x80 through 0xFF of code page
+// 850.
+const codePage850 string = "ÇüéâäàåçêëèïîìÄÅ" +
+	"ÉæÆôöòûùÿÖÜø£Ø×ƒ" +
+	"áíóúñÑªº¿®¬½¼¡«»" +
+	"░▒▓│┤ÁÂÀ©╣║╗╝¢¥┐" +
+	"└┴┬├─┼ãÃ╚╔╩╦╠═╬¤" +
+	"ðÐÊËÈıÍÎÏ┘┌█▄¦Ì▀" +
+	"ÓßÔÒõÕµþÞÚÛÙýÝ¯´" +
+	"\u00ad±‗¾¶§÷¸°¨·¹³²■\u00a0"
+
+// codePage1252 lists the characters of bytes 0x80 through 0xFF of code page
+// 1252. Bytes 0xA0 through 0xFF match ISO 8859-1; the replacement character
+// marks undefined bytes.
+const codePage1252 string = "€\ufffd‚ƒ„…†‡ˆ‰Š‹Œ\ufffdŽ\ufffd" +
+	"\ufffd‘’“”•–—˜™š›œ\ufffdžŸ" +
+	"\u00a0¡¢£¤¥¦§¨©ª«¬\u00ad®¯" +
+	"°±²³´µ¶·¸¹º»¼½¾¿" +
+	"ÀÁÂÃÄÅÆÇÈÉÊËÌÍÎÏ" +
+	"ÐÑÒÓÔÕÖ×ØÙÚÛÜÝÞß" +
+	"àáâãäåæçèéêëìíîï" +
+	"ðñòóôõö÷øùúûüýþÿ"
+
+// codePageEncoders is the collection of byte values for the non-ASCII
+// characters of each supported single-byte code page.
+var codePageEncoders = map[CodePage]map[rune]byte{
+	CodePage437:  codePageEncoder(codePage437),
+	CodePage850:  codePageEncoder(codePage850),
+	CodePage1252: codePageEncoder(codePage1252),
+}
+
+// codePageEncoder returns the byte values for the characters of bytes 0x80
+// through 0xFF of a single-byte code page.
+func codePageEncoder(chars string) map[rune]byte {
+	encoder := make(map[rune]byte, 128)
+
+	b := 0x80
+	for _, r := range chars {
+		if r != utf8.RuneError {
+			encoder[r] = byte(b)
+		}
+		b++
+	}
+
+	return encoder
+}
+
+// String provides the name of the code page.
+func (cp CodePage) String() string {
+	switch cp {
+	case CodePageUTF8:
+		return "UTF-8"
+	default:
+		return fmt.Sprintf("CP%d", int(cp))
+	}
+}
+
+// SetCodePage encodes plugin output using the given console code page
+// instead of UTF-8. This is intended for plugins launched as external
+// scripts by NSClient++ (see OutputProfileNSClient) which decode script
+// output using the console or ANSI code page of the Windows host (see
+// DetectCodePage) unless configured otherwise, producing garbage for
+// non-ASCII characters.
+//
+// Characters not present in the code page are replaced by an ASCII
+// approximation where known (as for ASCIIModeTransliterate), otherwise by
+// "?". An error wrapping ErrUnsupportedCodePage is returned if cp is not
+// one of CodePageUTF8, CodePage437, CodePage850 or CodePage1252.
+func (p *Plugin) SetCodePage(cp CodePage) error {
+	if _, ok := codePageEncoders[cp]; !ok && cp != CodePageUTF8 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCodePage, cp)
+	}
+
+	p.codePage = cp
+
+	return nil
+}
+
+// DetectCodePage returns the code page used to decode plugin output on this
+// host. On Windows this is the output code page of the attached console or
+// the ANSI code page if no console is attached. CodePageUTF8 is returned on
+// other platforms.
+//
+// The returned code page may not be supported by SetCodePage; client code
+// should fall back to ASCII output (see SetASCIIOutput) in that case.
+func DetectCodePage() CodePage {
+	return detectCodePage()
+}
+
+// encodeOutput encodes the given plugin output using the code page
+// requested via SetCodePage.
+func (p Plugin) encodeOutput(pluginOutput string) string {
+	encoder, ok := codePageEncoders[p.codePage]
+	if !ok {
+		return pluginOutput
+	}
+
+	var b strings.Builder
+	b.Grow(len(pluginOutput))
+
+	for _, r := range pluginOutput {
+		switch encoded, known := encoder[r]; {
+		case r <= unicode.MaxASCII:
+			b.WriteRune(r)
+
+		case known:
+			b.WriteByte(encoded)
+
+		default:
+			b.WriteString(toASCII(string(r), ASCIIModeTransliterate))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,16 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build !windows
+
+package nagios
+
+// detectCodePage returns CodePageUTF8 as console code pages are specific
+// to Windows.
+func detectCodePage() CodePage {
+	return CodePageUTF8
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestSetCodePageEncodesOutput asserts that plugin output is encoded using
+// the requested console code page, transliterating characters which are
+// not present in the code page.
+func TestSetCodePageEncodesOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		codePage nagios.CodePage
+		want     string
+	}{
+		"utf-8": {
+			codePage: nagios.CodePageUTF8,
+			want:     "OK: Größe 5 µs → ✓\r\n",
+		},
+		"cp437": {
+			codePage: nagios.CodePage437,
+			want:     "OK: Gr\x94\xe1e 5 \xe6s -> v\r\n",
+		},
+		"cp850": {
+			codePage: nagios.CodePage850,
+			want:     "OK: Gr\x94\xe1e 5 \xe6s -> v\r\n",
+		},
+		"cp1252": {
+			codePage: nagios.CodePage1252,
+			want:     "OK: Gr\xf6\xdfe 5 \xb5s -> v\r\n",
+		},
+	}
+
+	for name, tt := range tests {
+		var outputBuffer strings.Builder
+
+		plugin := nagios.NewPlugin(
+			nagios.WithOutputTarget(&outputBuffer),
+			nagios.WithSkipOSExit(),
+			nagios.WithOutputProfile(nagios.OutputProfileNSClient),
+		)
+
+		if err := plugin.SetCodePage(tt.codePage); err != nil {
+			t.Fatalf("%s: failed to set code page: %v", name, err)
+		}
+
+		plugin.ServiceOutput = "OK: Größe 5 µs → ✓"
+		plugin.ReturnCheckResults()
+
+		// Omit the default time metric.
+		got, _, _ := strings.Cut(outputBuffer.String(), " |")
+		got = strings.TrimSuffix(got, "\r\n") + "\r\n"
+
+		if got != tt.want {
+			t.Errorf("%s: want output %q, got %q", name, tt.want, got)
+		}
+	}
+}
+
+// TestSetCodePageRejectsUnsupportedCodePages asserts that code pages
+// without an encoder are rejected.
+func TestSetCodePageRejectsUnsupportedCodePages(t *testing.T) {
+	t.Parallel()
+
+	var plugin nagios.Plugin
+
+	err := plugin.SetCodePage(nagios.CodePage(936))
+	if !errors.Is(err, nagios.ErrUnsupportedCodePage) {
+		t.Errorf("want error %v, got %v", nagios.ErrUnsupportedCodePage, err)
+	}
+}
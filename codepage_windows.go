@@ -0,0 +1,30 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build windows
+
+package nagios
+
+import "syscall"
+
+var (
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleOutput = kernel32.NewProc("GetConsoleOutputCP")
+	procGetACP           = kernel32.NewProc("GetACP")
+)
+
+// detectCodePage returns the output code page of the attached console, or
+// the ANSI code page if no console is attached.
+func detectCodePage() CodePage {
+	if cp, _, _ := procGetConsoleOutput.Call(); cp != 0 {
+		return CodePage(cp)
+	}
+
+	cp, _, _ := procGetACP.Call()
+
+	return CodePage(cp)
+}
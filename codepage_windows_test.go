@@ -0,0 +1,74 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build windows
+
+package nagios_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestDetectCodePageReportsWindowsCodePage asserts that a code page is
+// reported by the Windows API.
+func TestDetectCodePageReportsWindowsCodePage(t *testing.T) {
+	t.Parallel()
+
+	if cp := nagios.DetectCodePage(); cp <= 0 {
+		t.Errorf("want a Windows code page, got %v", cp)
+	}
+}
+
+// TestExitCodePropagatesOnWindows asserts that the plugin exit code and
+// CRLF output are seen by the parent process, as when the plugin is
+// launched as an NSClient++ external script.
+func TestExitCodePropagatesOnWindows(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv("GO_NAGIOS_HELPER_PROCESS") == "1" {
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcessEmitsCritical$")
+	cmd.Env = append(os.Environ(), "GO_NAGIOS_HELPER_PROCESS=1")
+
+	output, err := cmd.Output()
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("want exit error, got %v (output %q)", err, output)
+	}
+
+	if code := exitErr.ExitCode(); code != nagios.StateCRITICALExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateCRITICALExitCode, code)
+	}
+
+	got := string(output)
+	if !strings.HasPrefix(got, "CRITICAL: disk full") ||
+		!strings.HasSuffix(got, "\r\n") ||
+		strings.Count(got, "\n") != strings.Count(got, "\r\n") {
+		t.Errorf("want CRLF terminated output, got %q", got)
+	}
+}
+
+// TestHelperProcessEmitsCritical is run as a child process by
+// TestExitCodePropagatesOnWindows.
+func TestHelperProcessEmitsCritical(t *testing.T) {
+	if os.Getenv("GO_NAGIOS_HELPER_PROCESS") != "1" {
+		t.Skip("helper process")
+	}
+
+	plugin := nagios.NewPlugin(nagios.WithOutputProfile(nagios.OutputProfileNSClient))
+	defer plugin.ReturnCheckResults()
+
+	plugin.Critical("disk full")
+}
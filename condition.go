@@ -0,0 +1,422 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidCondition indicates that a condition expression could not be
+// parsed.
+var ErrInvalidCondition = errors.New("invalid condition")
+
+// Condition is a boolean expression over collected metrics, such as:
+//
+//	used_pct > 85 && inodes_pct > 90
+//	(load1 >= 10 || load5 >= 8) && !(cpu_count > 16)
+//
+// Operands are metric names (performance data labels) or numbers compared
+// using the <, <=, >, >=, == and != operators. Comparisons are combined
+// using the && (and), || (or) and ! (not) operators and parentheses; &&
+// binds more tightly than ||.
+//
+// A Condition is created using ParseCondition.
+type Condition struct {
+	text string
+	root conditionNode
+}
+
+// conditionNode is a node of a parsed Condition.
+type conditionNode interface {
+	eval(values map[string]float64) (bool, error)
+}
+
+// ParseCondition parses the given condition expression. An error wrapping
+// ErrInvalidCondition is returned if the expression cannot be parsed.
+func ParseCondition(s string) (*Condition, error) {
+	tokens, err := tokenizeCondition(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCondition, s, err)
+	}
+
+	cp := conditionParser{tokens: tokens}
+
+	root, err := cp.parseOr()
+	if err == nil && cp.pos < len(cp.tokens) {
+		err = fmt.Errorf("unexpected %q", cp.tokens[cp.pos])
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCondition, s, err)
+	}
+
+	return &Condition{text: strings.TrimSpace(s), root: root}, nil
+}
+
+// String provides the condition expression as given.
+func (c Condition) String() string {
+	return c.text
+}
+
+// Evaluate evaluates the condition against the given metric values. Metric
+// names are matched exactly or, failing that, case-insensitively. An error
+// wrapping ErrMissingMetric is returned if a referenced metric is not
+// present.
+func (c Condition) Evaluate(values map[string]float64) (bool, error) {
+	return c.root.eval(values)
+}
+
+// EvaluateConditions evaluates the given critical and warning conditions
+// (in that order; either may be nil) against the collected performance data
+// and returns the matching exit status code. StateOKExitCode is returned if
+// neither condition is met.
+//
+// The condition which was met is described in LongServiceOutput. A
+// condition which cannot be evaluated (e.g., referencing a missing metric)
+// is recorded as an error and results in an UNKNOWN state.
+func (p *Plugin) EvaluateConditions(warning *Condition, critical *Condition) int {
+	values := p.perfDataValues()
+
+	for _, condition := range []struct {
+		condition *Condition
+		state     int
+	}{
+		{condition: critical, state: StateCRITICALExitCode},
+		{condition: warning, state: StateWARNINGExitCode},
+	} {
+		if condition.condition == nil {
+			continue
+		}
+
+		met, err := condition.condition.Evaluate(values)
+		switch {
+		case err != nil:
+			p.AddError(fmt.Errorf("failed to evaluate %s condition: %w", strings.ToLower(stateLabel(condition.state)), err))
+			return StateUNKNOWNExitCode
+		case met:
+			p.WithDetail(fmt.Sprintf("%s condition met: %s", stateLabel(condition.state), condition.condition))
+			return condition.state
+		}
+	}
+
+	return StateOKExitCode
+}
+
+// tokenizeCondition splits a condition expression into tokens.
+func tokenizeCondition(s string) ([]string, error) {
+	var tokens []string
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+
+		case strings.ContainsRune("<>=!&|", r):
+			op := string(r)
+			if i+1 < len(runes) {
+				if pair := string(runes[i : i+2]); isConditionOperator(pair) {
+					op = pair
+				}
+			}
+
+			if !isConditionOperator(op) {
+				return nil, fmt.Errorf("unknown operator %q", op)
+			}
+
+			tokens = append(tokens, op)
+			i += len(op)
+
+		case isConditionOperandRune(r) || r == '-':
+			j := i + 1
+			for j < len(runes) && isConditionOperandRune(runes[j]) {
+				j++
+			}
+
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+
+	return tokens, nil
+}
+
+// isConditionOperator indicates whether the given text is a supported
+// condition operator.
+func isConditionOperator(op string) bool {
+	switch op {
+	case "<", "<=", ">", ">=", "==", "!=", "&&", "||", "!":
+		return true
+	default:
+		return false
+	}
+}
+
+// isConditionOperandRune indicates whether the given rune may be used within
+// a metric name or number.
+func isConditionOperandRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+// conditionParser is a recursive descent parser for condition expressions.
+type conditionParser struct {
+	tokens []string
+	pos    int
+}
+
+// peek returns the current token or an empty string at the end of input.
+func (cp *conditionParser) peek() string {
+	if cp.pos < len(cp.tokens) {
+		return cp.tokens[cp.pos]
+	}
+
+	return ""
+}
+
+// parseOr parses a sequence of && expressions separated by ||.
+func (cp *conditionParser) parseOr() (conditionNode, error) {
+	left, err := cp.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for cp.peek() == "||" {
+		cp.pos++
+
+		right, err := cp.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = conditionOr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses a sequence of unary expressions separated by &&.
+func (cp *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := cp.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for cp.peek() == "&&" {
+		cp.pos++
+
+		right, err := cp.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = conditionAnd{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseUnary parses a negation, a parenthesized expression or a
+// comparison.
+func (cp *conditionParser) parseUnary() (conditionNode, error) {
+	switch cp.peek() {
+	case "!":
+		cp.pos++
+
+		operand, err := cp.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return conditionNot{operand: operand}, nil
+
+	case "(":
+		cp.pos++
+
+		node, err := cp.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if cp.peek() != ")" {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		cp.pos++
+
+		return node, nil
+	}
+
+	return cp.parseComparison()
+}
+
+// parseComparison parses a comparison between two operands.
+func (cp *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := cp.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	op := cp.peek()
+	switch op {
+	case "<", "<=", ">", ">=", "==", "!=":
+		cp.pos++
+	case "":
+		return nil, fmt.Errorf("missing comparison after %q", left)
+	default:
+		return nil, fmt.Errorf("expected comparison operator, got %q", op)
+	}
+
+	right, err := cp.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return conditionComparison{left: left, op: op, right: right}, nil
+}
+
+// parseOperand parses a metric name or number.
+func (cp *conditionParser) parseOperand() (conditionOperand, error) {
+	token := cp.peek()
+	if token == "" {
+		return conditionOperand{}, errors.New("unexpected end of condition")
+	}
+
+	first := []rune(token)[0]
+	if !isConditionOperandRune(first) && first != '-' {
+		return conditionOperand{}, fmt.Errorf("expected metric or number, got %q", token)
+	}
+
+	cp.pos++
+
+	if value, err := strconv.ParseFloat(token, 64); err == nil {
+		return conditionOperand{text: token, value: value, literal: true}, nil
+	}
+
+	if first == '-' || unicode.IsDigit(first) {
+		return conditionOperand{}, fmt.Errorf("invalid number %q", token)
+	}
+
+	return conditionOperand{text: token}, nil
+}
+
+// conditionOperand is a metric name or numeric literal.
+type conditionOperand struct {
+	text    string
+	value   float64
+	literal bool
+}
+
+func (o conditionOperand) String() string {
+	return o.text
+}
+
+// resolve returns the value of the operand.
+func (o conditionOperand) resolve(values map[string]float64) (float64, error) {
+	if o.literal {
+		return o.value, nil
+	}
+
+	if value, ok := values[o.text]; ok {
+		return value, nil
+	}
+
+	for name, value := range values {
+		if strings.EqualFold(name, o.text) {
+			return value, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrMissingMetric, o.text)
+}
+
+// conditionComparison compares two operands.
+type conditionComparison struct {
+	left  conditionOperand
+	op    string
+	right conditionOperand
+}
+
+func (c conditionComparison) eval(values map[string]float64) (bool, error) {
+	left, err := c.left.resolve(values)
+	if err != nil {
+		return false, err
+	}
+
+	right, err := c.right.resolve(values)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.op {
+	case "<":
+		return left < right, nil
+	case "<=":
+		return left <= right, nil
+	case ">":
+		return left > right, nil
+	case ">=":
+		return left >= right, nil
+	case "==":
+		return left == right, nil
+	default:
+		return left != right, nil
+	}
+}
+
+// conditionAnd is met if both operands are met.
+type conditionAnd struct {
+	left  conditionNode
+	right conditionNode
+}
+
+func (c conditionAnd) eval(values map[string]float64) (bool, error) {
+	left, err := c.left.eval(values)
+	if err != nil || !left {
+		return false, err
+	}
+
+	return c.right.eval(values)
+}
+
+// conditionOr is met if either operand is met.
+type conditionOr struct {
+	left  conditionNode
+	right conditionNode
+}
+
+func (c conditionOr) eval(values map[string]float64) (bool, error) {
+	left, err := c.left.eval(values)
+	if err != nil || left {
+		return left, err
+	}
+
+	return c.right.eval(values)
+}
+
+// conditionNot is met if its operand is not met.
+type conditionNot struct {
+	operand conditionNode
+}
+
+func (c conditionNot) eval(values map[string]float64) (bool, error) {
+	met, err := c.operand.eval(values)
+
+	return !met && err == nil, err
+}
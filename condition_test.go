@@ -0,0 +1,124 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestConditionEvaluate asserts that condition expressions are evaluated
+// using the expected operator precedence.
+func TestConditionEvaluate(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]float64{
+		"used_pct":   88,
+		"inodes_pct": 92,
+		"Load1":      4.5,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "used_pct > 85 && inodes_pct > 90", want: true},
+		{expr: "used_pct > 90 && inodes_pct > 90", want: false},
+		{expr: "used_pct > 90 || inodes_pct >= 92", want: true},
+		{expr: "used_pct > 90 || inodes_pct > 90 && load1 < 1", want: false},
+		{expr: "(used_pct > 90 || inodes_pct > 90) && !(load1 >= 5)", want: true},
+		{expr: "load1 != 4.5", want: false},
+		{expr: "-1 < used_pct", want: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := nagios.ParseCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := c.Evaluate(values)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %t, got %t", tt.want, got)
+			}
+		})
+	}
+
+	c, _ := nagios.ParseCondition("swap_pct > 50")
+	if _, err := c.Evaluate(values); !errors.Is(err, nagios.ErrMissingMetric) {
+		t.Errorf("want missing metric error, got %v", err)
+	}
+}
+
+// TestParseConditionRejectsInvalidExpressions asserts that malformed
+// expressions are rejected.
+func TestParseConditionRejectsInvalidExpressions(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"",
+		"used_pct",
+		"used_pct > ",
+		"used_pct = 85",
+		"(used_pct > 85",
+		"used_pct > 85 &&",
+		"used_pct > 85 inodes_pct",
+		"used_pct > 8x5",
+		"used_pct > $1",
+	} {
+		if _, err := nagios.ParseCondition(expr); !errors.Is(err, nagios.ErrInvalidCondition) {
+			t.Errorf("want error for %q, got %v", expr, err)
+		}
+	}
+}
+
+// TestEvaluateConditions asserts that conditions are evaluated against the
+// collected performance data and that the met condition is described.
+func TestEvaluateConditions(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "used_pct", Value: "88", UnitOfMeasurement: "%"},
+		nagios.PerformanceData{Label: "inodes_pct", Value: "72", UnitOfMeasurement: "%"},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	warning, _ := nagios.ParseCondition("used_pct > 85 || inodes_pct > 85")
+	critical, _ := nagios.ParseCondition("used_pct > 85 && inodes_pct > 90")
+
+	if got := plugin.EvaluateConditions(warning, critical); got != nagios.StateWARNINGExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateWARNINGExitCode, got)
+	}
+
+	plugin.ServiceOutput = "disk usage high"
+	plugin.ReturnCheckResults()
+
+	if got, want := outputBuffer.String(), "WARNING condition met: used_pct > 85 || inodes_pct > 85"; !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
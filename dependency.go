@@ -0,0 +1,78 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDependency indicates that a sub-check depends on an unknown
+// sub-check or on itself (directly or via a dependency cycle).
+var ErrInvalidDependency = errors.New("invalid sub-check dependency")
+
+// resolveDependencies resolves the dependencies of each of the given checks
+// (by name) to indexes within the collection. An error wrapping
+// ErrInvalidDependency is returned for each check which depends on an
+// unknown check or which is part of (or depends on) a dependency cycle.
+func resolveDependencies(checks []NamedCheck) ([][]int, []error) {
+	indexes := make(map[string]int, len(checks))
+	for i := len(checks) - 1; i >= 0; i-- {
+		indexes[checks[i].Name] = i
+	}
+
+	dependencies := make([][]int, len(checks))
+	errs := make([]error, len(checks))
+
+	// Number of unresolved dependencies and reverse edges used to order the
+	// checks (Kahn's algorithm); checks left unordered are part of (or
+	// depend on) a cycle.
+	pending := make([]int, len(checks))
+	dependents := make([][]int, len(checks))
+
+	for i, check := range checks {
+		for _, name := range check.DependsOn {
+			dependency, ok := indexes[name]
+			if !ok {
+				errs[i] = fmt.Errorf("%w: %s depends on unknown check %s", ErrInvalidDependency, check.Name, name)
+				continue
+			}
+
+			dependencies[i] = append(dependencies[i], dependency)
+			dependents[dependency] = append(dependents[dependency], i)
+			pending[i]++
+		}
+	}
+
+	ready := make([]int, 0, len(checks))
+	for i := range checks {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+
+		for _, dependent := range dependents[i] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	for i, check := range checks {
+		if pending[i] > 0 && errs[i] == nil {
+			errs[i] = fmt.Errorf("%w: %s is part of (or depends on) a dependency cycle", ErrInvalidDependency, check.Name)
+		}
+	}
+
+	return dependencies, errs
+}
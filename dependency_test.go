@@ -0,0 +1,110 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRunChecksHonorsDependencies asserts that sub-checks run only after
+// their dependencies are OK and are skipped as DEPENDENT otherwise.
+func TestRunChecksHonorsDependencies(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var order []string
+
+	probe := func(name string, exitCode int) func(context.Context) nagios.CheckResult {
+		return func(context.Context) nagios.CheckResult {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+
+			return nagios.CheckResult{ExitCode: exitCode}
+		}
+	}
+
+	checks := []nagios.NamedCheck{
+		{Name: "logout", DependsOn: []string{"query"}, Check: probe("logout", nagios.StateOKExitCode)},
+		{Name: "query", DependsOn: []string{"auth"}, Check: probe("query", nagios.StateCRITICALExitCode)},
+		{Name: "auth", Check: probe("auth", nagios.StateOKExitCode)},
+		{Name: "report", DependsOn: []string{"auth"}, Check: probe("report", nagios.StateOKExitCode)},
+	}
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	results := plugin.RunChecks(checks, 0, 0)
+
+	if len(order) != 3 || order[0] != "auth" {
+		t.Errorf("want auth run first and logout skipped, got %v", order)
+	}
+
+	if results[0].ExitCode != nagios.StateDEPENDENTExitCode {
+		t.Errorf("want logout skipped as DEPENDENT, got %+v", results[0])
+	}
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want rollup state %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"CRITICAL: 1 critical, 1 dependent, 2 ok",
+		"* logout: DEPENDENT - skipped, depends on query (CRITICAL)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestRunChecksRejectsInvalidDependencies asserts that sub-checks with
+// unknown or cyclic dependencies are reported instead of being run.
+func TestRunChecksRejectsInvalidDependencies(t *testing.T) {
+	t.Parallel()
+
+	ok := func(context.Context) nagios.CheckResult {
+		return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+	}
+
+	checks := []nagios.NamedCheck{
+		{Name: "a", DependsOn: []string{"b"}, Check: ok},
+		{Name: "b", DependsOn: []string{"a"}, Check: ok},
+		{Name: "c", DependsOn: []string{"missing"}, Check: ok},
+		{Name: "d", Check: ok},
+	}
+
+	plugin := nagios.Plugin{}
+
+	results := plugin.RunChecks(checks, 0, 0)
+
+	for _, result := range results[:3] {
+		if !errors.Is(result.Err, nagios.ErrInvalidDependency) {
+			t.Errorf("want invalid dependency reported for %s, got %+v", result.Name, result)
+		}
+	}
+
+	if results[3].ExitCode != nagios.StateOKExitCode {
+		t.Errorf("want d OK, got %+v", results[3])
+	}
+}
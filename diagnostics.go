@@ -0,0 +1,140 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// defaultDiagnosticsLabel is the header text for the diagnostics section.
+const defaultDiagnosticsLabel string = "DIAGNOSTICS"
+
+// diagnostic is a single name/value entry in the diagnostics section.
+type diagnostic struct {
+	name  string
+	value string
+}
+
+// WithDiagnostics is an Option used to enable the diagnostics section. See
+// also EnableDiagnostics.
+func WithDiagnostics() Option {
+	return func(p *Plugin) {
+		p.EnableDiagnostics()
+	}
+}
+
+// EnableDiagnostics enables an opt-in diagnostics section in
+// LongServiceOutput summarizing Go runtime memory statistics, garbage
+// collection activity and build information along with any entries
+// recorded via AddDiagnostic (and environment variables captured via
+// EnableEnvironmentCapture). This is intended to be enabled via a debug
+// flag to help diagnose plugins that grow slow or memory hungry over time.
+func (p *Plugin) EnableDiagnostics() {
+	p.diagnosticsEnabled = true
+}
+
+// AddDiagnostic records a named value for display in the diagnostics
+// section. Entries are displayed in the order recorded and only if the
+// diagnostics section is enabled.
+func (p *Plugin) AddDiagnostic(name string, value interface{}) {
+	p.diagnostics = append(p.diagnostics, diagnostic{
+		name:  name,
+		value: fmt.Sprint(value),
+	})
+}
+
+// handleDiagnosticsSection is a wrapper around the logic used to
+// handle/process the Diagnostics section header and listing.
+func (p Plugin) handleDiagnosticsSection(w io.Writer) {
+	if !p.diagnosticsEnabled {
+		return
+	}
+
+	fmt.Fprintf(w,
+		"%s%s%s%s",
+		CheckOutputEOL,
+		p.sectionLabel(p.message(MessageDiagnosticsLabel, defaultDiagnosticsLabel)),
+		CheckOutputEOL,
+		CheckOutputEOL,
+	)
+
+	for _, entry := range p.runtimeDiagnostics() {
+		fmt.Fprintf(w, "%s%s", p.listItem("%s: %s", entry.name, entry.value), CheckOutputEOL)
+	}
+
+	for _, entry := range p.capturedEnvironment() {
+		fmt.Fprintf(w, "%s%s", p.listItem("%s: %s", entry.name, entry.value), CheckOutputEOL)
+	}
+
+	for _, entry := range p.diagnostics {
+		fmt.Fprintf(w, "%s%s", p.listItem("%s: %s", entry.name, entry.value), CheckOutputEOL)
+	}
+}
+
+// runtimeDiagnostics collects Go runtime and build information.
+func (p Plugin) runtimeDiagnostics() []diagnostic {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	entries := []diagnostic{
+		{name: "Go version", value: runtime.Version()},
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		entries = append(entries, diagnostic{
+			name:  "Main module",
+			value: fmt.Sprintf("%s %s", buildInfo.Main.Path, buildInfo.Main.Version),
+		})
+	}
+
+	if !p.start.IsZero() {
+		entries = append(entries, diagnostic{
+			name:  "Runtime",
+			value: time.Since(p.start).Round(time.Millisecond).String(),
+		})
+	}
+
+	var lastPause time.Duration
+	if memStats.NumGC > 0 {
+		lastPause = time.Duration(memStats.PauseNs[(memStats.NumGC+255)%256])
+	}
+
+	entries = append(entries,
+		diagnostic{name: "Goroutines", value: fmt.Sprint(runtime.NumGoroutine())},
+		diagnostic{name: "Heap in use", value: formatBytes(memStats.HeapInuse)},
+		diagnostic{name: "Heap allocated", value: formatBytes(memStats.HeapAlloc)},
+		diagnostic{name: "Total allocated", value: formatBytes(memStats.TotalAlloc)},
+		diagnostic{name: "System memory", value: formatBytes(memStats.Sys)},
+		diagnostic{
+			name:  "GC cycles",
+			value: fmt.Sprintf("%d (total pause %v, last pause %v)", memStats.NumGC, time.Duration(memStats.PauseTotalNs), lastPause),
+		},
+	)
+
+	return entries
+}
+
+// formatBytes formats the given number of bytes using binary units.
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
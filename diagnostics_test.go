@@ -0,0 +1,61 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestDiagnosticsSectionIsOptIn asserts that the diagnostics section is
+// only emitted when enabled and that it includes runtime details and
+// client code entries.
+func TestDiagnosticsSectionIsOptIn(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{false, true} {
+		plugin := nagios.Plugin{}
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		if enabled {
+			plugin.EnableDiagnostics()
+		}
+
+		plugin.AddDiagnostic("Cache entries", 42)
+		plugin.OK("all good")
+		plugin.ReturnCheckResults()
+
+		got := outputBuffer.String()
+
+		if !enabled {
+			if strings.Contains(got, "**DIAGNOSTICS**") {
+				t.Errorf("want diagnostics section omitted, got %q", got)
+			}
+			continue
+		}
+
+		for _, want := range []string{
+			"OK: all good" + nagios.CheckOutputEOL + "**DIAGNOSTICS**" + nagios.CheckOutputEOL,
+			"* Go version: go",
+			"* Heap allocated: ",
+			"* GC cycles: ",
+			"* Cache entries: 42" + nagios.CheckOutputEOL,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("want output containing %q, got %q", want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Defaults used when rendering a diff in LongServiceOutput.
+const (
+	// DefaultDiffContextLines is the number of unchanged lines shown around
+	// each change.
+	DefaultDiffContextLines int = 3
+
+	// DefaultDiffMaxLines is the maximum number of diff lines shown in
+	// LongServiceOutput if not specified by client code.
+	DefaultDiffMaxLines int = 100
+
+	// DefaultDiffMaxLineWidth is the maximum number of characters shown for
+	// each diff line; longer lines are truncated.
+	DefaultDiffMaxLineWidth int = 200
+)
+
+// maxDiffCells is the largest number of line comparisons made when
+// computing a diff. Larger inputs are diffed by replacing the changed
+// region as a whole to bound memory use.
+const maxDiffCells int = 4_000_000
+
+// Unified diff line prefixes.
+const (
+	diffContext byte = ' '
+	diffDelete  byte = '-'
+	diffInsert  byte = '+'
+)
+
+// diffOp is a single line of a line-based diff.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// UnifiedDiff returns a unified diff of the given expected and actual
+// content, labeled with the given names (e.g., "expected" and "actual") and
+// showing the given number of unchanged lines around each change. An empty
+// string is returned if the content is identical.
+func UnifiedDiff(expectedName string, actualName string, expected string, actual string, contextLines int) string {
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	ops := diffLines(splitLines(expected), splitLines(actual))
+
+	var changed bool
+	for _, op := range ops {
+		if op.kind != diffContext {
+			changed = true
+			break
+		}
+	}
+
+	if !changed {
+		return ""
+	}
+
+	// Track the number of expected and actual lines preceding each op.
+	aBefore := make([]int, len(ops)+1)
+	bBefore := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aBefore[i+1], bBefore[i+1] = aBefore[i], bBefore[i]
+		if op.kind != diffInsert {
+			aBefore[i+1]++
+		}
+		if op.kind != diffDelete {
+			bBefore[i+1]++
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", expectedName, actualName)
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == diffContext {
+			i++
+			continue
+		}
+
+		// Extend the hunk while changes are close enough for their context
+		// lines to overlap.
+		start := max(0, i-contextLines)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != diffContext {
+				end = j
+			} else if j-end > 2*contextLines {
+				break
+			}
+		}
+		stop := min(len(ops), end+contextLines+1)
+
+		fmt.Fprintf(&b,
+			"@@ -%s +%s @@\n",
+			hunkRange(aBefore[start], aBefore[stop]-aBefore[start]),
+			hunkRange(bBefore[start], bBefore[stop]-bBefore[start]),
+		)
+
+		for _, op := range ops[start:stop] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+
+		i = stop
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// hunkRange formats the range of a hunk given the number of preceding lines
+// and the number of lines in the hunk, following the GNU diff conventions.
+func hunkRange(before int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprint(before + 1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
+
+// splitLines splits the given content into lines, ignoring a trailing
+// newline and CR characters of CRLF line endings.
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// diffLines computes a line-based diff of a and b using the longest common
+// subsequence of the lines between their common prefix and suffix.
+func diffLines(a []string, b []string) []diffOp {
+	var prefix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	var suffix int
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: diffContext, line: line})
+	}
+
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: diffContext, line: line})
+	}
+
+	return ops
+}
+
+// diffMiddle computes a line-based diff of a and b, which are expected to
+// differ in their first and last lines.
+func diffMiddle(a []string, b []string) []diffOp {
+	n, m := len(a), len(b)
+
+	ops := make([]diffOp, 0, n+m)
+
+	if (n+1)*(m+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{kind: diffDelete, line: line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{kind: diffInsert, line: line})
+		}
+
+		return ops
+	}
+
+	// lcs[i*(m+1)+j] is the length of the longest common subsequence of
+	// a[i:] and b[j:].
+	lcs := make([]int, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			default:
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: diffContext, line: a[i]})
+			i++
+			j++
+		case j == m || (i < n && lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]):
+			ops = append(ops, diffOp{kind: diffDelete, line: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: diffInsert, line: b[j]})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// WithDiff appends a unified diff (see UnifiedDiff) of the given expected
+// and actual content to LongServiceOutput, wrapped in a fenced code block
+// if Markdown is enabled (see SetMarkdown). Nothing is appended if the
+// content is identical.
+//
+// The diff is capped at maxLines lines (DefaultDiffMaxLines if maxLines is
+// less than 1) with a note of the number of omitted lines, and each line is
+// capped at DefaultDiffMaxLineWidth characters. Control characters are
+// removed and Markdown code fences within the content are neutralized so
+// that the diff displays readably in the web UI and notifications. The
+// receiver is returned to allow chaining further calls.
+func (p *Plugin) WithDiff(expectedName string, actualName string, expected string, actual string, maxLines int) *Plugin {
+	diff := UnifiedDiff(expectedName, actualName, expected, actual, DefaultDiffContextLines)
+	if diff == "" {
+		return p
+	}
+
+	if maxLines < 1 {
+		maxLines = DefaultDiffMaxLines
+	}
+
+	lines := strings.Split(diff, "\n")
+
+	var omitted int
+	if len(lines) > maxLines {
+		omitted = len(lines) - maxLines
+		lines = lines[:maxLines]
+	}
+
+	for i, line := range lines {
+		lines[i] = sanitizeDiffLine(line)
+	}
+
+	if omitted > 0 {
+		lines = append(lines, fmt.Sprintf("[%d more diff lines omitted]", omitted))
+	}
+
+	return p.WithDetail(p.codeBlock(lines...))
+}
+
+// sanitizeDiffLine removes control characters from the given diff line,
+// neutralizes Markdown code fences and truncates the line to
+// DefaultDiffMaxLineWidth characters.
+func sanitizeDiffLine(line string) string {
+	line = strings.ReplaceAll(stripControlChars(line), markdownFence, "'''")
+
+	if runes := []rune(line); len(runes) > DefaultDiffMaxLineWidth {
+		line = string(runes[:DefaultDiffMaxLineWidth]) + "..."
+	}
+
+	return line
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestUnifiedDiff asserts that changes are rendered as unified diff hunks
+// with the requested number of context lines.
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	expected := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	actual := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+
+	want := strings.Join([]string{
+		"--- /etc/ntp.conf (expected)",
+		"+++ /etc/ntp.conf (actual)",
+		"@@ -1,3 +1,3 @@",
+		" a",
+		"-b",
+		"+B",
+		" c",
+		"@@ -10 +10,2 @@",
+		" j",
+		"+k",
+	}, "\n")
+
+	got := nagios.UnifiedDiff("/etc/ntp.conf (expected)", "/etc/ntp.conf (actual)", expected, actual, 1)
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if got := nagios.UnifiedDiff("expected", "actual", "a\nb\n", "a\r\nb\r\n", 3); got != "" {
+		t.Errorf("want no diff for identical content, got %q", got)
+	}
+}
+
+// TestWithDiffCapsAndSanitizesOutput asserts that diffs appended to
+// LongServiceOutput are capped and sanitized.
+func TestWithDiffCapsAndSanitizesOutput(t *testing.T) {
+	t.Parallel()
+
+	var expected, actual strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&expected, "server%02d.example.com\n", i)
+	}
+	actual.WriteString("```\n\x1b[31mrogue\x1b[0m\n")
+
+	plugin := nagios.Plugin{}
+	plugin.WithDiff("expected", "actual", expected.String(), actual.String(), 10)
+
+	got := plugin.LongServiceOutput
+
+	for _, want := range []string{
+		"```" + nagios.CheckOutputEOL + "--- expected",
+		"-server01.example.com",
+		"[15 more diff lines omitted]" + nagios.CheckOutputEOL + "```",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	for _, unwanted := range []string{"+```", "\x1b", "server10.example.com"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("want output without %q, got:\n%s", unwanted, got)
+		}
+	}
+}
@@ -24,8 +24,233 @@ inclusion into the project.
     results displayed in web UI, email notifications
   - Plugin type with ReturnCheckResults method used to process and return
     all applicable check results to Nagios for further processing/display
+  - Plugin methods (e.g., SetSummary, SetState, AddPerfData, AddError) used
+    to record check results without directly modifying fields; the ExitState
+    type and New constructor remain as deprecated aliases
+  - Chainable result methods (e.g., OK, Critical, WithPerfData, WithDetail)
+    used to express simple plugin results in a single statement
+  - Exit helpers (e.g., ExitCritical) used to record a result and end
+    plugin execution early while still running deferred functions
+  - StateError type used to record errors which carry a plugin state; the
+    most severe state determines the final plugin state and is attributed
+    in the Errors section
+  - ParseServiceState function used to extract the leading state label
+    from plugin output (e.g., in wrapper plugins)
+  - Range type used to parse, evaluate and display threshold ranges in the
+    guideline format; typed threshold ranges drive both the Thresholds
+    section and state evaluation
+  - Aggregation expressions (And, Or, AtLeast) used to derive the plugin
+    state from sub-results (e.g., CRITICAL only if 2 of 3 replicas are
+    down), with the evaluated expression shown in LongServiceOutput
+  - Merge method used to combine independently built results (e.g., a
+    library check plus plugin specific checks) using the most severe state,
+    with configurable joining of one-line summaries (see SetSummaryJoin)
+  - Clone method used to snapshot the plugin state (e.g., for intermediate
+    reporting or "what-if" evaluations) without modifying the live result
+  - Stable, versioned JSON representation of the complete result (see
+    Result, ImportResult and the Plugin MarshalJSON/UnmarshalJSON methods)
+    used to pass results from check workers to a parent process
+  - Protocol buffer schema and codec for check results (see the resultpb
+    package) used to transport results from remote Go agents to a central
+    forwarder
+  - gRPC remote check execution service (see the checkrpc package) with a
+    reference server hosting registered Go checks, as a modern alternative
+    to NRPE
+  - Sink interface used to emit the final result to destinations selected
+    at runtime (see SetSinks and ParseSink), with built-in plugin output and
+    webhook sinks plus NSCA, NRDP, command file and Icinga 2 API sinks
+    provided by the passive and icinga2 packages
+  - Before and after emit hooks used to inspect or modify the result just
+    before rendering (e.g., redaction, tagging) and to observe the final
+    result once emitted (e.g., metrics) without changing each plugin
+  - OutputFilter interface used to transform rendered plugin output in the
+    order registered, with built-in sanitization, truncation and redaction
+    filters
+  - PerfDataEncoder interface used to select the representation of
+    performance data by name, with built-in classic Nagios, JSON,
+    OpenMetrics and InfluxDB line protocol encoders
+  - Quorum helper replicating check_cluster semantics: threshold ranges are
+    evaluated against the number of non-OK members
+  - RunChecks method used to run independent sub-checks (e.g., 50
+    endpoints) concurrently with a concurrency limit and timeout, merging
+    their performance data with name prefixes and computing the rollup state
+  - Auto-generated summary for sub-check results (e.g., "CRITICAL: 2
+    critical, 1 warning, 47 ok") with non-OK sub-checks listed first in
+    LongServiceOutput
+  - Per sub-check timeouts (NamedCheck.Timeout) so that one stuck backend
+    is reported (UNKNOWN by default, see SetCheckTimeoutState) along with
+    its elapsed time without masking the results of the other sub-checks
+  - Rate limit (SetCheckRateLimit) for sub-checks run by RunChecks, with
+    the effective rate reported in the diagnostics section
+  - Dependencies between sub-checks run by RunChecks (NamedCheck.DependsOn)
+    with skipped sub-checks reported as DEPENDENT
+  - Fail-fast mode (EnableFailFast) used to cancel outstanding sub-checks
+    run by RunChecks once a sub-check reports CRITICAL
+  - Progress reporter (StartProgress, StartProgressFile) used to write a
+    periodic heartbeat (phase, targets done) to stderr or a status file
+  - Overall plugin timeout (SetTimeout) with optional reporting of the
+    partial results gathered by RunChecks when the timeout expires
+  - TimeRemaining method used by collectors to adapt as the plugin timeout
+    approaches
+  - StreamResults method used to consume sub-check results incrementally
+    from collectors so that very large jobs need not hold all results in
+    memory
+  - Group type (similar to errgroup) used to run tasks concurrently, with
+    task errors, panics and durations recorded by the plugin
+  - Negate method used to remap the state of an inner check (like the
+    negate utility), with support for custom mappings and a timeout state
+  - RunExternalPlugin function used to run an external plugin with a
+    timeout and parse its output (ParsePluginOutput, ParsePerfData) so that
+    the result can be augmented and re-emitted by wrapper plugins
+  - NSClient++ NRPE and REST API clients (see the nsclient package)
+    returning results in the same form as RunExternalPlugin for
+    Windows-focused checks driving NSCP agents
+  - Optional structured diagnostics via log/slog (see WithLogger and
+    NewLogHandler); diagnostics are written to stderr or a file and never
+    to plugin output
+  - Opt-in diagnostics section summarizing Go runtime memory statistics,
+    garbage collection activity and build information (see
+    EnableDiagnostics)
+  - Opt-in environment section (command line, plugin version, Go version,
+    hostname) emitted only at the highest verbosity level (SetVerbosity)
+    to make plugin bug reports self-contained
+  - Privilege-drop helper (see RunPrivileged) running a privileged
+    operation (e.g., opening a raw socket) and then permanently dropping
+    root privileges, recording the transitions in the diagnostics section
+  - Opt-in capture of allowlisted environment variables (with secrets
+    redacted) in the diagnostics section to troubleshoot plugins behaving
+    differently under the scheduler than in a shell (see
+    EnableEnvironmentCapture)
+  - Version details (plugin name, semantic version and VCS/build details
+    from debug.ReadBuildInfo) for the -V (--version) flag in the format
+    recommended by the plugin guidelines (see ReadVersionInfo and the
+    cmdline package)
+  - Unified diff helper (UnifiedDiff, WithDiff) with size capping and
+    sanitization for configuration drift plugins showing expected vs actual
+    content in LongServiceOutput
+  - Host context section (hostname, OS, kernel, container and
+    virtualization detection) for fleet-wide plugins running on
+    heterogeneous nodes (see EnableHostContext and GatherHostContext)
+  - Optional footer line noting the check start time, duration and
+    scheduled check interval (from environment macros) formatted per
+    output profile
+  - Optional mirroring of the final state and summary to syslog with a
+    configurable facility and per-state severity mapping (see EnableSyslog)
+  - Optional structured logging of each run to the systemd journal on
+    Linux (STATE, SERVICE, EXIT_CODE and DURATION fields; see
+    EnableJournald)
+  - Translation of invalid exit codes (outside of 0-4) to UNKNOWN with an
+    explanatory error
+  - Threshold setters which reject invalid range syntax immediately
+  - Validation that ServiceOutput is a single line, with optional folding of
+    embedded newlines into spaces
+  - Performance data label validation with optional sanitization (equals
+    signs, quotes, leading digits) and warnings for labels exceeding the
+    19 character RRD uniqueness window
+  - Validation that performance data Value, Min, Max, Warn and Crit fields
+    use a consistent unit of measurement
+  - Optional strict mode converting output validation failures (invalid
+    performance data, oversize output, state label not matching the exit
+    code) into an UNKNOWN result with an explanation
+  - Per-plugin output profiles (Nagios Core, Nagios XI, Icinga Web,
+    NSClient++) and EOL selection (space+LF, LF, CRLF)
+  - Optional encoding of plugin output using a Windows console code page
+    (UTF-8, 437, 850, 1252) for plugins launched as NSClient++ external
+    scripts (see SetCodePage and DetectCodePage)
+  - Hyperlinks (runbook, dashboard, ticket) attached to results via AddLink,
+    listed as plain URLs in plugin output and as links by the HTML and JSON
+    encoders (EncodeLinksHTML, EncodeLinksJSON)
+  - Message catalog hook (SetMessageCatalog) used to localize built-in
+    labels (THRESHOLDS, ERRORS, DETAILED INFO) and summary phrases
+  - Markdown formatting (fenced panic details, bold section labels, bullet
+    lists) enabled or disabled per output profile or via SetMarkdown
+  - Optional truncation of oversize output with a marker pointing to a
+    spill file (with rotation) holding the complete report
+  - Removal of control characters and ANSI escape sequences (e.g., from
+    panic or error content) from emitted output
+  - Optional ASCII-only output mode which strips or transliterates
+    non-ASCII and control characters for transports which mangle UTF-8
+  - Optional substitution of pipe characters in ServiceOutput and
+    LongServiceOutput so that stray pipes are not interpreted as performance
+    data
+  - Optional performance data metadata (display name, description, graph
+    hints) included in JSON and OpenMetrics encoded output but excluded from
+    the classic performance data format
+  - Helpers to summarize samples collected during a run (min, max, average
+    and percentiles) as a consistent family of performance data metrics
+  - Optional persisted state (via a StateStore; a file-locked, schema
+    versioned JSON store is provided) recording timestamped state transitions
+    between runs, with a helper to include the last state change (e.g.,
+    "Last state change: 2d4h ago (was WARNING)") in LongServiceOutput
+  - Optional trend annotations (↑/↓/→ with delta) for performance data
+    values using values persisted by the last run, with optional "_delta"
+    performance data metrics
+  - Support for metrics where low values alert (e.g., free space or
+    replica count) with plain language rendering of threshold ranges (see
+    SetLowThresholds and Range.Describe)
+  - Range sets used to alert if a value falls in any of a list of ranges
+    (e.g., outside of a healthy temperature band), see SetWarningRangeSet
+    and SetCriticalRangeSet
+  - Plugin-declared default thresholds combined with user overrides, with
+    the origin of the effective thresholds displayed in the THRESHOLDS
+    section (see SetDefaultThresholds and OverrideThresholds)
+  - Per-metric threshold sets (ParseThresholdSet, EvaluateThresholdSet)
+    evaluated against collected performance data in one call
+  - Threshold values with units (e.g., "500ms", "2GiB", "80%") normalized
+    against metric units of measurement, with unit mistakes reported as
+    errors (see NormalizeRange)
+  - Auto-composed one-line summary listing each breached metric with its
+    value and threshold (see BreachSummary), with breaches also reported as
+    errors wrapping ErrWarningThresholdCrossed or ErrCriticalThresholdCrossed
+    (see ThresholdError)
+  - Limits expressed as a percentage of a known total, an absolute value
+    or both (whichever is stricter) resolved to threshold ranges (see
+    SetLimitThresholds)
+  - Condition expressions (e.g., "used_pct > 85 && inodes_pct > 90")
+    evaluated against collected performance data (see ParseCondition and
+    EvaluateConditions)
+  - Typed condition builder (Metric predicates combined using All, Any and
+    Not) yielding both a state and an explanation of which condition fired
+    (see EvaluatePredicates)
+  - Optional time window thresholds (e.g., business hours vs nights and
+    weekends) using cron-like schedules, evaluated and displayed by the
+    library (see AddThresholdWindow)
+  - Optional recovery thresholds (hysteresis) applied based on the
+    persisted previous state to stop boundary-hugging metrics from flapping
+    (see SetRecoveryThresholds)
+  - Optional evaluation of thresholds against a moving average of the last
+    N persisted samples to smooth noisy metrics (see SetMovingAverage)
+  - Optional operator-controlled remapping of the final plugin state
+    (e.g., UNKNOWN as CRITICAL) noting the original state in the one-line
+    summary (see SetStateRemapping and the cmdline package)
+  - Optional scheduled downtime awareness (via a DowntimeChecker; a local
+    maintenance flag file checker is provided, with Livestatus and Icinga 2
+    API checkers in the livestatus and icinga2 packages) annotating and
+    optionally downgrading results while in downtime
+  - Optional enrichment of non-OK results with an existing acknowledgement
+    (e.g., "Acknowledged by jdoe: reason") via an AcknowledgementSource (a
+    Livestatus implementation is provided in the livestatus package)
+  - Optional run history (via a HistoryStore; an append-only JSON Lines
+    store with retention limits is provided) retaining the final state and
+    numeric performance data of thousands of runs for trend and SLA use
+  - Optional evaluation of deviation from a per-weekday/per-hour baseline
+    built from the run history (see EvaluateBaseline)
+  - Optional on-disk caching of expensive data collection with a TTL (see
+    DiskCache), reporting cache freshness in LongServiceOutput and the cache
+    age as a performance data metric
+  - Optional rotating output trace file recording the byte-exact output and
+    exit code of every run (see WithOutputTrace)
+  - Plugin output is flushed before exit; failed writes (e.g., an NRPE
+    daemon closing the pipe early) are reported on stderr instead of
+    terminating the plugin via SIGPIPE and may be recorded to a trace file
+    (see WithOutputFailureTrace)
+  - Optional tracing of plugin runs via a minimal Tracer interface; a root
+    span records the final state and performance data and child spans are
+    recorded for sub-checks and Group tasks. Exporting spans (e.g., via
+    OpenTelemetry and OTLP) requires an adapter provided by client code
   - Optional support for collecting/emitting performance data generated by
-    plugins (default time metric emitted if using constructor)
+    plugins (default time metric emitted if using constructor; the label may
+    be customized via WithRuntimeMetric)
   - Supports "branding" callback function to display application name,
     version, or other information as a "trailer" for check results provided to
     Nagios
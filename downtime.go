@@ -0,0 +1,174 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// statusLookupTimeout is the maximum time spent on each lookup of status
+	// details (e.g., active downtime) from the monitoring system when check
+	// results are returned.
+	statusLookupTimeout time.Duration = 5 * time.Second
+
+	// downtimeSummaryNote is appended (in parentheses) to the one-line
+	// summary while in scheduled downtime.
+	downtimeSummaryNote string = "in scheduled downtime"
+
+	// downtimeLabel is the text emitted prior to the downtime details.
+	downtimeLabel string = "Scheduled downtime"
+)
+
+// Downtime describes an active scheduled downtime (or maintenance window)
+// for the host or service being checked.
+type Downtime struct {
+	// Author is the user who scheduled the downtime, if known.
+	Author string
+
+	// Comment is the reason given for the downtime, if known.
+	Comment string
+
+	// End is when the downtime is scheduled to end, if known.
+	End time.Time
+}
+
+// String provides a summary of the downtime (e.g., "Scheduled downtime by
+// jdoe until 2024-01-01 18:00: patching").
+func (d Downtime) String() string {
+	var b strings.Builder
+
+	b.WriteString(downtimeLabel)
+
+	if d.Author != "" {
+		fmt.Fprintf(&b, " by %s", d.Author)
+	}
+
+	if !d.End.IsZero() {
+		fmt.Fprintf(&b, " until %s", d.End.Local().Format("2006-01-02 15:04"))
+	}
+
+	if d.Comment != "" {
+		fmt.Fprintf(&b, ": %s", d.Comment)
+	}
+
+	return b.String()
+}
+
+// DowntimeChecker reports active scheduled downtime for the host or service
+// being checked. Implementations are provided for a local maintenance flag
+// file (see MaintenanceFile) and in the livestatus and icinga2 packages.
+type DowntimeChecker interface {
+	// ActiveDowntime returns the active downtime or nil if none is active.
+	ActiveDowntime(ctx context.Context) (*Downtime, error)
+}
+
+// DowntimeCheckerFunc is an adapter allowing an ordinary function to be
+// used as a DowntimeChecker.
+type DowntimeCheckerFunc func(ctx context.Context) (*Downtime, error)
+
+// ActiveDowntime calls f(ctx).
+func (f DowntimeCheckerFunc) ActiveDowntime(ctx context.Context) (*Downtime, error) {
+	return f(ctx)
+}
+
+// MaintenanceFile returns a DowntimeChecker which reports active downtime
+// while a maintenance flag file exists at the given path. The first line of
+// the file (if any) is used as the downtime comment.
+func MaintenanceFile(path string) DowntimeChecker {
+	return DowntimeCheckerFunc(func(context.Context) (*Downtime, error) {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, nil
+		case err != nil:
+			return nil, err
+		}
+
+		comment, _, _ := strings.Cut(string(data), "\n")
+
+		return &Downtime{Comment: strings.TrimSpace(comment)}, nil
+	})
+}
+
+// WithDowntimeChecker is an Option used to check for active scheduled
+// downtime. See also SetDowntimeChecker.
+func WithDowntimeChecker(checker DowntimeChecker) Option {
+	return func(p *Plugin) {
+		p.SetDowntimeChecker(checker)
+	}
+}
+
+// SetDowntimeChecker causes the given checker to be consulted for active
+// scheduled downtime when check results are returned. While in downtime,
+// the one-line summary is annotated with "(in scheduled downtime)" and the
+// downtime details are included in LongServiceOutput. See also
+// DowngradeInDowntime.
+//
+// Failure to check for downtime does not affect plugin output; the failure
+// is logged instead (see SetLogger).
+func (p *Plugin) SetDowntimeChecker(checker DowntimeChecker) {
+	p.downtimeChecker = checker
+}
+
+// DowngradeInDowntime causes a final plugin state more severe than the given
+// state (e.g., StateOKExitCode or StateWARNINGExitCode) to be replaced with
+// the given state while in scheduled downtime. This requires a downtime
+// checker (see SetDowntimeChecker).
+func (p *Plugin) DowngradeInDowntime(exitCode int) {
+	p.downtimeDowngradeState = &exitCode
+}
+
+// applyDowntime checks for active scheduled downtime (if enabled) and
+// annotates (and optionally downgrades) the check results.
+func (p *Plugin) applyDowntime() {
+	if p.downtimeChecker == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusLookupTimeout)
+	defer cancel()
+
+	downtime, err := p.downtimeChecker.ActiveDowntime(ctx)
+	switch {
+	case err != nil:
+		p.Logger().Warn("failed to check for scheduled downtime", "error", err)
+		return
+	case downtime == nil:
+		return
+	}
+
+	summary := strings.TrimRight(p.ServiceOutput, trailingWhitespaceCutSet)
+	p.ServiceOutput = fmt.Sprintf(
+		"%s (%s)%s",
+		summary,
+		p.message(MessageInDowntime, downtimeSummaryNote),
+		p.ServiceOutput[len(summary):],
+	)
+
+	// Downtime details are provided by a remote system and may contain the
+	// performance data separator.
+	p.WithDetail(EscapePipes(downtime.String(), p.pipeReplacement))
+
+	if p.downtimeDowngradeState != nil &&
+		stateSeverity(p.ExitStatusCode) > stateSeverity(*p.downtimeDowngradeState) {
+		p.Logger().Debug(
+			"plugin state downgraded during scheduled downtime",
+			"from", stateLabel(p.ExitStatusCode),
+			"to", stateLabel(*p.downtimeDowngradeState),
+		)
+
+		p.ExitStatusCode = *p.downtimeDowngradeState
+	}
+}
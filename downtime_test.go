@@ -0,0 +1,63 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestMaintenanceFileDowngradesResults asserts that results are annotated
+// and downgraded while a maintenance flag file exists.
+func TestMaintenanceFileDowngradesResults(t *testing.T) {
+	t.Parallel()
+
+	flagFile := filepath.Join(t.TempDir(), "maintenance")
+
+	run := func() (string, int) {
+		plugin := nagios.Plugin{}
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetDowntimeChecker(nagios.MaintenanceFile(flagFile))
+		plugin.DowngradeInDowntime(nagios.StateWARNINGExitCode)
+
+		plugin.ServiceOutput = "CRITICAL: database unreachable"
+		plugin.ExitStatusCode = nagios.StateCRITICALExitCode
+		plugin.ReturnCheckResults()
+
+		return outputBuffer.String(), plugin.ExitStatusCode
+	}
+
+	if got, exitCode := run(); strings.Contains(got, "downtime") || exitCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want results unchanged without flag file, got %q (exit code %d)", got, exitCode)
+	}
+
+	if err := os.WriteFile(flagFile, []byte("storage migration | phase 2\n"), 0o600); err != nil {
+		t.Fatalf("failed to write flag file: %v", err)
+	}
+
+	got, exitCode := run()
+
+	want := "CRITICAL: database unreachable (in scheduled downtime) \n \nScheduled downtime: storage migration ¦ phase 2 \n"
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if exitCode != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, exitCode)
+	}
+}
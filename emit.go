@@ -0,0 +1,99 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// flusher is implemented by buffered output targets (e.g., *bufio.Writer).
+type flusher interface {
+	Flush() error
+}
+
+// syncer is implemented by file backed output targets (e.g., *os.File).
+type syncer interface {
+	Sync() error
+}
+
+// WithOutputFailureTrace is an Option used to record plugin output to a
+// trace file if emitting it fails. See also SetOutputFailureTrace.
+func WithOutputFailureTrace(path string) Option {
+	return func(p *Plugin) {
+		p.SetOutputFailureTrace(path)
+	}
+}
+
+// SetOutputFailureTrace enables recording plugin output to the trace file at
+// the given path only if writing the output to the output target fails
+// (e.g., because an NRPE daemon closed the pipe before reading the output).
+// Records use the format and default rotation settings described by
+// SetOutputTrace and note the write error so that the results of the run
+// are not silently lost.
+//
+// This setting replaces any previous SetOutputTrace setting (and vice
+// versa). Records written due to SetOutputTrace also note write errors.
+func (p *Plugin) SetOutputFailureTrace(path string) {
+	p.SetOutputTrace(path, DefaultOutputTraceMaxSize, DefaultOutputTraceMaxBackups)
+	p.outputTrace.failuresOnly = true
+}
+
+// flushOutput flushes buffered output targets and syncs file backed output
+// targets so that plugin output is handed off before the plugin exits.
+// Errors from syncing targets which do not support it (e.g., pipes and
+// terminals) are ignored.
+func flushOutput(w any) error {
+	if f, ok := w.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if s, ok := w.(syncer); ok {
+		if err := s.Sync(); err != nil && !syncUnsupported(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOutput writes the given plugin output to w and flushes it. Writes to
+// a closed pipe are returned as errors rather than the process being
+// terminated by SIGPIPE.
+func writeOutput(w io.Writer, pluginOutput string) error {
+	ignoreSIGPIPE()
+
+	if _, err := io.WriteString(w, pluginOutput); err != nil {
+		return err
+	}
+
+	return flushOutput(w)
+}
+
+// reportEmitFailure logs the failure to emit plugin output and notes it on
+// os.Stderr as the plugin output itself could not be delivered.
+func (p *Plugin) reportEmitFailure(err error) {
+	brokenPipe := isBrokenPipe(err)
+
+	p.Logger().Error(
+		"failed to emit plugin output",
+		"error", err,
+		"broken_pipe", brokenPipe,
+		"exit_code", p.ExitStatusCode,
+	)
+
+	reason := err.Error()
+	if brokenPipe {
+		reason = "output reader closed before plugin output was read: " + reason
+	}
+
+	fmt.Fprintf(os.Stderr, "failed to emit plugin output: %s\n", reason)
+}
@@ -0,0 +1,25 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package nagios
+
+// ignoreSIGPIPE is a no-op as SIGPIPE is not delivered on this platform.
+func ignoreSIGPIPE() {}
+
+// isBrokenPipe always reports false as broken pipes are not detected on this
+// platform.
+func isBrokenPipe(error) bool {
+	return false
+}
+
+// syncUnsupported always reports true as syncing output targets is not
+// expected to be supported on this platform.
+func syncUnsupported(error) bool {
+	return true
+}
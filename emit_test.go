@@ -0,0 +1,108 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// failingWriter is an output target which rejects all writes with the
+// given error.
+type failingWriter struct {
+	err error
+}
+
+func (fw failingWriter) Write([]byte) (int, error) {
+	return 0, fw.err
+}
+
+// TestOutputFailureTraceRecordsFailedEmission asserts that plugin output is
+// recorded to the failure trace file, along with the write error, if
+// emitting it fails.
+func TestOutputFailureTraceRecordsFailedEmission(t *testing.T) {
+	t.Parallel()
+
+	tracePath := filepath.Join(t.TempDir(), "failed.trace")
+
+	plugin := nagios.NewPlugin(nagios.WithOutputFailureTrace(tracePath))
+	plugin.SetOutputTarget(failingWriter{err: fmt.Errorf("write |1: %w", syscall.EPIPE)})
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Critical("disk full")
+	plugin.ReturnCheckResults()
+
+	got, err := os.ReadFile(tracePath)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+
+	for _, want := range []string{"exit_code=2", "emit_error=", "CRITICAL: disk full"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("want trace file to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestOutputFailureTraceSkipsSuccessfulEmission asserts that plugin output
+// is not recorded to the failure trace file if emitting it succeeds.
+func TestOutputFailureTraceSkipsSuccessfulEmission(t *testing.T) {
+	t.Parallel()
+
+	tracePath := filepath.Join(t.TempDir(), "failed.trace")
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin()
+	plugin.SetOutputFailureTrace(tracePath)
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.OK("all good")
+	plugin.ReturnCheckResults()
+
+	if _, err := os.Stat(tracePath); !os.IsNotExist(err) {
+		t.Errorf("want no trace file after successful emission, got: %v", err)
+	}
+
+	if want, got := "OK: all good", outputBuffer.String(); !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
+
+// TestEmitFlushesBufferedOutputTarget asserts that buffered output targets
+// are flushed once plugin output is emitted.
+func TestEmitFlushesBufferedOutputTarget(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin()
+	plugin.SetOutputTarget(bufio.NewWriter(&outputBuffer))
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Warning("queue backlog")
+	plugin.ReturnCheckResults()
+
+	if want, got := "WARNING: queue backlog", outputBuffer.String(); !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package nagios
+
+import (
+	"errors"
+	"os/signal"
+	"syscall"
+)
+
+// ignoreSIGPIPE prevents the Go runtime from terminating the plugin via
+// SIGPIPE when writing to a closed standard output or standard error pipe
+// so that the write error (EPIPE) can be handled instead.
+func ignoreSIGPIPE() {
+	signal.Ignore(syscall.SIGPIPE)
+}
+
+// isBrokenPipe indicates whether err was caused by writing to a pipe whose
+// reader has gone away.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
+
+// syncUnsupported indicates whether err was caused by syncing a file
+// descriptor which does not support it (e.g., a pipe or terminal).
+func syncUnsupported(err error) bool {
+	return errors.Is(err, syscall.EINVAL) ||
+		errors.Is(err, syscall.ENOTSUP) ||
+		errors.Is(err, syscall.ENOTTY)
+}
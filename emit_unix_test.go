@@ -0,0 +1,59 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package nagios_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestEmitToClosedPipeIsTraced asserts that writing plugin output to a pipe
+// whose reader has already gone away is handled (rather than terminating
+// the plugin) and that the output is recorded to the failure trace file.
+func TestEmitToClosedPipeIsTraced(t *testing.T) {
+	t.Parallel()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	defer func() { _ = writer.Close() }()
+
+	if err := reader.Close(); err != nil {
+		t.Fatalf("failed to close pipe reader: %v", err)
+	}
+
+	tracePath := filepath.Join(t.TempDir(), "failed.trace")
+
+	plugin := nagios.NewPlugin()
+	plugin.SetOutputFailureTrace(tracePath)
+	plugin.SetOutputTarget(writer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Unknown("agent unreachable")
+	plugin.ReturnCheckResults()
+
+	got, err := os.ReadFile(tracePath)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+
+	for _, want := range []string{"broken pipe", "UNKNOWN: agent unreachable"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("want trace file to contain %q, got:\n%s", want, got)
+		}
+	}
+}
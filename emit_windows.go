@@ -0,0 +1,44 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build windows
+
+package nagios
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Windows system error codes not provided by the syscall package.
+const (
+	// errorInvalidHandle is the ERROR_INVALID_HANDLE error, returned when
+	// syncing a console handle.
+	errorInvalidHandle syscall.Errno = 6
+
+	// errorNoData is the ERROR_NO_DATA error ("The pipe is being closed"),
+	// returned when writing to a pipe whose reader has gone away.
+	errorNoData syscall.Errno = 232
+)
+
+// ignoreSIGPIPE is a no-op as SIGPIPE is not delivered on Windows.
+func ignoreSIGPIPE() {}
+
+// isBrokenPipe indicates whether err was caused by writing to a pipe whose
+// reader has gone away.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ERROR_BROKEN_PIPE) ||
+		errors.Is(err, errorNoData)
+}
+
+// syncUnsupported indicates whether err was caused by syncing a handle which
+// does not support it (e.g., a console).
+func syncUnsupported(err error) bool {
+	return errors.Is(err, syscall.EINVAL) ||
+		errors.Is(err, errorInvalidHandle)
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// environmentPatternWildcard marks an environment capture allowlist entry
+// as a prefix (e.g., "NAGIOS_*").
+const environmentPatternWildcard string = "*"
+
+// unsetEnvironmentValue is shown for allowlisted environment variables
+// which are not set.
+const unsetEnvironmentValue string = "(not set)"
+
+// DefaultEnvironmentAllowlist is the allowlist of environment variables
+// captured by EnableEnvironmentCapture if none is given. Entries ending in
+// "*" match all variables with the given prefix.
+var DefaultEnvironmentAllowlist = []string{
+	"PATH",
+	"HOME",
+	"USER",
+	"SHELL",
+	"PWD",
+	"TMPDIR",
+	"TZ",
+	"LANG",
+	"LC_*",
+	"HTTP_PROXY",
+	"HTTPS_PROXY",
+	"NO_PROXY",
+	"NAGIOS_*",
+	"ICINGA_*",
+}
+
+// WithEnvironmentCapture is an Option used to capture environment
+// variables in the diagnostics section. See also EnableEnvironmentCapture.
+func WithEnvironmentCapture(allowlist ...string) Option {
+	return func(p *Plugin) {
+		p.EnableEnvironmentCapture(allowlist...)
+	}
+}
+
+// EnableEnvironmentCapture lists the environment variables matching the
+// given allowlist (DefaultEnvironmentAllowlist if empty) in the
+// diagnostics section (see EnableDiagnostics). Entries ending in "*" match
+// all variables with the given prefix; other entries are listed even if
+// not set. This is useful when plugins behave differently under the
+// scheduler's environment than in an interactive shell.
+//
+// Values of variables whose names suggest secrets (e.g., containing
+// "PASS", "TOKEN" or "KEY") are redacted, as are passwords within URL
+// values (e.g., proxy credentials).
+func (p *Plugin) EnableEnvironmentCapture(allowlist ...string) {
+	if len(allowlist) == 0 {
+		allowlist = DefaultEnvironmentAllowlist
+	}
+
+	p.environmentAllowlist = allowlist
+}
+
+// capturedEnvironment collects the diagnostics entries for the environment
+// variables requested via EnableEnvironmentCapture.
+func (p Plugin) capturedEnvironment() []diagnostic {
+	if len(p.environmentAllowlist) == 0 {
+		return nil
+	}
+
+	return captureEnvironment(os.Environ(), p.environmentAllowlist)
+}
+
+// captureEnvironment returns the sanitized entries of the given environment
+// (in "NAME=value" form) matching the allowlist, sorted by name.
+func captureEnvironment(environ []string, allowlist []string) []diagnostic {
+	values := make(map[string]string)
+
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		if environmentAllowed(name, allowlist) {
+			values[name] = sanitizeEnvironmentValue(name, value)
+		}
+	}
+
+	for _, pattern := range allowlist {
+		if strings.HasSuffix(pattern, environmentPatternWildcard) {
+			continue
+		}
+
+		if _, ok := values[pattern]; !ok {
+			values[pattern] = unsetEnvironmentValue
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]diagnostic, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, diagnostic{name: "Env " + name, value: values[name]})
+	}
+
+	return entries
+}
+
+// environmentAllowed indicates whether the environment variable with the
+// given name matches the allowlist.
+func environmentAllowed(name string, allowlist []string) bool {
+	for _, pattern := range allowlist {
+		prefix, isPrefix := strings.CutSuffix(pattern, environmentPatternWildcard)
+
+		switch {
+		case isPrefix && strings.HasPrefix(name, prefix):
+			return true
+		case !isPrefix && name == pattern:
+			return true
+		}
+	}
+
+	return false
+}
+
+// sanitizeEnvironmentValue redacts the value of the environment variable
+// with the given name if it is likely to be a secret, and redacts
+// passwords within URL values.
+func sanitizeEnvironmentValue(name string, value string) string {
+	if isSensitiveFlag(name) {
+		return redactedValue
+	}
+
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+			return u.String()
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// defaultEnvironmentLabel is the header text for the environment section.
+const defaultEnvironmentLabel string = "ENVIRONMENT"
+
+// Verbosity levels as described by the plugin development guidelines for
+// the -v (--verbose) flag.
+//
+// https://nagios-plugins.org/doc/guidelines.html#AEN41
+const (
+	// VerbositySingleLine is the default verbosity: single line, minimal
+	// output.
+	VerbositySingleLine int = iota
+
+	// VerbosityAdditional is single line output with additional
+	// information.
+	VerbosityAdditional
+
+	// VerbosityConfiguration is multi line output with configuration debug
+	// information.
+	VerbosityConfiguration
+
+	// VerbosityDiagnostic is output with as much detail as possible for
+	// plugin problem diagnosis.
+	VerbosityDiagnostic
+)
+
+// redactedValue replaces sensitive command line values in the environment
+// section.
+const redactedValue string = "REDACTED"
+
+// sensitiveFlagNames are substrings of flag names whose values are
+// redacted from the command line shown in the environment section.
+var sensitiveFlagNames = []string{"pass", "secret", "token", "key", "auth"}
+
+// WithVerbosity is an Option used to set the verbosity level. See also
+// SetVerbosity.
+func WithVerbosity(level int) Option {
+	return func(p *Plugin) {
+		p.SetVerbosity(level)
+	}
+}
+
+// SetVerbosity sets the verbosity level requested by the user (e.g., via
+// repeated -v flags). See VerbositySingleLine and related constants.
+func (p *Plugin) SetVerbosity(level int) {
+	p.verbosity = level
+}
+
+// Verbosity returns the verbosity level. See also SetVerbosity.
+func (p Plugin) Verbosity() int {
+	return p.verbosity
+}
+
+// WithEnvironmentSection is an Option used to enable the environment
+// section. See also EnableEnvironmentSection.
+func WithEnvironmentSection(version string) Option {
+	return func(p *Plugin) {
+		p.EnableEnvironmentSection(version)
+	}
+}
+
+// EnableEnvironmentSection enables an opt-in environment section in
+// LongServiceOutput listing the command line (with the values of flags
+// such as --password redacted), the given plugin version, the Go runtime
+// version and the hostname. The section is emitted only at
+// VerbosityDiagnostic verbosity (see SetVerbosity) and is intended to make
+// plugin bug reports self-contained.
+func (p *Plugin) EnableEnvironmentSection(version string) {
+	p.environmentEnabled = true
+	p.pluginVersion = version
+}
+
+// handleEnvironmentSection is a wrapper around the logic used to
+// handle/process the Environment section header and listing.
+func (p Plugin) handleEnvironmentSection(w io.Writer) {
+	if !p.environmentEnabled || p.verbosity < VerbosityDiagnostic {
+		return
+	}
+
+	fmt.Fprintf(w,
+		"%s%s%s%s",
+		CheckOutputEOL,
+		p.sectionLabel(p.message(MessageEnvironmentLabel, defaultEnvironmentLabel)),
+		CheckOutputEOL,
+		CheckOutputEOL,
+	)
+
+	for _, entry := range p.environment() {
+		fmt.Fprintf(w, "%s%s", p.listItem("%s: %s", entry.name, entry.value), CheckOutputEOL)
+	}
+}
+
+// environment collects the entries of the environment section.
+func (p Plugin) environment() []diagnostic {
+	version := p.pluginVersion
+	if version == "" {
+		version = "unknown"
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = fmt.Sprintf("unknown (%v)", err)
+	}
+
+	return []diagnostic{
+		{name: "Command line", value: commandLine(os.Args)},
+		{name: "Plugin version", value: version},
+		{name: "Go version", value: runtime.Version()},
+		{name: "Hostname", value: hostname},
+	}
+}
+
+// commandLine formats the given command line arguments for display,
+// quoting arguments which contain whitespace or quotes and redacting the
+// values of sensitive flags.
+func commandLine(args []string) string {
+	formatted := make([]string, 0, len(args))
+
+	var redactNext bool
+
+	for _, arg := range args {
+		switch {
+		case redactNext:
+			arg = redactedValue
+			redactNext = false
+
+		case strings.HasPrefix(arg, "-"):
+			name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if isSensitiveFlag(name) {
+				if hasValue {
+					arg = arg[:strings.Index(arg, "=")+1] + redactedValue
+				} else {
+					redactNext = true
+				}
+			}
+		}
+
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+
+		formatted = append(formatted, arg)
+	}
+
+	return strings.Join(formatted, " ")
+}
+
+// isSensitiveFlag indicates whether the value of the flag with the given
+// name should be redacted.
+func isSensitiveFlag(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, sensitive := range sensitiveFlagNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
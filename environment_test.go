@@ -0,0 +1,63 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestEnvironmentSectionRequiresDiagnosticVerbosity asserts that the
+// environment section is only emitted when enabled and at the diagnostic
+// verbosity level.
+func TestEnvironmentSectionRequiresDiagnosticVerbosity(t *testing.T) {
+	t.Parallel()
+
+	for _, verbosity := range []int{nagios.VerbosityConfiguration, nagios.VerbosityDiagnostic} {
+		var outputBuffer strings.Builder
+
+		plugin := nagios.NewPlugin(
+			nagios.WithOutputTarget(&outputBuffer),
+			nagios.WithSkipOSExit(),
+			nagios.WithEnvironmentSection("v1.2.3"),
+			nagios.WithVerbosity(verbosity),
+		)
+
+		plugin.OK("all good")
+		plugin.ReturnCheckResults()
+
+		got := outputBuffer.String()
+
+		if verbosity < nagios.VerbosityDiagnostic {
+			if strings.Contains(got, "**ENVIRONMENT**") {
+				t.Errorf("want environment section omitted at verbosity %d, got %q", verbosity, got)
+			}
+			continue
+		}
+
+		hostname, err := os.Hostname()
+		if err != nil {
+			t.Fatalf("failed to retrieve hostname: %v", err)
+		}
+
+		for _, want := range []string{
+			"**ENVIRONMENT**",
+			"* Command line: " + os.Args[0],
+			"* Plugin version: v1.2.3",
+			"* Go version: go",
+			"* Hostname: " + hostname,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("want output containing %q, got %q", want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Supported EOL values for plugin output. See CheckOutputEOL for background.
+const (
+	// EOLSpaceLF is a UNIX EOL with a single leading space. This is the
+	// default and matches CheckOutputEOL.
+	EOLSpaceLF string = CheckOutputEOL
+
+	// EOLLF is a UNIX EOL.
+	EOLLF string = "\n"
+
+	// EOLCRLF is a DOS EOL.
+	EOLCRLF string = "\r\n"
+)
+
+// ErrUnsupportedEOL indicates that an EOL value other than EOLSpaceLF, EOLLF
+// or EOLCRLF was requested.
+var ErrUnsupportedEOL = errors.New("unsupported EOL value")
+
+// ErrUnknownOutputProfile indicates that an output profile name was not
+// recognized.
+var ErrUnknownOutputProfile = errors.New("unknown output profile")
+
+// OutputProfile identifies the system which displays plugin output. The
+// profile determines output formatting choices (e.g., the EOL used) which
+// differ between Nagios Core, Nagios XI and Icinga Web.
+type OutputProfile int
+
+// Supported OutputProfile values.
+const (
+	// OutputProfileDefault uses formatting choices which give acceptable
+	// results for both Nagios Core and Nagios XI.
+	OutputProfileDefault OutputProfile = iota
+
+	// OutputProfileNagiosCore targets the Nagios Core web UI.
+	OutputProfileNagiosCore
+
+	// OutputProfileNagiosXI targets the Nagios XI web UI.
+	OutputProfileNagiosXI
+
+	// OutputProfileIcingaWeb targets Icinga Web 2, which displays UNIX EOLs
+	// as expected without a leading space.
+	OutputProfileIcingaWeb
+
+	// OutputProfileNSClient targets plugins launched as external scripts by
+	// NSClient++ on Windows, which expects DOS EOLs. See also SetCodePage
+	// for console code page handling.
+	//
+	// NSClient++ propagates the exit code of the process it launched. If a
+	// plugin is launched via a batch or PowerShell wrapper script the
+	// wrapper must exit with the plugin exit code (e.g., "exit /b
+	// %ERRORLEVEL%" or "exit $LASTEXITCODE"), otherwise the result is
+	// reported as OK (or UNKNOWN) regardless of the plugin state.
+	OutputProfileNSClient
+)
+
+// outputProfileNames is the collection of names for each OutputProfile.
+var outputProfileNames = map[OutputProfile]string{
+	OutputProfileDefault:    "default",
+	OutputProfileNagiosCore: "nagios-core",
+	OutputProfileNagiosXI:   "nagios-xi",
+	OutputProfileIcingaWeb:  "icinga-web",
+	OutputProfileNSClient:   "nsclient",
+}
+
+// ParseOutputProfile returns the OutputProfile with the given name (e.g.,
+// "nagios-xi"). This is intended for use with a command-line flag. An error
+// wrapping ErrUnknownOutputProfile is returned if the name is not
+// recognized.
+func ParseOutputProfile(name string) (OutputProfile, error) {
+	for profile, profileName := range outputProfileNames {
+		if strings.EqualFold(name, profileName) {
+			return profile, nil
+		}
+	}
+
+	return OutputProfileDefault, fmt.Errorf("%w: %q", ErrUnknownOutputProfile, name)
+}
+
+// String provides the name of the output profile.
+func (op OutputProfile) String() string {
+	if name, ok := outputProfileNames[op]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("OutputProfile(%d)", int(op))
+}
+
+// EOL returns the EOL used by the output profile.
+func (op OutputProfile) EOL() string {
+	switch op {
+	case OutputProfileIcingaWeb:
+		return EOLLF
+	case OutputProfileNSClient:
+		return EOLCRLF
+	default:
+		return EOLSpaceLF
+	}
+}
+
+// WithOutputProfile is an Option used to select the output profile. See
+// also SetOutputProfile.
+func WithOutputProfile(profile OutputProfile) Option {
+	return func(p *Plugin) {
+		p.SetOutputProfile(profile)
+	}
+}
+
+// SetOutputProfile selects the output profile used to make formatting
+// choices (e.g., the EOL used) for the system which displays plugin output.
+func (p *Plugin) SetOutputProfile(profile OutputProfile) {
+	p.outputProfile = profile
+}
+
+// SetEOL overrides the EOL selected by the output profile. An error wrapping
+// ErrUnsupportedEOL is returned if eol is not one of EOLSpaceLF, EOLLF or
+// EOLCRLF.
+//
+// Library generated line endings are emitted using the selected EOL. Client
+// code should use CheckOutputEOL (or the value returned by the EOL method)
+// for line endings within ServiceOutput and LongServiceOutput so that they
+// are also emitted using the selected EOL.
+func (p *Plugin) SetEOL(eol string) error {
+	switch eol {
+	case EOLSpaceLF, EOLLF, EOLCRLF:
+		p.eol = eol
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedEOL, eol)
+	}
+}
+
+// EOL returns the EOL used for plugin output. This is the value set via
+// SetEOL if specified, otherwise the EOL used by the output profile.
+func (p Plugin) EOL() string {
+	if p.eol != "" {
+		return p.eol
+	}
+
+	return p.outputProfile.EOL()
+}
+
+// translateEOL replaces CheckOutputEOL line endings in the given plugin
+// output with the selected EOL.
+func (p Plugin) translateEOL(pluginOutput string) string {
+	eol := p.EOL()
+	if eol == CheckOutputEOL {
+		return pluginOutput
+	}
+
+	return strings.ReplaceAll(pluginOutput, CheckOutputEOL, eol)
+}
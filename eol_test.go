@@ -0,0 +1,94 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestOutputProfileSelectsEOL asserts that library and client code line
+// endings are emitted using the EOL selected by the output profile or
+// override.
+func TestOutputProfileSelectsEOL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		profile nagios.OutputProfile
+		eol     string
+		want    string
+	}{
+		"default": {
+			profile: nagios.OutputProfileDefault,
+			want:    "OK: summary \n \nline one \nline two \n",
+		},
+		"icinga web": {
+			profile: nagios.OutputProfileIcingaWeb,
+			want:    "OK: summary\n\nline one\nline two\n",
+		},
+		"nsclient": {
+			profile: nagios.OutputProfileNSClient,
+			want:    "OK: summary\r\n\r\nline one\r\nline two\r\n",
+		},
+		"crlf override": {
+			profile: nagios.OutputProfileIcingaWeb,
+			eol:     nagios.EOLCRLF,
+			want:    "OK: summary\r\n\r\nline one\r\nline two\r\n",
+		},
+	}
+
+	for name, tt := range tests {
+		plugin := nagios.Plugin{}
+		plugin.SetOutputProfile(tt.profile)
+
+		if tt.eol != "" {
+			if err := plugin.SetEOL(tt.eol); err != nil {
+				t.Fatalf("%s: failed to set EOL: %v", name, err)
+			}
+		}
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetSummary("OK: summary")
+		plugin.SetLongServiceOutput("line one" + nagios.CheckOutputEOL + "line two")
+		plugin.ReturnCheckResults()
+
+		if d := cmp.Diff(tt.want, outputBuffer.String()); d != "" {
+			t.Errorf("%s: (-want, +got)\n:%s", name, d)
+		}
+	}
+}
+
+// TestSetEOLRejectsUnsupportedValues asserts that unsupported EOL values
+// and output profile names are rejected.
+func TestSetEOLRejectsUnsupportedValues(t *testing.T) {
+	t.Parallel()
+
+	var plugin nagios.Plugin
+
+	if err := plugin.SetEOL("\r"); !errors.Is(err, nagios.ErrUnsupportedEOL) {
+		t.Errorf("want %v, got %v", nagios.ErrUnsupportedEOL, err)
+	}
+
+	if _, err := nagios.ParseOutputProfile("nagios-2"); !errors.Is(err, nagios.ErrUnknownOutputProfile) {
+		t.Errorf("want %v, got %v", nagios.ErrUnknownOutputProfile, err)
+	}
+
+	profile, err := nagios.ParseOutputProfile("Icinga-Web")
+	if err != nil || profile != nagios.OutputProfileIcingaWeb {
+		t.Errorf("want %v, got %v (%v)", nagios.OutputProfileIcingaWeb, profile, err)
+	}
+}
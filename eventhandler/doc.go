@@ -0,0 +1,36 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package eventhandler provides common types and helper functions for use by
+Nagios event handlers (remediation scripts) written in Go.
+
+# OVERVIEW
+
+Event handlers are commands run by Nagios (and similar monitoring systems)
+when a host or service changes state. By convention the state, state type
+and attempt number are passed as command arguments using the $SERVICESTATE$,
+$SERVICESTATETYPE$ and $SERVICEATTEMPT$ macros (or the $HOST...$
+equivalents). This package parses those values, provides guard helpers used
+to decide whether to act on an event (e.g., "only act on the third SOFT
+CRITICAL attempt or on a HARD CRITICAL state") and logs the actions taken so
+that remediation behavior can be reviewed after the fact.
+
+# FEATURES
+
+  - Parse event details (state, state type, attempt) from conventional
+    command arguments or from Nagios environment macros
+  - Guard type used to express conditions such as "only act on HARD CRITICAL
+    attempt 3"
+  - Handler type used to run guarded remediation actions, logging each
+    decision and outcome
+
+See also:
+
+  - https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/4/en/eventhandlers.html
+*/
+package eventhandler
@@ -0,0 +1,334 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package eventhandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// State type values provided via the $SERVICESTATETYPE$ and $HOSTSTATETYPE$
+// macros.
+const (
+	StateTypeSoft string = "SOFT"
+	StateTypeHard string = "HARD"
+)
+
+// Host state values provided via the $HOSTSTATE$ macro. Service state
+// values match the state labels provided by the nagios package (e.g.,
+// nagios.StateCRITICALLabel).
+const (
+	HostStateUpLabel          string = "UP"
+	HostStateDownLabel        string = "DOWN"
+	HostStateUnreachableLabel string = "UNREACHABLE"
+)
+
+// Environment variables set by Nagios when environment macros are enabled
+// (enable_environment_macros=1).
+const (
+	envServiceState       string = "NAGIOS_SERVICESTATE"
+	envServiceStateType   string = "NAGIOS_SERVICESTATETYPE"
+	envServiceAttempt     string = "NAGIOS_SERVICEATTEMPT"
+	envServiceDescription string = "NAGIOS_SERVICEDESC"
+	envHostState          string = "NAGIOS_HOSTSTATE"
+	envHostStateType      string = "NAGIOS_HOSTSTATETYPE"
+	envHostAttempt        string = "NAGIOS_HOSTATTEMPT"
+	envHostName           string = "NAGIOS_HOSTNAME"
+)
+
+// Sentinel error collection. Exported for potential use by client code to
+// detect & handle specific error scenarios.
+var (
+	// ErrMissingArguments indicates that fewer than the required number of
+	// event handler arguments were provided.
+	ErrMissingArguments = errors.New("missing event handler arguments")
+
+	// ErrInvalidState indicates that an unrecognized state value was
+	// provided.
+	ErrInvalidState = errors.New("invalid state")
+
+	// ErrInvalidStateType indicates that an unrecognized state type value
+	// was provided.
+	ErrInvalidStateType = errors.New("invalid state type")
+
+	// ErrInvalidAttempt indicates that an invalid attempt number was
+	// provided.
+	ErrInvalidAttempt = errors.New("invalid attempt number")
+
+	// ErrEnvironmentMacrosMissing indicates that the expected environment
+	// macros were not found.
+	ErrEnvironmentMacrosMissing = errors.New("event handler environment macros not found")
+)
+
+// Event represents the details of a host or service state change passed to
+// an event handler.
+type Event struct {
+	// State is the current host or service state (e.g., CRITICAL, DOWN).
+	State string
+
+	// StateType is the current state type, SOFT or HARD.
+	StateType string
+
+	// Attempt is the current check attempt number.
+	Attempt int
+
+	// HostName is the optional name of the host associated with the event.
+	HostName string
+
+	// ServiceDescription is the optional description of the service
+	// associated with the event. This is empty for host events.
+	ServiceDescription string
+}
+
+// Parse parses event handler arguments provided in the conventional order:
+// state, state type, attempt number and optionally the host name and
+// service description.
+//
+//	command_line $USER1$/eventhandler $SERVICESTATE$ $SERVICESTATETYPE$ $SERVICEATTEMPT$ $HOSTNAME$ $SERVICEDESC$
+//
+// The program name should not be included (e.g., pass os.Args[1:]).
+func Parse(args []string) (Event, error) {
+	if len(args) < 3 {
+		return Event{}, fmt.Errorf(
+			"%w: want state, state type and attempt, got %d argument(s)",
+			ErrMissingArguments,
+			len(args),
+		)
+	}
+
+	event, err := newEvent(args[0], args[1], args[2])
+	if err != nil {
+		return Event{}, err
+	}
+
+	if len(args) > 3 {
+		event.HostName = args[3]
+	}
+
+	if len(args) > 4 {
+		event.ServiceDescription = args[4]
+	}
+
+	return event, nil
+}
+
+// FromEnv builds an Event from the environment macros set by Nagios when
+// enable_environment_macros is enabled. Service macros are preferred; host
+// macros are used if service macros are not set.
+func FromEnv() (Event, error) {
+	if state := os.Getenv(envServiceState); state != "" {
+		event, err := newEvent(state, os.Getenv(envServiceStateType), os.Getenv(envServiceAttempt))
+		if err != nil {
+			return Event{}, err
+		}
+
+		event.HostName = os.Getenv(envHostName)
+		event.ServiceDescription = os.Getenv(envServiceDescription)
+
+		return event, nil
+	}
+
+	if state := os.Getenv(envHostState); state != "" {
+		event, err := newEvent(state, os.Getenv(envHostStateType), os.Getenv(envHostAttempt))
+		if err != nil {
+			return Event{}, err
+		}
+
+		event.HostName = os.Getenv(envHostName)
+
+		return event, nil
+	}
+
+	return Event{}, ErrEnvironmentMacrosMissing
+}
+
+// newEvent validates the given macro values and builds an Event.
+func newEvent(state string, stateType string, attempt string) (Event, error) {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	switch state {
+	case nagios.StateOKLabel,
+		nagios.StateWARNINGLabel,
+		nagios.StateCRITICALLabel,
+		nagios.StateUNKNOWNLabel,
+		HostStateUpLabel,
+		HostStateDownLabel,
+		HostStateUnreachableLabel:
+	default:
+		return Event{}, fmt.Errorf("%w: %q", ErrInvalidState, state)
+	}
+
+	stateType = strings.ToUpper(strings.TrimSpace(stateType))
+	switch stateType {
+	case StateTypeSoft, StateTypeHard:
+	default:
+		return Event{}, fmt.Errorf("%w: %q", ErrInvalidStateType, stateType)
+	}
+
+	attemptNum, err := strconv.Atoi(strings.TrimSpace(attempt))
+	if err != nil || attemptNum < 1 {
+		return Event{}, fmt.Errorf("%w: %q", ErrInvalidAttempt, attempt)
+	}
+
+	return Event{
+		State:     state,
+		StateType: stateType,
+		Attempt:   attemptNum,
+	}, nil
+}
+
+// IsHard indicates whether the event is for a HARD state.
+func (e Event) IsHard() bool {
+	return e.StateType == StateTypeHard
+}
+
+// IsRecovery indicates whether the event reports a recovery (OK or UP)
+// state.
+func (e Event) IsRecovery() bool {
+	return e.State == nagios.StateOKLabel || e.State == HostStateUpLabel
+}
+
+// String provides a human readable summary of the event.
+func (e Event) String() string {
+	var target string
+	switch {
+	case e.ServiceDescription != "":
+		target = fmt.Sprintf("%s/%s ", e.HostName, e.ServiceDescription)
+	case e.HostName != "":
+		target = e.HostName + " "
+	}
+
+	return fmt.Sprintf("%s%s %s (attempt %d)", target, e.StateType, e.State, e.Attempt)
+}
+
+// Guard describes the conditions under which an event handler action should
+// be taken. Zero values match any event.
+type Guard struct {
+	// States is the optional list of states which the event must match.
+	States []string
+
+	// StateType is the optional state type (SOFT or HARD) which the event
+	// must match.
+	StateType string
+
+	// Attempt is the optional check attempt number which the event must
+	// match.
+	Attempt int
+}
+
+// Allows indicates whether the given event satisfies all guard conditions.
+func (g Guard) Allows(e Event) bool {
+	if len(g.States) > 0 {
+		var matched bool
+		for _, state := range g.States {
+			if strings.EqualFold(state, e.State) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	if g.StateType != "" && !strings.EqualFold(g.StateType, e.StateType) {
+		return false
+	}
+
+	if g.Attempt != 0 && g.Attempt != e.Attempt {
+		return false
+	}
+
+	return true
+}
+
+// String provides a human readable summary of the guard conditions.
+func (g Guard) String() string {
+	parts := make([]string, 0, 3)
+
+	switch {
+	case g.StateType != "":
+		parts = append(parts, strings.ToUpper(g.StateType))
+	default:
+		parts = append(parts, "any type")
+	}
+
+	switch {
+	case len(g.States) > 0:
+		parts = append(parts, strings.ToUpper(strings.Join(g.States, "|")))
+	default:
+		parts = append(parts, "any state")
+	}
+
+	if g.Attempt != 0 {
+		parts = append(parts, fmt.Sprintf("attempt %d", g.Attempt))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Action is a remediation step taken in response to an event.
+type Action func(e Event) error
+
+// Handler evaluates guard conditions for an event and runs the associated
+// actions, logging each decision and outcome.
+type Handler struct {
+	// Event is the event being handled.
+	Event Event
+
+	// logger records the actions taken (or skipped) by the handler.
+	logger *log.Logger
+}
+
+// NewHandler creates a Handler for the given event. Decisions and outcomes
+// are logged to the given writer; os.Stderr is used if w is nil.
+func NewHandler(e Event, w io.Writer) *Handler {
+	if w == nil {
+		w = os.Stderr
+	}
+
+	return &Handler{
+		Event:  e,
+		logger: log.New(w, "eventhandler: ", log.LstdFlags),
+	}
+}
+
+// Run runs the named action if the event satisfies the guard conditions.
+// Whether the action was run is returned along with any error from the
+// action. Both the decision and the outcome are logged.
+func (h *Handler) Run(name string, guard Guard, action Action) (bool, error) {
+	if !guard.Allows(h.Event) {
+		h.logger.Printf(
+			"skipping action %q: event %s does not match guard (%s)",
+			name,
+			h.Event,
+			guard,
+		)
+
+		return false, nil
+	}
+
+	h.logger.Printf("running action %q for event %s", name, h.Event)
+
+	if err := action(h.Event); err != nil {
+		h.logger.Printf("action %q failed: %v", name, err)
+
+		return true, fmt.Errorf("action %q failed: %w", name, err)
+	}
+
+	h.logger.Printf("action %q completed successfully", name)
+
+	return true, nil
+}
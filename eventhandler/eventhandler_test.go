@@ -0,0 +1,125 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package eventhandler_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/eventhandler"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParse asserts that conventional event handler arguments are parsed.
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	got, err := eventhandler.Parse([]string{"critical", "soft", "3", "mail01", "SMTP"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := eventhandler.Event{
+		State:              nagios.StateCRITICALLabel,
+		StateType:          eventhandler.StateTypeSoft,
+		Attempt:            3,
+		HostName:           "mail01",
+		ServiceDescription: "SMTP",
+	}
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestParseRejectsInvalidArguments asserts that invalid or missing
+// arguments are rejected with the expected errors.
+func TestParseRejectsInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args []string
+		want error
+	}{
+		"missing":    {args: []string{"CRITICAL"}, want: eventhandler.ErrMissingArguments},
+		"state":      {args: []string{"BROKEN", "HARD", "1"}, want: eventhandler.ErrInvalidState},
+		"state type": {args: []string{"CRITICAL", "FIRM", "1"}, want: eventhandler.ErrInvalidStateType},
+		"attempt":    {args: []string{"CRITICAL", "HARD", "zero"}, want: eventhandler.ErrInvalidAttempt},
+	}
+
+	for name, tt := range tests {
+		if _, err := eventhandler.Parse(tt.args); !errors.Is(err, tt.want) {
+			t.Errorf("%s: want error wrapping %v, got %v", name, tt.want, err)
+		}
+	}
+}
+
+// TestFromEnv asserts that environment macros are used to build an event.
+func TestFromEnv(t *testing.T) {
+	t.Setenv("NAGIOS_SERVICESTATE", "WARNING")
+	t.Setenv("NAGIOS_SERVICESTATETYPE", "HARD")
+	t.Setenv("NAGIOS_SERVICEATTEMPT", "4")
+	t.Setenv("NAGIOS_HOSTNAME", "mail01")
+	t.Setenv("NAGIOS_SERVICEDESC", "SMTP")
+
+	got, err := eventhandler.FromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.State != nagios.StateWARNINGLabel || !got.IsHard() || got.Attempt != 4 {
+		t.Errorf("unexpected event: %+v", got)
+	}
+}
+
+// TestHandlerRunsOnlyMatchingActions asserts that actions are only run for
+// events matching the guard and that decisions are logged.
+func TestHandlerRunsOnlyMatchingActions(t *testing.T) {
+	t.Parallel()
+
+	event := eventhandler.Event{
+		State:     nagios.StateCRITICALLabel,
+		StateType: eventhandler.StateTypeHard,
+		Attempt:   3,
+	}
+
+	var logOutput strings.Builder
+	handler := eventhandler.NewHandler(event, &logOutput)
+
+	var restarted, paged bool
+
+	ran, err := handler.Run(
+		"restart service",
+		eventhandler.Guard{
+			States:    []string{nagios.StateCRITICALLabel},
+			StateType: eventhandler.StateTypeHard,
+			Attempt:   3,
+		},
+		func(eventhandler.Event) error { restarted = true; return nil },
+	)
+	if !ran || err != nil || !restarted {
+		t.Errorf("want matching action to run: ran=%t, err=%v", ran, err)
+	}
+
+	ran, err = handler.Run(
+		"page on-call",
+		eventhandler.Guard{StateType: eventhandler.StateTypeSoft},
+		func(eventhandler.Event) error { paged = true; return nil },
+	)
+	if ran || err != nil || paged {
+		t.Errorf("want non-matching action to be skipped: ran=%t, err=%v", ran, err)
+	}
+
+	for _, want := range []string{`running action "restart service"`, `skipping action "page on-call"`} {
+		if !strings.Contains(logOutput.String(), want) {
+			t.Errorf("want log output containing %q, got %q", want, logOutput.String())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"github.com/atc0005/go-nagios"
+)
+
+// Ignore this. This is just to satisfy the "whole file" example requirements
+// per https://go.dev/blog/examples.
+var _ = "https://github.com/atc0005/go-nagios"
+
+// ExampleChainResultMethods demonstrates chaining method calls to record the
+// plugin state, summary, performance data and details in a single
+// statement.
+func Example_chainResultMethods() {
+	// First, create an instance of the Plugin type. By default this value is
+	// configured to indicate a successful execution. This should be
+	// overridden by client code to indicate the final plugin state to Nagios
+	// when the plugin exits.
+	var plugin = nagios.NewPlugin()
+
+	// Second, immediately defer ReturnCheckResults() so that it runs as the
+	// last step in your client code. If you do not defer ReturnCheckResults()
+	// immediately any other deferred functions in your client code will not
+	// run.
+	defer plugin.ReturnCheckResults()
+
+	// more stuff here
+
+	// Record the final state, one-line summary, performance data and
+	// details. The OK state label is automatically prepended to the summary.
+	plugin.OK("10 of 10 mailboxes within quota").
+		WithPerfData(nagios.PerformanceData{Label: "mailboxes", Value: "10"}).
+		WithDetail("* largest mailbox: 1.2GB").
+		WithDetail("* smallest mailbox: 12MB")
+}
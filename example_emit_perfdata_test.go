@@ -10,7 +10,6 @@ package nagios_test
 import (
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/atc0005/go-nagios"
 )
@@ -22,20 +21,12 @@ var _ = "https://github.com/atc0005/go-nagios"
 // ExampleEmitPerformanceData demonstrates providing multiple plugin
 // performance data values explicitly.
 //
-// NOTE: While this example illustrates providing a time metric, this metric
-// is provided for you if using the nagios.NewPlugin constructor. If
-// specifying this value ourselves, *our* value takes precedence and the
-// default value is ignored.
+// NOTE: While this example illustrates enabling the runtime metric
+// explicitly, a `time` metric is provided for you if using the
+// nagios.NewPlugin constructor (see also the nagios.WithRuntimeMetric
+// option). If specifying a metric with the same label ourselves, *our* value
+// takes precedence and the default value is ignored.
 func Example_emitPerformanceData() {
-	// Start the timer. We'll use this to emit the plugin runtime as a
-	// performance data metric.
-	//
-	// NOTE: While this example illustrates providing a time metric, this
-	// metric is provided for you if using the nagios.NewPlugin constructor.
-	// If specifying this value ourselves, *our* value takes precedence and
-	// the default value is ignored.
-	pluginStart := time.Now()
-
 	// First, create an instance of the Plugin type. Here we're opting to
 	// manually construct the Plugin value instead of using the constructor
 	// (mostly for contrast).
@@ -63,16 +54,14 @@ func Example_emitPerformanceData() {
 	// deferred functions to run.
 	defer plugin.ReturnCheckResults()
 
+	// Start the timer. The plugin runtime is emitted as a `time` performance
+	// data metric when ReturnCheckResults is called.
+	//
+	// NOTE: This metric is provided by default if using the provided
+	// nagios.NewPlugin constructor.
+	plugin.SetRuntimeMetric("time")
+
 	pd := []nagios.PerformanceData{
-		{
-			// NOTE: This metric is provided by default if using the provided
-			// nagios.NewPlugin constructor.
-			//
-			// If we specify this value ourselves, *our* value takes
-			// precedence and the default value is ignored.
-			Label: "time",
-			Value: fmt.Sprintf("%dms", time.Since(pluginStart).Milliseconds()),
-		},
 		{
 			Label: "datacenters",
 			Value: fmt.Sprintf("%d", 2),
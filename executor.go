@@ -0,0 +1,557 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// checksNoun is used to refer to sub-checks in the one-line summary set
+	// by RunChecks.
+	checksNoun string = "checks"
+
+	// subCheckPerfDataSeparator separates the sub-check name from the
+	// performance data label of metrics merged by RunChecks.
+	subCheckPerfDataSeparator string = "_"
+
+	// elapsedPerfDataLabel is the performance data label used to record the
+	// time spent on a sub-check which timed out.
+	elapsedPerfDataLabel string = "elapsed"
+)
+
+// ErrCheckTimeout indicates that a sub-check did not complete before the
+// timeout expired.
+var ErrCheckTimeout = errors.New("check timed out")
+
+// ErrCheckCancelled indicates that a sub-check was cancelled (or not
+// started) because the rollup state could no longer improve.
+var ErrCheckCancelled = errors.New("check cancelled")
+
+// CheckResult is the result reported by a sub-check run by RunChecks.
+type CheckResult struct {
+	// ExitCode is the state of the sub-check.
+	ExitCode int
+
+	// Summary is a short description of the sub-check result.
+	Summary string
+
+	// PerfData is the collection of performance data metrics reported by
+	// the sub-check. Labels are prefixed with the sub-check name when
+	// merged.
+	PerfData []PerformanceData
+
+	// Err is an optional error encountered by the sub-check.
+	Err error
+}
+
+// NamedCheck is an independent probe (e.g., a single endpoint) run by
+// RunChecks. The check should return promptly once ctx is done.
+type NamedCheck struct {
+	// Name identifies the sub-check in plugin output and is used as a
+	// prefix for its performance data labels.
+	Name string
+
+	// Check is the probe logic.
+	Check func(ctx context.Context) CheckResult
+
+	// DependsOn is the optional collection of sub-check names which must
+	// complete with an OK state before this sub-check is run (e.g., "query"
+	// depends on "auth"). If a dependency is not OK this sub-check is
+	// skipped and reported as DEPENDENT.
+	DependsOn []string
+
+	// Timeout is the optional maximum time spent running the sub-check. A
+	// sub-check still running once the timeout expires is abandoned and
+	// reported using the check timeout state (see SetCheckTimeoutState) so
+	// that one stuck backend does not mask the results of the others.
+	Timeout time.Duration
+}
+
+// NamedCheckResult is the outcome of a NamedCheck.
+type NamedCheckResult struct {
+	CheckResult
+
+	// Name identifies the sub-check.
+	Name string
+
+	// Duration is the time spent running the sub-check.
+	Duration time.Duration
+}
+
+// SubResult returns the name and state of the sub-check for use with
+// aggregation expressions (see And, Or and AtLeast).
+func (ncr NamedCheckResult) SubResult() SubResult {
+	return SubResult{Name: ncr.Name, ExitCode: ncr.ExitCode}
+}
+
+// WithFailFast is an Option used to enable fail-fast mode for RunChecks. See
+// also EnableFailFast.
+func WithFailFast() Option {
+	return func(p *Plugin) {
+		p.EnableFailFast()
+	}
+}
+
+// EnableFailFast causes RunChecks to cancel outstanding sub-checks (and skip
+// those not yet started) as soon as a sub-check reports CRITICAL, as the
+// rollup state can no longer improve. This saves time on large fan-outs.
+// Cancelled sub-checks are reported as UNKNOWN with an error wrapping
+// ErrCheckCancelled; the context passed to running sub-checks is cancelled
+// so that they may return early.
+func (p *Plugin) EnableFailFast() {
+	p.failFast = true
+}
+
+// WithCheckTimeoutState is an Option used to override the state reported
+// for sub-checks which time out. See also SetCheckTimeoutState.
+func WithCheckTimeoutState(exitCode int) Option {
+	return func(p *Plugin) {
+		p.SetCheckTimeoutState(exitCode)
+	}
+}
+
+// SetCheckTimeoutState overrides the state (UNKNOWN by default) reported for
+// sub-checks run by RunChecks which do not complete before their timeout.
+func (p *Plugin) SetCheckTimeoutState(exitCode int) {
+	p.checkTimeoutState = &exitCode
+}
+
+// getCheckTimeoutState returns the state reported for sub-checks which time
+// out.
+func (p Plugin) getCheckTimeoutState() int {
+	if p.checkTimeoutState != nil {
+		return *p.checkTimeoutState
+	}
+
+	return StateUNKNOWNExitCode
+}
+
+// RunChecks runs the given independent checks concurrently, running at
+// most concurrency checks at a time (all checks at once if concurrency is
+// not positive). Checks still running once their own timeout (see
+// NamedCheck) or the overall timeout expires are abandoned and reported
+// using the check timeout state (UNKNOWN by default, see
+// SetCheckTimeoutState) with an error wrapping ErrCheckTimeout and the time
+// spent recorded as an "elapsed" performance data metric; an overall
+// timeout of zero disables the overall limit (the plugin timeout still
+// applies, see SetTimeout). A panic within a check is
+// recovered and reported as UNKNOWN. The rate at which checks are started
+// may be limited (see SetCheckRateLimit). If tracing is enabled (see
+// SetTracer), a child span is recorded for each sub-check which is started.
+//
+// Once all checks complete, the plugin state is set to the most severe
+// sub-check state (the rollup state) and the one-line summary
+// (ServiceOutput) to the number of sub-checks in each state:
+//
+//	CRITICAL: 2 critical, 1 warning, 47 ok
+//
+// The result of each sub-check is appended to LongServiceOutput (non-OK
+// sub-checks first, most severe first), sub-check
+// errors are recorded with the sub-check name and sub-check performance
+// data is merged with labels prefixed by the sub-check name (e.g.,
+// "web01_time"). The results are returned in the order given.
+func (p *Plugin) RunChecks(checks []NamedCheck, concurrency int, timeout time.Duration) []NamedCheckResult {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if deadline, ok := p.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+
+		// Report the effective timeout for unfinished sub-checks.
+		if remaining := time.Until(deadline).Round(time.Millisecond); timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	if concurrency <= 0 || concurrency > len(checks) {
+		concurrency = len(checks)
+	}
+
+	timeoutState := p.getCheckTimeoutState()
+	limiter := p.checkRateLimiter()
+
+	// Sub-checks use a separate context so that outstanding sub-checks can
+	// be cancelled once the rollup state can no longer improve.
+	checkCtx, cancelChecks := context.WithCancel(ctx)
+	defer cancelChecks()
+
+	results := make([]NamedCheckResult, len(checks))
+	done := make([]bool, len(checks))
+
+	type completion struct {
+		index    int
+		result   NamedCheckResult
+		finished bool
+	}
+
+	completions := make(chan completion, len(checks))
+	slots := make(chan struct{}, concurrency)
+
+	dependencies, dependencyErrs := resolveDependencies(checks)
+
+	// finished is closed once the result of the corresponding sub-check is
+	// known, at which point its state is available to dependent sub-checks.
+	finished := make([]chan struct{}, len(checks))
+	states := make([]int, len(checks))
+	for i := range finished {
+		finished[i] = make(chan struct{})
+	}
+
+	complete := func(i int, result NamedCheckResult) {
+		states[i] = result.ExitCode
+		close(finished[i])
+		completions <- completion{index: i, result: result, finished: true}
+	}
+
+	if p.progress != nil {
+		p.progress.SetTotal(len(checks))
+	}
+
+	start := time.Now()
+
+	var started int32
+
+	// launch starts the given sub-check once a slot is available, returning
+	// false if the sub-checks are cancelled first.
+	launch := func(i int) bool {
+		select {
+		case slots <- struct{}{}:
+		case <-checkCtx.Done():
+			return false
+		}
+
+		if limiter != nil {
+			if err := limiter.wait(checkCtx); err != nil {
+				<-slots
+				return false
+			}
+		}
+
+		// Both cases above may be ready at once; do not start further
+		// sub-checks once cancelled.
+		if checkCtx.Err() != nil {
+			<-slots
+			return false
+		}
+
+		atomic.AddInt32(&started, 1)
+
+		go func() {
+			defer func() { <-slots }()
+
+			span := p.StartSpan(checks[i].Name)
+
+			result, ok := runCheck(checkCtx, checks[i], timeoutState)
+			if !ok {
+				span.End()
+				completions <- completion{index: i}
+				return
+			}
+
+			p.endCheckSpan(span, result)
+			complete(i, result)
+		}()
+
+		return true
+	}
+
+	// awaitDependencies starts the given sub-check once all of its
+	// dependencies are OK or skips it as soon as one is not.
+	awaitDependencies := func(i int) {
+		for _, dependency := range dependencies[i] {
+			select {
+			case <-finished[dependency]:
+			case <-checkCtx.Done():
+				return
+			}
+
+			if states[dependency] != StateOKExitCode {
+				complete(i, skippedCheckResult(checks[i].Name, checks[dependency].Name, states[dependency]))
+				return
+			}
+		}
+
+		launch(i)
+	}
+
+	go func() {
+		for i := range checks {
+			switch {
+			case dependencyErrs[i] != nil:
+				complete(i, NamedCheckResult{
+					Name: checks[i].Name,
+					CheckResult: CheckResult{
+						ExitCode: StateUNKNOWNExitCode,
+						Summary:  "check not run",
+						Err:      dependencyErrs[i],
+					},
+				})
+			case len(dependencies[i]) > 0:
+				go awaitDependencies(i)
+			default:
+				if !launch(i) {
+					return
+				}
+			}
+		}
+	}()
+
+collect:
+	for remaining := len(checks); remaining > 0; remaining-- {
+		select {
+		case c := <-completions:
+			if !c.finished {
+				continue
+			}
+
+			results[c.index] = c.result
+			done[c.index] = true
+
+			if p.progress != nil {
+				p.progress.Add(1)
+			}
+
+			if p.failFast && c.result.ExitCode == StateCRITICALExitCode {
+				cancelChecks()
+			}
+		case <-checkCtx.Done():
+			break collect
+		}
+	}
+
+	// Include checks which completed just as the timeout expired.
+	for drained := false; !drained; {
+		select {
+		case c := <-completions:
+			results[c.index] = c.result
+			done[c.index] = c.finished
+		default:
+			drained = true
+		}
+	}
+
+	var unfinished bool
+	for i := range checks {
+		switch {
+		case done[i]:
+		case ctx.Err() == nil:
+			results[i] = cancelledCheckResult(checks[i].Name)
+		default:
+			unfinished = true
+			results[i] = timedOutCheckResult(checks[i].Name, time.Since(start), timeout, timeoutState)
+		}
+	}
+
+	if limiter != nil {
+		p.recordCheckRate(limiter, int(atomic.LoadInt32(&started)), time.Since(start))
+	}
+
+	if unfinished && p.partialResultsState != nil {
+		p.recordPartialCheckResults(results, done)
+		return results
+	}
+
+	p.recordCheckResults(results)
+
+	return results
+}
+
+// runCheck runs the given check, recovering from any panic. If the check
+// timeout expires first, the check is abandoned and reported using the
+// given timeout state. The check is reported as not finished if ctx is done
+// (i.e., the overall timeout expired or the check was cancelled) first.
+func runCheck(ctx context.Context, check NamedCheck, timeoutState int) (NamedCheckResult, bool) {
+	checkCtx := ctx
+	if check.Timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, check.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	checkResults := make(chan CheckResult, 1)
+	go func() {
+		checkResults <- safeCheck(checkCtx, check)
+	}()
+
+	var result NamedCheckResult
+
+	select {
+	case checkResult := <-checkResults:
+		result = NamedCheckResult{
+			CheckResult: checkResult,
+			Name:        check.Name,
+			Duration:    time.Since(start),
+		}
+	case <-checkCtx.Done():
+		result = timedOutCheckResult(check.Name, time.Since(start), check.Timeout, timeoutState)
+	}
+
+	// Results returned once ctx is done may be an artifact of cancellation.
+	if ctx.Err() != nil {
+		return NamedCheckResult{}, false
+	}
+
+	return result, true
+}
+
+// safeCheck runs the given check, reporting any panic as UNKNOWN.
+func safeCheck(ctx context.Context, check NamedCheck) (result CheckResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = CheckResult{
+				ExitCode: StateUNKNOWNExitCode,
+				Summary:  "check crashed",
+				Err:      fmt.Errorf("%w: %v", ErrPanicDetected, r),
+			}
+		}
+	}()
+
+	return check.Check(ctx)
+}
+
+// timedOutCheckResult returns the result reported for a check which did not
+// complete before the given timeout.
+func timedOutCheckResult(name string, elapsed time.Duration, timeout time.Duration, timeoutState int) NamedCheckResult {
+	return NamedCheckResult{
+		Name:     name,
+		Duration: elapsed,
+		CheckResult: CheckResult{
+			ExitCode: timeoutState,
+			Summary:  "check did not complete before timeout",
+			Err:      fmt.Errorf("%w after %s", ErrCheckTimeout, timeout),
+			PerfData: []PerformanceData{
+				{
+					Label:             elapsedPerfDataLabel,
+					Value:             fmt.Sprintf("%.3f", elapsed.Seconds()),
+					UnitOfMeasurement: "s",
+				},
+			},
+		},
+	}
+}
+
+// skippedCheckResult returns the result reported for a check which was not
+// run because the given dependency was not OK.
+func skippedCheckResult(name string, dependency string, dependencyState int) NamedCheckResult {
+	return NamedCheckResult{
+		Name: name,
+		CheckResult: CheckResult{
+			ExitCode: StateDEPENDENTExitCode,
+			Summary:  fmt.Sprintf("skipped, depends on %s (%s)", dependency, stateLabel(dependencyState)),
+		},
+	}
+}
+
+// cancelledCheckResult returns the result reported for a check which was
+// cancelled (or not started) in fail-fast mode.
+func cancelledCheckResult(name string) NamedCheckResult {
+	return NamedCheckResult{
+		Name: name,
+		CheckResult: CheckResult{
+			ExitCode: StateUNKNOWNExitCode,
+			Summary:  "check cancelled after CRITICAL result",
+			Err:      ErrCheckCancelled,
+		},
+	}
+}
+
+// recordCheckResults records the given sub-check results as described by
+// RunChecks.
+func (p *Plugin) recordCheckResults(results []NamedCheckResult) {
+	states := make([]int, 0, len(results))
+	counts := make(map[string]int)
+
+	for _, result := range results {
+		states = append(states, result.ExitCode)
+		counts[stateLabel(result.ExitCode)]++
+	}
+
+	for _, result := range sortedBySeverity(results) {
+		p.recordCheckResult(result, true)
+	}
+
+	state := worstExitCode(states...)
+
+	p.ExitStatusCode = state
+	p.ServiceOutput = fmt.Sprintf("%s: %s", stateLabel(state), stateCountsText(counts))
+}
+
+// sortedBySeverity returns a copy of the given results ordered from the
+// most to the least severe state, retaining the given order of results
+// with the same state.
+func sortedBySeverity(results []NamedCheckResult) []NamedCheckResult {
+	sorted := make([]NamedCheckResult, len(results))
+	copy(sorted, results)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return stateSeverity(sorted[i].ExitCode) > stateSeverity(sorted[j].ExitCode)
+	})
+
+	return sorted
+}
+
+// stateCountsText lists the given number of results by state label, most
+// severe first (e.g., "2 critical, 1 warning, 47 ok"). States without
+// results are omitted.
+func stateCountsText(counts map[string]int) string {
+	// Ordered from most to least severe for display purposes.
+	labels := []string{
+		StateCRITICALLabel,
+		StateWARNINGLabel,
+		StateUNKNOWNLabel,
+		StateDEPENDENTLabel,
+		StateOKLabel,
+	}
+
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if count := counts[label]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, strings.ToLower(label)))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// recordCheckResult records the details (optionally omitted for OK
+// results), errors and performance data of a single sub-check result.
+func (p *Plugin) recordCheckResult(result NamedCheckResult, includeOK bool) {
+	if includeOK || result.ExitCode != StateOKExitCode {
+		line := p.listItem("%s: %s", result.Name, stateLabel(result.ExitCode))
+		if result.Summary != "" {
+			line += " - " + result.Summary
+		}
+		p.WithDetail(line)
+	}
+
+	if result.Err != nil {
+		p.AddError(fmt.Errorf("%s: %w", result.Name, result.Err))
+	}
+
+	for _, pd := range result.PerfData {
+		pd.Label = result.Name + subCheckPerfDataSeparator + pd.Label
+		if err := p.AddPerfData(false, pd); err != nil {
+			p.AddError(fmt.Errorf("%s: %w", result.Name, err))
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRunChecksRollsUpResults asserts that sub-checks run concurrently
+// within the concurrency limit and that their results are rolled up.
+func TestRunChecksRollsUpResults(t *testing.T) {
+	t.Parallel()
+
+	var running, maxRunning int32
+
+	probe := func(exitCode int, summary string) func(context.Context) nagios.CheckResult {
+		return func(context.Context) nagios.CheckResult {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if n <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, n) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			return nagios.CheckResult{
+				ExitCode: exitCode,
+				Summary:  summary,
+				PerfData: []nagios.PerformanceData{{Label: "time", Value: "12", UnitOfMeasurement: "ms"}},
+			}
+		}
+	}
+
+	checks := []nagios.NamedCheck{
+		{Name: "web01", Check: probe(nagios.StateOKExitCode, "200 OK")},
+		{Name: "web02", Check: probe(nagios.StateWARNINGExitCode, "slow response")},
+		{Name: "web03", Check: probe(nagios.StateOKExitCode, "200 OK")},
+		{Name: "web04", Check: func(context.Context) nagios.CheckResult { panic("nil map") }},
+	}
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	results := plugin.RunChecks(checks, 2, 0)
+
+	if maxRunning > 2 {
+		t.Errorf("want at most 2 concurrent checks, got %d", maxRunning)
+	}
+
+	if len(results) != len(checks) || results[1].Name != "web02" {
+		t.Fatalf("want results in the order given, got %+v", results)
+	}
+
+	if !errors.Is(results[3].Err, nagios.ErrPanicDetected) {
+		t.Errorf("want panic reported for web04, got %v", results[3].Err)
+	}
+
+	if plugin.ExitStatusCode != nagios.StateWARNINGExitCode {
+		t.Errorf("want rollup state %d, got %d", nagios.StateWARNINGExitCode, plugin.ExitStatusCode)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"WARNING: 1 warning, 1 unknown, 2 ok",
+		"* web02: WARNING - slow response",
+		"* web04: UNKNOWN - check crashed",
+		"'web01_time'=12ms;;;;",
+		"'web03_time'=12ms;;;;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	// Non-OK results are listed first, most severe first.
+	warning := strings.Index(got, "* web02: WARNING")
+	unknown := strings.Index(got, "* web04: UNKNOWN")
+	ok := strings.Index(got, "* web01: OK")
+	if !(warning < unknown && unknown < ok) {
+		t.Errorf("want non-OK results listed before OK results, most severe first, got:\n%s", got)
+	}
+}
+
+// TestRunChecksAbandonsStuckChecks asserts that checks still running when
+// the timeout expires are reported as UNKNOWN without delaying the results
+// of the other checks.
+func TestRunChecksAbandonsStuckChecks(t *testing.T) {
+	t.Parallel()
+
+	stuck := make(chan struct{})
+	t.Cleanup(func() { close(stuck) })
+
+	checks := []nagios.NamedCheck{
+		{Name: "db01", Check: func(context.Context) nagios.CheckResult {
+			return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+		}},
+		{Name: "db02", Check: func(context.Context) nagios.CheckResult {
+			<-stuck
+			return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+		}},
+	}
+
+	plugin := nagios.Plugin{}
+
+	results := plugin.RunChecks(checks, 0, 50*time.Millisecond)
+
+	if results[0].ExitCode != nagios.StateOKExitCode {
+		t.Errorf("want db01 OK, got %d", results[0].ExitCode)
+	}
+
+	if results[1].ExitCode != nagios.StateUNKNOWNExitCode || !errors.Is(results[1].Err, nagios.ErrCheckTimeout) {
+		t.Errorf("want db02 timed out, got %+v", results[1])
+	}
+
+	if plugin.ExitStatusCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want rollup state %d, got %d", nagios.StateUNKNOWNExitCode, plugin.ExitStatusCode)
+	}
+}
+
+// TestRunChecksAppliesPerCheckTimeouts asserts that a check exceeding its
+// own timeout is reported using the configured timeout state along with its
+// elapsed time.
+func TestRunChecksAppliesPerCheckTimeouts(t *testing.T) {
+	t.Parallel()
+
+	stuck := make(chan struct{})
+	t.Cleanup(func() { close(stuck) })
+
+	checks := []nagios.NamedCheck{
+		{Name: "api01", Check: func(context.Context) nagios.CheckResult {
+			return nagios.CheckResult{ExitCode: nagios.StateOKExitCode, Summary: "200 OK"}
+		}},
+		{Name: "api02", Timeout: 20 * time.Millisecond, Check: func(context.Context) nagios.CheckResult {
+			<-stuck
+			return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+		}},
+	}
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+	plugin.SetCheckTimeoutState(nagios.StateCRITICALExitCode)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	results := plugin.RunChecks(checks, 0, time.Minute)
+
+	if !errors.Is(results[1].Err, nagios.ErrCheckTimeout) {
+		t.Errorf("want api02 timed out, got %+v", results[1])
+	}
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want rollup state %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"* api01: OK - 200 OK",
+		"* api02: CRITICAL - check did not complete before timeout",
+		"api02: check timed out after 20ms",
+		"'api02_elapsed'=0.0",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestRunChecksFailFast asserts that outstanding sub-checks are cancelled
+// once a sub-check reports CRITICAL when fail-fast mode is enabled.
+func TestRunChecksFailFast(t *testing.T) {
+	t.Parallel()
+
+	slow := func(ctx context.Context) nagios.CheckResult {
+		select {
+		case <-ctx.Done():
+			return nagios.CheckResult{ExitCode: nagios.StateUNKNOWNExitCode, Err: ctx.Err()}
+		case <-time.After(time.Minute):
+			return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+		}
+	}
+
+	checks := []nagios.NamedCheck{
+		{Name: "link01", Check: func(context.Context) nagios.CheckResult {
+			return nagios.CheckResult{ExitCode: nagios.StateCRITICALExitCode, Summary: "link down"}
+		}},
+		{Name: "link02", Check: slow},
+		{Name: "link03", Check: slow},
+		{Name: "link04", Check: slow},
+	}
+
+	plugin := nagios.Plugin{}
+	plugin.EnableFailFast()
+
+	start := time.Now()
+	results := plugin.RunChecks(checks, 2, 0)
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("want outstanding checks cancelled, took %s", elapsed)
+	}
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want rollup state %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	for _, result := range results[1:] {
+		if !errors.Is(result.Err, nagios.ErrCheckCancelled) {
+			t.Errorf("want %s cancelled, got %+v", result.Name, result)
+		}
+	}
+}
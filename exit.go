@@ -0,0 +1,61 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"runtime"
+)
+
+// ExitOK sets the plugin state to OK and the one-line summary to the given
+// summary prefixed with the OK state label, then immediately ends plugin
+// execution. See ExitCritical for details.
+func (p *Plugin) ExitOK(summary string) {
+	p.OK(summary)
+	terminate()
+}
+
+// ExitWarning sets the plugin state to WARNING and the one-line summary to
+// the given summary prefixed with the WARNING state label, then immediately
+// ends plugin execution. See ExitCritical for details.
+func (p *Plugin) ExitWarning(summary string) {
+	p.Warning(summary)
+	terminate()
+}
+
+// ExitCritical sets the plugin state to CRITICAL and the one-line summary to
+// the given summary prefixed with the CRITICAL state label, then immediately
+// ends plugin execution. This is intended for plugins that need to bail out
+// from deep within a call stack without plumbing return values upward.
+//
+// Execution is ended by terminating the calling goroutine (via
+// runtime.Goexit). Unlike calling os.Exit directly, all deferred functions
+// are run, including the deferred ReturnCheckResults method which is
+// responsible for emitting plugin output and setting the exit code. Because
+// this is not a panic, ReturnCheckResults does not report a plugin crash.
+//
+// IMPORTANT: This method must be called from the same goroutine which
+// deferred ReturnCheckResults (usually main). If called from another
+// goroutine only that goroutine is terminated.
+func (p *Plugin) ExitCritical(summary string) {
+	p.Critical(summary)
+	terminate()
+}
+
+// ExitUnknown sets the plugin state to UNKNOWN and the one-line summary to
+// the given summary prefixed with the UNKNOWN state label, then immediately
+// ends plugin execution. See ExitCritical for details.
+func (p *Plugin) ExitUnknown(summary string) {
+	p.Unknown(summary)
+	terminate()
+}
+
+// terminate ends execution of the calling goroutine after running all
+// deferred functions.
+func terminate() {
+	runtime.Goexit()
+}
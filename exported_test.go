@@ -11,6 +11,7 @@ package nagios_test
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -414,3 +415,303 @@ func TestEmptyClientPerfDataAndConstructedPluginProducesDefaultTimeMetric(t *tes
 		t.Logf("OK: Emitted performance data contains the expected time metric.")
 	}
 }
+
+// TestPluginSettersProduceExpectedOutput asserts that using the setter
+// methods produces the same output as assigning field values directly.
+func TestPluginSettersProduceExpectedOutput(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetSummary("WARNING: 3 of 10 mailboxes over quota")
+	plugin.SetLongServiceOutput("see mailbox report for details")
+	plugin.SetState(nagios.StateWARNINGExitCode)
+
+	// Process exit state, emit output to our output buffer.
+	plugin.ReturnCheckResults()
+
+	want := "WARNING: 3 of 10 mailboxes over quota" +
+		nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"see mailbox report for details" +
+		nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if plugin.ExitStatusCode != nagios.StateWARNINGExitCode {
+		t.Errorf(
+			"want exit status code %d, got %d",
+			nagios.StateWARNINGExitCode,
+			plugin.ExitStatusCode,
+		)
+	}
+}
+
+// TestChainedResultMethodsProduceExpectedOutput asserts that chained result
+// methods record the expected state, summary and details.
+func TestChainedResultMethodsProduceExpectedOutput(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Critical("2 of 10 mailboxes over quota").
+		WithPerfData(nagios.PerformanceData{Label: "over_quota", Value: "2"}).
+		WithDetail("* alice: 5.1GB").
+		WithDetail("* bob: 6.3GB")
+
+	// Process exit state, emit output to our output buffer.
+	plugin.ReturnCheckResults()
+
+	want := "CRITICAL: 2 of 10 mailboxes over quota" +
+		nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"* alice: 5.1GB" + nagios.CheckOutputEOL +
+		"* bob: 6.3GB" + nagios.CheckOutputEOL +
+		" | 'over_quota'=2;;;;" + nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf(
+			"want exit status code %d, got %d",
+			nagios.StateCRITICALExitCode,
+			plugin.ExitStatusCode,
+		)
+	}
+}
+
+// TestWithPerfDataRecordsValidationErrors asserts that invalid performance
+// data provided via the chainable method is recorded as an error.
+func TestWithPerfDataRecordsValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	plugin.OK("all good").WithPerfData(nagios.PerformanceData{Value: "2"})
+
+	if len(plugin.Errors) != 1 || !errors.Is(plugin.Errors[0], nagios.ErrPerformanceDataMissingLabel) {
+		t.Errorf(
+			"want single error wrapping %v, got %v",
+			nagios.ErrPerformanceDataMissingLabel,
+			plugin.Errors,
+		)
+	}
+}
+
+// TestNewPluginAppliesOptions asserts that functional options provided to
+// the constructor are applied.
+func TestNewPluginAppliesOptions(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithErrorsLabel("PROBLEMS"),
+		nagios.WithBrandingCallback(func() string { return "check-mailbox v1.0.0" }),
+	)
+
+	plugin.Warning("1 of 10 mailboxes over quota").
+		WithDetail("* alice: 5.1GB").
+		WithError(errors.New("mailbox alice over quota"))
+
+	// Process exit state, emit output to our output buffer.
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{"**PROBLEMS**", "check-mailbox v1.0.0", "'time'="} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output containing %q, got %q", want, got)
+		}
+	}
+}
+
+// TestFormattedSettersEmitLiteralText asserts that text recorded via the
+// formatted setters is emitted without further interpretation of percent
+// characters.
+func TestFormattedSettersEmitLiteralText(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	errQuota := errors.New("quota exceeded")
+
+	// The '% o' pattern is treated as a formatting verb if passed through
+	// fmt.Printf and related functions a second time.
+	plugin.SetServiceOutputf("%s: datastore usage is %s of capacity", nagios.StateCRITICALLabel, "96% over")
+	plugin.AddErrorf("datastore %q at %d%% usage: %w", "vol6", 96, errQuota)
+
+	// Process exit state, emit output to our output buffer.
+	plugin.ReturnCheckResults()
+
+	want := "CRITICAL: datastore usage is 96% over of capacity" +
+		nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"**ERRORS**" +
+		nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		`* datastore "vol6" at 96% usage: quota exceeded` +
+		nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if !errors.Is(plugin.Errors[0], errQuota) {
+		t.Errorf("want error wrapping %v, got %v", errQuota, plugin.Errors[0])
+	}
+}
+
+// TestStateWithErrorHelpersRecordStateSummaryAndError asserts that the
+// one-call state helpers record the state, summary and error together.
+func TestStateWithErrorHelpersRecordStateSummaryAndError(t *testing.T) {
+	t.Parallel()
+
+	errConnect := errors.New("connection refused")
+
+	tests := map[string]struct {
+		apply       func(p *nagios.Plugin, summary string, err error) *nagios.Plugin
+		wantCode    int
+		wantSummary string
+	}{
+		"warning": {
+			apply:       (*nagios.Plugin).WarningWithError,
+			wantCode:    nagios.StateWARNINGExitCode,
+			wantSummary: "WARNING: failed to connect",
+		},
+		"critical": {
+			apply:       (*nagios.Plugin).CriticalWithError,
+			wantCode:    nagios.StateCRITICALExitCode,
+			wantSummary: "CRITICAL: failed to connect",
+		},
+		"unknown": {
+			apply:       (*nagios.Plugin).UnknownWithError,
+			wantCode:    nagios.StateUNKNOWNExitCode,
+			wantSummary: "UNKNOWN: failed to connect",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			plugin := nagios.NewPlugin()
+			tt.apply(plugin, "failed to connect", errConnect)
+
+			if plugin.ExitStatusCode != tt.wantCode {
+				t.Errorf("want exit status code %d, got %d", tt.wantCode, plugin.ExitStatusCode)
+			}
+
+			if plugin.ServiceOutput != tt.wantSummary {
+				t.Errorf("want summary %q, got %q", tt.wantSummary, plugin.ServiceOutput)
+			}
+
+			if len(plugin.Errors) != 1 || !errors.Is(plugin.Errors[0], errConnect) {
+				t.Errorf("want single error %v, got %v", errConnect, plugin.Errors)
+			}
+		})
+	}
+}
+
+// TestExitCriticalRunsDeferredFunctionsAndEmitsOutput asserts that the exit
+// helpers end execution immediately while still running deferred functions,
+// including ReturnCheckResults.
+func TestExitCriticalRunsDeferredFunctionsAndEmitsOutput(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+	)
+
+	var cleanupRan bool
+
+	done := make(chan struct{})
+
+	// The exit helpers terminate the calling goroutine, so we simulate the
+	// main function of a plugin using a separate goroutine.
+	go func() {
+		defer close(done)
+		defer plugin.ReturnCheckResults()
+		defer func() { cleanupRan = true }()
+
+		func() {
+			plugin.ExitCritical("failed to query datastore")
+		}()
+
+		t.Error("execution continued after ExitCritical call")
+	}()
+
+	<-done
+
+	if !cleanupRan {
+		t.Error("deferred function did not run")
+	}
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf(
+			"want exit status code %d, got %d",
+			nagios.StateCRITICALExitCode,
+			plugin.ExitStatusCode,
+		)
+	}
+
+	want := "CRITICAL: failed to query datastore | 'time'="
+	if got := outputBuffer.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("want output with prefix %q, got %q", want, got)
+	}
+
+	if len(plugin.Errors) != 0 {
+		t.Errorf("unexpected errors recorded: %v", plugin.Errors)
+	}
+}
+
+// TestWithRuntimeMetricUsesCustomLabel asserts that the runtime metric is
+// emitted using the custom label in place of the default time metric.
+func TestWithRuntimeMetricUsesCustomLabel(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithRuntimeMetric("runtime"),
+	)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.OK("all good")
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+	if !strings.Contains(got, " | 'runtime'=") || strings.Contains(got, "'time'=") {
+		t.Errorf("want runtime metric using custom label, got %q", got)
+	}
+}
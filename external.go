@@ -0,0 +1,131 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// DefaultExternalPluginTimeout is the timeout applied by RunExternalPlugin
+// if a timeout is not specified by client code. This matches the default
+// service check timeout used by Nagios.
+const DefaultExternalPluginTimeout time.Duration = 60 * time.Second
+
+var (
+	// ErrExternalPluginTimeout indicates that an external plugin did not
+	// complete within the specified timeout.
+	ErrExternalPluginTimeout = errors.New("external plugin timed out")
+
+	// ErrExternalPluginFailedToRun indicates that an external plugin could
+	// not be run (e.g., the command was not found or is not executable).
+	ErrExternalPluginFailedToRun = errors.New("external plugin failed to run")
+)
+
+// ExternalResult is the result of running an external plugin.
+type ExternalResult struct {
+	ParsedOutput
+
+	// ExitCode is the exit code of the external plugin.
+	ExitCode int
+
+	// Stdout is the unmodified standard output of the external plugin.
+	Stdout string
+
+	// Stderr is the standard error output of the external plugin. Nagios
+	// ignores this output, but it is often useful for troubleshooting.
+	Stderr string
+
+	// Duration is how long the external plugin ran.
+	Duration time.Duration
+}
+
+// RunExternalPlugin runs the given external plugin command with the given
+// timeout, capturing its output and exit code. The output is parsed into
+// the one-line summary, LongServiceOutput and performance data. If timeout
+// is zero DefaultExternalPluginTimeout is used.
+//
+// This is the foundation for wrapper and aggregation plugins; the result
+// may be recorded using ImportExternalResult and augmented before being
+// emitted.
+//
+// An error wrapping ErrExternalPluginTimeout is returned if the plugin does
+// not complete in time and an error wrapping ErrExternalPluginFailedToRun
+// is returned if the plugin could not be run. In both cases the ExitCode
+// of the result is set to StateUNKNOWNExitCode. An error wrapping
+// ErrInvalidPerformanceData is returned along with the (otherwise complete)
+// result if the performance data emitted by the plugin could not be parsed.
+func RunExternalPlugin(timeout time.Duration, name string, args ...string) (ExternalResult, error) {
+	if timeout <= 0 {
+		timeout = DefaultExternalPluginTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+
+	result := ExternalResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		result.ExitCode = StateUNKNOWNExitCode
+		return result, fmt.Errorf("%w: %s did not complete within %v", ErrExternalPluginTimeout, name, timeout)
+
+	case errors.As(runErr, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+
+	case runErr != nil:
+		result.ExitCode = StateUNKNOWNExitCode
+		return result, fmt.Errorf("%w: %s: %v", ErrExternalPluginFailedToRun, name, runErr)
+	}
+
+	parsed, err := ParsePluginOutput(result.Stdout)
+	result.ParsedOutput = parsed
+	if err != nil {
+		return result, fmt.Errorf("failed to parse output from %s: %w", name, err)
+	}
+
+	return result, nil
+}
+
+// ImportExternalResult records the state, one-line summary,
+// LongServiceOutput and performance data from the given external plugin
+// result. The receiver is returned to allow chaining further calls (e.g.,
+// to add details or errors before emitting the results).
+func (p *Plugin) ImportExternalResult(result ExternalResult) *Plugin {
+	p.ExitStatusCode = result.ExitCode
+	p.ServiceOutput = result.ServiceOutput
+
+	if result.LongServiceOutput != "" {
+		p.WithDetail(result.LongServiceOutput)
+	}
+
+	if len(result.PerfData) > 0 {
+		// Metrics emitted by external plugins are passed through as-is.
+		_ = p.AddPerfData(true, result.PerfData...)
+	}
+
+	return p
+}
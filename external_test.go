@@ -0,0 +1,81 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestRunExternalPluginAndReemit asserts that an external plugin's exit
+// code and output are captured, parsed and re-emitted with additions.
+func TestRunExternalPluginAndReemit(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	result, err := nagios.RunExternalPlugin(
+		5*time.Second,
+		"sh", "-c", `printf 'WARNING: 3 jobs queued | jobs=3;2;5\nqueue: default\n'; exit 1`,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ExitCode != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, result.ExitCode)
+	}
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.ImportExternalResult(result).WithDetail("wrapped by test")
+	plugin.ReturnCheckResults()
+
+	want := "WARNING: 3 jobs queued" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"queue: default" + nagios.CheckOutputEOL +
+		"wrapped by test" + nagios.CheckOutputEOL +
+		" | 'jobs'=3;2;5;;" + nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestRunExternalPluginTimeout asserts that a plugin which does not
+// complete in time is reported as UNKNOWN.
+func TestRunExternalPluginTimeout(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	result, err := nagios.RunExternalPlugin(50*time.Millisecond, "sleep", "5")
+	if !errors.Is(err, nagios.ErrExternalPluginTimeout) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrExternalPluginTimeout, err)
+	}
+
+	if result.ExitCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateUNKNOWNExitCode, result.ExitCode)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"regexp"
+	"strings"
+)
+
+// OutputFilter is implemented by types which transform rendered plugin
+// output just before it is emitted (e.g., to remove sensitive values). See
+// also AddOutputFilter.
+type OutputFilter interface {
+	// FilterOutput returns the given rendered plugin output after applying
+	// the filter.
+	FilterOutput(pluginOutput string) string
+}
+
+// OutputFilterFunc is an adapter allowing an ordinary function to be used
+// as an OutputFilter.
+type OutputFilterFunc func(pluginOutput string) string
+
+// FilterOutput calls f(pluginOutput).
+func (f OutputFilterFunc) FilterOutput(pluginOutput string) string {
+	return f(pluginOutput)
+}
+
+// WithOutputFilter is an Option used to register filters applied to
+// rendered plugin output. See also AddOutputFilter.
+func WithOutputFilter(filters ...OutputFilter) Option {
+	return func(p *Plugin) {
+		p.AddOutputFilter(filters...)
+	}
+}
+
+// AddOutputFilter registers filters applied by ReturnCheckResults to the
+// rendered plugin output just before it is emitted. Filters are applied in
+// the order registered, each receiving the output of the previous filter,
+// after output truncation (see SetOutputSpill) and strict mode size
+// enforcement. Filters also apply to the output seen by every other
+// destination: the summary, long service output, errors and link URLs of
+// the result passed to sinks (see SetSinks) and emit hooks, and the summary
+// written to syslog, journald and tracing spans.
+//
+// Filters may be called concurrently (e.g., when recording sub-check spans,
+// see SetTracer) and must not modify shared state.
+func (p *Plugin) AddOutputFilter(filters ...OutputFilter) {
+	for _, filter := range filters {
+		if filter != nil {
+			p.outputFilters = append(p.outputFilters, filter)
+		}
+	}
+}
+
+// filterOutput applies each registered output filter to the given output
+// in the order registered.
+func (p *Plugin) filterOutput(output *strings.Builder) {
+	if len(p.outputFilters) == 0 {
+		return
+	}
+
+	filtered := p.filterText(output.String())
+
+	output.Reset()
+	output.WriteString(filtered)
+}
+
+// filterText applies each registered output filter to the given text in the
+// order registered.
+func (p *Plugin) filterText(text string) string {
+	for _, filter := range p.outputFilters {
+		text = filter.FilterOutput(text)
+	}
+
+	return text
+}
+
+// filteredResult returns the plugin result with each registered output
+// filter applied to the text fields which may contain sensitive values.
+func (p *Plugin) filteredResult() Result {
+	result := p.Result()
+
+	if len(p.outputFilters) == 0 {
+		return result
+	}
+
+	result.ServiceOutput = p.filterText(result.ServiceOutput)
+	result.LongServiceOutput = p.filterText(result.LongServiceOutput)
+
+	for i := range result.Errors {
+		result.Errors[i] = p.filterText(result.Errors[i])
+	}
+
+	for i := range result.Links {
+		result.Links[i].URL = p.filterText(result.Links[i].URL)
+	}
+
+	return result
+}
+
+// SanitizeFilter returns an OutputFilter which removes control characters
+// (and ANSI escape sequences) other than newlines and tabs and handles
+// non-ASCII characters using the given mode. This is the same
+// transformation applied to all plugin output (see SetASCIIOutput) and is
+// intended for use with text produced by other filters or for sanitizing
+// text outside of a Plugin value.
+func SanitizeFilter(mode ASCIIMode) OutputFilter {
+	return OutputFilterFunc(func(pluginOutput string) string {
+		pluginOutput = stripControlChars(pluginOutput)
+
+		if mode == ASCIIModeDisabled {
+			return pluginOutput
+		}
+
+		return toASCII(pluginOutput, mode)
+	})
+}
+
+// TruncateFilter returns an OutputFilter which truncates plugin output
+// exceeding maxSize bytes (DefaultMaxOutputSize if less than 1), appending
+// a marker noting that output was truncated. Performance data on the last
+// line of output is retained and multi-byte characters are not split.
+func TruncateFilter(maxSize int) OutputFilter {
+	if maxSize < 1 {
+		maxSize = DefaultMaxOutputSize
+	}
+
+	return OutputFilterFunc(func(pluginOutput string) string {
+		if len(pluginOutput) <= maxSize {
+			return pluginOutput
+		}
+
+		text, perfData := splitPerfData(pluginOutput)
+		keep := maxSize - len(perfData) - len(CheckOutputEOL) - len(spillMarkerNoFile)
+
+		text = strings.TrimRight(truncateUTF8(text, keep), " \t\r\n")
+
+		switch {
+		case text == "":
+			text = spillMarkerNoFile
+		default:
+			text += CheckOutputEOL + spillMarkerNoFile
+		}
+
+		return text + perfData
+	})
+}
+
+// splitPerfData splits the given rendered plugin output into the text and
+// the trailing performance data (including the separator), if present.
+func splitPerfData(pluginOutput string) (string, string) {
+	i := strings.LastIndex(pluginOutput, " "+perfDataSeparator)
+	if i < 0 || strings.Contains(strings.TrimRight(pluginOutput[i:], "\r\n"), "\n") {
+		return pluginOutput, ""
+	}
+
+	return pluginOutput[:i], pluginOutput[i:]
+}
+
+// RedactFilter returns an OutputFilter which replaces each of the given
+// values (e.g., passwords or API tokens known to the plugin) within plugin
+// output with the text "REDACTED". Empty values are ignored.
+func RedactFilter(values ...string) OutputFilter {
+	oldnew := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		if value != "" {
+			oldnew = append(oldnew, value, redactedValue)
+		}
+	}
+
+	replacer := strings.NewReplacer(oldnew...)
+
+	return OutputFilterFunc(replacer.Replace)
+}
+
+// RedactPatternFilter returns an OutputFilter which replaces text within
+// plugin output matching any of the given patterns with the text
+// "REDACTED". If a pattern contains a capturing group only the text
+// matched by the first group is replaced (e.g., `token=(\S+)` retains the
+// "token=" prefix).
+func RedactPatternFilter(patterns ...*regexp.Regexp) OutputFilter {
+	return OutputFilterFunc(func(pluginOutput string) string {
+		for _, pattern := range patterns {
+			pluginOutput = redactMatches(pattern, pluginOutput)
+		}
+
+		return pluginOutput
+	})
+}
+
+// redactMatches replaces text matching the given pattern (or the first
+// capturing group of the pattern, if present) with the redacted value.
+func redactMatches(pattern *regexp.Regexp, s string) string {
+	matches := pattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if len(match) >= 4 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+
+		b.WriteString(s[last:start])
+		b.WriteString(redactedValue)
+		last = end
+	}
+
+	b.WriteString(s[last:])
+
+	return b.String()
+}
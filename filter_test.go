@@ -0,0 +1,106 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestOutputFiltersAppliedInOrder asserts that registered output filters
+// are applied to rendered output in the order registered.
+func TestOutputFiltersAppliedInOrder(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputFilter(
+			nagios.RedactFilter("hunter2"),
+			nagios.RedactPatternFilter(regexp.MustCompile(`token=(\S+)`)),
+		),
+	)
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	// Applied last; sees the output of the redaction filters.
+	plugin.AddOutputFilter(nagios.OutputFilterFunc(func(pluginOutput string) string {
+		return strings.ReplaceAll(pluginOutput, "REDACTED", "***")
+	}))
+
+	plugin.ServiceOutput = "OK: login succeeded with password hunter2"
+	plugin.LongServiceOutput = "request: GET /api?token=abc123 HTTP/1.1"
+	plugin.ExitStatusCode = nagios.StateOKExitCode
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"OK: login succeeded with password ***",
+		"request: GET /api?token=*** HTTP/1.1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	for _, secret := range []string{"hunter2", "abc123"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("want output without %q, got:\n%s", secret, got)
+		}
+	}
+}
+
+// TestTruncateFilterRetainsPerfData asserts that the truncation filter
+// limits output size while retaining trailing performance data.
+func TestTruncateFilterRetainsPerfData(t *testing.T) {
+	t.Parallel()
+
+	const maxSize = 200
+
+	perfData := " | 'time'=1s;;;;" + nagios.CheckOutputEOL
+	input := "OK: all good" + nagios.CheckOutputEOL + strings.Repeat("é detail line"+nagios.CheckOutputEOL, 50) + perfData
+
+	got := nagios.TruncateFilter(maxSize).FilterOutput(input)
+
+	if len(got) > maxSize {
+		t.Errorf("want output of at most %d bytes, got %d:\n%s", maxSize, len(got), got)
+	}
+
+	if !strings.HasSuffix(got, perfData) {
+		t.Errorf("want output to end with %q, got:\n%s", perfData, got)
+	}
+
+	if !strings.Contains(got, "[output truncated]") {
+		t.Errorf("want output to contain %q, got:\n%s", "[output truncated]", got)
+	}
+
+	short := "OK: all good" + perfData
+	if got := nagios.TruncateFilter(maxSize).FilterOutput(short); got != short {
+		t.Errorf("want output %q unchanged, got %q", short, got)
+	}
+}
+
+// TestSanitizeFilter asserts that the sanitization filter removes control
+// characters and applies the requested ASCII mode.
+func TestSanitizeFilter(t *testing.T) {
+	t.Parallel()
+
+	input := "\x1b[31mCRITICAL\x1b[0m: café\x07 down\n"
+	want := "CRITICAL: cafe down\n"
+
+	if got := nagios.SanitizeFilter(nagios.ASCIIModeTransliterate).FilterOutput(input); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
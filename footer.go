@@ -0,0 +1,125 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables holding the scheduled check interval. Nagios does
+// not provide a macro for the check interval, so the interval is read from
+// a custom variable (e.g., `_CHECK_INTERVAL 5` in the service definition)
+// exported by Nagios with environment macros enabled.
+const (
+	EnvServiceCheckInterval string = "NAGIOS__SERVICECHECK_INTERVAL"
+	EnvHostCheckInterval    string = "NAGIOS__HOSTCHECK_INTERVAL"
+)
+
+// defaultIntervalLength is the number of seconds per check interval unit
+// used by Nagios (the default interval_length setting).
+const defaultIntervalLength time.Duration = 60 * time.Second
+
+// Time layouts used to display timestamps for each output profile.
+const (
+	timeLayoutDefault string = "2006-01-02 15:04:05 MST"
+	timeLayoutISO8601 string = time.RFC3339
+)
+
+// footerCheckedAtLabel is the footer text preceding the check start time.
+const footerCheckedAtLabel string = "Checked at"
+
+// footerDurationPrecision is the precision of the check duration shown in
+// the footer.
+const footerDurationPrecision time.Duration = time.Millisecond
+
+// TimeLayout returns the layout used by the output profile to display
+// timestamps. Icinga Web uses ISO 8601 timestamps throughout its UI.
+func (op OutputProfile) TimeLayout() string {
+	switch op {
+	case OutputProfileIcingaWeb:
+		return timeLayoutISO8601
+	default:
+		return timeLayoutDefault
+	}
+}
+
+// WithFooter is an Option used to enable the footer. See also EnableFooter.
+func WithFooter() Option {
+	return func(p *Plugin) {
+		p.EnableFooter()
+	}
+}
+
+// EnableFooter enables an optional footer line noting when the check
+// started, how long it took and the scheduled check interval (if
+// available, see EnvServiceCheckInterval):
+//
+//	Checked at 2024-01-02 15:04:05 UTC in 1.204s (check interval 5m0s)
+//
+// Timestamps are formatted using the layout of the output profile (see
+// OutputProfile.TimeLayout). A check interval given as a plain number is
+// interpreted as a number of minutes (the default Nagios interval_length);
+// a Go duration (e.g., "90s") is also accepted. The footer is omitted if
+// the plugin start time is unknown (see NewPlugin).
+func (p *Plugin) EnableFooter() {
+	p.footerEnabled = true
+}
+
+// handleFooter is a wrapper around the logic used to handle/process the
+// footer line.
+func (p Plugin) handleFooter(w io.Writer) {
+	if !p.footerEnabled || p.start.IsZero() {
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s%s", CheckOutputEOL, p.footerText(time.Now()), CheckOutputEOL)
+}
+
+// footerText provides the footer line as of the given time.
+func (p Plugin) footerText(now time.Time) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b,
+		"%s %s in %s",
+		p.message(MessageCheckedAt, footerCheckedAtLabel),
+		p.start.Format(p.outputProfile.TimeLayout()),
+		now.Sub(p.start).Round(footerDurationPrecision),
+	)
+
+	if interval, ok := checkIntervalFromEnv(); ok {
+		fmt.Fprintf(&b, " (check interval %s)", interval)
+	}
+
+	return b.String()
+}
+
+// checkIntervalFromEnv returns the scheduled check interval from the
+// environment, if available.
+func checkIntervalFromEnv() (time.Duration, bool) {
+	for _, name := range []string{EnvServiceCheckInterval, EnvHostCheckInterval} {
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			continue
+		}
+
+		if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+			return interval, true
+		}
+
+		if units, err := strconv.ParseFloat(value, 64); err == nil && units > 0 {
+			return time.Duration(units * float64(defaultIntervalLength)), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestFooterIncludesCheckIntervalFromEnv asserts that the footer notes the
+// start time, duration and the check interval read from the environment.
+func TestFooterIncludesCheckIntervalFromEnv(t *testing.T) {
+	t.Setenv(nagios.EnvServiceCheckInterval, "5")
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithFooter(),
+	)
+
+	plugin.OK("all good")
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	want := regexp.MustCompile(
+		`Checked at \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} \S+ in \S+ \(check interval 5m0s\)`,
+	)
+
+	if !want.MatchString(got) {
+		t.Errorf("want output matching %q, got %q", want, got)
+	}
+}
+
+// TestFooterUsesOutputProfileTimeLayout asserts that the footer is omitted
+// unless enabled and formats the start time using the layout of the output
+// profile.
+func TestFooterUsesOutputProfileTimeLayout(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{false, true} {
+		var outputBuffer strings.Builder
+
+		plugin := nagios.NewPlugin(
+			nagios.WithOutputTarget(&outputBuffer),
+			nagios.WithSkipOSExit(),
+			nagios.WithOutputProfile(nagios.OutputProfileIcingaWeb),
+		)
+
+		if enabled {
+			plugin.EnableFooter()
+		}
+
+		plugin.OK("all good")
+		plugin.ReturnCheckResults()
+
+		got := outputBuffer.String()
+
+		if !enabled {
+			if strings.Contains(got, "Checked at") {
+				t.Errorf("want footer omitted, got %q", got)
+			}
+			continue
+		}
+
+		want := regexp.MustCompile(`\nChecked at \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\S* in \S+\n`)
+		if !want.MatchString(got) {
+			t.Errorf("want output matching %q, got %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// durationPerfDataLabel is the performance data label suffix used to record
+// the time spent on a Group task.
+const durationPerfDataLabel string = "duration"
+
+// Group is a lightweight alternative to RunChecks (similar to errgroup)
+// which runs tasks concurrently on behalf of a plugin. Errors returned by
+// tasks, panics within tasks and the time spent on each task are recorded
+// by the plugin once Wait is called.
+//
+// A Group is created using NewGroup and must not be copied.
+type Group struct {
+	plugin *Plugin
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	tasks  []*groupTask
+}
+
+// groupTask is the outcome of a task run by a Group.
+type groupTask struct {
+	name     string
+	err      error
+	duration time.Duration
+}
+
+// NewGroup returns a Group bound to the plugin.
+func (p *Plugin) NewGroup() *Group {
+	return &Group{plugin: p}
+}
+
+// Go runs the given task in a new goroutine. The task should not modify the
+// plugin; the outcome of the task is recorded by Wait instead. If tracing
+// is enabled (see SetTracer), a child span named after the task is
+// recorded.
+func (g *Group) Go(name string, fn func() error) {
+	task := groupTask{name: name}
+
+	g.mu.Lock()
+	g.tasks = append(g.tasks, &task)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		span := g.plugin.StartSpan(name)
+		start := time.Now()
+
+		defer func() {
+			task.duration = time.Since(start)
+
+			if r := recover(); r != nil {
+				task.err = fmt.Errorf("%w: %v", ErrPanicDetected, r)
+			}
+
+			if task.err != nil {
+				span.SetAttribute(SpanAttrError, g.plugin.filterText(task.err.Error()))
+			}
+
+			span.End()
+		}()
+
+		task.err = fn()
+	}()
+}
+
+// Wait blocks until all tasks complete and then records their outcome in
+// the order the tasks were started. Errors are recorded (see AddError)
+// prefixed with the task name, panics are recorded as errors wrapping
+// ErrPanicDetected and the time spent on each task is recorded as a
+// "duration" performance data metric prefixed with the task name (e.g.,
+// "web01_duration").
+//
+// The first error encountered (in the order the tasks were started) is
+// returned. Wait should be called once all tasks have been started.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var firstErr error
+
+	for _, task := range g.tasks {
+		if task.err != nil {
+			err := fmt.Errorf("%s: %w", task.name, task.err)
+			g.plugin.AddError(err)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+
+		pd := PerformanceData{
+			Label:             task.name + subCheckPerfDataSeparator + durationPerfDataLabel,
+			Value:             fmt.Sprintf("%.3f", task.duration.Seconds()),
+			UnitOfMeasurement: "s",
+		}
+
+		if err := g.plugin.AddPerfData(false, pd); err != nil {
+			g.plugin.AddError(fmt.Errorf("%s: %w", task.name, err))
+		}
+	}
+
+	g.tasks = nil
+
+	return firstErr
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestGroupRecordsTaskOutcomes asserts that errors, panics and durations of
+// Group tasks are recorded by the plugin.
+func TestGroupRecordsTaskOutcomes(t *testing.T) {
+	t.Parallel()
+
+	errUnreachable := errors.New("host unreachable")
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	g := plugin.NewGroup()
+	g.Go("sw01", func() error { return nil })
+	g.Go("sw02", func() error { return errUnreachable })
+	g.Go("sw03", func() error { panic("index out of range") })
+
+	err := g.Wait()
+	if !errors.Is(err, errUnreachable) {
+		t.Errorf("want first error %v, got %v", errUnreachable, err)
+	}
+
+	if len(plugin.Errors) != 2 || !errors.Is(plugin.Errors[1], nagios.ErrPanicDetected) {
+		t.Fatalf("want task error and panic recorded, got %v", plugin.Errors)
+	}
+
+	plugin.ServiceOutput = "switches polled"
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"sw02: host unreachable",
+		"'sw01_duration'=0.0",
+		"'sw03_duration'=0.0",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// maxStateTransitions is the number of state transitions retained in
+	// the persisted state file.
+	maxStateTransitions int = 50
+
+	// lastStateChangeLabel is the text emitted prior to the last state
+	// change summary.
+	lastStateChangeLabel string = "Last state change"
+)
+
+// StateTransition records a change in plugin state between two runs.
+type StateTransition struct {
+	// Time is when the new state was first observed.
+	Time time.Time `json:"time"`
+
+	// From is the previous state (exit code).
+	From int `json:"from"`
+
+	// To is the new state (exit code).
+	To int `json:"to"`
+}
+
+// WithStateFile is an Option used to persist plugin state between runs in
+// the given file. See also SetStateFile.
+func WithStateFile(path string) Option {
+	return func(p *Plugin) {
+		p.SetStateFile(path)
+	}
+}
+
+// SetStateFile persists plugin state between runs in the given file using a
+// JSONStateStore with default settings. See SetStateStore for details.
+func (p *Plugin) SetStateFile(path string) {
+	p.SetStateStore(NewJSONStateStore(path))
+}
+
+// WithStateStore is an Option used to persist plugin state between runs
+// using the given store. See also SetStateStore.
+func WithStateStore(store StateStore) Option {
+	return func(p *Plugin) {
+		p.SetStateStore(store)
+	}
+}
+
+// SetStateStore persists plugin state between runs using the given store.
+// The final state of each run is compared against the previous run and any
+// transitions are recorded with a timestamp. Each plugin (and each plugin
+// instance, if checking multiple targets) should use its own store.
+//
+// Failure to read or write persisted state does not affect plugin output;
+// the failure is logged instead (see SetLogger).
+func (p *Plugin) SetStateStore(store StateStore) {
+	p.stateStore = store
+}
+
+// ShowLastStateChange includes a summary of the last state change in
+// LongServiceOutput (e.g., "Last state change: 2d4h ago (was WARNING)").
+// This requires a state store (see SetStateStore).
+func (p *Plugin) ShowLastStateChange() {
+	p.showLastStateChange = true
+}
+
+// StateTransitions returns the state transitions recorded in the state
+// store, oldest first.
+func (p *Plugin) StateTransitions() ([]StateTransition, error) {
+	if p.stateStore == nil {
+		return nil, nil
+	}
+
+	state, err := p.stateStore.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return state.Transitions, nil
+}
+
+// annotateStateHistory annotates the plugin result using the state store
+// (if enabled) before output is rendered: the last state change is noted
+// (see ShowLastStateChange) and trends are computed from the given
+// performance data values and those of the previous run (see
+// ShowPerfDataTrends). Nothing is persisted; see updateStateHistory.
+func (p *Plugin) annotateStateHistory(values map[string]float64) {
+	if p.stateStore == nil || (!p.showLastStateChange && !p.showPerfDataTrends && !p.emitPerfDataDeltas) {
+		return
+	}
+
+	state, err := p.stateStore.Load()
+	if err != nil {
+		p.Logger().Warn("failed to load persisted state", "error", err)
+		return
+	}
+
+	now := time.Now()
+
+	if p.showLastStateChange {
+		transitions := state.Transitions
+		if state.LastState != nil && *state.LastState != p.ExitStatusCode {
+			transitions = append(transitions, StateTransition{
+				Time: now,
+				From: *state.LastState,
+				To:   p.ExitStatusCode,
+			})
+		}
+
+		p.WithDetail(lastStateChangeText(transitions, now))
+	}
+
+	p.applyTrends(values, state.LastValues)
+}
+
+// updateStateHistory records the final plugin state and the given
+// performance data values in the state store (if enabled), noting a
+// transition if the state differs from the previous run. This is called
+// once the emitted state is known (e.g., after strict mode size
+// enforcement).
+func (p *Plugin) updateStateHistory(values map[string]float64) {
+	if p.stateStore == nil {
+		return
+	}
+
+	now := time.Now()
+
+	err := p.stateStore.Update(func(state *PersistedState) error {
+		if state.LastState != nil && *state.LastState != p.ExitStatusCode {
+			state.Transitions = append(state.Transitions, StateTransition{
+				Time: now,
+				From: *state.LastState,
+				To:   p.ExitStatusCode,
+			})
+
+			if len(state.Transitions) > maxStateTransitions {
+				state.Transitions = state.Transitions[len(state.Transitions)-maxStateTransitions:]
+			}
+		}
+
+		exitCode := p.ExitStatusCode
+		state.LastState = &exitCode
+
+		p.recordMovingAverageSample(state, values)
+		state.LastValues = values
+
+		return nil
+	})
+
+	if err != nil {
+		p.Logger().Warn("failed to update persisted state", "error", err)
+	}
+}
+
+// lastStateChangeText summarizes the most recent state transition.
+func lastStateChangeText(transitions []StateTransition, now time.Time) string {
+	if len(transitions) == 0 {
+		return lastStateChangeLabel + ": none recorded"
+	}
+
+	last := transitions[len(transitions)-1]
+
+	age := "now"
+	if elapsed := now.Sub(last.Time); elapsed >= time.Second {
+		age = formatAge(elapsed) + " ago"
+	}
+
+	return fmt.Sprintf("%s: %s (was %s)", lastStateChangeLabel, age, stateLabel(last.From))
+}
+
+// formatAge formats the given duration using the two most significant units
+// (e.g., 2d4h, 3h12m, 5m30s).
+func formatAge(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm%ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestStateFileRecordsTransitions asserts that state transitions between
+// runs are recorded and summarized in LongServiceOutput.
+func TestStateFileRecordsTransitions(t *testing.T) {
+	t.Parallel()
+
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	run := func(exitCode int) string {
+		plugin := nagios.Plugin{}
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetStateFile(stateFile)
+		plugin.ShowLastStateChange()
+
+		plugin.SetState(exitCode)
+		plugin.SetSummary("summary")
+		plugin.ReturnCheckResults()
+
+		return outputBuffer.String()
+	}
+
+	if got := run(nagios.StateOKExitCode); !strings.Contains(got, "Last state change: none recorded") {
+		t.Errorf("want no recorded transitions on first run, got %q", got)
+	}
+
+	if got := run(nagios.StateWARNINGExitCode); !strings.Contains(got, "Last state change: now (was OK)") {
+		t.Errorf("want transition from OK reported, got %q", got)
+	}
+
+	if got := run(nagios.StateWARNINGExitCode); !strings.Contains(got, "(was OK)") {
+		t.Errorf("want earlier transition from OK reported, got %q", got)
+	}
+
+	plugin := nagios.Plugin{}
+	plugin.SetStateFile(stateFile)
+
+	transitions, err := plugin.StateTransitions()
+	if err != nil {
+		t.Fatalf("failed to read state transitions: %v", err)
+	}
+
+	if len(transitions) != 1 ||
+		transitions[0].From != nagios.StateOKExitCode ||
+		transitions[0].To != nagios.StateWARNINGExitCode {
+		t.Errorf("unexpected state transitions: %+v", transitions)
+	}
+}
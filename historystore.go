@@ -0,0 +1,383 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings for the JSON Lines history store.
+const (
+	// DefaultHistoryMaxRecords is the maximum number of run records retained
+	// by a JSONLinesHistoryStore if not specified by client code.
+	DefaultHistoryMaxRecords int = 10000
+
+	// DefaultHistoryCompactSize is the history file size (in bytes) above
+	// which a JSONLinesHistoryStore removes expired and excess records if not
+	// specified by client code. This is large enough to hold
+	// DefaultHistoryMaxRecords records of a typical plugin.
+	DefaultHistoryCompactSize int64 = 4 * 1024 * 1024
+
+	// historyCompactLowWaterPercent is the size (as a percentage of the
+	// compaction size) a history file is reduced to when compacted, so that
+	// the file is not rewritten again until further records are appended.
+	historyCompactLowWaterPercent int64 = 75
+)
+
+// ErrHistoryStoreNotEnabled indicates that a feature requiring a history
+// store was used without one. See SetHistoryStore.
+var ErrHistoryStoreNotEnabled = errors.New("history store not enabled")
+
+// HistoryRecord is the result of a single plugin run as retained by a
+// HistoryStore.
+type HistoryRecord struct {
+	// Time is when the plugin run completed.
+	Time time.Time `json:"time"`
+
+	// State is the final state (exit code) of the plugin run.
+	State int `json:"state"`
+
+	// Values is the collection of numeric performance data values emitted by
+	// the plugin run, indexed by label.
+	Values map[string]float64 `json:"values,omitempty"`
+}
+
+// HistoryStore retains the results of a large number of plugin runs (e.g.,
+// thousands) for use in trend thresholds and SLA calculations. Unlike a
+// StateStore, which persists a small state document that is rewritten on
+// every run, a HistoryStore is append oriented.
+//
+// HistoryStore is a separate interface rather than a StateStore
+// implementation because the StateStore methods load and rewrite the whole
+// state document on every run, which does not scale to thousands of
+// records; history is instead appended and queried by time. Plugins may use
+// both (see SetStateStore and SetHistoryStore).
+//
+// Implementations must be safe for use by concurrent runs of the same
+// plugin. A JSON Lines file implementation is provided; client code may
+// provide other implementations (e.g., one backed by SQLite, which is not
+// provided so that this module does not depend on a database driver).
+type HistoryStore interface {
+	// Append records the result of a plugin run.
+	Append(record HistoryRecord) error
+
+	// Records returns the retained records with a Time after since, oldest
+	// first. All retained records are returned if since is the zero value.
+	Records(since time.Time) ([]HistoryRecord, error)
+}
+
+// JSONLinesHistoryStore is a HistoryStore which appends each run record as
+// a line of JSON to a local file. Appends are serialized between processes
+// using the same locking approach as JSONStateStore. Expired and excess
+// records are removed once the file exceeds the compaction size, so the
+// cost of a typical run is a single append. Compaction also removes the
+// oldest records as needed to reduce the file to 75% of the compaction
+// size, so the compaction size bounds the retained history along with the
+// maximum number of records.
+type JSONLinesHistoryStore struct {
+	path        string
+	lockTimeout time.Duration
+	maxRecords  int
+	maxAge      time.Duration
+	compactSize int64
+}
+
+// JSONLinesHistoryStoreOption is a functional option used to configure a
+// JSONLinesHistoryStore.
+type JSONLinesHistoryStoreOption func(*JSONLinesHistoryStore)
+
+// WithHistoryRetention is a JSONLinesHistoryStoreOption used to override the
+// maximum number of records retained and to discard records older than
+// maxAge. A zero value leaves the corresponding limit unchanged; records are
+// not discarded based on age unless maxAge is specified.
+func WithHistoryRetention(maxRecords int, maxAge time.Duration) JSONLinesHistoryStoreOption {
+	return func(s *JSONLinesHistoryStore) {
+		if maxRecords > 0 {
+			s.maxRecords = maxRecords
+		}
+
+		if maxAge > 0 {
+			s.maxAge = maxAge
+		}
+	}
+}
+
+// WithHistoryCompactSize is a JSONLinesHistoryStoreOption used to override
+// the history file size (in bytes) above which expired and excess records
+// are removed.
+func WithHistoryCompactSize(size int64) JSONLinesHistoryStoreOption {
+	return func(s *JSONLinesHistoryStore) {
+		if size > 0 {
+			s.compactSize = size
+		}
+	}
+}
+
+// WithHistoryLockTimeout is a JSONLinesHistoryStoreOption used to override
+// the maximum time spent waiting to acquire the history file lock.
+func WithHistoryLockTimeout(timeout time.Duration) JSONLinesHistoryStoreOption {
+	return func(s *JSONLinesHistoryStore) {
+		if timeout > 0 {
+			s.lockTimeout = timeout
+		}
+	}
+}
+
+// NewJSONLinesHistoryStore returns a JSONLinesHistoryStore which retains run
+// records in the file at the given path.
+func NewJSONLinesHistoryStore(path string, options ...JSONLinesHistoryStoreOption) *JSONLinesHistoryStore {
+	s := JSONLinesHistoryStore{
+		path:        path,
+		lockTimeout: DefaultStateLockTimeout,
+		maxRecords:  DefaultHistoryMaxRecords,
+		compactSize: DefaultHistoryCompactSize,
+	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s
+}
+
+// Path returns the path of the history file.
+func (s *JSONLinesHistoryStore) Path() string {
+	return s.path
+}
+
+// Append appends the given record to the history file while holding the
+// history file lock, compacting the file if it has grown beyond the
+// compaction size.
+func (s *JSONLinesHistoryStore) Append(record HistoryRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+
+	lock, err := acquireStateLock(s.path+stateLockFileSuffix, s.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if info.Size() <= s.compactSize {
+		return nil
+	}
+
+	return s.compact(time.Now())
+}
+
+// Records returns the retained records with a Time after since, oldest
+// first. Records outside of the retention limits which have not yet been
+// compacted are omitted.
+func (s *JSONLinesHistoryStore) Records(since time.Time) ([]HistoryRecord, error) {
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	records = s.retain(records, time.Now())
+
+	for i, record := range records {
+		if record.Time.After(since) {
+			return records[i:], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// read decodes all records in the history file. Lines which cannot be
+// decoded (e.g., a partial line left by an interrupted write) are skipped.
+func (s *JSONLinesHistoryStore) read() ([]HistoryRecord, error) {
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var records []HistoryRecord
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+
+	for scanner.Scan() {
+		var record HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return records, nil
+}
+
+// retain returns the given records which fall within the retention limits.
+func (s *JSONLinesHistoryStore) retain(records []HistoryRecord, now time.Time) []HistoryRecord {
+	if s.maxAge > 0 {
+		cutoff := now.Add(-s.maxAge)
+		for len(records) > 0 && records[0].Time.Before(cutoff) {
+			records = records[1:]
+		}
+	}
+
+	if len(records) > s.maxRecords {
+		records = records[len(records)-s.maxRecords:]
+	}
+
+	return records
+}
+
+// compact rewrites the history file, removing records outside of the
+// retention limits along with the oldest records as needed to reduce the
+// file to the low-water mark. The caller must hold the history file lock.
+func (s *JSONLinesHistoryStore) compact(now time.Time) error {
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	records = s.retain(records, now)
+
+	lines := make([][]byte, len(records))
+	var size int64
+
+	for i, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode history record: %w", err)
+		}
+
+		lines[i] = append(line, '\n')
+		size += int64(len(lines[i]))
+	}
+
+	lowWater := s.compactSize * historyCompactLowWaterPercent / 100
+	for len(lines) > 0 && size > lowWater {
+		size -= int64(len(lines[0]))
+		lines = lines[1:]
+	}
+
+	return writeFileAtomic(s.path, bytes.Join(lines, nil))
+}
+
+// WithHistoryStore is an Option used to retain the result of each plugin run
+// using the given store. See also SetHistoryStore.
+func WithHistoryStore(store HistoryStore) Option {
+	return func(p *Plugin) {
+		p.SetHistoryStore(store)
+	}
+}
+
+// SetHistoryStore retains the result of each plugin run (the final state and
+// numeric performance data values) using the given store. This is intended
+// for plugins needing a longer history than is practical to keep in a
+// StateStore. Each plugin (and each plugin instance, if checking multiple
+// targets) should use its own store.
+//
+// Failure to record the run does not affect plugin output; the failure is
+// logged instead (see SetLogger).
+func (p *Plugin) SetHistoryStore(store HistoryStore) {
+	p.historyStore = store
+}
+
+// History returns the run records retained by the history store with a Time
+// after since, oldest first.
+func (p *Plugin) History(since time.Time) ([]HistoryRecord, error) {
+	if p.historyStore == nil {
+		return nil, nil
+	}
+
+	return p.historyStore.Records(since)
+}
+
+// appendHistory records the final state of the current run and the given
+// performance data values in the history store (if enabled).
+func (p *Plugin) appendHistory(values map[string]float64) {
+	if p.historyStore == nil {
+		return
+	}
+
+	record := HistoryRecord{
+		Time:   time.Now(),
+		State:  p.ExitStatusCode,
+		Values: values,
+	}
+
+	if err := p.historyStore.Append(record); err != nil {
+		p.Logger().Warn("failed to append run history", "error", err)
+	}
+}
+
+// perfDataValues returns the numeric performance data values collected by
+// the plugin, indexed by label. Values which are not numeric (e.g., "U")
+// are omitted.
+func (p *Plugin) perfDataValues() map[string]float64 {
+	if len(p.perfData) == 0 {
+		return nil
+	}
+
+	values := make(map[string]float64, len(p.perfData))
+	for _, pd := range p.perfData {
+		if value, ok := parsePerfDataValue(pd.Value); ok {
+			values[pd.Label] = value
+		}
+	}
+
+	return values
+}
+
+// parsePerfDataValue parses the numeric portion of the given performance
+// data value, ignoring any unit of measurement suffix (e.g., "874ms").
+func parsePerfDataValue(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	unit, _ := numericUnit(value)
+
+	f, err := strconv.ParseFloat(strings.TrimSuffix(value, unit), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return f, true
+}
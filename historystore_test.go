@@ -0,0 +1,188 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestJSONLinesHistoryStoreAppliesRetention asserts that records beyond the
+// retention limit are removed once the compaction size is exceeded and that
+// records are returned oldest first.
+func TestJSONLinesHistoryStoreAppliesRetention(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	// Each record is encoded as a 62 byte line; the file is compacted once
+	// the seventh record is appended.
+	store := nagios.NewJSONLinesHistoryStore(
+		path,
+		nagios.WithHistoryRetention(5, 0),
+		nagios.WithHistoryCompactSize(420),
+	)
+
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 8; i++ {
+		record := nagios.HistoryRecord{
+			Time:   start.Add(time.Duration(i) * time.Minute),
+			State:  i % 3,
+			Values: map[string]float64{"load": float64(i)},
+		}
+
+		if err := store.Append(record); err != nil {
+			t.Fatalf("append %d failed: %v", i, err)
+		}
+	}
+
+	records, err := store.Records(time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read records: %v", err)
+	}
+
+	var got []float64
+	for _, record := range records {
+		got = append(got, record.Values["load"])
+	}
+
+	want := []float64{3, 4, 5, 6, 7}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	recent, err := store.Records(start.Add(5 * time.Minute))
+	if err != nil {
+		t.Fatalf("failed to read records: %v", err)
+	}
+
+	if len(recent) != 2 {
+		t.Errorf("want 2 records after cutoff, got %d", len(recent))
+	}
+}
+
+// TestJSONLinesHistoryStoreCompactsToLowWaterMark asserts that compaction
+// reduces the history file below the compaction size, so that the file is
+// not rewritten on every append once the retained records exceed the
+// compaction size.
+func TestJSONLinesHistoryStoreCompactsToLowWaterMark(t *testing.T) {
+	t.Parallel()
+
+	const compactSize = 4096
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	store := nagios.NewJSONLinesHistoryStore(
+		path,
+		nagios.WithHistoryRetention(10000, 0),
+		nagios.WithHistoryCompactSize(compactSize),
+	)
+
+	const appends = 500
+
+	var (
+		compactions int
+		lastSize    int64
+	)
+
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < appends; i++ {
+		record := nagios.HistoryRecord{
+			Time:   start.Add(time.Duration(i) * time.Minute),
+			Values: map[string]float64{"load": float64(i)},
+		}
+
+		if err := store.Append(record); err != nil {
+			t.Fatalf("append %d failed: %v", i, err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed to stat history file: %v", err)
+		}
+
+		if info.Size() > compactSize {
+			t.Fatalf("append %d: want history file of at most %d bytes, got %d", i, compactSize, info.Size())
+		}
+
+		if info.Size() < lastSize {
+			compactions++
+		}
+		lastSize = info.Size()
+	}
+
+	// Each compaction frees a quarter of the compaction size, roughly 16
+	// records of 62 bytes.
+	if compactions == 0 || compactions > appends/10 {
+		t.Errorf("want between 1 and %d compactions, got %d", appends/10, compactions)
+	}
+
+	records, err := store.Records(time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read records: %v", err)
+	}
+
+	if len(records) == 0 || records[len(records)-1].Values["load"] != appends-1 {
+		t.Errorf("want most recent record retained, got %d records", len(records))
+	}
+}
+
+// TestPluginAppendsRunHistory asserts that the final state and numeric
+// performance data values of a plugin run are recorded in the history
+// store.
+func TestPluginAppendsRunHistory(t *testing.T) {
+	t.Parallel()
+
+	store := nagios.NewJSONLinesHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	var outputBuffer bytes.Buffer
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+	plugin.SetHistoryStore(store)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.ServiceOutput = "WARNING: load is high"
+	plugin.ExitStatusCode = nagios.StateWARNINGExitCode
+
+	if err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "load1", Value: "4.5"},
+		nagios.PerformanceData{Label: "time", Value: "874ms"},
+		nagios.PerformanceData{Label: "reading", Value: "U"},
+	); err != nil {
+		t.Fatalf("failed to add performance data: %v", err)
+	}
+
+	plugin.ReturnCheckResults()
+
+	records, err := plugin.History(time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("want 1 record, got %d", len(records))
+	}
+
+	if records[0].State != nagios.StateWARNINGExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateWARNINGExitCode, records[0].State)
+	}
+
+	want := map[string]float64{"load1": 4.5, "time": 874}
+	if d := cmp.Diff(want, records[0].Values); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
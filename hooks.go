@@ -0,0 +1,109 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+)
+
+// BeforeEmitHook is a function called with the plugin just before its
+// output is rendered. Hooks may inspect and modify the result (e.g., redact
+// sensitive values, add tags or details) to implement cross-cutting
+// concerns without changing each plugin. See also AddBeforeEmitHook.
+type BeforeEmitHook func(p *Plugin)
+
+// AfterEmitHook is a function called with the final result and the
+// rendered plugin output once the output has been emitted (e.g., to record
+// metrics about plugin runs). See also AddAfterEmitHook.
+type AfterEmitHook func(result Result, pluginOutput string)
+
+// emitHook is a function called with the final (filtered) plugin result
+// after plugin output has been emitted. Hooks are used to mirror check results to other
+// destinations (e.g., syslog) and must not modify the Plugin value.
+type emitHook func(p *Plugin, result Result)
+
+// WithBeforeEmitHook is an Option used to register a function called just
+// before plugin output is rendered. See also AddBeforeEmitHook.
+func WithBeforeEmitHook(hook BeforeEmitHook) Option {
+	return func(p *Plugin) {
+		p.AddBeforeEmitHook(hook)
+	}
+}
+
+// AddBeforeEmitHook registers a function called by ReturnCheckResults just
+// before plugin output is rendered. Hooks are called in the order
+// registered, after the built-in adjustments (e.g., error state
+// escalation, validation, downtime and state remapping) so that they see
+// the result as it will be emitted; changes made by hooks are emitted
+// as-is. A panicking hook is recorded as an error wrapping
+// ErrPanicDetected.
+func (p *Plugin) AddBeforeEmitHook(hook BeforeEmitHook) {
+	p.beforeEmitHooks = append(p.beforeEmitHooks, hook)
+}
+
+// WithAfterEmitHook is an Option used to register a function called once
+// plugin output has been emitted. See also AddAfterEmitHook.
+func WithAfterEmitHook(hook AfterEmitHook) Option {
+	return func(p *Plugin) {
+		p.AddAfterEmitHook(hook)
+	}
+}
+
+// AddAfterEmitHook registers a function called by ReturnCheckResults once
+// plugin output has been emitted (see also SetSinks), in the order
+// registered. The hook receives the final result and the rendered plugin
+// output. A panicking hook is logged and does not affect other hooks.
+func (p *Plugin) AddAfterEmitHook(hook AfterEmitHook) {
+	p.afterEmitHooks = append(p.afterEmitHooks, hook)
+}
+
+// runBeforeEmitHooks calls each registered before emit hook in the order
+// registered.
+func (p *Plugin) runBeforeEmitHooks() {
+	for i, hook := range p.beforeEmitHooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					p.AddError(fmt.Errorf("%w: before emit hook %d: %v", ErrPanicDetected, i+1, r))
+				}
+			}()
+
+			hook(p)
+		}()
+	}
+
+	// Hooks may have assigned an invalid exit code.
+	p.sanitizeExitCode()
+}
+
+// addEmitHook registers a function to be called after plugin output has been
+// emitted.
+func (p *Plugin) addEmitHook(hook emitHook) {
+	p.emitHooks = append(p.emitHooks, hook)
+}
+
+// runEmitHooks calls each registered emit hook and then each registered
+// after emit hook in the order registered with the given result and
+// rendered plugin output.
+func (p *Plugin) runEmitHooks(result Result, pluginOutput string) {
+	for _, hook := range p.emitHooks {
+		hook(p, result)
+	}
+
+	for i, hook := range p.afterEmitHooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					p.Logger().Error("after emit hook panicked", "hook", i+1, "panic", r)
+				}
+			}()
+
+			hook(result, pluginOutput)
+		}()
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestBeforeEmitHookModifiesOutput asserts that before emit hooks are able
+// to modify the result prior to rendering, in the order registered.
+func TestBeforeEmitHookModifiesOutput(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	redact := func(p *nagios.Plugin) {
+		p.ServiceOutput = strings.ReplaceAll(p.ServiceOutput, "hunter2", "[REDACTED]")
+		p.LongServiceOutput = strings.ReplaceAll(p.LongServiceOutput, "hunter2", "[REDACTED]")
+	}
+
+	tag := func(p *nagios.Plugin) {
+		p.ServiceOutput += " [env=prod]"
+	}
+
+	plugin := nagios.NewPlugin(
+		nagios.WithBeforeEmitHook(redact),
+		nagios.WithBeforeEmitHook(tag),
+	)
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.ServiceOutput = "OK: login succeeded with password hunter2"
+	plugin.LongServiceOutput = "password: hunter2"
+	plugin.ExitStatusCode = nagios.StateOKExitCode
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	want := "OK: login succeeded with password [REDACTED] [env=prod]"
+	if !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+
+	if strings.Contains(got, "hunter2") {
+		t.Errorf("want output without %q, got:\n%s", "hunter2", got)
+	}
+}
+
+// TestBeforeEmitHookPanicIsRecorded asserts that a panicking before emit
+// hook is recorded as an error and does not prevent output from being
+// emitted.
+func TestBeforeEmitHookPanicIsRecorded(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin()
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.AddBeforeEmitHook(func(*nagios.Plugin) {
+		panic("boom")
+	})
+
+	plugin.ServiceOutput = "OK: all good"
+	plugin.ExitStatusCode = nagios.StateOKExitCode
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{"OK: all good", "before emit hook 1: boom"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestAfterEmitHookObservesResult asserts that after emit hooks receive the
+// final result and rendered output, even when an earlier hook panics.
+func TestAfterEmitHookObservesResult(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	var (
+		gotResult nagios.Result
+		gotOutput string
+	)
+
+	plugin := nagios.NewPlugin(
+		nagios.WithBeforeEmitHook(func(p *nagios.Plugin) {
+			p.ExitStatusCode = nagios.StateWARNINGExitCode
+		}),
+	)
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.AddAfterEmitHook(func(nagios.Result, string) {
+		panic("boom")
+	})
+	plugin.AddAfterEmitHook(func(result nagios.Result, pluginOutput string) {
+		gotResult = result
+		gotOutput = pluginOutput
+	})
+
+	plugin.ServiceOutput = "WARNING: queue depth elevated"
+	plugin.ExitStatusCode = nagios.StateOKExitCode
+
+	plugin.ReturnCheckResults()
+
+	if gotResult.ExitCode != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, gotResult.ExitCode)
+	}
+
+	if gotResult.ServiceOutput != "WARNING: queue depth elevated" {
+		t.Errorf("want service output %q, got %q", "WARNING: queue depth elevated", gotResult.ServiceOutput)
+	}
+
+	if gotOutput != outputBuffer.String() {
+		t.Errorf("want hook output to match emitted output, got:\n%s", gotOutput)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// defaultHostContextLabel is the header text for the host context section.
+const defaultHostContextLabel string = "HOST CONTEXT"
+
+// Files (relative to the filesystem root) used to gather host context.
+const (
+	hostContextOSRelease     string = "etc/os-release"
+	hostContextKernelRelease string = "proc/sys/kernel/osrelease"
+	hostContextInitCgroup    string = "proc/1/cgroup"
+	hostContextCPUInfo       string = "proc/cpuinfo"
+	hostContextDockerEnv     string = ".dockerenv"
+	hostContextPodmanEnv     string = "run/.containerenv"
+	hostContextDMIVendor     string = "sys/class/dmi/id/sys_vendor"
+	hostContextDMIProduct    string = "sys/class/dmi/id/product_name"
+)
+
+// Values displayed for host context details which could not be determined.
+const (
+	hostContextUnknown string = "unknown"
+	hostContextNone    string = "none detected"
+)
+
+// containerMarkers maps substrings of the control groups of the init
+// process to the container runtime they indicate.
+var containerMarkers = []struct {
+	marker  string
+	runtime string
+}{
+	{marker: "kubepods", runtime: "kubernetes"},
+	{marker: "docker", runtime: "docker"},
+	{marker: "libpod", runtime: "podman"},
+	{marker: "containerd", runtime: "containerd"},
+	{marker: "lxc", runtime: "lxc"},
+}
+
+// hypervisorMarkers maps substrings of the DMI system vendor or product
+// name to the hypervisor they indicate.
+var hypervisorMarkers = []struct {
+	marker     string
+	hypervisor string
+}{
+	{marker: "vmware", hypervisor: "vmware"},
+	{marker: "virtualbox", hypervisor: "virtualbox"},
+	{marker: "kvm", hypervisor: "kvm"},
+	{marker: "qemu", hypervisor: "qemu"},
+	{marker: "microsoft corporation", hypervisor: "hyper-v"},
+	{marker: "xen", hypervisor: "xen"},
+	{marker: "amazon ec2", hypervisor: "aws"},
+	{marker: "google", hypervisor: "gce"},
+	{marker: "openstack", hypervisor: "openstack"},
+}
+
+// HostContext describes the host running the plugin. This is useful for
+// fleet-wide plugins where the same service runs on heterogeneous nodes.
+// Details which could not be determined are empty.
+type HostContext struct {
+	// Hostname is the name of the host.
+	Hostname string
+
+	// OS is the operating system name and version (e.g., "Ubuntu 22.04.3
+	// LTS") if known, otherwise the Go operating system name (e.g.,
+	// "linux").
+	OS string
+
+	// Arch is the Go architecture name (e.g., "amd64").
+	Arch string
+
+	// Kernel is the kernel release (e.g., "5.15.0-91-generic").
+	Kernel string
+
+	// Container is the detected container runtime (e.g., "docker",
+	// "kubernetes").
+	Container string
+
+	// Virtualization is the detected hypervisor (e.g., "kvm", "vmware").
+	Virtualization string
+}
+
+// GatherHostContext gathers details of the host running the plugin.
+// Container and virtualization detection use well-known Linux files and
+// are empty on other platforms.
+func GatherHostContext() HostContext {
+	hc := gatherHostContext(os.DirFS("/"), os.Getenv("container"))
+
+	if hostname, err := os.Hostname(); err == nil {
+		hc.Hostname = hostname
+	}
+
+	return hc
+}
+
+// gatherHostContext gathers host details (except the hostname) from the
+// given filesystem root and value of the container environment variable
+// (set by systemd-nspawn, podman and others).
+func gatherHostContext(root fs.FS, containerEnv string) HostContext {
+	hc := HostContext{
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		Kernel: readFirstLine(root, hostContextKernelRelease),
+	}
+
+	if name := osReleaseName(root); name != "" {
+		hc.OS = name
+	}
+
+	hc.Container = detectContainer(root, containerEnv)
+	hc.Virtualization = detectHypervisor(root)
+
+	return hc
+}
+
+// osReleaseName returns the PRETTY_NAME value of the os-release file.
+func osReleaseName(root fs.FS) string {
+	f, err := root.Open(hostContextOSRelease)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "PRETTY_NAME=")
+		if ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+
+	return ""
+}
+
+// detectContainer returns the container runtime the host is running in.
+func detectContainer(root fs.FS, containerEnv string) string {
+	if containerEnv != "" {
+		return containerEnv
+	}
+
+	cgroup := strings.ToLower(readAll(root, hostContextInitCgroup))
+	for _, m := range containerMarkers {
+		if strings.Contains(cgroup, m.marker) {
+			return m.runtime
+		}
+	}
+
+	if fileExists(root, hostContextDockerEnv) {
+		return "docker"
+	}
+
+	if fileExists(root, hostContextPodmanEnv) {
+		return "podman"
+	}
+
+	return ""
+}
+
+// detectHypervisor returns the hypervisor the host is running on.
+func detectHypervisor(root fs.FS) string {
+	dmi := strings.ToLower(
+		readFirstLine(root, hostContextDMIVendor) + " " + readFirstLine(root, hostContextDMIProduct),
+	)
+
+	for _, m := range hypervisorMarkers {
+		if strings.Contains(dmi, m.marker) {
+			return m.hypervisor
+		}
+	}
+
+	// The CPU flags indicate a hypervisor even if it cannot be identified.
+	for _, line := range strings.Split(readAll(root, hostContextCPUInfo), "\n") {
+		if strings.HasPrefix(line, "flags") && strings.Contains(line, " hypervisor") {
+			return "unknown hypervisor"
+		}
+	}
+
+	return ""
+}
+
+// readAll returns the content of the given file or an empty string if the
+// file cannot be read.
+func readAll(root fs.FS, name string) string {
+	data, err := fs.ReadFile(root, name)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// readFirstLine returns the first line of the given file or an empty string
+// if the file cannot be read.
+func readFirstLine(root fs.FS, name string) string {
+	line, _, _ := strings.Cut(readAll(root, name), "\n")
+
+	return strings.TrimSpace(line)
+}
+
+// fileExists indicates whether the given file exists.
+func fileExists(root fs.FS, name string) bool {
+	_, err := fs.Stat(root, name)
+
+	return err == nil
+}
+
+// WithHostContext is an Option used to enable the host context section.
+// See also EnableHostContext.
+func WithHostContext() Option {
+	return func(p *Plugin) {
+		p.EnableHostContext()
+	}
+}
+
+// EnableHostContext enables a host context section in LongServiceOutput
+// listing the details gathered by GatherHostContext (hostname, OS, kernel,
+// container and virtualization).
+func (p *Plugin) EnableHostContext() {
+	p.hostContextEnabled = true
+}
+
+// handleHostContextSection is a wrapper around the logic used to
+// handle/process the Host Context section header and listing.
+func (p Plugin) handleHostContextSection(w io.Writer) {
+	if !p.hostContextEnabled {
+		return
+	}
+
+	fmt.Fprintf(w,
+		"%s%s%s%s",
+		CheckOutputEOL,
+		p.sectionLabel(p.message(MessageHostContextLabel, defaultHostContextLabel)),
+		CheckOutputEOL,
+		CheckOutputEOL,
+	)
+
+	for _, entry := range GatherHostContext().entries() {
+		fmt.Fprintf(w, "%s%s", p.listItem("%s: %s", entry.name, entry.value), CheckOutputEOL)
+	}
+}
+
+// entries provides the host context as section entries.
+func (hc HostContext) entries() []diagnostic {
+	osName := hc.OS
+	if hc.Arch != "" {
+		osName = fmt.Sprintf("%s (%s)", hc.OS, hc.Arch)
+	}
+
+	valueOr := func(value string, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+
+	return []diagnostic{
+		{name: "Hostname", value: valueOr(hc.Hostname, hostContextUnknown)},
+		{name: "OS", value: valueOr(osName, hostContextUnknown)},
+		{name: "Kernel", value: valueOr(hc.Kernel, hostContextUnknown)},
+		{name: "Container", value: valueOr(hc.Container, hostContextNone)},
+		{name: "Virtualization", value: valueOr(hc.Virtualization, hostContextNone)},
+	}
+}
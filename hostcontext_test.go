@@ -0,0 +1,61 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestHostContextSectionIsOptIn asserts that the host context section is
+// only emitted when enabled and lists each host detail.
+func TestHostContextSectionIsOptIn(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{false, true} {
+		var outputBuffer strings.Builder
+
+		plugin := nagios.NewPlugin(
+			nagios.WithOutputTarget(&outputBuffer),
+			nagios.WithSkipOSExit(),
+		)
+
+		if enabled {
+			plugin.EnableHostContext()
+		}
+
+		plugin.OK("all good")
+		plugin.ReturnCheckResults()
+
+		got := outputBuffer.String()
+
+		if !enabled {
+			if strings.Contains(got, "**HOST CONTEXT**") {
+				t.Errorf("want host context section omitted, got %q", got)
+			}
+			continue
+		}
+
+		hc := nagios.GatherHostContext()
+
+		for _, want := range []string{
+			"**HOST CONTEXT**",
+			"* Hostname: " + hc.Hostname,
+			"* OS: " + hc.OS + " (" + hc.Arch + ")",
+			"* Kernel: ",
+			"* Container: ",
+			"* Virtualization: ",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("want output containing %q, got %q", want, got)
+			}
+		}
+	}
+}
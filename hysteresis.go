@@ -0,0 +1,89 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetRecoveryThresholds sets separate recovery ("exit") thresholds used in
+// place of the warning and critical ("enter") thresholds while the previous
+// run was in the matching (or a more severe) state. For example, with a
+// warning threshold of "80" and a warning recovery threshold of "75", the
+// WARNING state is entered once the value exceeds 80 but is only left once
+// the value drops to 75 or below. This stops metrics hugging a threshold
+// boundary from producing flapping notifications.
+//
+// The warning recovery threshold applies if the previous run was WARNING or
+// CRITICAL and the critical recovery threshold applies if the previous run
+// was CRITICAL. An empty threshold disables recovery handling for that
+// level. This requires a state store (see SetStateStore); the enter
+// thresholds are used if the previous state is unknown.
+//
+// An error wrapping ErrInvalidRange is returned and no thresholds are
+// modified if either threshold cannot be parsed.
+func (p *Plugin) SetRecoveryThresholds(warning string, critical string) error {
+	var warningRange, criticalRange *Range
+
+	if strings.TrimSpace(warning) != "" {
+		r, err := ParseRange(warning)
+		if err != nil {
+			return fmt.Errorf("invalid warning recovery threshold: %w", err)
+		}
+
+		warningRange = &r
+	}
+
+	if strings.TrimSpace(critical) != "" {
+		r, err := ParseRange(critical)
+		if err != nil {
+			return fmt.Errorf("invalid critical recovery threshold: %w", err)
+		}
+
+		criticalRange = &r
+	}
+
+	p.warningRecoveryRange = warningRange
+	p.criticalRecoveryRange = criticalRange
+
+	return nil
+}
+
+// applyHysteresis returns the warning and critical threshold ranges to use
+// given the state of the previous run, substituting recovery thresholds
+// where applicable.
+func (p Plugin) applyHysteresis(warning *Range, critical *Range) (*Range, *Range) {
+	if (p.warningRecoveryRange == nil && p.criticalRecoveryRange == nil) || p.stateStore == nil {
+		return warning, critical
+	}
+
+	state, err := p.stateStore.Load()
+	if err != nil {
+		p.Logger().Warn("failed to load previous state; using enter thresholds", "error", err)
+
+		return warning, critical
+	}
+
+	if state.LastState == nil {
+		return warning, critical
+	}
+
+	previous := *state.LastState
+
+	if p.warningRecoveryRange != nil &&
+		(previous == StateWARNINGExitCode || previous == StateCRITICALExitCode) {
+		warning = p.warningRecoveryRange
+	}
+
+	if p.criticalRecoveryRange != nil && previous == StateCRITICALExitCode {
+		critical = p.criticalRecoveryRange
+	}
+
+	return warning, critical
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRecoveryThresholdsApplyAfterAlert asserts that recovery thresholds are
+// used in place of the enter thresholds while the previous run was in an
+// alert state.
+func TestRecoveryThresholdsApplyAfterAlert(t *testing.T) {
+	t.Parallel()
+
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	run := func(usage float64) int {
+		plugin := nagios.Plugin{}
+		plugin.SetOutputTarget(io.Discard)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetStateFile(stateFile)
+
+		if err := plugin.SetWarningThreshold("80"); err != nil {
+			t.Fatalf("failed to set warning threshold: %v", err)
+		}
+
+		if err := plugin.SetCriticalThreshold("90"); err != nil {
+			t.Fatalf("failed to set critical threshold: %v", err)
+		}
+
+		if err := plugin.SetRecoveryThresholds("75", "85"); err != nil {
+			t.Fatalf("failed to set recovery thresholds: %v", err)
+		}
+
+		plugin.ExitStatusCode = plugin.EvaluateThresholds(usage)
+		plugin.ServiceOutput = "usage evaluated"
+		plugin.ReturnCheckResults()
+
+		return plugin.ExitStatusCode
+	}
+
+	tests := []struct {
+		usage float64
+		want  int
+	}{
+		{usage: 78, want: nagios.StateOKExitCode},
+		{usage: 81, want: nagios.StateWARNINGExitCode},
+		// Below the enter threshold but above the recovery threshold.
+		{usage: 78, want: nagios.StateWARNINGExitCode},
+		{usage: 91, want: nagios.StateCRITICALExitCode},
+		{usage: 87, want: nagios.StateCRITICALExitCode},
+		{usage: 84, want: nagios.StateWARNINGExitCode},
+		{usage: 75, want: nagios.StateOKExitCode},
+		{usage: 78, want: nagios.StateOKExitCode},
+	}
+
+	for i, tt := range tests {
+		if got := run(tt.usage); got != tt.want {
+			t.Errorf("run %d (usage %v): want state %d, got %d", i, tt.usage, tt.want, got)
+		}
+	}
+}
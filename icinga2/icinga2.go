@@ -0,0 +1,216 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package icinga2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+const (
+	// objectsPath is the path of the API objects endpoints, relative to the
+	// API base URL.
+	objectsPath string = "/v1/objects/"
+
+	// methodOverrideHeader is used to send a GET query with a request body
+	// (e.g., a filter) via POST as recommended by the API documentation.
+	methodOverrideHeader string = "X-HTTP-Method-Override"
+
+	// maxErrorBodySize is the maximum number of bytes of an error response
+	// included in returned errors.
+	maxErrorBodySize int64 = 512
+)
+
+// ErrQueryFailed indicates that the Icinga 2 API rejected a query.
+var ErrQueryFailed = errors.New("icinga2 API query failed")
+
+// Client sends queries to the Icinga 2 API.
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// Object is an API object returned by a query.
+type Object struct {
+	// Name is the full name of the object (e.g., "web01!http").
+	Name string `json:"name"`
+
+	// Type is the object type (e.g., "Downtime").
+	Type string `json:"type"`
+
+	// Attrs is the collection of requested object attributes.
+	Attrs map[string]json.RawMessage `json:"attrs"`
+}
+
+// queryRequest is the body of an objects query.
+type queryRequest struct {
+	Filter     string            `json:"filter,omitempty"`
+	FilterVars map[string]string `json:"filter_vars,omitempty"`
+	Attrs      []string          `json:"attrs,omitempty"`
+}
+
+// queryResponse is the body of an objects query response.
+type queryResponse struct {
+	Results []Object `json:"results"`
+}
+
+// ClientOption is a functional option used to configure a Client value
+// when constructed via NewClient.
+type ClientOption func(*Client)
+
+// WithCredentials is a ClientOption used to specify the API user
+// credentials sent using HTTP basic authentication.
+func WithCredentials(username string, password string) ClientOption {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithHTTPClient is a ClientOption used to specify the HTTP client used to
+// send requests (e.g., one configured with a CA bundle via the transport
+// package). If not specified, http.DefaultClient is used.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// NewClient returns a Client which queries the Icinga 2 API at the given
+// base URL (e.g., "https://icinga.example.com:5665"). Default settings are
+// used unless overridden by the given options (e.g., WithCredentials).
+func NewClient(baseURL string, options ...ClientOption) *Client {
+	c := Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return &c
+}
+
+// Objects queries objects of the given type (the plural URL form, e.g.,
+// "downtimes") matching the given filter expression and returns the
+// requested attributes. Filter variables are passed separately from the
+// expression so that values do not need to be escaped.
+//
+// An error wrapping ErrQueryFailed is returned if the API rejects the
+// query.
+func (c *Client) Objects(ctx context.Context, objectType string, filter string, filterVars map[string]string, attrs ...string) ([]Object, error) {
+	body, err := json.Marshal(queryRequest{
+		Filter:     filter,
+		FilterVars: filterVars,
+		Attrs:      attrs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.baseURL+objectsPath+objectType,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(methodOverrideHeader, http.MethodGet)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+		return nil, fmt.Errorf(
+			"%w: %s: %s",
+			ErrQueryFailed,
+			resp.Status,
+			strings.TrimSpace(string(detail)),
+		)
+	}
+
+	var result queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode query response: %w", err)
+	}
+
+	return result.Results, nil
+}
+
+// DowntimeChecker returns a nagios.DowntimeChecker which queries for a
+// downtime in effect for the given service or for the host it belongs to.
+// If service is empty, only host downtime is considered.
+func (c *Client) DowntimeChecker(host string, service string) nagios.DowntimeChecker {
+	return nagios.DowntimeCheckerFunc(func(ctx context.Context) (*nagios.Downtime, error) {
+		filter := `downtime.host_name == host && downtime.service_name == "" && downtime.is_in_effect`
+		if service != "" {
+			filter = `downtime.host_name == host && ` +
+				`(downtime.service_name == service || downtime.service_name == "") && ` +
+				`downtime.is_in_effect`
+		}
+
+		objects, err := c.Objects(
+			ctx,
+			"downtimes",
+			filter,
+			map[string]string{"host": host, "service": service},
+			"author", "comment", "end_time",
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(objects) == 0 {
+			return nil, nil
+		}
+
+		var author, comment string
+		var endTime float64
+
+		attrs := objects[0].Attrs
+		_ = json.Unmarshal(attrs["author"], &author)
+		_ = json.Unmarshal(attrs["comment"], &comment)
+		_ = json.Unmarshal(attrs["end_time"], &endTime)
+
+		downtime := nagios.Downtime{
+			Author:  author,
+			Comment: comment,
+		}
+
+		if endTime > 0 {
+			downtime.End = time.Unix(int64(endTime), 0)
+		}
+
+		return &downtime, nil
+	})
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package icinga2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/passive"
+	"github.com/atc0005/go-nagios/transport"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestDowntimeCheckerReportsActiveDowntime asserts that downtimes in effect
+// are queried using a filter with filter variables and that the first
+// result is reported.
+func TestDowntimeCheckerReportsActiveDowntime(t *testing.T) {
+	t.Parallel()
+
+	var got queryRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/objects/downtimes" || r.Header.Get(methodOverrideHeader) != http.MethodGet {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		if user, pass, ok := r.BasicAuth(); !ok || user != "monitor" || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&got)
+
+		_, _ = w.Write([]byte(`{"results":[{"name":"web01!HTTP!1","type":"Downtime",` +
+			`"attrs":{"author":"jdoe","comment":"kernel patching","end_time":1704128400.0}}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	checker := NewClient(server.URL, WithCredentials("monitor", "secret"), WithHTTPClient(server.Client())).DowntimeChecker("web01", "HTTP")
+
+	downtime, err := checker.ActiveDowntime(context.Background())
+	if err != nil {
+		t.Fatalf("failed to check downtime: %v", err)
+	}
+
+	if downtime == nil || downtime.Author != "jdoe" || downtime.Comment != "kernel patching" ||
+		downtime.End.Unix() != 1704128400 {
+		t.Errorf("unexpected downtime: %+v", downtime)
+	}
+
+	if got.FilterVars["host"] != "web01" || got.FilterVars["service"] != "HTTP" {
+		t.Errorf("unexpected filter variables: %v", got.FilterVars)
+	}
+
+	unauthorized := NewClient(server.URL, WithCredentials("monitor", "wrong"), WithHTTPClient(server.Client()))
+	if _, err := unauthorized.Objects(context.Background(), "downtimes", "", nil); !errors.Is(err, ErrQueryFailed) {
+		t.Errorf("want error wrapping %v, got %v", ErrQueryFailed, err)
+	}
+}
+
+// TestClientSubmitProcessesCheckResults asserts that passive results are
+// submitted via the process-check-result action with performance data
+// separated from the plugin output.
+func TestClientSubmitProcessesCheckResults(t *testing.T) {
+	t.Parallel()
+
+	var got []processCheckResultRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != processCheckResultPath || r.Method != http.MethodPost {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		var request processCheckResultRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		if request.Host == "missing" {
+			http.Error(w, `{"error":404,"status":"No objects found."}`, http.StatusNotFound)
+			return
+		}
+
+		got = append(got, request)
+		_, _ = w.Write([]byte(`{"results":[{"code":200,"status":"Successfully processed check result"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var sink passive.Sink = NewClient(server.URL, WithCredentials("monitor", "secret"), WithHTTPClient(server.Client()))
+
+	err := sink.Submit(context.Background(), []passive.CheckResult{
+		{HostName: "node01", ServiceDescription: "disk", ExitCode: 2, Output: "CRITICAL: / 98% used | '/'=98%;;90;;"},
+		{HostName: "node02", ExitCode: 0, Output: "UP: reachable"},
+	})
+	if err != nil {
+		t.Fatalf("failed to submit: %v", err)
+	}
+
+	want := []processCheckResultRequest{
+		{
+			Type:            "Service",
+			Service:         "node01!disk",
+			ExitStatus:      2,
+			PluginOutput:    "CRITICAL: / 98% used",
+			PerformanceData: []string{"'/'=98%;;90;;"},
+		},
+		{Type: "Host", Host: "node02", PluginOutput: "UP: reachable"},
+	}
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	err = sink.Submit(context.Background(), []passive.CheckResult{{HostName: "missing", Output: "UP"}})
+	if !errors.Is(err, ErrQueryFailed) {
+		t.Errorf("want error %v, got %v", ErrQueryFailed, err)
+	}
+}
+
+// TestParseSinkSubmitsPluginResult asserts that Icinga 2 sink
+// specifications submit plugin results via the API.
+func TestParseSinkSubmitsPluginResult(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan processCheckResultRequest, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, _ := r.BasicAuth(); user != "monitor" || password != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var request processCheckResultRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		received <- request
+		_, _ = w.Write([]byte(`{"results":[{"code":200,"status":"Successfully processed check result"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	spec := strings.Replace(server.URL, "http://", SinkSchemeHTTP+"://monitor:secret@", 1) + "?host=node01&service=disk"
+
+	sink, err := nagios.ParseSink(spec)
+	if err != nil {
+		t.Fatalf("failed to parse sink: %v", err)
+	}
+
+	err = sink.Emit(nagios.Result{
+		ExitCode:      nagios.StateCRITICALExitCode,
+		ServiceOutput: "CRITICAL: / 98% used",
+		PerfData:      []nagios.PerformanceData{{Label: "/", Value: "98", UnitOfMeasurement: "%"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to emit result: %v", err)
+	}
+
+	want := processCheckResultRequest{
+		Type:            "Service",
+		Service:         "node01!disk",
+		ExitStatus:      2,
+		PluginOutput:    "CRITICAL: / 98% used",
+		PerformanceData: []string{"'/'=98%;;;;"},
+	}
+
+	if d := cmp.Diff(want, <-received); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestParseSinkSpoolsUndeliveredResults asserts that the delivery
+// parameters of Icinga 2 sink specifications are applied, queueing results
+// in the spool if the API is unavailable.
+func TestParseSinkSpoolsUndeliveredResults(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+
+	spec := strings.Replace(server.URL, "http://", SinkSchemeHTTP+"://monitor:secret@", 1) +
+		"?host=node01&service=disk&" + passive.SinkParamSpool + "=" + url.QueryEscape(dir)
+
+	sink, err := nagios.ParseSink(spec)
+	if err != nil {
+		t.Fatalf("failed to parse sink: %v", err)
+	}
+
+	err = sink.Emit(nagios.Result{ExitCode: nagios.StateOKExitCode, ServiceOutput: "OK: / 40% used"})
+	if !errors.Is(err, transport.ErrPayloadSpooled) {
+		t.Fatalf("want error wrapping %v, got %v", transport.ErrPayloadSpooled, err)
+	}
+
+	spool, err := transport.NewSpool(dir)
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+
+	if got, err := spool.Len(); err != nil || got != 1 {
+		t.Errorf("want 1 queued entry, got %d (err: %v)", got, err)
+	}
+}
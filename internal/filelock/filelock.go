@@ -0,0 +1,58 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+// Package filelock provides the exclusive lock used to serialize access to
+// files shared by concurrent plugin runs (e.g., the JSON state file and the
+// passive result spool).
+//
+// On platforms with flock support an advisory lock is held on the lock file
+// and released automatically by the kernel if the process exits without
+// releasing it. On other platforms the lock is held by exclusively creating
+// the lock file; lock files older than the given stale age are assumed to
+// have been left behind by a process which exited without releasing them
+// and are removed.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// retryInterval is the time spent waiting between attempts to acquire a
+// lock.
+const retryInterval time.Duration = 25 * time.Millisecond
+
+// lockFilePerms are the permissions applied to created lock files.
+const lockFilePerms = 0o600
+
+// ErrTimeout indicates that a lock could not be acquired before the timeout
+// expired.
+var ErrTimeout = errors.New("timeout acquiring file lock")
+
+// Acquire acquires an exclusive lock on the lock file at the given path,
+// retrying until the timeout expires. The stale age only applies on
+// platforms without flock support (see package documentation). An error
+// wrapping ErrTimeout is returned if the lock is held by another process
+// once the timeout expires.
+func Acquire(path string, timeout time.Duration, staleAge time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		lock, acquired, err := tryLock(path, staleAge)
+		switch {
+		case err != nil:
+			return nil, err
+		case acquired:
+			return lock, nil
+		case time.Now().After(deadline):
+			return nil, fmt.Errorf("%w: %s", ErrTimeout, path)
+		}
+
+		time.Sleep(retryInterval)
+	}
+}
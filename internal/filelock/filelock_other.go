@@ -0,0 +1,49 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package filelock
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Lock is a lock held via exclusive creation of a lock file. This is used
+// on platforms without flock support.
+type Lock struct {
+	path string
+}
+
+// tryLock attempts to acquire the lock by exclusively creating the given
+// lock file. Lock files older than the given stale age are removed.
+func tryLock(path string, staleAge time.Duration) (*Lock, bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, lockFilePerms)
+	switch {
+	case errors.Is(err, fs.ErrExist):
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleAge {
+			_ = os.Remove(path)
+		}
+
+		return nil, false, nil
+
+	case err != nil:
+		return nil, false, err
+	}
+
+	_ = f.Close()
+
+	return &Lock{path: path}, true, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() {
+	_ = os.Remove(l.path)
+}
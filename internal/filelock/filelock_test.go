@@ -0,0 +1,43 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package filelock_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios/internal/filelock"
+)
+
+// TestAcquireIsExclusive asserts that a held lock cannot be acquired again
+// until it is released.
+func TestAcquireIsExclusive(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".lock")
+
+	lock, err := filelock.Acquire(path, time.Second, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to acquire lock: %v", err)
+	}
+
+	if _, err := filelock.Acquire(path, 50*time.Millisecond, time.Minute); !errors.Is(err, filelock.ErrTimeout) {
+		t.Errorf("want error wrapping %v, got %v", filelock.ErrTimeout, err)
+	}
+
+	lock.Release()
+
+	lock, err = filelock.Acquire(path, time.Second, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to acquire released lock: %v", err)
+	}
+
+	lock.Release()
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package filelock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Lock is an advisory lock held on a lock file.
+type Lock struct {
+	file *os.File
+}
+
+// tryLock attempts to acquire an exclusive flock on the given lock file
+// without blocking. The stale age is not used as the lock is released
+// automatically by the kernel if the process exits without releasing it.
+func tryLock(path string, _ time.Duration) (*Lock, bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, lockFilePerms)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return &Lock{file: f}, true, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() {
+	_ = syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	_ = l.file.Close()
+}
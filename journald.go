@@ -0,0 +1,137 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultJournaldSocket is the path to the systemd journal native protocol
+// socket used if not specified by client code.
+const DefaultJournaldSocket string = "/run/systemd/journal/socket"
+
+// ErrJournaldUnsupported indicates that the systemd journal is not
+// supported on the current platform.
+var ErrJournaldUnsupported = errors.New("journald is only supported on Linux")
+
+// Structured journal fields emitted for each plugin run in addition to
+// MESSAGE, PRIORITY and SYSLOG_IDENTIFIER.
+const (
+	JournalFieldState    string = "STATE"
+	JournalFieldService  string = "SERVICE"
+	JournalFieldExitCode string = "EXIT_CODE"
+	JournalFieldDuration string = "DURATION"
+)
+
+// JournaldOptions configures structured logging of check results to the
+// systemd journal.
+type JournaldOptions struct {
+	// Identifier is the syslog identifier used to filter entries (e.g.,
+	// journalctl -t check_disk). If empty, the name of the running
+	// executable is used.
+	Identifier string
+
+	// Service is the optional name of the service being checked. This is
+	// recorded in the SERVICE field.
+	Service string
+
+	// SocketPath is the path to the journal socket. If empty,
+	// DefaultJournaldSocket is used.
+	SocketPath string
+}
+
+// EnableJournald writes a structured entry for each plugin run to the
+// systemd journal after check results are emitted. In addition to the
+// one-line summary (MESSAGE), the entry includes the STATE, SERVICE,
+// EXIT_CODE and DURATION fields so that journalctl can be used as a
+// troubleshooting tool on agent hosts:
+//
+//	journalctl -t check_disk STATE=CRITICAL
+//
+// Failure to write to the journal does not affect plugin output; the failure
+// is logged instead (see SetLogger). An error wrapping
+// ErrJournaldUnsupported is returned on platforms other than Linux.
+func (p *Plugin) EnableJournald(options JournaldOptions) error {
+	if !journaldSupported {
+		return ErrJournaldUnsupported
+	}
+
+	if options.Identifier == "" {
+		options.Identifier = filepath.Base(os.Args[0])
+	}
+
+	if options.SocketPath == "" {
+		options.SocketPath = DefaultJournaldSocket
+	}
+
+	severities := defaultSyslogSeverities()
+
+	p.addEmitHook(func(p *Plugin, result Result) {
+		priority := syslogSeverities[severities[StateUNKNOWNExitCode]]
+		if name, ok := severities[p.ExitStatusCode]; ok {
+			priority = syslogSeverities[name]
+		}
+
+		fields := [][2]string{
+			{"MESSAGE", strings.TrimSpace(result.ServiceOutput)},
+			{"PRIORITY", fmt.Sprint(priority)},
+			{"SYSLOG_IDENTIFIER", options.Identifier},
+			{JournalFieldState, stateLabel(p.ExitStatusCode)},
+			{JournalFieldExitCode, fmt.Sprint(p.ExitStatusCode)},
+		}
+
+		if options.Service != "" {
+			fields = append(fields, [2]string{JournalFieldService, options.Service})
+		}
+
+		if !p.start.IsZero() {
+			fields = append(fields, [2]string{
+				JournalFieldDuration,
+				time.Since(p.start).Round(time.Millisecond).String(),
+			})
+		}
+
+		if err := writeJournal(options.SocketPath, encodeJournalFields(fields)); err != nil {
+			p.Logger().Warn("failed to write check result to journal", "error", err)
+		}
+	})
+
+	return nil
+}
+
+// encodeJournalFields encodes the given fields using the journal native
+// protocol. Values containing newlines use the binary safe form: the field
+// name, a newline, the value length as a little-endian uint64, the value and
+// a trailing newline.
+func encodeJournalFields(fields [][2]string) []byte {
+	var buf bytes.Buffer
+
+	for _, field := range fields {
+		name, value := field[0], field[1]
+
+		if !strings.Contains(value, "\n") {
+			fmt.Fprintf(&buf, "%s=%s\n", name, value)
+			continue
+		}
+
+		buf.WriteString(name)
+		buf.WriteByte('\n')
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,33 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build linux
+
+package nagios
+
+import (
+	"net"
+)
+
+// journaldSupported indicates whether the systemd journal is supported on
+// this platform.
+const journaldSupported bool = true
+
+// writeJournal sends the encoded entry to the journal socket.
+func writeJournal(socketPath string, entry []byte) error {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(entry); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	return conn.Close()
+}
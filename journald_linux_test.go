@@ -0,0 +1,81 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build linux
+
+package nagios_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestEnableJournaldWritesStructuredFields asserts that a structured entry
+// with the expected fields is written to the journal socket.
+func TestEnableJournaldWritesStructuredFields(t *testing.T) {
+	t.Parallel()
+
+	// Unix socket paths are limited in length, so avoid t.TempDir.
+	dir, err := os.MkdirTemp("", "journal")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("failed to listen on journal socket: %v", err)
+	}
+	defer conn.Close()
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(nagios.WithOutputTarget(&outputBuffer))
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	err = plugin.EnableJournald(nagios.JournaldOptions{
+		Identifier: "check_disk",
+		Service:    "Disk /var",
+		SocketPath: socketPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to enable journald: %v", err)
+	}
+
+	plugin.Warning("85% used")
+	plugin.ReturnCheckResults()
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read journal entry: %v", err)
+	}
+	got := string(buf[:n])
+
+	for _, want := range []string{
+		"MESSAGE=WARNING: 85% used\n",
+		"PRIORITY=4\n",
+		"SYSLOG_IDENTIFIER=check_disk\n",
+		"STATE=WARNING\n",
+		"SERVICE=Disk /var\n",
+		"EXIT_CODE=1\n",
+		"DURATION=",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want journal entry containing %q, got %q", want, got)
+		}
+	}
+}
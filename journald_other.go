@@ -0,0 +1,19 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build !linux
+
+package nagios
+
+// journaldSupported indicates whether the systemd journal is supported on
+// this platform.
+const journaldSupported bool = false
+
+// writeJournal is not supported on this platform.
+func writeJournal(string, []byte) error {
+	return ErrJournaldUnsupported
+}
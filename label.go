@@ -0,0 +1,113 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RRDLabelUniqueLength is the number of leading characters of a performance
+// data label which should be unique. Labels are truncated to this length by
+// RRD based graphing tools (e.g., PNP4Nagios), causing metrics whose labels
+// share the same leading characters to collide or silently disappear.
+const RRDLabelUniqueLength int = 19
+
+// perfDataLabelInvalidChars is the collection of characters which break
+// parsing of performance data if used in a label.
+const perfDataLabelInvalidChars string = `='`
+
+// perfDataLabelReplacedChars is the collection of characters replaced in a
+// performance data label when sanitizing.
+const perfDataLabelReplacedChars string = `='"`
+
+// perfDataLabelReplacement is substituted for invalid characters in a
+// performance data label when sanitizing.
+const perfDataLabelReplacement rune = '_'
+
+// validateLabel asserts that the performance data label does not contain
+// characters which break parsing of performance data.
+func (pd PerformanceData) validateLabel() error {
+	if i := strings.IndexAny(pd.Label, perfDataLabelInvalidChars); i >= 0 {
+		return fmt.Errorf(
+			"%w: label %q contains %q",
+			ErrPerformanceDataInvalidLabel,
+			pd.Label,
+			pd.Label[i:i+1],
+		)
+	}
+
+	return nil
+}
+
+// SanitizePerfDataLabel replaces characters which break parsing of
+// performance data (equals signs and quotes) in the given label with
+// underscores. A label with a leading digit is prefixed with an underscore
+// as many graphing tools do not accept metric names beginning with a digit.
+func SanitizePerfDataLabel(label string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(perfDataLabelReplacedChars, r) {
+			return perfDataLabelReplacement
+		}
+
+		return r
+	}, label)
+
+	if sanitized != "" && sanitized[0] >= '0' && sanitized[0] <= '9' {
+		sanitized = string(perfDataLabelReplacement) + sanitized
+	}
+
+	return sanitized
+}
+
+// WithPerfDataLabelSanitization is an Option used to sanitize performance
+// data labels as they are added. See also SanitizePerfDataLabels.
+func WithPerfDataLabelSanitization() Option {
+	return func(p *Plugin) {
+		p.SanitizePerfDataLabels()
+	}
+}
+
+// SanitizePerfDataLabels indicates that performance data labels should be
+// sanitized (see SanitizePerfDataLabel) as they are added to the collection
+// instead of being rejected by validation.
+func (p *Plugin) SanitizePerfDataLabels() {
+	p.sanitizePerfDataLabels = true
+}
+
+// warnPerfDataLabels logs a warning for any performance data labels which
+// exceed the RRD uniqueness window, noting labels which collide within it.
+func (p Plugin) warnPerfDataLabels() {
+	seen := make(map[string]string, len(p.perfData))
+
+	for _, pd := range p.getSortedPerfData() {
+		if len(pd.Label) <= RRDLabelUniqueLength {
+			seen[pd.Label] = pd.Label
+			continue
+		}
+
+		prefix := pd.Label[:RRDLabelUniqueLength]
+
+		if other, ok := seen[prefix]; ok {
+			p.Logger().Warn(
+				"performance data labels collide within RRD uniqueness window",
+				"label", pd.Label,
+				"other", other,
+				"window", RRDLabelUniqueLength,
+			)
+		} else {
+			p.Logger().Warn(
+				"performance data label exceeds RRD uniqueness window",
+				"label", pd.Label,
+				"window", RRDLabelUniqueLength,
+			)
+		}
+
+		seen[prefix] = pd.Label
+	}
+}
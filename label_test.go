@@ -0,0 +1,92 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestPerfDataLabelValidation asserts that labels containing characters
+// which break parsing of performance data are rejected.
+func TestPerfDataLabelValidation(t *testing.T) {
+	t.Parallel()
+
+	for _, label := range []string{"a=b", "it's"} {
+		pd := nagios.PerformanceData{Label: label, Value: "1"}
+
+		if err := pd.Validate(); !errors.Is(err, nagios.ErrPerformanceDataInvalidLabel) {
+			t.Errorf("%q: want %v, got %v", label, nagios.ErrPerformanceDataInvalidLabel, err)
+		}
+	}
+}
+
+// TestSanitizePerfDataLabels asserts that labels are sanitized as they are
+// added when requested.
+func TestSanitizePerfDataLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"a=b":           "a_b",
+		`it's "quoted"`: "it_s _quoted_",
+		"5min_load":     "_5min_load",
+		"load":          "load",
+	}
+
+	for label, want := range tests {
+		if got := nagios.SanitizePerfDataLabel(label); got != want {
+			t.Errorf("%q: want %q, got %q", label, want, got)
+		}
+	}
+
+	plugin := nagios.Plugin{}
+	plugin.SanitizePerfDataLabels()
+
+	if err := plugin.AddPerfData(false, nagios.PerformanceData{Label: "1=2", Value: "3"}); err != nil {
+		t.Fatalf("want sanitized label accepted, got %v", err)
+	}
+
+	if got := plugin.PerfData(); len(got) != 1 || got[0].Label != "_1_2" {
+		t.Errorf("want sanitized label %q, got %+v", "_1_2", got)
+	}
+}
+
+// TestPerfDataLabelLengthWarning asserts that a warning is logged for
+// labels which collide within the RRD uniqueness window.
+func TestPerfDataLabelLengthWarning(t *testing.T) {
+	t.Parallel()
+
+	var logBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetLogger(slog.New(nagios.NewLogHandler(&logBuffer, slog.LevelWarn)))
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "datastore_used_space_percent", Value: "1"},
+		nagios.PerformanceData{Label: "datastore_used_space_bytes", Value: "2"},
+	)
+	if err != nil {
+		t.Fatalf("failed to add perfdata: %v", err)
+	}
+
+	plugin.ReturnCheckResults()
+
+	if got := logBuffer.String(); !strings.Contains(got, "collide within RRD uniqueness window") {
+		t.Errorf("want collision warning logged, got %q", got)
+	}
+}
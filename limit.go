@@ -0,0 +1,172 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Limit format special characters.
+const (
+	limitPercentSuffix   string  = "%"
+	limitChoiceSeparator string  = ","
+	limitMaxPercentage   float64 = 100
+)
+
+// ErrInvalidLimit indicates that a limit could not be parsed because it
+// does not follow the expected format.
+var ErrInvalidLimit = errors.New("invalid limit")
+
+// LimitKind indicates which direction a Limit applies in.
+type LimitKind int
+
+const (
+	// LimitMinimum indicates that values below the limit trigger an alert
+	// (e.g., free disk space).
+	LimitMinimum LimitKind = iota
+
+	// LimitMaximum indicates that values above the limit trigger an alert
+	// (e.g., used disk space).
+	LimitMaximum
+)
+
+// Limit is a threshold expressed as a percentage of a known total, an
+// absolute value or both, in which case the stricter of the two applies
+// (e.g., warn when free disk space falls below 10% or 5 GB, whichever is
+// reached first).
+type Limit struct {
+	// Percent is the optional limit as a percentage of the total.
+	Percent *float64
+
+	// Absolute is the optional limit as an absolute value.
+	Absolute *float64
+}
+
+// ParseLimit parses a limit given as a percentage (e.g., "10%"), an
+// absolute value (e.g., "5368709120") or both separated by a comma (e.g.,
+// "10%,5368709120"). An error wrapping ErrInvalidLimit is returned if the
+// value cannot be parsed.
+func ParseLimit(s string) (Limit, error) {
+	var l Limit
+
+	input := strings.TrimSpace(s)
+	if input == "" {
+		return l, fmt.Errorf("%w: empty value", ErrInvalidLimit)
+	}
+
+	for _, choice := range strings.Split(input, limitChoiceSeparator) {
+		choice = strings.TrimSpace(choice)
+
+		isPercent := strings.HasSuffix(choice, limitPercentSuffix)
+
+		value, err := strconv.ParseFloat(strings.TrimSuffix(choice, limitPercentSuffix), 64)
+		switch {
+		case err != nil:
+			return Limit{}, fmt.Errorf("%w: %q: %q is not a number", ErrInvalidLimit, s, choice)
+		case value < 0:
+			return Limit{}, fmt.Errorf("%w: %q: %q is negative", ErrInvalidLimit, s, choice)
+		case isPercent && value > limitMaxPercentage:
+			return Limit{}, fmt.Errorf("%w: %q: %q exceeds 100%%", ErrInvalidLimit, s, choice)
+		case isPercent && l.Percent != nil, !isPercent && l.Absolute != nil:
+			return Limit{}, fmt.Errorf("%w: %q: duplicate %q", ErrInvalidLimit, s, choice)
+		case isPercent:
+			l.Percent = &value
+		default:
+			l.Absolute = &value
+		}
+	}
+
+	return l, nil
+}
+
+// Value returns the effective limit for the given total. If both a
+// percentage and an absolute value are set, the stricter of the two (the
+// one reached first) is returned.
+func (l Limit) Value(total float64, kind LimitKind) float64 {
+	values := make([]float64, 0, 2)
+
+	if l.Percent != nil {
+		values = append(values, total**l.Percent/limitMaxPercentage)
+	}
+
+	if l.Absolute != nil {
+		values = append(values, *l.Absolute)
+	}
+
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	value := values[0]
+	for _, v := range values[1:] {
+		switch kind {
+		case LimitMinimum:
+			value = math.Max(value, v)
+		default:
+			value = math.Min(value, v)
+		}
+	}
+
+	return value
+}
+
+// Range returns the threshold Range equivalent to the effective limit for
+// the given total.
+func (l Limit) Range(total float64, kind LimitKind) Range {
+	value := l.Value(total, kind)
+
+	if kind == LimitMinimum {
+		return Range{Start: value, End: math.Inf(1)}
+	}
+
+	return Range{Start: math.Inf(-1), End: value}
+}
+
+// SetLimitThresholds parses the given warning and critical limits (see
+// ParseLimit), resolves them against the given total and sets the
+// resulting WarningThreshold, WarningRange, CriticalThreshold and
+// CriticalRange values for use by EvaluateThresholds. An empty limit leaves
+// the corresponding threshold unset. An error wrapping ErrInvalidLimit is
+// returned and no thresholds are modified if either limit cannot be parsed.
+func (p *Plugin) SetLimitThresholds(total float64, kind LimitKind, warning string, critical string) error {
+	ranges := make([]*Range, 2)
+
+	for i, limit := range []struct {
+		name string
+		text string
+	}{
+		{name: "warning", text: warning},
+		{name: "critical", text: critical},
+	} {
+		if strings.TrimSpace(limit.text) == "" {
+			continue
+		}
+
+		l, err := ParseLimit(limit.text)
+		if err != nil {
+			return fmt.Errorf("invalid %s limit: %w", limit.name, err)
+		}
+
+		r := l.Range(total, kind)
+		ranges[i] = &r
+	}
+
+	if ranges[0] != nil {
+		p.setWarningRange(ranges[0], ranges[0].String())
+	}
+
+	if ranges[1] != nil {
+		p.setCriticalRange(ranges[1], ranges[1].String())
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestLimitValueUsesStricterChoice asserts that the stricter of a
+// percentage and an absolute limit applies.
+func TestLimitValueUsesStricterChoice(t *testing.T) {
+	t.Parallel()
+
+	l, err := nagios.ParseLimit("10%,5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		total float64
+		kind  nagios.LimitKind
+		want  float64
+	}{
+		{name: "minimum percentage stricter", total: 100, kind: nagios.LimitMinimum, want: 10},
+		{name: "minimum absolute stricter", total: 20, kind: nagios.LimitMinimum, want: 5},
+		{name: "maximum percentage stricter", total: 20, kind: nagios.LimitMaximum, want: 2},
+		{name: "maximum absolute stricter", total: 100, kind: nagios.LimitMaximum, want: 5},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := l.Value(tt.total, tt.kind); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	for _, s := range []string{"", "ten%", "-5", "150%", "10%,20%"} {
+		if _, err := nagios.ParseLimit(s); !errors.Is(err, nagios.ErrInvalidLimit) {
+			t.Errorf("want error for %q, got %v", s, err)
+		}
+	}
+}
+
+// TestSetLimitThresholds asserts that resolved limits drive threshold
+// evaluation and display.
+func TestSetLimitThresholds(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	// Free space on a 200 GB volume: warn below 10% (20) or 30, critical
+	// below 5% (10) or 5.
+	if err := plugin.SetLimitThresholds(200, nagios.LimitMinimum, "10%,30", "5%,5"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plugin.WarningThreshold != "30:" || plugin.CriticalThreshold != "10:" {
+		t.Errorf("want thresholds 30: and 10:, got %s and %s", plugin.WarningThreshold, plugin.CriticalThreshold)
+	}
+
+	for value, want := range map[float64]int{
+		50: nagios.StateOKExitCode,
+		25: nagios.StateWARNINGExitCode,
+		8:  nagios.StateCRITICALExitCode,
+	} {
+		if got := plugin.EvaluateThresholds(value); got != want {
+			t.Errorf("want state %d for %v, got %d", want, value, got)
+		}
+	}
+
+	if err := plugin.SetLimitThresholds(200, nagios.LimitMinimum, "bogus", ""); !errors.Is(err, nagios.ErrInvalidLimit) {
+		t.Errorf("want invalid limit error, got %v", err)
+	}
+}
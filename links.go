@@ -0,0 +1,136 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// defaultLinksLabel is the header text for the links section.
+const defaultLinksLabel string = "LINKS"
+
+// Well-known link kinds. Client code may use additional kinds.
+const (
+	// LinkRunbook is the kind of link used for the runbook (or playbook)
+	// describing how to respond to the result.
+	LinkRunbook string = "runbook"
+
+	// LinkDashboard is the kind of link used for a dashboard with further
+	// details of the monitored system.
+	LinkDashboard string = "dashboard"
+
+	// LinkTicket is the kind of link used for a ticket (or issue) tracking
+	// the problem.
+	LinkTicket string = "ticket"
+)
+
+// ErrInvalidLink indicates that a link could not be added because the kind
+// is empty or the URL is not an absolute URL.
+var ErrInvalidLink = errors.New("invalid link")
+
+// Link is a hyperlink attached to a plugin result so that responders can
+// navigate directly to related resources (e.g., a runbook).
+type Link struct {
+	// Kind describes the linked resource (e.g., LinkRunbook).
+	Kind string `json:"kind"`
+
+	// URL is the absolute URL of the linked resource.
+	URL string `json:"url"`
+}
+
+// AddLink attaches a hyperlink of the given kind (e.g., LinkRunbook) to the
+// plugin result. Links are listed as plain URLs in the links section of
+// LongServiceOutput; see also EncodeLinksHTML and EncodeLinksJSON. An error
+// wrapping ErrInvalidLink is returned and the link is not added if kind is
+// empty or rawURL is not an absolute URL.
+func (p *Plugin) AddLink(kind string, rawURL string) error {
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		return fmt.Errorf("%w: missing kind for %q", ErrInvalidLink, rawURL)
+	}
+
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("%w: %s URL %q is not an absolute URL", ErrInvalidLink, kind, rawURL)
+	}
+
+	p.links = append(p.links, Link{Kind: kind, URL: u.String()})
+
+	return nil
+}
+
+// Links returns a copy of the hyperlinks attached to the plugin result in
+// the order added.
+func (p Plugin) Links() []Link {
+	links := make([]Link, len(p.links))
+	copy(links, p.links)
+
+	return links
+}
+
+// handleLinksSection is a wrapper around the logic used to handle/process
+// the Links section header and listing.
+func (p Plugin) handleLinksSection(w io.Writer) {
+	if len(p.links) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w,
+		"%s%s%s%s",
+		CheckOutputEOL,
+		p.sectionLabel(p.message(MessageLinksLabel, defaultLinksLabel)),
+		CheckOutputEOL,
+		CheckOutputEOL,
+	)
+
+	for _, link := range p.links {
+		fmt.Fprintf(w, "%s%s", p.listItem("%s: %s", link.Kind, link.URL), CheckOutputEOL)
+	}
+}
+
+// EncodeLinksJSON writes the given links to w as a JSON array. URLs are
+// written as-is (without escaping HTML characters such as "&") so that they
+// remain usable by consumers.
+func EncodeLinksJSON(w io.Writer, links []Link) error {
+	if links == nil {
+		links = []Link{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(links)
+}
+
+// EncodeLinksHTML writes the given links to w as an HTML list of anchor
+// elements labeled with the link kind. Values are escaped.
+func EncodeLinksHTML(w io.Writer, links []Link) error {
+	var sb strings.Builder
+
+	sb.WriteString("<ul>\n")
+
+	for _, link := range links {
+		fmt.Fprintf(&sb,
+			"<li><a href=\"%s\">%s</a></li>\n",
+			html.EscapeString(link.URL),
+			html.EscapeString(link.Kind),
+		)
+	}
+
+	sb.WriteString("</ul>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
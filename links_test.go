@@ -0,0 +1,107 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestAddLinkListsPlainURLs asserts that attached links are listed as plain
+// URLs in plugin output.
+func TestAddLinkListsPlainURLs(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+	)
+
+	plugin.ServiceOutput = "CRITICAL: replication lag 900s"
+	plugin.ExitStatusCode = nagios.StateCRITICALExitCode
+
+	for kind, url := range map[string]string{
+		nagios.LinkRunbook:   "https://wiki.example.com/runbooks/replication",
+		nagios.LinkDashboard: "https://grafana.example.com/d/db01",
+	} {
+		if err := plugin.AddLink(kind, url); err != nil {
+			t.Fatalf("failed to add %s link: %v", kind, err)
+		}
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"**LINKS**",
+		"* runbook: https://wiki.example.com/runbooks/replication",
+		"* dashboard: https://grafana.example.com/d/db01",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestAddLinkRejectsInvalidLinks asserts that links without a kind or an
+// absolute URL are rejected.
+func TestAddLinkRejectsInvalidLinks(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	for _, link := range []nagios.Link{
+		{Kind: "", URL: "https://wiki.example.com"},
+		{Kind: nagios.LinkTicket, URL: "/browse/OPS-1"},
+		{Kind: nagios.LinkTicket, URL: "jira"},
+	} {
+		if err := plugin.AddLink(link.Kind, link.URL); !errors.Is(err, nagios.ErrInvalidLink) {
+			t.Errorf("%+v: want %v, got %v", link, nagios.ErrInvalidLink, err)
+		}
+	}
+
+	if links := plugin.Links(); len(links) != 0 {
+		t.Errorf("want no links added, got %+v", links)
+	}
+}
+
+// TestEncodeLinks asserts that links are encoded as escaped HTML anchors and
+// as a JSON array.
+func TestEncodeLinks(t *testing.T) {
+	t.Parallel()
+
+	links := []nagios.Link{
+		{Kind: nagios.LinkTicket, URL: "https://jira.example.com/browse/OPS-1?a=1&b=2"},
+	}
+
+	var htmlOutput strings.Builder
+	if err := nagios.EncodeLinksHTML(&htmlOutput, links); err != nil {
+		t.Fatalf("failed to encode HTML: %v", err)
+	}
+
+	wantHTML := "<ul>\n<li><a href=\"https://jira.example.com/browse/OPS-1?a=1&amp;b=2\">ticket</a></li>\n</ul>\n"
+	if got := htmlOutput.String(); got != wantHTML {
+		t.Errorf("want HTML %q, got %q", wantHTML, got)
+	}
+
+	var jsonOutput strings.Builder
+	if err := nagios.EncodeLinksJSON(&jsonOutput, links); err != nil {
+		t.Fatalf("failed to encode JSON: %v", err)
+	}
+
+	wantJSON := `[{"kind":"ticket","url":"https://jira.example.com/browse/OPS-1?a=1&b=2"}]` + "\n"
+	if got := jsonOutput.String(); got != wantJSON {
+		t.Errorf("want JSON %q, got %q", wantJSON, got)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package livestatus
+
+import (
+	"context"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// acknowledgementCommentType is the comments table entry_type value used for
+// acknowledgement comments.
+const acknowledgementCommentType string = "4"
+
+// acknowledgementColumns is the collection of columns requested from the
+// comments table.
+var acknowledgementColumns = []string{"author", "comment", "entry_time"}
+
+// AcknowledgementSource returns a nagios.AcknowledgementSource which checks
+// whether the problem with the given service (or host, if service is empty)
+// has been acknowledged and, if so, returns the most recent acknowledgement
+// comment.
+func (c *Client) AcknowledgementSource(host string, service string) nagios.AcknowledgementSource {
+	return nagios.AcknowledgementSourceFunc(func(ctx context.Context) (*nagios.Acknowledgement, error) {
+		table := "services"
+		headers := []string{Filter("host_name", host), Filter("description", service)}
+
+		if service == "" {
+			table = "hosts"
+			headers = []string{Filter("name", host)}
+		}
+
+		rows, err := c.Query(ctx, table, []string{"acknowledged"}, headers...)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == 0 || rowFloat(rows[0], 0) != 1 {
+			return nil, nil
+		}
+
+		rows, err = c.Query(ctx, "comments", acknowledgementColumns,
+			Filter("host_name", host),
+			Filter("service_description", service),
+			Filter("entry_type", acknowledgementCommentType),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// The problem is acknowledged; the comment may be missing if it was
+		// deleted separately.
+		ack := nagios.Acknowledgement{}
+
+		var latest float64
+		for _, row := range rows {
+			entryTime := rowFloat(row, 2)
+			if entryTime < latest {
+				continue
+			}
+
+			latest = entryTime
+			ack.Author = rowString(row, 0)
+			ack.Comment = rowString(row, 1)
+		}
+
+		return &ack, nil
+	})
+}
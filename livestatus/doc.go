@@ -0,0 +1,35 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package livestatus provides a minimal client for the Livestatus query
+protocol used to look up the current status of hosts and services from a
+running monitoring core (Nagios with the Livestatus broker module, Naemon,
+Checkmk).
+
+# OVERVIEW
+
+Plugins occasionally need context from the monitoring core itself, such as
+whether the service being checked is in scheduled downtime or has been
+acknowledged. This package sends Livestatus queries over a UNIX socket or
+TCP connection and decodes the JSON formatted response.
+
+# FEATURES
+
+  - Client type used to send Livestatus queries with filters
+  - DowntimeChecker used with the nagios.Plugin SetDowntimeChecker method
+    to annotate (or downgrade) results while the host or service is in
+    scheduled downtime
+  - AcknowledgementSource used with the nagios.Plugin
+    SetAcknowledgementSource method to include an existing acknowledgement
+    (e.g., "Acknowledged by jdoe: vendor ticket opened") in check output
+
+See also:
+
+  - https://docs.checkmk.com/latest/en/livestatus.html
+*/
+package livestatus
@@ -0,0 +1,60 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package livestatus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// downtimeColumns is the collection of columns requested from the downtimes
+// table.
+var downtimeColumns = []string{"author", "comment", "end_time"}
+
+// DowntimeChecker returns a nagios.DowntimeChecker which queries the
+// downtimes table for an active downtime of the given service or of the
+// host it belongs to. If service is empty, only host downtime is
+// considered.
+func (c *Client) DowntimeChecker(host string, service string) nagios.DowntimeChecker {
+	return nagios.DowntimeCheckerFunc(func(ctx context.Context) (*nagios.Downtime, error) {
+		now := time.Now().Unix()
+
+		headers := []string{
+			Filter("host_name", host),
+			// Host downtimes have an empty service description.
+			Filter("service_description", ""),
+		}
+
+		if service != "" {
+			headers = append(headers, Filter("service_description", service), "Or: 2")
+		}
+
+		headers = append(headers,
+			fmt.Sprintf("Filter: start_time <= %d", now),
+			fmt.Sprintf("Filter: end_time >= %d", now),
+		)
+
+		rows, err := c.Query(ctx, "downtimes", downtimeColumns, headers...)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == 0 {
+			return nil, nil
+		}
+
+		return &nagios.Downtime{
+			Author:  rowString(rows[0], 0),
+			Comment: rowString(rows[0], 1),
+			End:     rowTime(rows[0], 2),
+		}, nil
+	})
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package livestatus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the maximum time spent on a query if the context
+	// used does not specify a deadline.
+	DefaultTimeout time.Duration = 10 * time.Second
+
+	// responseHeaderLength is the length of the fixed16 response header.
+	responseHeaderLength int = 16
+
+	// responseStatusOK is the response status code for a successful query.
+	responseStatusOK int = 200
+)
+
+var (
+	// ErrQueryFailed indicates that the Livestatus server rejected a query.
+	ErrQueryFailed = errors.New("livestatus query failed")
+
+	// ErrInvalidResponse indicates that the Livestatus server returned a
+	// response which could not be decoded.
+	ErrInvalidResponse = errors.New("invalid livestatus response")
+
+	// ErrInvalidQueryValue indicates that a query value (e.g., a filter
+	// value) contains a newline, which would alter the query.
+	ErrInvalidQueryValue = errors.New("invalid livestatus query value")
+)
+
+// Client sends queries to a Livestatus server.
+type Client struct {
+	network string
+	address string
+}
+
+// NewClient returns a Client which connects to the Livestatus server at the
+// given address. The network is "unix" for a UNIX socket (e.g.,
+// "/usr/local/nagios/var/rw/live") or "tcp" for a TCP connection (e.g.,
+// "monitoring.example.com:6557").
+func NewClient(network string, address string) *Client {
+	return &Client{
+		network: network,
+		address: address,
+	}
+}
+
+// Filter returns a filter header matching rows where the given column
+// equals the given value (e.g., "Filter: host_name = web01").
+func Filter(column string, value string) string {
+	return fmt.Sprintf("Filter: %s = %s", column, value)
+}
+
+// Query sends a GET query for the given table and columns along with any
+// additional headers (e.g., filters) and returns the resulting rows. Each
+// row holds the values of the requested columns, in order, as decoded from
+// JSON.
+//
+// An error wrapping ErrInvalidQueryValue is returned if the table, a column
+// or a header contains a newline. An error wrapping ErrQueryFailed is
+// returned if the server rejects the query.
+func (c *Client) Query(ctx context.Context, table string, columns []string, headers ...string) ([][]interface{}, error) {
+	query, err := buildQuery(table, columns, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to livestatus: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := io.WriteString(conn, query); err != nil {
+		return nil, fmt.Errorf("failed to send livestatus query: %w", err)
+	}
+
+	return readResponse(conn)
+}
+
+// buildQuery builds a Livestatus query requesting a JSON formatted response
+// with a fixed length response header.
+func buildQuery(table string, columns []string, headers []string) (string, error) {
+	values := append([]string{table}, columns...)
+	values = append(values, headers...)
+
+	for _, value := range values {
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("%w: %q", ErrInvalidQueryValue, value)
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "GET %s\n", table)
+
+	if len(columns) > 0 {
+		fmt.Fprintf(&b, "Columns: %s\n", strings.Join(columns, " "))
+	}
+
+	for _, header := range headers {
+		fmt.Fprintf(&b, "%s\n", header)
+	}
+
+	b.WriteString("OutputFormat: json\n")
+	b.WriteString("ResponseHeader: fixed16\n")
+	b.WriteString("\n")
+
+	return b.String(), nil
+}
+
+// readResponse reads and decodes a response with a fixed16 response header.
+func readResponse(r io.Reader) ([][]interface{}, error) {
+	header := make([]byte, responseHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %v", ErrInvalidResponse, err)
+	}
+
+	status, err := strconv.Atoi(strings.TrimSpace(string(header[:3])))
+	if err != nil {
+		return nil, fmt.Errorf("%w: header %q", ErrInvalidResponse, header)
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(string(header[4:])))
+	if err != nil {
+		return nil, fmt.Errorf("%w: header %q", ErrInvalidResponse, header)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("%w: failed to read body: %v", ErrInvalidResponse, err)
+	}
+
+	if status != responseStatusOK {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrQueryFailed, status, strings.TrimSpace(string(body)))
+	}
+
+	var rows [][]interface{}
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	return rows, nil
+}
+
+// rowString returns the string value at the given index of a row or an
+// empty string if the value is not a string.
+func rowString(row []interface{}, i int) string {
+	if i >= len(row) {
+		return ""
+	}
+
+	s, _ := row[i].(string)
+
+	return s
+}
+
+// rowFloat returns the numeric value at the given index of a row or zero if
+// the value is not a number.
+func rowFloat(row []interface{}, i int) float64 {
+	if i >= len(row) {
+		return 0
+	}
+
+	f, _ := row[i].(float64)
+
+	return f
+}
+
+// rowTime returns the UNIX timestamp at the given index of a row as a
+// time.Time value or the zero value if the value is not a (non-zero)
+// number.
+func rowTime(row []interface{}, i int) time.Time {
+	seconds := rowFloat(row, i)
+	if seconds == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(seconds), 0)
+}
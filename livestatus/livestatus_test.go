@@ -0,0 +1,149 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package livestatus
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// response is a reply sent by the fake Livestatus server.
+type response struct {
+	status int
+	body   string
+}
+
+// serve starts a fake Livestatus server which records the queries received
+// and replies to each connection in turn with the given responses.
+func serve(t *testing.T, responses ...response) (string, <-chan string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	queries := make(chan string, len(responses))
+
+	go func() {
+		for _, resp := range responses {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			var query strings.Builder
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() && scanner.Text() != "" {
+				query.WriteString(scanner.Text() + "\n")
+			}
+			queries <- query.String()
+
+			fmt.Fprintf(conn, "%3d %11d\n%s", resp.status, len(resp.body), resp.body)
+			_ = conn.Close()
+		}
+	}()
+
+	return ln.Addr().String(), queries
+}
+
+// TestDowntimeCheckerReportsActiveDowntime asserts that the downtimes table
+// is queried for host and service downtime and that the first row is
+// reported.
+func TestDowntimeCheckerReportsActiveDowntime(t *testing.T) {
+	t.Parallel()
+
+	addr, queries := serve(t, response{200, `[["jdoe","kernel patching",1704128400]]`})
+
+	checker := NewClient("tcp", addr).DowntimeChecker("web01", "HTTP")
+
+	downtime, err := checker.ActiveDowntime(context.Background())
+	if err != nil {
+		t.Fatalf("failed to check downtime: %v", err)
+	}
+
+	if downtime == nil || downtime.Author != "jdoe" || downtime.Comment != "kernel patching" ||
+		downtime.End.Unix() != 1704128400 {
+		t.Errorf("unexpected downtime: %+v", downtime)
+	}
+
+	query := <-queries
+	for _, want := range []string{
+		"GET downtimes\n",
+		"Columns: author comment end_time\n",
+		"Filter: host_name = web01\n",
+		"Filter: service_description = \nFilter: service_description = HTTP\nOr: 2\n",
+		"OutputFormat: json\n",
+		"ResponseHeader: fixed16\n",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("want query to contain %q, got:\n%s", want, query)
+		}
+	}
+}
+
+// TestAcknowledgementSourceReportsLatestComment asserts that the most recent
+// acknowledgement comment is reported for an acknowledged service and that
+// nothing is reported otherwise.
+func TestAcknowledgementSourceReportsLatestComment(t *testing.T) {
+	t.Parallel()
+
+	addr, queries := serve(t,
+		response{200, `[[1]]`},
+		response{200, `[["asmith","old comment",1704100000],["jdoe","vendor ticket 1234",1704120000]]`},
+		response{200, `[[0]]`},
+	)
+
+	source := NewClient("tcp", addr).AcknowledgementSource("web01", "HTTP")
+
+	ack, err := source.Acknowledgement(context.Background())
+	if err != nil {
+		t.Fatalf("failed to look up acknowledgement: %v", err)
+	}
+
+	if ack == nil || ack.String() != "Acknowledged by jdoe: vendor ticket 1234" {
+		t.Errorf("unexpected acknowledgement: %+v", ack)
+	}
+
+	if query := <-queries; !strings.Contains(query, "GET services\nColumns: acknowledged\n") {
+		t.Errorf("unexpected status query:\n%s", query)
+	}
+
+	if query := <-queries; !strings.Contains(query, "Filter: entry_type = 4\n") {
+		t.Errorf("unexpected comments query:\n%s", query)
+	}
+
+	ack, err = source.Acknowledgement(context.Background())
+	if err != nil || ack != nil {
+		t.Errorf("want no acknowledgement, got %+v (error %v)", ack, err)
+	}
+}
+
+// TestQueryReportsFailures asserts that rejected queries and invalid query
+// values are reported.
+func TestQueryReportsFailures(t *testing.T) {
+	t.Parallel()
+
+	addr, _ := serve(t, response{400, "Invalid GET request, no such table 'bogus'\n"})
+
+	_, err := NewClient("tcp", addr).Query(context.Background(), "bogus", nil)
+	if !errors.Is(err, ErrQueryFailed) {
+		t.Errorf("want error wrapping %v, got %v", ErrQueryFailed, err)
+	}
+
+	_, err = NewClient("tcp", addr).Query(context.Background(), "services", nil, Filter("host_name", "web01\nCommand: x"))
+	if !errors.Is(err, ErrInvalidQueryValue) {
+		t.Errorf("want error wrapping %v, got %v", ErrInvalidQueryValue, err)
+	}
+}
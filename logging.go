@@ -0,0 +1,69 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// NewLogHandler returns a slog.Handler which writes text formatted
+// diagnostics at or above the given level to w. If w is nil, os.Stderr is
+// used. Diagnostics must never be written to os.Stdout as Nagios would
+// interpret them as plugin output.
+func NewLogHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	if w == nil {
+		w = os.Stderr
+	}
+
+	return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+}
+
+// discardHandler is a slog.Handler which discards all log records. This is
+// used when client code has not provided a logger.
+type discardHandler struct{}
+
+// Enabled always reports false so that log records are not constructed.
+func (discardHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+// Handle discards the log record.
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs returns the receiver unchanged.
+func (dh discardHandler) WithAttrs([]slog.Attr) slog.Handler { return dh }
+
+// WithGroup returns the receiver unchanged.
+func (dh discardHandler) WithGroup(string) slog.Handler { return dh }
+
+// WithLogger is an Option used to assign a logger used for library (and
+// client code) diagnostics. See also SetLogger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Plugin) {
+		p.SetLogger(logger)
+	}
+}
+
+// SetLogger assigns a logger used for library diagnostics. The logger is
+// also available to client code via the Logger method so that diagnostics
+// from both are written to the same destination. Diagnostics are discarded
+// if a logger is not set.
+func (p *Plugin) SetLogger(logger *slog.Logger) {
+	p.logger = logger
+}
+
+// Logger returns the logger used for diagnostics. If a logger has not been
+// set, a logger which discards all output is returned.
+func (p *Plugin) Logger() *slog.Logger {
+	if p.logger == nil {
+		return slog.New(discardHandler{})
+	}
+
+	return p.logger
+}
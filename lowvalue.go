@@ -0,0 +1,90 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseLowThreshold parses a threshold for a metric where low values alert
+// (e.g., free space, battery charge or replica count). A plain number
+// (e.g., "20") is treated as the lowest healthy value ("20:", alerting if
+// the value falls below 20) rather than the guideline meaning of a plain
+// number (alerting if the value is below 0 or above 20). Any other value is
+// parsed as a standard range, including "@" inside ranges (e.g., "@0:20"
+// alerts if the value is between 0 and 20 inclusive).
+//
+// An error wrapping ErrInvalidRange is returned if the value cannot be
+// parsed.
+func ParseLowThreshold(s string) (Range, error) {
+	input := strings.TrimSpace(s)
+
+	if value, err := strconv.ParseFloat(input, 64); err == nil && !math.IsNaN(value) && !math.IsInf(value, 0) {
+		return Range{Start: value, End: math.Inf(1)}, nil
+	}
+
+	return ParseRange(input)
+}
+
+// SetLowThresholds sets the warning and critical thresholds (either may be
+// empty) for a metric where low values alert, parsing each using
+// ParseLowThreshold. The THRESHOLDS section displays each threshold along
+// with a plain language description (e.g., "20: (alert if < 20)").
+//
+// An error wrapping ErrInvalidRange is returned and no thresholds are
+// modified if either value cannot be parsed or if the critical threshold is
+// higher than the warning threshold (which would prevent the WARNING state
+// from being reached).
+func (p *Plugin) SetLowThresholds(warning string, critical string) error {
+	ranges := make([]*Range, 2)
+
+	for i, threshold := range []struct {
+		name string
+		text string
+	}{
+		{name: "warning", text: warning},
+		{name: "critical", text: critical},
+	} {
+		if strings.TrimSpace(threshold.text) == "" {
+			continue
+		}
+
+		r, err := ParseLowThreshold(threshold.text)
+		if err != nil {
+			return fmt.Errorf("invalid %s threshold: %w", threshold.name, err)
+		}
+		ranges[i] = &r
+	}
+
+	warningRange, criticalRange := ranges[0], ranges[1]
+
+	if warningRange != nil && criticalRange != nil &&
+		!warningRange.AlertInside && !criticalRange.AlertInside &&
+		math.IsInf(warningRange.End, 1) && math.IsInf(criticalRange.End, 1) &&
+		criticalRange.Start > warningRange.Start {
+		return fmt.Errorf(
+			"%w: critical threshold %s is higher than warning threshold %s for a metric where low values alert",
+			ErrInvalidRange,
+			formatRangeBoundary(criticalRange.Start),
+			formatRangeBoundary(warningRange.Start),
+		)
+	}
+
+	if warningRange != nil {
+		p.setWarningRange(warningRange, fmt.Sprintf("%s (%s)", warningRange, warningRange.Describe()))
+	}
+
+	if criticalRange != nil {
+		p.setCriticalRange(criticalRange, fmt.Sprintf("%s (%s)", criticalRange, criticalRange.Describe()))
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRangeDescribe asserts that ranges are described in plain language.
+func TestRangeDescribe(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"10":     "alert if < 0 or > 10",
+		"10:":    "alert if < 10",
+		"~:10":   "alert if > 10",
+		"10:20":  "alert if < 10 or > 20",
+		"@10:20": "alert if >= 10 and <= 20",
+		"@~:5":   "alert if <= 5",
+	}
+
+	for threshold, want := range tests {
+		r, err := nagios.ParseRange(threshold)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", threshold, err)
+		}
+
+		if got := r.Describe(); got != want {
+			t.Errorf("want %q for %q, got %q", want, threshold, got)
+		}
+	}
+}
+
+// TestSetLowThresholds asserts that plain numbers are treated as the lowest
+// healthy value for metrics where low values alert.
+func TestSetLowThresholds(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.SetLowThresholds("20", "10"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for value, want := range map[float64]int{
+		50: nagios.StateOKExitCode,
+		15: nagios.StateWARNINGExitCode,
+		5:  nagios.StateCRITICALExitCode,
+	} {
+		if got := plugin.EvaluateThresholds(value); got != want {
+			t.Errorf("want state %d for %v, got %d", want, value, got)
+		}
+	}
+
+	if err := plugin.SetLowThresholds("10", "20"); !errors.Is(err, nagios.ErrInvalidRange) {
+		t.Errorf("want error for critical above warning, got %v", err)
+	}
+
+	plugin.ServiceOutput = "battery at 50%"
+	plugin.LongServiceOutput = "on mains power"
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"* CRITICAL: 10: (alert if < 10)",
+		"* WARNING: 20: (alert if < 20)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
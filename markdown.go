@@ -0,0 +1,100 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown formatting special characters used in plugin output.
+const (
+	markdownBold     string = "**"
+	markdownFence    string = "```"
+	markdownListItem string = "* "
+)
+
+// Markdown indicates whether the output profile uses Markdown formatting
+// (fenced code blocks, bold section labels and bullet lists) in plugin
+// output. Markdown is not used for Nagios Core, which displays it literally
+// and strips backticks from output (due to default
+// `illegal_macro_output_chars` settings).
+func (op OutputProfile) Markdown() bool {
+	switch op {
+	case OutputProfileNagiosCore:
+		return false
+	default:
+		return true
+	}
+}
+
+// WithMarkdown is an Option used to enable or disable Markdown formatting
+// in plugin output. See also SetMarkdown.
+func WithMarkdown(enabled bool) Option {
+	return func(p *Plugin) {
+		p.SetMarkdown(enabled)
+	}
+}
+
+// SetMarkdown overrides whether Markdown formatting selected by the output
+// profile is used in plugin output. If disabled, panic details are emitted
+// without fenced code blocks, section labels (e.g., ERRORS) without bold
+// emphasis and list items (e.g., recorded errors, sub-check results) without
+// bullets.
+//
+// List items recorded by the library while building LongServiceOutput
+// (e.g., by RunChecks or Quorum) use the setting in effect when recorded, so
+// this should be set before recording results.
+func (p *Plugin) SetMarkdown(enabled bool) {
+	p.markdown = &enabled
+}
+
+// Markdown indicates whether Markdown formatting is used for plugin output.
+// This is the value set via SetMarkdown if specified, otherwise the value
+// used by the output profile.
+func (p Plugin) Markdown() bool {
+	if p.markdown != nil {
+		return *p.markdown
+	}
+
+	return p.outputProfile.Markdown()
+}
+
+// sectionLabel formats the given section label, using bold emphasis if
+// Markdown formatting is enabled.
+func (p Plugin) sectionLabel(label string) string {
+	if !p.Markdown() {
+		return label
+	}
+
+	return markdownBold + label + markdownBold
+}
+
+// listItem formats the given text as a list item, using a bullet if
+// Markdown formatting is enabled.
+func (p Plugin) listItem(format string, a ...interface{}) string {
+	item := fmt.Sprintf(format, a...)
+
+	if !p.Markdown() {
+		return item
+	}
+
+	return markdownListItem + item
+}
+
+// codeBlock formats the given lines as a block of preformatted text,
+// wrapped in a fenced code block if Markdown formatting is enabled.
+func (p Plugin) codeBlock(lines ...string) string {
+	block := strings.Join(lines, CheckOutputEOL)
+
+	if !p.Markdown() {
+		return block
+	}
+
+	return markdownFence + CheckOutputEOL + block + CheckOutputEOL + markdownFence
+}
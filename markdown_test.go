@@ -0,0 +1,119 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestMarkdownSelectedByOutputProfile asserts that Markdown formatting is
+// disabled for the Nagios Core output profile unless overridden.
+func TestMarkdownSelectedByOutputProfile(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	if !plugin.Markdown() {
+		t.Error("want Markdown enabled for the default output profile")
+	}
+
+	plugin.SetOutputProfile(nagios.OutputProfileNagiosCore)
+	if plugin.Markdown() {
+		t.Error("want Markdown disabled for the Nagios Core output profile")
+	}
+
+	plugin.SetMarkdown(true)
+	if !plugin.Markdown() {
+		t.Error("want Markdown enabled by override")
+	}
+}
+
+// TestDisabledMarkdownOmitsFormatting asserts that section labels and list
+// items are emitted without Markdown formatting if disabled.
+func TestDisabledMarkdownOmitsFormatting(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithMarkdown(false),
+	)
+
+	if err := plugin.SetCriticalThreshold("90"); err != nil {
+		t.Fatalf("failed to set critical threshold: %v", err)
+	}
+
+	plugin.ServiceOutput = "CRITICAL: 2/3 members non-OK"
+	plugin.AddError(errors.New("connection refused"))
+	plugin.Quorum("", nil, nil,
+		nagios.SubResult{Name: "node1", ExitCode: nagios.StateOKExitCode},
+	)
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"\nERRORS",
+		"\nconnection refused",
+		"\nTHRESHOLDS",
+		"\nCRITICAL: 90",
+		"\nnode1: OK",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	for _, unwanted := range []string{"**", "* "} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("want output without %q, got:\n%s", unwanted, got)
+		}
+	}
+}
+
+// TestDisabledMarkdownOmitsPanicFences asserts that panic details are
+// emitted without fenced code blocks if Markdown is disabled.
+func TestDisabledMarkdownOmitsPanicFences(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithOutputProfile(nagios.OutputProfileNagiosCore),
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer plugin.ReturnCheckResults()
+
+		panic("nil map")
+	}()
+
+	<-done
+
+	got := outputBuffer.String()
+
+	if !strings.Contains(got, "nil map") {
+		t.Errorf("want output to contain panic details, got:\n%s", got)
+	}
+
+	if strings.Contains(got, "```") {
+		t.Errorf("want output without fenced code blocks, got:\n%s", got)
+	}
+}
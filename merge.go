@@ -0,0 +1,173 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultMergeSeparator is the text used to join the one-line summaries of
+// merged results unless client code specifies otherwise. See also
+// SetSummaryJoin.
+const DefaultMergeSeparator string = "; "
+
+// SummaryJoinFunc combines the one-line summaries of merged results into a
+// single one-line summary for the given (merged) state. Summaries are given
+// most severe first with any leading state label (e.g., "CRITICAL: ")
+// removed; empty summaries are omitted.
+type SummaryJoinFunc func(state int, summaries []string) string
+
+// WithSummaryJoin is an Option used to set the function which combines the
+// one-line summaries of merged results. See also SetSummaryJoin.
+func WithSummaryJoin(fn SummaryJoinFunc) Option {
+	return func(p *Plugin) {
+		p.SetSummaryJoin(fn)
+	}
+}
+
+// SetSummaryJoin sets the function used by Merge to combine one-line
+// summaries. By default summaries are joined using DefaultMergeSeparator
+// and prefixed with the label of the merged state:
+//
+//	CRITICAL: disk /var is 97% full; certificate expires in 12 days
+func (p *Plugin) SetSummaryJoin(fn SummaryJoinFunc) {
+	p.summaryJoin = fn
+}
+
+// Merge combines the result recorded by other into the receiver, enabling
+// composition of independently built results (e.g., from a library check
+// plus plugin specific checks):
+//
+//   - the plugin state is set to the most severe state of both results
+//   - the one-line summaries are combined (see SetSummaryJoin)
+//   - errors, LongServiceOutput, links, diagnostics and threshold breaches
+//     of other are appended to those of the receiver
+//   - performance data metrics and thresholds of other are added unless the
+//     receiver already has a metric with the same label or a threshold set
+//
+// Settings of other (e.g., output target or options) are not merged. The
+// receiver is returned to allow chaining further calls.
+func (p *Plugin) Merge(other *Plugin) *Plugin {
+	if other == nil || other == p {
+		return p
+	}
+
+	state := worstExitCode(p.ExitStatusCode, other.ExitStatusCode)
+
+	// Order from most to least severe, keeping the receiver first if both
+	// results have the same state.
+	results := []*Plugin{p, other}
+	sort.SliceStable(results, func(i, j int) bool {
+		return stateSeverity(results[i].ExitStatusCode) > stateSeverity(results[j].ExitStatusCode)
+	})
+
+	summaries := make([]string, 0, len(results))
+	for _, result := range results {
+		if summary := trimStateLabel(result.ServiceOutput); summary != "" {
+			summaries = append(summaries, summary)
+		}
+	}
+
+	join := p.summaryJoin
+	if join == nil {
+		join = joinSummaries
+	}
+
+	if len(summaries) > 0 {
+		p.ServiceOutput = join(state, summaries)
+	}
+
+	if stateSeverity(other.ExitStatusCode) > stateSeverity(p.ExitStatusCode) && other.stateDrivenBy != nil {
+		p.stateDrivenBy = other.stateDrivenBy
+	}
+
+	p.ExitStatusCode = state
+
+	if p.LastError == nil {
+		p.LastError = other.LastError
+	}
+
+	p.Errors = append(p.Errors, other.Errors...)
+
+	if other.LongServiceOutput != "" {
+		p.WithDetail(other.LongServiceOutput)
+	}
+
+	p.mergePerfData(other)
+	p.mergeThresholds(other)
+
+	p.links = append(p.links, other.links...)
+	p.diagnostics = append(p.diagnostics, other.diagnostics...)
+	p.thresholdBreaches = append(p.thresholdBreaches, other.thresholdBreaches...)
+
+	return p
+}
+
+// mergePerfData adds the performance data metrics of other whose labels are
+// not already used by the receiver.
+func (p *Plugin) mergePerfData(other *Plugin) {
+	if len(other.perfData) == 0 {
+		return
+	}
+
+	if p.perfData == nil {
+		p.perfData = make(map[string]PerformanceData, len(other.perfData))
+	}
+
+	for key, pd := range other.perfData {
+		if _, exists := p.perfData[key]; !exists {
+			p.perfData[key] = pd
+		}
+	}
+}
+
+// mergeThresholds copies the thresholds of other which are not already set
+// on the receiver.
+func (p *Plugin) mergeThresholds(other *Plugin) {
+	if p.WarningThreshold == "" {
+		p.WarningThreshold = other.WarningThreshold
+	}
+
+	if p.CriticalThreshold == "" {
+		p.CriticalThreshold = other.CriticalThreshold
+	}
+
+	if p.WarningRange == nil {
+		p.WarningRange = other.WarningRange
+	}
+
+	if p.CriticalRange == nil {
+		p.CriticalRange = other.CriticalRange
+	}
+}
+
+// joinSummaries is the default SummaryJoinFunc.
+func joinSummaries(state int, summaries []string) string {
+	return stateLabel(state) + ": " + strings.Join(summaries, DefaultMergeSeparator)
+}
+
+// trimStateLabel removes a leading state label (e.g., "CRITICAL: ") from
+// the given one-line summary.
+func trimStateLabel(summary string) string {
+	summary = strings.TrimSpace(summary)
+
+	for _, label := range []string{
+		StateOKLabel,
+		StateWARNINGLabel,
+		StateCRITICALLabel,
+		StateUNKNOWNLabel,
+		StateDEPENDENTLabel,
+	} {
+		if rest, found := strings.CutPrefix(summary, label+":"); found {
+			return strings.TrimSpace(rest)
+		}
+	}
+
+	return summary
+}
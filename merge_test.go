@@ -0,0 +1,98 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestMergeCombinesResults asserts that merging two results uses the most
+// severe state and combines summaries, errors, details and performance
+// data.
+func TestMergeCombinesResults(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin()
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.OK("certificate expires in 90 days").WithDetail("issuer: Example CA")
+	if err := plugin.AddPerfData(false, nagios.PerformanceData{Label: "days_left", Value: "90"}); err != nil {
+		t.Fatalf("failed to add performance data: %v", err)
+	}
+
+	library := nagios.NewPlugin()
+	library.Critical("disk /var is 97% full").
+		WithDetail("mount: /var").
+		WithError(errors.New("inode usage unavailable"))
+	if err := library.AddPerfData(false,
+		nagios.PerformanceData{Label: "var_used", Value: "97", UnitOfMeasurement: "%"},
+		nagios.PerformanceData{Label: "days_left", Value: "1"},
+	); err != nil {
+		t.Fatalf("failed to add performance data: %v", err)
+	}
+
+	plugin.Merge(library)
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"CRITICAL: disk /var is 97% full; certificate expires in 90 days",
+		"issuer: Example CA",
+		"mount: /var",
+		"inode usage unavailable",
+		"'var_used'=97%",
+		"'days_left'=90",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "'days_left'=1;") {
+		t.Errorf("want receiver performance data to take precedence, got:\n%s", got)
+	}
+}
+
+// TestMergeUsesSummaryJoin asserts that a custom SummaryJoinFunc is used to
+// combine one-line summaries.
+func TestMergeUsesSummaryJoin(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.NewPlugin(nagios.WithSummaryJoin(func(state int, summaries []string) string {
+		return strings.Join(summaries, " | ")
+	}))
+	plugin.Warning("queue backlog")
+
+	other := nagios.NewPlugin()
+	other.OK("consumers healthy")
+
+	plugin.Merge(other)
+
+	if want, got := "queue backlog | consumers healthy", plugin.ServiceOutput; got != want {
+		t.Errorf("want summary %q, got %q", want, got)
+	}
+
+	if plugin.ExitStatusCode != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, plugin.ExitStatusCode)
+	}
+}
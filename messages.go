@@ -0,0 +1,128 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+// MessageKey identifies a built-in label or phrase of plugin output which
+// may be localized via a MessageCatalog.
+type MessageKey string
+
+// Built-in labels and phrases which may be localized. State labels (e.g.,
+// CRITICAL) are not localized as monitoring systems and client code rely
+// on them.
+const (
+	// MessageThresholdsLabel is the header text of the thresholds section
+	// ("THRESHOLDS").
+	MessageThresholdsLabel MessageKey = "thresholds_label"
+
+	// MessageErrorsLabel is the header text of the errors section
+	// ("ERRORS").
+	MessageErrorsLabel MessageKey = "errors_label"
+
+	// MessageDetailedInfoLabel is the header text of the LongServiceOutput
+	// section ("DETAILED INFO").
+	MessageDetailedInfoLabel MessageKey = "detailed_info_label"
+
+	// MessageDiagnosticsLabel is the header text of the diagnostics section
+	// ("DIAGNOSTICS").
+	MessageDiagnosticsLabel MessageKey = "diagnostics_label"
+
+	// MessageLinksLabel is the header text of the links section ("LINKS").
+	MessageLinksLabel MessageKey = "links_label"
+
+	// MessageEnvironmentLabel is the header text of the environment section
+	// ("ENVIRONMENT").
+	MessageEnvironmentLabel MessageKey = "environment_label"
+
+	// MessageHostContextLabel is the header text of the host context
+	// section ("HOST CONTEXT").
+	MessageHostContextLabel MessageKey = "host_context_label"
+
+	// MessageStateDrivenBy is the text emitted prior to the error
+	// responsible for the final plugin state ("State driven by").
+	MessageStateDrivenBy MessageKey = "state_driven_by"
+
+	// MessagePanicSummary is the one-line summary (following the CRITICAL
+	// state label) used when a panic is detected ("plugin crash detected.
+	// See details via web UI or run plugin manually via CLI.").
+	MessagePanicSummary MessageKey = "panic_summary"
+
+	// MessageInDowntime is appended to the one-line summary while in
+	// scheduled downtime ("in scheduled downtime", shown in parentheses).
+	MessageInDowntime MessageKey = "in_downtime"
+
+	// MessagePartialResults prefixes the counts of the one-line summary of
+	// partial results ("partial results").
+	MessagePartialResults MessageKey = "partial_results"
+
+	// MessageCheckedAt is the footer text preceding the check start time
+	// ("Checked at").
+	MessageCheckedAt MessageKey = "checked_at"
+
+	// MessageStateRemapped is the text noting the original state of
+	// remapped results ("state remapped from", shown in parentheses).
+	MessageStateRemapped MessageKey = "state_remapped"
+)
+
+// MessageCatalog provides localized text for built-in labels and phrases
+// of plugin output. The default (English) text is used for any message the
+// catalog does not provide.
+type MessageCatalog interface {
+	// Message returns the localized text for the given key and whether the
+	// catalog provides it.
+	Message(key MessageKey) (string, bool)
+}
+
+// MessageCatalogMap is a MessageCatalog backed by a map of localized text.
+//
+//	nagios.MessageCatalogMap{
+//		nagios.MessageErrorsLabel:       "FEHLER",
+//		nagios.MessageThresholdsLabel:   "SCHWELLENWERTE",
+//		nagios.MessageDetailedInfoLabel: "DETAILS",
+//	}
+type MessageCatalogMap map[MessageKey]string
+
+// Message returns the localized text for the given key and whether the map
+// provides it.
+func (m MessageCatalogMap) Message(key MessageKey) (string, bool) {
+	text, ok := m[key]
+	if !ok || text == "" {
+		return "", false
+	}
+
+	return text, true
+}
+
+// WithMessageCatalog is an Option used to localize built-in labels and
+// phrases. See also SetMessageCatalog.
+func WithMessageCatalog(catalog MessageCatalog) Option {
+	return func(p *Plugin) {
+		p.SetMessageCatalog(catalog)
+	}
+}
+
+// SetMessageCatalog sets the catalog used to localize built-in labels and
+// phrases of plugin output (see MessageThresholdsLabel and related
+// constants) so that non-English teams can localize output. Labels set
+// explicitly by client code (e.g., via SetErrorsLabel) take precedence.
+func (p *Plugin) SetMessageCatalog(catalog MessageCatalog) {
+	p.messageCatalog = catalog
+}
+
+// message returns the localized text for the given key if provided by the
+// message catalog, otherwise the given default text.
+func (p Plugin) message(key MessageKey, defaultText string) string {
+	if p.messageCatalog == nil {
+		return defaultText
+	}
+
+	if text, ok := p.messageCatalog.Message(key); ok {
+		return text
+	}
+
+	return defaultText
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestMessageCatalogLocalizesBuiltInLabels asserts that built-in labels
+// are localized using the message catalog, falling back to the default
+// text, and that labels set by client code take precedence.
+func TestMessageCatalogLocalizesBuiltInLabels(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithMessageCatalog(nagios.MessageCatalogMap{
+			nagios.MessageErrorsLabel:       "FEHLER",
+			nagios.MessageThresholdsLabel:   "SCHWELLENWERTE",
+			nagios.MessageDetailedInfoLabel: "",
+		}),
+	)
+
+	plugin.SetDetailedInfoLabel("DETAILS")
+
+	if err := plugin.SetCriticalThreshold("90"); err != nil {
+		t.Fatalf("failed to set critical threshold: %v", err)
+	}
+
+	plugin.Critical("Festplatte voll").WithDetail("/var: 95%")
+	plugin.AddError(errors.New("Schreibfehler"))
+	plugin.EnableDiagnostics()
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"**FEHLER**",
+		"**SCHWELLENWERTE**",
+		"**DETAILS**",
+		"**DIAGNOSTICS**",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestMessageCatalogLocalizesPanicSummary asserts that the panic summary
+// phrase is localized using the message catalog.
+func TestMessageCatalogLocalizesPanicSummary(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithMessageCatalog(nagios.MessageCatalogMap{
+			nagios.MessagePanicSummary: "Plugin abgestürzt",
+		}),
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer plugin.ReturnCheckResults()
+
+		panic("nil map")
+	}()
+
+	<-done
+
+	if want, got := "CRITICAL: Plugin abgestürzt", outputBuffer.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("want output with prefix %q, got:\n%s", want, got)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Well-known graph hint keys for MetricMetadata. Downstream processors may
+// support additional keys.
+const (
+	// GraphHintType indicates how the metric should be graphed (e.g.,
+	// "gauge" or "counter"). OpenMetrics output uses this value as the
+	// metric type.
+	GraphHintType string = "type"
+
+	// GraphHintColor indicates the preferred color for the metric (e.g.,
+	// "#ff0000").
+	GraphHintColor string = "color"
+
+	// GraphHintStacked indicates whether the metric should be stacked with
+	// other metrics ("true" or "false").
+	GraphHintStacked string = "stacked"
+)
+
+// OpenMetrics metric types used when encoding performance data.
+const (
+	openMetricsTypeGauge   string = "gauge"
+	openMetricsTypeCounter string = "counter"
+
+	// openMetricsCounterUOM is the performance data UOM used to indicate a
+	// continuous counter.
+	openMetricsCounterUOM string = "c"
+
+	// openMetricsUnknownValue is the performance data value used to indicate
+	// that the actual value couldn't be determined.
+	openMetricsUnknownValue string = "U"
+)
+
+// MetricMetadata is optional information about a performance data metric
+// intended for downstream processors. Metadata is not included in the
+// classic performance data format emitted to Nagios.
+type MetricMetadata struct {
+	// DisplayName is a human friendly name for the metric.
+	DisplayName string `json:"display_name,omitempty"`
+
+	// Description explains what the metric measures.
+	Description string `json:"description,omitempty"`
+
+	// GraphHints is a collection of key/value hints for graphing the metric.
+	// See GraphHintType and related constants for well-known keys.
+	GraphHints map[string]string `json:"graph_hints,omitempty"`
+}
+
+// WithMetadata returns a copy of the PerformanceData value with the given
+// metadata attached.
+func (pd PerformanceData) WithMetadata(metadata MetricMetadata) PerformanceData {
+	pd.Metadata = &metadata
+	return pd
+}
+
+// PerfData returns a copy of the performance data metrics sorted by label.
+func (p Plugin) PerfData() []PerformanceData {
+	return p.getSortedPerfData()
+}
+
+// EncodePerfDataJSON writes the given performance data metrics (including
+// any metadata) to w as a JSON array.
+func EncodePerfDataJSON(w io.Writer, perfData []PerformanceData) error {
+	if perfData == nil {
+		perfData = []PerformanceData{}
+	}
+
+	return json.NewEncoder(w).Encode(perfData)
+}
+
+// EncodeOpenMetrics writes the given performance data metrics to w in the
+// OpenMetrics text format. Metric names are derived from the performance
+// data label with unsupported characters replaced by underscores. Metadata
+// descriptions are emitted as HELP text and the GraphHintType hint (or a UOM
+// of "c") selects the metric type. Metrics with an unknown ("U") value are
+// omitted.
+func EncodeOpenMetrics(w io.Writer, perfData []PerformanceData) error {
+	var sb strings.Builder
+
+	for _, pd := range perfData {
+		if pd.Value == openMetricsUnknownValue {
+			continue
+		}
+
+		name := openMetricsName(pd.Label)
+		metricType := openMetricsTypeGauge
+		sampleName := name
+
+		if pd.UnitOfMeasurement == openMetricsCounterUOM {
+			metricType = openMetricsTypeCounter
+		}
+
+		if pd.Metadata != nil {
+			if hint, ok := pd.Metadata.GraphHints[GraphHintType]; ok && hint != "" {
+				metricType = hint
+			}
+		}
+
+		if metricType == openMetricsTypeCounter {
+			sampleName = name + "_total"
+		}
+
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", name, metricType)
+
+		if pd.Metadata != nil && pd.Metadata.Description != "" {
+			fmt.Fprintf(&sb, "# HELP %s %s\n", name, openMetricsEscape(pd.Metadata.Description))
+		}
+
+		fmt.Fprintf(&sb, "%s %s\n", sampleName, pd.Value)
+	}
+
+	sb.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// openMetricsName converts a performance data label into a valid
+// OpenMetrics metric name.
+func openMetricsName(label string) string {
+	name := []rune(label)
+
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			name[i] = '_'
+		}
+	}
+
+	return string(name)
+}
+
+// openMetricsEscape escapes HELP text as required by the OpenMetrics text
+// format.
+func openMetricsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestMetricMetadataExcludedFromClassicFormat asserts that metadata does
+// not alter the classic performance data format.
+func TestMetricMetadataExcludedFromClassicFormat(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{
+		Label:             "rtt",
+		Value:             "12",
+		UnitOfMeasurement: "ms",
+	}
+
+	withMetadata := pd.WithMetadata(nagios.MetricMetadata{
+		DisplayName: "Round trip time",
+		Description: "Round trip time to the target",
+	})
+
+	if d := cmp.Diff(pd.String(), withMetadata.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestEncodePerfDataJSONIncludesMetadata asserts that metadata is included
+// in JSON encoded performance data.
+func TestEncodePerfDataJSONIncludesMetadata(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		nagios.PerformanceData{
+			Label: "rtt",
+			Value: "12",
+		}.WithMetadata(nagios.MetricMetadata{
+			DisplayName: "Round trip time",
+			GraphHints:  map[string]string{nagios.GraphHintColor: "#00ff00"},
+		}),
+	}
+
+	var sb strings.Builder
+	if err := nagios.EncodePerfDataJSON(&sb, perfData); err != nil {
+		t.Fatalf("failed to encode perfdata: %v", err)
+	}
+
+	want := `[{"label":"rtt","value":"12","metadata":{"display_name":"Round trip time","graph_hints":{"color":"#00ff00"}}}]` + "\n"
+
+	if d := cmp.Diff(want, sb.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestEncodeOpenMetricsUsesMetadata asserts that descriptions and graph type
+// hints are used when encoding OpenMetrics output.
+func TestEncodeOpenMetricsUsesMetadata(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		nagios.PerformanceData{
+			Label: "open connections",
+			Value: "42",
+		}.WithMetadata(nagios.MetricMetadata{
+			Description: "Number of open connections",
+		}),
+		{
+			Label:             "bytes_sent",
+			Value:             "1024",
+			UnitOfMeasurement: "c",
+		},
+		{
+			Label: "unknown",
+			Value: "U",
+		},
+	}
+
+	var sb strings.Builder
+	if err := nagios.EncodeOpenMetrics(&sb, perfData); err != nil {
+		t.Fatalf("failed to encode perfdata: %v", err)
+	}
+
+	want := "# TYPE open_connections gauge\n" +
+		"# HELP open_connections Number of open connections\n" +
+		"open_connections 42\n" +
+		"# TYPE bytes_sent counter\n" +
+		"bytes_sent_total 1024\n" +
+		"# EOF\n"
+
+	if d := cmp.Diff(want, sb.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
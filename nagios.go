@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"runtime/debug"
 	"strings"
@@ -54,6 +55,9 @@ const (
 // newlines in Nagios XI output (see GH-109). Using a UNIX EOL with a single
 // leading space appears to give the intended results for both Nagios Core and
 // Nagios XI.
+//
+// This is the default EOL; see Plugin.SetOutputProfile and Plugin.SetEOL to
+// select a different EOL for a specific plugin instance.
 const CheckOutputEOL string = " \n"
 
 // Default header text for various sections of the output if not overridden.
@@ -63,6 +67,10 @@ const (
 	defaultDetailedInfoLabel string = "DETAILED INFO"
 )
 
+// panicSummary is the one-line summary (following the CRITICAL state label)
+// used when a panic is detected.
+const panicSummary string = "plugin crash detected. See details via web UI or run plugin manually via CLI."
+
 // Default performance data metrics emitted if not specified by client code.
 const (
 	defaultTimeMetricLabel             string = "time"
@@ -91,6 +99,23 @@ var (
 	// ErrNoPerformanceDataProvided indicates that client code did not provide
 	// the expected PerformanceData value(s).
 	ErrNoPerformanceDataProvided = errors.New("no performance data provided")
+
+	// ErrPerformanceDataUOMMismatch indicates that client code provided a
+	// PerformanceData value where the Value, Min or Max fields use a unit of
+	// measurement other than the one specified by the UnitOfMeasurement
+	// field.
+	ErrPerformanceDataUOMMismatch = errors.New("provided performance data uses inconsistent unit of measurement")
+
+	// ErrPerformanceDataThresholdUOMMismatch indicates that client code
+	// provided a PerformanceData value where the Warn and Crit fields use
+	// different units of measurement or a unit of measurement other than the
+	// one specified by the UnitOfMeasurement field.
+	ErrPerformanceDataThresholdUOMMismatch = errors.New("provided performance data thresholds use inconsistent unit of measurement")
+
+	// ErrPerformanceDataInvalidLabel indicates that client code provided a
+	// PerformanceData value where the label contains characters (equals
+	// signs or single quotes) which break parsing of performance data.
+	ErrPerformanceDataInvalidLabel = errors.New("provided performance data label contains invalid characters")
 )
 
 // ServiceState represents the status label and exit code for a service check.
@@ -123,14 +148,14 @@ type PerformanceData struct {
 	// documentation) is to use underscores for separating multiple words. For
 	// example, 'percent_packet_loss' instead of 'percent packet loss',
 	// 'percentPacketLoss' or 'percent-packet-loss.
-	Label string
+	Label string `json:"label"`
 
 	// Value is the data point associated with the performance data label.
 	//
 	// Value is in class [-0-9.] and must be the same UOM as Min and Max UOM.
 	// Value may be a literal "U" instead, this would indicate that the actual
 	// value couldn't be determined.
-	Value string
+	Value string `json:"value"`
 
 	// UnitOfMeasurement is an optional unit of measurement (UOM). If
 	// provided, consists of a string of zero or more characters. Numbers,
@@ -144,27 +169,34 @@ type PerformanceData struct {
 	// 3) % - percentage
 	// 4) B - bytes (also KB, MB, TB)
 	// 5) c - a continuous counter (such as bytes transmitted on an interface)
-	UnitOfMeasurement string
+	UnitOfMeasurement string `json:"uom,omitempty"`
 
 	// Warn is in the range format (see the Section called Threshold and
 	// Ranges). Must be the same UOM as Crit. An empty string is permitted.
 	//
 	// https://nagios-plugins.org/doc/guidelines.html#THRESHOLDFORMAT
-	Warn string
+	Warn string `json:"warn,omitempty"`
 
 	// Crit is in the range format (see the Section called Threshold and
 	// Ranges). Must be the same UOM as Warn. An empty string is permitted.
 	//
 	// https://nagios-plugins.org/doc/guidelines.html#THRESHOLDFORMAT
-	Crit string
+	Crit string `json:"crit,omitempty"`
 
 	// Min is in class [-0-9.] and must be the same UOM as Value and Max. Min
 	// is not required if UOM=%. An empty string is permitted.
-	Min string
+	Min string `json:"min,omitempty"`
 
 	// Max is in class [-0-9.] and must be the same UOM as Value and Min. Max
 	// is not required if UOM=%. An empty string is permitted.
-	Max string
+	Max string `json:"max,omitempty"`
+
+	// Metadata is optional information about the metric intended for
+	// downstream processors (e.g., display name, description and graph
+	// hints). Metadata is not included in the classic performance data
+	// format emitted to Nagios but is included by the JSON and OpenMetrics
+	// encoders.
+	Metadata *MetricMetadata `json:"metadata,omitempty"`
 }
 
 // Validate performs basic validation of PerformanceData. An error is returned
@@ -181,7 +213,11 @@ func (pd PerformanceData) Validate() error {
 	// TODO: Expand validation
 	// https://nagios-plugins.org/doc/guidelines.html
 	default:
-		return nil
+		if err := pd.validateLabel(); err != nil {
+			return err
+		}
+
+		return pd.validateUOM()
 
 	}
 }
@@ -240,6 +276,10 @@ type Plugin struct {
 	// in LongServiceOutput as a list when ending the service check.
 	Errors []error
 
+	// stateDrivenBy is the recorded StateError (if any) responsible for the
+	// final plugin state.
+	stateDrivenBy *StateError
+
 	// ExitStatusCode is the exit or exit status code provided to the Nagios
 	// instance that calls this service check. These status codes indicate to
 	// Nagios "state" the service is considered to be in. The most common
@@ -268,6 +308,18 @@ type Plugin struct {
 	// is used for display purposes.
 	CriticalThreshold string
 
+	// WarningRange is an optional typed threshold range used to determine
+	// when the service check has crossed into a WARNING state. If
+	// WarningThreshold is not set, this value is also used for display
+	// purposes. See also EvaluateThresholds.
+	WarningRange *Range
+
+	// CriticalRange is an optional typed threshold range used to determine
+	// when the service check has crossed into a CRITICAL state. If
+	// CriticalThreshold is not set, this value is also used for display
+	// purposes. See also EvaluateThresholds.
+	CriticalRange *Range
+
 	// thresholdLabel is an optional custom label used in place of the
 	// standard text prior to a list of threshold values.
 	thresholdsLabel string
@@ -300,21 +352,283 @@ type Plugin struct {
 	// termination to emit branding details at the end of the notification.
 	// See also ExitCallBackFunc.
 	BrandingCallback ExitCallBackFunc
+
+	// logger is the optional logger used for diagnostics. See also
+	// SetLogger.
+	logger *slog.Logger
+
+	// outputTrace holds the optional settings for recording emitted plugin
+	// output. See also SetOutputTrace.
+	outputTrace *outputTrace
+
+	// tracer is the optional tracer used to record spans for the plugin run.
+	// See also SetTracer.
+	tracer Tracer
+
+	// rootSpan is the span covering the full plugin run if tracing is
+	// enabled.
+	rootSpan Span
+
+	// runtimeMetricLabel is an optional custom label used in place of the
+	// default label for the plugin runtime performance data metric.
+	runtimeMetricLabel string
+
+	// diagnosticsEnabled indicates whether client code has opted to emit
+	// the diagnostics section. See also EnableDiagnostics.
+	diagnosticsEnabled bool
+
+	// diagnostics is the collection of entries recorded for display in the
+	// diagnostics section.
+	diagnostics []diagnostic
+
+	// emitHooks is the collection of functions called with the final plugin
+	// output after it has been emitted.
+	emitHooks []emitHook
+
+	// stateStore is the optional store used to persist plugin state between
+	// runs. See also SetStateStore.
+	stateStore StateStore
+
+	// showLastStateChange indicates whether client code has opted to
+	// include a summary of the last state change in LongServiceOutput.
+	showLastStateChange bool
+
+	// foldServiceOutput indicates whether client code has opted to fold
+	// embedded newlines in ServiceOutput into spaces.
+	foldServiceOutput bool
+
+	// pipeReplacement is the optional text substituted for pipe characters
+	// in ServiceOutput and LongServiceOutput. See also SetPipeReplacement.
+	pipeReplacement string
+
+	// strictMode indicates whether client code has opted to convert
+	// validation failures into an UNKNOWN result. See also
+	// EnableStrictMode.
+	strictMode bool
+
+	// maxOutputSize is the optional maximum size in bytes of plugin output
+	// enforced in strict mode. See also SetMaxOutputSize.
+	maxOutputSize int
+
+	// asciiMode controls how non-ASCII characters in plugin output are
+	// handled. See also SetASCIIOutput.
+	asciiMode ASCIIMode
+
+	// outputProfile identifies the system which displays plugin output. See
+	// also SetOutputProfile.
+	outputProfile OutputProfile
+
+	// eol is the optional EOL override for plugin output. See also SetEOL.
+	eol string
+
+	// sanitizePerfDataLabels indicates whether client code has opted to
+	// sanitize performance data labels as they are added. See also
+	// SanitizePerfDataLabels.
+	sanitizePerfDataLabels bool
+
+	// historyStore optionally retains the result of each plugin run. See
+	// also SetHistoryStore.
+	historyStore HistoryStore
+
+	// showPerfDataTrends indicates whether client code has opted to annotate
+	// performance data values with the trend since the last run. See also
+	// ShowPerfDataTrends.
+	showPerfDataTrends bool
+
+	// emitPerfDataDeltas indicates whether client code has opted to emit
+	// delta performance data metrics. See also EmitPerfDataDeltas.
+	emitPerfDataDeltas bool
+
+	// movingAverageLabel is the label of the performance data metric whose
+	// values are persisted for moving average threshold evaluation. See also
+	// SetMovingAverage.
+	movingAverageLabel string
+
+	// movingAverageSamples is the number of samples used for moving average
+	// threshold evaluation.
+	movingAverageSamples int
+
+	// thresholdWindows is the collection of thresholds which apply during
+	// specific schedules. See also AddThresholdWindow.
+	thresholdWindows []thresholdWindow
+
+	// warningRecoveryRange is the optional threshold range used in place of
+	// the warning threshold range while the previous run was WARNING or
+	// CRITICAL. See also SetRecoveryThresholds.
+	warningRecoveryRange *Range
+
+	// criticalRecoveryRange is the optional threshold range used in place of
+	// the critical threshold range while the previous run was CRITICAL.
+	criticalRecoveryRange *Range
+
+	// downtimeChecker is optionally consulted for active scheduled downtime.
+	// See also SetDowntimeChecker.
+	downtimeChecker DowntimeChecker
+
+	// downtimeDowngradeState is the optional state that more severe states
+	// are replaced with while in scheduled downtime. See also
+	// DowngradeInDowntime.
+	downtimeDowngradeState *int
+
+	// acknowledgementSource is optionally consulted for an existing
+	// acknowledgement. See also SetAcknowledgementSource.
+	acknowledgementSource AcknowledgementSource
+
+	// checkTimeoutState is the optional state reported for sub-checks which
+	// time out. See also SetCheckTimeoutState.
+	checkTimeoutState *int
+
+	// checkRateLimit is the optional maximum number of sub-checks started
+	// per second by RunChecks. See also SetCheckRateLimit.
+	checkRateLimit float64
+
+	// checkRateBurst is the maximum number of sub-checks started at once
+	// when the sub-check rate limit is enabled.
+	checkRateBurst int
+
+	// deadline is the optional time at which the overall plugin timeout
+	// expires. See also SetTimeout.
+	deadline time.Time
+
+	// partialResultsState is the optional minimum state reported when
+	// RunChecks reports partial results. See also EnablePartialResults.
+	partialResultsState *int
+
+	// failFast indicates whether RunChecks cancels outstanding sub-checks
+	// once a sub-check reports CRITICAL. See also EnableFailFast.
+	failFast bool
+
+	// progress is the optional running Progress reporter. See also
+	// StartProgress.
+	progress *Progress
+
+	// thresholdBreaches is the collection of threshold breaches recorded
+	// during evaluation. See also ThresholdBreaches.
+	thresholdBreaches []ThresholdBreach
+
+	// warningThresholdOrigin and criticalThresholdOrigin are the optional
+	// origins of the warning and critical thresholds displayed in the
+	// THRESHOLDS section. See also SetDefaultThresholds.
+	warningThresholdOrigin  string
+	criticalThresholdOrigin string
+
+	// warningThresholdOverridden and criticalThresholdOverridden indicate
+	// whether the warning and critical thresholds were set by the user and
+	// should not be replaced by default thresholds.
+	warningThresholdOverridden  bool
+	criticalThresholdOverridden bool
+
+	// warningRangeSet and criticalRangeSet are the optional lists of
+	// warning and critical threshold ranges. See also SetWarningRangeSet.
+	warningRangeSet  RangeSet
+	criticalRangeSet RangeSet
+
+	// markdown is the optional override of whether Markdown formatting is
+	// used for plugin output. See also SetMarkdown.
+	markdown *bool
+
+	// links is the collection of hyperlinks attached to the plugin result.
+	// See also AddLink.
+	links []Link
+
+	// spillDir is the optional directory where the complete output is
+	// written if oversize output is truncated. See also SetOutputSpill.
+	spillDir string
+
+	// spillRetention is the number of spill files retained.
+	spillRetention int
+
+	// verbosity is the verbosity level requested by the user. See also
+	// SetVerbosity.
+	verbosity int
+
+	// environmentEnabled indicates whether the environment section is
+	// emitted (at VerbosityDiagnostic). See also EnableEnvironmentSection.
+	environmentEnabled bool
+
+	// pluginVersion is the plugin version listed in the environment
+	// section.
+	pluginVersion string
+
+	// footerEnabled indicates whether the footer line is emitted. See also
+	// EnableFooter.
+	footerEnabled bool
+
+	// hostContextEnabled indicates whether the host context section is
+	// emitted. See also EnableHostContext.
+	hostContextEnabled bool
+
+	// messageCatalog is the optional catalog used to localize built-in
+	// labels and phrases. See also SetMessageCatalog.
+	messageCatalog MessageCatalog
+
+	// codePage is the optional console code page used to encode plugin
+	// output. See also SetCodePage.
+	codePage CodePage
+
+	// stateRemapping is the optional mapping applied to the final plugin
+	// state. See also SetStateRemapping.
+	stateRemapping StateMapping
+
+	// environmentAllowlist is the optional allowlist of environment
+	// variables listed in the diagnostics section. See also
+	// EnableEnvironmentCapture.
+	environmentAllowlist []string
+
+	// summaryJoin is the optional function used by Merge to combine
+	// one-line summaries. See also SetSummaryJoin.
+	summaryJoin SummaryJoinFunc
+
+	// sinks is the optional collection of destinations the final result is
+	// emitted to instead of the output target. See also SetSinks.
+	sinks []Sink
+
+	// beforeEmitHooks is the collection of functions called just before
+	// plugin output is rendered. See also AddBeforeEmitHook.
+	beforeEmitHooks []BeforeEmitHook
+
+	// afterEmitHooks is the collection of functions called once plugin
+	// output has been emitted. See also AddAfterEmitHook.
+	afterEmitHooks []AfterEmitHook
+
+	// outputFilters is the collection of filters applied to rendered plugin
+	// output before it is emitted. See also AddOutputFilter.
+	outputFilters []OutputFilter
 }
 
 // NewPlugin constructs a new Plugin value in the same way that client code
 // has been using this library. We also record a default time performance data
 // metric. This default metric is ignored if supplied by client code.
-func NewPlugin() *Plugin {
+//
+// Optional settings may be provided using functional options (e.g.,
+// WithOutputTarget). Options are applied in the order given.
+func NewPlugin(options ...Option) *Plugin {
 	es := Plugin{
 		start:          time.Now(),
 		LastError:      nil,
 		ExitStatusCode: StateOKExitCode,
 	}
 
+	for _, option := range options {
+		option(&es)
+	}
+
 	return &es
 }
 
+// ExitState is the previous name of the Plugin type. It is retained as an
+// alias so that existing client code continues to compile.
+//
+// Deprecated: Use Plugin instead.
+type ExitState = Plugin
+
+// New constructs a new ExitState value.
+//
+// Deprecated: Use NewPlugin instead.
+func New() *ExitState {
+	return NewPlugin()
+}
+
 // ReturnCheckResults is intended to provide a reliable way to return a
 // desired exit code from applications used as Nagios plugins. In most cases,
 // this method should be registered as the first deferred function in client
@@ -365,8 +679,9 @@ func (p *Plugin) ReturnCheckResults() {
 		p.AddError(fmt.Errorf("%w: %s", ErrPanicDetected, err))
 
 		p.ServiceOutput = fmt.Sprintf(
-			"%s: plugin crash detected. See details via web UI or run plugin manually via CLI.",
+			"%s: %s",
 			StateCRITICALLabel,
+			p.message(MessagePanicSummary, panicSummary),
 		)
 
 		// Gather stack trace associated with panic.
@@ -375,41 +690,126 @@ func (p *Plugin) ReturnCheckResults() {
 		// Wrap stack trace details in an attempt to prevent these details
 		// from being interpreted as formatting characters when passed through
 		// web UI, text, email, Teams, etc. We use Markdown fenced code blocks
-		// instead of `<pre>` start/end tags because Nagios strips out angle
-		// brackets (due to default `illegal_macro_output_chars` settings).
-		p.LongServiceOutput = fmt.Sprintf(
-			"```%s%s%s%s%s%s```",
-			CheckOutputEOL,
-			err,
-			CheckOutputEOL,
-			CheckOutputEOL,
-			stackTrace,
-			CheckOutputEOL,
-		)
+		// (if enabled, see SetMarkdown) instead of `<pre>` start/end tags
+		// because Nagios strips out angle brackets (due to default
+		// `illegal_macro_output_chars` settings).
+		p.LongServiceOutput = p.codeBlock(fmt.Sprint(err), "", string(stackTrace))
 
 		p.ExitStatusCode = StateCRITICALExitCode
 
+		p.Logger().Error("plugin panic detected", "panic", err)
+
 	}
 
-	p.handleServiceOutputSection(&output)
+	// Escalate the final plugin state if recorded errors call for a more
+	// severe state than the one set by client code.
+	p.applyErrorStates()
 
-	p.handleErrorsSection(&output)
+	// Translate invalid exit codes (e.g., an errno or HTTP status code
+	// accidentally assigned by client code or carried by a recorded
+	// StateError) into UNKNOWN.
+	p.sanitizeExitCode()
 
-	p.handleThresholdsSection(&output)
+	// Apply requested fixes to (and validate) output before it is emitted.
+	p.validateOutput()
 
-	p.handleLongServiceOutput(&output)
+	// Annotate (and optionally downgrade) the results if the host or service
+	// is in scheduled downtime. This follows validation so that a downgraded
+	// state is not reported as mismatching the summary state label.
+	p.applyDowntime()
 
-	// If set, call user-provided branding function before emitting
-	// performance data and exiting application.
-	if p.BrandingCallback != nil {
-		fmt.Fprintf(&output, "%s%s%s", CheckOutputEOL, p.BrandingCallback(), CheckOutputEOL)
+	// Remap the final state as requested by the operator (e.g., UNKNOWN as
+	// CRITICAL), noting the original state in the one-line summary.
+	p.applyStateRemapping()
+
+	// Include existing acknowledgement details for non-OK results if
+	// requested.
+	p.applyAcknowledgement()
+
+	// Allow registered hooks to inspect and modify the result before it is
+	// recorded and rendered.
+	p.runBeforeEmitHooks()
+
+	// Capture the collected performance data values before output is
+	// rendered (and possibly replaced by strict mode).
+	values := p.perfDataValues()
+
+	// Note the last state change and trends from the previous run if
+	// persistence is enabled.
+	p.annotateStateHistory(values)
+
+	p.renderOutput(&output)
+
+	// Truncate oversize output, retaining the complete output in a spill
+	// file, if requested.
+	p.truncateOutput(&output)
+
+	// Replace oversize output with an explanation if strict mode is enabled.
+	if p.enforceOutputSize(output.Len()) {
+		output.Reset()
+		p.renderOutput(&output)
 	}
 
-	p.handlePerformanceData(&output)
+	// Record the emitted state (and any state transition) if persistence
+	// is enabled. This follows strict mode size enforcement so that the
+	// persisted state matches the exit code.
+	p.updateStateHistory(values)
+
+	// Retain the result of this run if a history store is enabled.
+	p.appendHistory(values)
+
+	// Apply any registered output filters (e.g., redaction).
+	p.filterOutput(&output)
+
+	p.Logger().Debug(
+		"emitting check results",
+		"state", stateLabel(p.ExitStatusCode),
+		"exit_code", p.ExitStatusCode,
+		"errors", len(p.Errors),
+		"perfdata", len(p.perfData),
+		"bytes", output.Len(),
+	)
+
+	// Encode output using the console code page expected by the agent
+	// launching the plugin, if requested.
+	encoded := p.encodeOutput(output.String())
+
+	// Apply output filters to the result shared by every other destination
+	// so that redacted values do not leak through sinks, hooks, syslog,
+	// journald or tracing.
+	result := p.filteredResult()
 
 	// Emit all collected plugin output using user-specified or fallback
-	// output target.
-	p.emitOutput(output.String())
+	// output target, or the final result to the configured sinks. A failed
+	// write (e.g., the reader closed the pipe early) is reported instead of
+	// silently discarding the results.
+	var emitErr error
+	wroteOutput := true
+
+	switch {
+	case len(p.sinks) > 0:
+		wroteOutput, emitErr = p.emitToSinks(result, encoded)
+	default:
+		emitErr = p.emitOutput(encoded)
+	}
+
+	if emitErr != nil {
+		p.reportEmitFailure(emitErr)
+	}
+
+	// Record a byte-exact copy of the emitted output if requested. Nothing
+	// is recorded if plugin output was not written (e.g., results were
+	// only submitted to passive sinks).
+	if wroteOutput {
+		p.traceOutput(encoded, emitErr)
+	}
+
+	// Mirror the final results to any enabled destinations (e.g., syslog).
+	p.runEmitHooks(result, output.String())
+
+	// Record final results on the root span (if tracing is enabled) before
+	// the plugin exits.
+	p.endRootSpan(result)
 
 	// TODO: Should we offer an option to redirect the log message to stderr
 	// to another error output sink?
@@ -423,6 +823,41 @@ func (p *Plugin) ReturnCheckResults() {
 	}
 }
 
+// renderOutput writes all output sections, branding details and performance
+// data to w, applying any requested character transformations and EOL
+// translation.
+func (p *Plugin) renderOutput(w io.Writer) {
+	var output strings.Builder
+
+	p.handleServiceOutputSection(&output)
+
+	p.handleErrorsSection(&output)
+
+	p.handleThresholdsSection(&output)
+
+	p.handleLongServiceOutput(&output)
+
+	p.handleLinksSection(&output)
+
+	p.handleDiagnosticsSection(&output)
+
+	p.handleEnvironmentSection(&output)
+
+	p.handleHostContextSection(&output)
+
+	// If set, call user-provided branding function before emitting
+	// performance data and exiting application.
+	if p.BrandingCallback != nil {
+		fmt.Fprintf(&output, "%s%s%s", CheckOutputEOL, p.BrandingCallback(), CheckOutputEOL)
+	}
+
+	p.handleFooter(&output)
+
+	p.handlePerformanceData(&output)
+
+	fmt.Fprint(w, p.translateEOL(p.sanitizeOutput(output.String())))
+}
+
 // AddPerfData adds provided performance data to the collection overwriting
 // any previous performance data metrics using the same label.
 //
@@ -436,6 +871,15 @@ func (p *Plugin) AddPerfData(skipValidate bool, perfData ...PerformanceData) err
 		return ErrNoPerformanceDataProvided
 	}
 
+	if p.sanitizePerfDataLabels {
+		sanitized := make([]PerformanceData, len(perfData))
+		for i := range perfData {
+			sanitized[i] = perfData[i]
+			sanitized[i].Label = SanitizePerfDataLabel(perfData[i].Label)
+		}
+		perfData = sanitized
+	}
+
 	if !skipValidate {
 		for i := range perfData {
 			if err := perfData[i].Validate(); err != nil {
@@ -460,6 +904,46 @@ func (p *Plugin) AddError(err ...error) {
 	p.Errors = append(p.Errors, err...)
 }
 
+// SetSummary sets the one-line summary (ServiceOutput) for the plugin. The
+// summary is emitted as-is; no formatting is applied.
+func (p *Plugin) SetSummary(summary string) {
+	p.ServiceOutput = summary
+}
+
+// SetServiceOutputf formats according to a format specifier and sets the
+// result as the one-line summary (ServiceOutput) for the plugin.
+//
+// Formatting is applied once at call time; the resulting text is stored and
+// later emitted as a literal value. Any percent characters in the formatted
+// result (e.g., from a provided argument such as "95%") are not interpreted
+// as formatting verbs when the plugin output is emitted.
+func (p *Plugin) SetServiceOutputf(format string, a ...interface{}) {
+	p.ServiceOutput = fmt.Sprintf(format, a...)
+}
+
+// AddErrorf formats according to a format specifier and appends the
+// resulting error to the collection. As with fmt.Errorf, the %w verb may be
+// used to wrap another error.
+//
+// Formatting is applied once at call time; the error text is emitted as a
+// literal value.
+func (p *Plugin) AddErrorf(format string, a ...interface{}) {
+	p.AddError(fmt.Errorf(format, a...))
+}
+
+// SetLongServiceOutput sets the detailed output (LongServiceOutput) for the
+// plugin, replacing any existing content.
+func (p *Plugin) SetLongServiceOutput(output string) {
+	p.LongServiceOutput = output
+}
+
+// SetState sets the exit status code for the plugin. This value indicates
+// to Nagios what state the plugin has completed in (e.g., OK, WARNING, ...).
+// See the StateOKExitCode constant and related constants for valid values.
+func (p *Plugin) SetState(exitCode int) {
+	p.ExitStatusCode = exitCode
+}
+
 // SetOutputTarget assigns a target for Nagios plugin output. By default
 // output is emitted to os.Stdout.
 func (p *Plugin) SetOutputTarget(w io.Writer) {
@@ -471,6 +955,20 @@ func (p *Plugin) SetOutputTarget(w io.Writer) {
 	p.outputSink = w
 }
 
+// SetRuntimeMetric enables the plugin runtime performance data metric using
+// the given label (e.g., "runtime"). The metric value is the time elapsed
+// between the start of the plugin (construction via NewPlugin, or this call
+// for Plugin values constructed manually) and the emission of check
+// results, in milliseconds. A metric with the same label recorded by client
+// code takes precedence.
+func (p *Plugin) SetRuntimeMetric(label string) {
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+
+	p.runtimeMetricLabel = label
+}
+
 // SkipOSExit indicates that the os.Exit(x) step used to signal to Nagios what
 // state plugin execution has completed in (e.g., OK, WARNING, ...) should be
 // skipped. If skipped, a message is logged to os.Stderr in place of the
@@ -482,9 +980,9 @@ func (p *Plugin) SkipOSExit() {
 	p.shouldSkipOSExit = true
 }
 
-// emitOutput writes final plugin output to the previously set output target.
-// No further modifications to plugin output are performed.
-func (p Plugin) emitOutput(pluginOutput string) {
+// emitOutput writes final plugin output to the previously set output target
+// and flushes it. No further modifications to plugin output are performed.
+func (p Plugin) emitOutput(pluginOutput string) error {
 
 	// Emit all collected output using user-specified output target. Fall back
 	// to standard output if not set.
@@ -492,16 +990,20 @@ func (p Plugin) emitOutput(pluginOutput string) {
 		p.outputSink = os.Stdout
 	}
 
-	fmt.Fprint(p.outputSink, pluginOutput)
+	return writeOutput(p.outputSink, pluginOutput)
 }
 
 // tryAddDefaultTimeMetric inserts a default `time` performance data metric
 // into the collection IF client code has not already specified such a value
-// AND we have a non-zero start value to use.
+// AND we have a non-zero start value to use. If client code specified a
+// custom runtime metric label (see SetRuntimeMetric) that label is used
+// instead.
 func (p *Plugin) tryAddDefaultTimeMetric() {
 
+	label := p.getRuntimeMetricLabel()
+
 	// We already have an existing time metric, skip replacing it.
-	if _, hasTimeMetric := p.perfData[defaultTimeMetricLabel]; hasTimeMetric {
+	if _, hasTimeMetric := p.perfData[strings.ToLower(label)]; hasTimeMetric {
 		return
 	}
 
@@ -516,7 +1018,21 @@ func (p *Plugin) tryAddDefaultTimeMetric() {
 		p.perfData = make(map[string]PerformanceData)
 	}
 
-	p.perfData[defaultTimeMetricLabel] = defaultTimeMetric(p.start)
+	timeMetric := defaultTimeMetric(p.start)
+	timeMetric.Label = label
+
+	p.perfData[strings.ToLower(label)] = timeMetric
+}
+
+// getRuntimeMetricLabel retrieves the custom runtime metric label if set,
+// otherwise returns the default value.
+func (p Plugin) getRuntimeMetricLabel() string {
+	switch {
+	case p.runtimeMetricLabel != "":
+		return p.runtimeMetricLabel
+	default:
+		return defaultTimeMetricLabel
+	}
 }
 
 // defaultTimeMetric is a helper function that wraps the logic used to provide
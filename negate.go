@@ -0,0 +1,180 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StateMapping maps plugin exit codes to replacement exit codes. Exit codes
+// without an entry are left unchanged.
+type StateMapping map[int]int
+
+// NegateStateMapping returns the default mapping used by the negate utility
+// from the Monitoring Plugins project: OK and CRITICAL are swapped while
+// WARNING and UNKNOWN are left unchanged.
+func NegateStateMapping() StateMapping {
+	return StateMapping{
+		StateOKExitCode:       StateCRITICALExitCode,
+		StateCRITICALExitCode: StateOKExitCode,
+	}
+}
+
+// Apply returns the replacement for the given exit code or the original exit
+// code if there is no mapping for it.
+func (sm StateMapping) Apply(exitCode int) int {
+	if mapped, ok := sm[exitCode]; ok {
+		return mapped
+	}
+
+	return exitCode
+}
+
+// negateConfig holds the settings used by the Negate method.
+type negateConfig struct {
+	mapping          StateMapping
+	timeout          time.Duration
+	timeoutState     int
+	substituteLabels bool
+}
+
+// NegateOption is a functional option used to configure the Negate method.
+type NegateOption func(*negateConfig)
+
+// NegateWithMapping is a NegateOption used to replace the default
+// NegateStateMapping with a custom mapping (e.g., to map WARNING to OK).
+func NegateWithMapping(mapping StateMapping) NegateOption {
+	return func(nc *negateConfig) {
+		nc.mapping = mapping
+	}
+}
+
+// NegateWithTimeout is a NegateOption used to limit how long the inner check
+// may run. If the timeout is reached, the given state is used as the final
+// plugin state (the state mapping is not applied to it).
+func NegateWithTimeout(timeout time.Duration, state int) NegateOption {
+	return func(nc *negateConfig) {
+		nc.timeout = timeout
+		nc.timeoutState = state
+	}
+}
+
+// NegateWithLabelSubstitution is a NegateOption used to replace the leading
+// state label in the inner check's one-line summary with the label of the
+// remapped state (e.g., "CRITICAL: port open" becomes "OK: port open").
+func NegateWithLabelSubstitution() NegateOption {
+	return func(nc *negateConfig) {
+		nc.substituteLabels = true
+	}
+}
+
+// Negate runs the given inner check and records its results with the state
+// remapped, allowing plugins to express conditions such as "alert when this
+// succeeds" without an external wrapper binary. By default OK and CRITICAL
+// states are swapped (see NegateStateMapping).
+//
+// The inner check records results using a separate Plugin value. Its
+// one-line summary, LongServiceOutput, errors, thresholds and performance
+// data are copied to the receiver once the inner check completes. The
+// receiver is returned to allow chaining further calls.
+//
+//	plugin.Negate(func(inner *nagios.Plugin) {
+//		checkTelnetDisabled(inner)
+//	}, nagios.NegateWithTimeout(10*time.Second, nagios.StateUNKNOWNExitCode))
+func (p *Plugin) Negate(check func(inner *Plugin), options ...NegateOption) *Plugin {
+	cfg := negateConfig{
+		mapping:      NegateStateMapping(),
+		timeoutState: StateCRITICALExitCode,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	inner := &Plugin{}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				inner.ExitStatusCode = StateUNKNOWNExitCode
+				inner.AddError(fmt.Errorf("inner check panicked: %v", r))
+			}
+		}()
+
+		check(inner)
+	}()
+
+	if cfg.timeout > 0 {
+		timer := time.NewTimer(cfg.timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+			p.ExitStatusCode = cfg.timeoutState
+			p.ServiceOutput = fmt.Sprintf(
+				"%s: inner check timed out after %v",
+				stateLabel(cfg.timeoutState),
+				cfg.timeout,
+			)
+
+			return p
+		}
+	} else {
+		<-done
+	}
+
+	originalState := inner.ExitStatusCode
+	p.ExitStatusCode = cfg.mapping.Apply(originalState)
+
+	p.ServiceOutput = inner.ServiceOutput
+	if cfg.substituteLabels {
+		p.ServiceOutput = substituteStateLabel(
+			inner.ServiceOutput,
+			stateLabel(originalState),
+			stateLabel(p.ExitStatusCode),
+		)
+	}
+
+	if inner.LongServiceOutput != "" {
+		p.WithDetail(inner.LongServiceOutput)
+	}
+
+	p.AddError(inner.Errors...)
+
+	if inner.WarningThreshold != "" || inner.WarningRange != nil {
+		p.WarningThreshold = inner.WarningThreshold
+		p.WarningRange = inner.WarningRange
+	}
+
+	if inner.CriticalThreshold != "" || inner.CriticalRange != nil {
+		p.CriticalThreshold = inner.CriticalThreshold
+		p.CriticalRange = inner.CriticalRange
+	}
+
+	if len(inner.perfData) > 0 {
+		// Metrics were validated when recorded by the inner check.
+		_ = p.AddPerfData(true, inner.getSortedPerfData()...)
+	}
+
+	return p
+}
+
+// substituteStateLabel replaces the given leading state label in the
+// one-line summary with the replacement label.
+func substituteStateLabel(summary string, label string, replacement string) string {
+	if label == replacement || !strings.HasPrefix(summary, label) {
+		return summary
+	}
+
+	return replacement + strings.TrimPrefix(summary, label)
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestNegateSwapsOKAndCritical asserts that the default mapping swaps OK
+// and CRITICAL states, substitutes the summary label when requested and
+// copies the inner check results.
+func TestNegateSwapsOKAndCritical(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Negate(func(inner *nagios.Plugin) {
+		inner.Critical("telnet port 23 open").
+			WithPerfData(nagios.PerformanceData{Label: "open_ports", Value: "1"})
+	}, nagios.NegateWithLabelSubstitution())
+
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateOKExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateOKExitCode, plugin.ExitStatusCode)
+	}
+
+	want := "OK: telnet port 23 open | 'open_ports'=1;;;;" + nagios.CheckOutputEOL
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestNegateCustomMappingAndTimeout asserts that custom mappings replace
+// the default mapping and that the timeout state is used when the inner
+// check does not complete in time.
+func TestNegateCustomMappingAndTimeout(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+	plugin.Negate(func(inner *nagios.Plugin) {
+		inner.Warning("disk usage high")
+	}, nagios.NegateWithMapping(nagios.StateMapping{
+		nagios.StateWARNINGExitCode: nagios.StateOKExitCode,
+	}))
+
+	if plugin.ExitStatusCode != nagios.StateOKExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateOKExitCode, plugin.ExitStatusCode)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+
+	plugin = nagios.Plugin{}
+	plugin.Negate(func(inner *nagios.Plugin) {
+		<-release
+	}, nagios.NegateWithTimeout(10*time.Millisecond, nagios.StateUNKNOWNExitCode))
+
+	if plugin.ExitStatusCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateUNKNOWNExitCode, plugin.ExitStatusCode)
+	}
+
+	want := "UNKNOWN: inner check timed out after 10ms"
+	if d := cmp.Diff(want, plugin.ServiceOutput); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package notify provides common types and helper functions for use by Nagios
+notification commands written in Go.
+
+# OVERVIEW
+
+Notification commands are run by Nagios (and similar monitoring systems) to
+inform contacts of host and service problems and recoveries. Details of the
+notification are made available as macros, either passed explicitly as
+command arguments or exported as NAGIOS_* environment variables. This package
+reads those macros, renders them using message templates and delivers the
+result using one of the built-in senders, allowing teams to write
+notification commands with the same library used for their plugins.
+
+# FEATURES
+
+  - Notification type populated from Nagios environment macros
+  - Message rendering using default or custom templates in plain text, HTML
+    or Markdown formats
+  - Sender interface with built-in SMTP and generic webhook (JSON over HTTP)
+    implementations; the webhook sender accepts an *http.Client so that the
+    TLS and proxy options from the transport package may be used
+
+See also:
+
+  - https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/4/en/notifications.html
+  - https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/4/en/macrolist.html
+*/
+package notify
@@ -0,0 +1,134 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package notify
+
+import (
+	"errors"
+	"os"
+)
+
+// Notification type values provided via the $NOTIFICATIONTYPE$ macro.
+const (
+	TypeProblem           string = "PROBLEM"
+	TypeRecovery          string = "RECOVERY"
+	TypeAcknowledgement   string = "ACKNOWLEDGEMENT"
+	TypeFlappingStart     string = "FLAPPINGSTART"
+	TypeFlappingStop      string = "FLAPPINGSTOP"
+	TypeFlappingDisabled  string = "FLAPPINGDISABLED"
+	TypeDowntimeStart     string = "DOWNTIMESTART"
+	TypeDowntimeEnd       string = "DOWNTIMEEND"
+	TypeDowntimeCancelled string = "DOWNTIMECANCELLED"
+	TypeCustom            string = "CUSTOM"
+)
+
+// ErrNotificationMacrosMissing indicates that the expected notification
+// environment macros were not found.
+var ErrNotificationMacrosMissing = errors.New("notification environment macros not found")
+
+// Notification represents the details of a host or service notification.
+// Fields are named after the Nagios macros used to populate them.
+type Notification struct {
+	// Type is the notification type (e.g., PROBLEM, RECOVERY).
+	Type string `json:"type"`
+
+	// HostName is the short name of the host ($HOSTNAME$).
+	HostName string `json:"host_name"`
+
+	// HostAlias is the long name or description of the host ($HOSTALIAS$).
+	HostAlias string `json:"host_alias,omitempty"`
+
+	// HostAddress is the address of the host ($HOSTADDRESS$).
+	HostAddress string `json:"host_address,omitempty"`
+
+	// ServiceDescription is the description of the service ($SERVICEDESC$).
+	// This is empty for host notifications.
+	ServiceDescription string `json:"service_description,omitempty"`
+
+	// State is the current host or service state (e.g., CRITICAL, DOWN).
+	State string `json:"state"`
+
+	// Output is the one-line summary from the most recent check.
+	Output string `json:"output"`
+
+	// LongOutput is the full (multi-line) output from the most recent check.
+	LongOutput string `json:"long_output,omitempty"`
+
+	// DateTime is the date and time of the notification ($LONGDATETIME$).
+	DateTime string `json:"date_time,omitempty"`
+
+	// Author is the author of an acknowledgement or custom notification
+	// ($NOTIFICATIONAUTHOR$).
+	Author string `json:"author,omitempty"`
+
+	// Comment is the comment provided with an acknowledgement or custom
+	// notification ($NOTIFICATIONCOMMENT$).
+	Comment string `json:"comment,omitempty"`
+
+	// ContactName is the short name of the contact being notified
+	// ($CONTACTNAME$).
+	ContactName string `json:"contact_name,omitempty"`
+
+	// ContactEmail is the email address of the contact being notified
+	// ($CONTACTEMAIL$).
+	ContactEmail string `json:"contact_email,omitempty"`
+}
+
+// FromEnv builds a Notification from the environment macros set by Nagios
+// when enable_environment_macros is enabled. Service macros are used if set,
+// otherwise host macros are used.
+func FromEnv() (Notification, error) {
+	n := Notification{
+		Type:         os.Getenv("NAGIOS_NOTIFICATIONTYPE"),
+		HostName:     os.Getenv("NAGIOS_HOSTNAME"),
+		HostAlias:    os.Getenv("NAGIOS_HOSTALIAS"),
+		HostAddress:  os.Getenv("NAGIOS_HOSTADDRESS"),
+		DateTime:     os.Getenv("NAGIOS_LONGDATETIME"),
+		Author:       os.Getenv("NAGIOS_NOTIFICATIONAUTHOR"),
+		Comment:      os.Getenv("NAGIOS_NOTIFICATIONCOMMENT"),
+		ContactName:  os.Getenv("NAGIOS_CONTACTNAME"),
+		ContactEmail: os.Getenv("NAGIOS_CONTACTEMAIL"),
+	}
+
+	switch {
+	case os.Getenv("NAGIOS_SERVICEDESC") != "":
+		n.ServiceDescription = os.Getenv("NAGIOS_SERVICEDESC")
+		n.State = os.Getenv("NAGIOS_SERVICESTATE")
+		n.Output = os.Getenv("NAGIOS_SERVICEOUTPUT")
+		n.LongOutput = os.Getenv("NAGIOS_LONGSERVICEOUTPUT")
+	default:
+		n.State = os.Getenv("NAGIOS_HOSTSTATE")
+		n.Output = os.Getenv("NAGIOS_HOSTOUTPUT")
+		n.LongOutput = os.Getenv("NAGIOS_LONGHOSTOUTPUT")
+	}
+
+	if n.Type == "" || n.HostName == "" {
+		return Notification{}, ErrNotificationMacrosMissing
+	}
+
+	return n, nil
+}
+
+// IsService indicates whether the notification is for a service (rather
+// than a host).
+func (n Notification) IsService() bool {
+	return n.ServiceDescription != ""
+}
+
+// Subject provides a short summary of the notification suitable for use as
+// an email subject line or chat message title.
+//
+//	** PROBLEM Service Alert: mail01/SMTP is CRITICAL **
+func (n Notification) Subject() string {
+	switch {
+	case n.IsService():
+		return "** " + n.Type + " Service Alert: " + n.HostName + "/" +
+			n.ServiceDescription + " is " + n.State + " **"
+	default:
+		return "** " + n.Type + " Host Alert: " + n.HostName + " is " + n.State + " **"
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package notify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios/notify"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testNotification() notify.Notification {
+	return notify.Notification{
+		Type:               notify.TypeProblem,
+		HostName:           "mail01",
+		HostAddress:        "192.0.2.10",
+		ServiceDescription: "SMTP",
+		State:              "CRITICAL",
+		Output:             "CRITICAL: connection refused <port 25>",
+	}
+}
+
+// TestFromEnv asserts that service notification macros are read from the
+// environment.
+func TestFromEnv(t *testing.T) {
+	t.Setenv("NAGIOS_NOTIFICATIONTYPE", notify.TypeProblem)
+	t.Setenv("NAGIOS_HOSTNAME", "mail01")
+	t.Setenv("NAGIOS_SERVICEDESC", "SMTP")
+	t.Setenv("NAGIOS_SERVICESTATE", "CRITICAL")
+	t.Setenv("NAGIOS_SERVICEOUTPUT", "CRITICAL: connection refused")
+	t.Setenv("NAGIOS_HOSTSTATE", "UP")
+
+	got, err := notify.FromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "** PROBLEM Service Alert: mail01/SMTP is CRITICAL **"
+	if d := cmp.Diff(want, got.Subject()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestRenderFormats asserts that each supported format renders the
+// notification details and that HTML output is escaped.
+func TestRenderFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := map[notify.Format]string{
+		notify.FormatText:     "Service: SMTP",
+		notify.FormatHTML:     "connection refused &lt;port 25&gt;",
+		notify.FormatMarkdown: "- **Service**: SMTP",
+	}
+
+	for format, want := range tests {
+		msg, err := notify.Render(testNotification(), format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("%s: want body containing %q, got %q", format, want, msg.Body)
+		}
+	}
+
+	if _, err := notify.Render(testNotification(), "pdf"); !errors.Is(err, notify.ErrUnsupportedFormat) {
+		t.Errorf("want error wrapping %v, got %v", notify.ErrUnsupportedFormat, err)
+	}
+}
+
+// TestWebhookSender asserts that messages are posted as JSON and that
+// non-2xx responses are reported as errors.
+func TestWebhookSender(t *testing.T) {
+	t.Parallel()
+
+	var got notify.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	msg, err := notify.Render(testNotification(), notify.FormatMarkdown)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sender := notify.WebhookSender{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}
+
+	if err := sender.Send(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := cmp.Diff(msg, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	sender.Headers = nil
+	if err := sender.Send(msg); !errors.Is(err, notify.ErrUnexpectedStatusCode) {
+		t.Errorf("want error wrapping %v, got %v", notify.ErrUnexpectedStatusCode, err)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// DefaultWebhookTimeout is the timeout applied to webhook requests if an
+// *http.Client is not provided by client code.
+const DefaultWebhookTimeout time.Duration = 30 * time.Second
+
+var (
+	// ErrMissingRecipients indicates that no recipients were specified for
+	// a message.
+	ErrMissingRecipients = errors.New("no recipients specified")
+
+	// ErrUnexpectedStatusCode indicates that a webhook endpoint responded
+	// with a non-2xx status code.
+	ErrUnexpectedStatusCode = errors.New("unexpected status code")
+)
+
+// Sender delivers a rendered notification message.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// SMTPSender delivers messages via email using an SMTP server.
+type SMTPSender struct {
+	// Addr is the address (host:port) of the SMTP server.
+	Addr string
+
+	// From is the sender email address.
+	From string
+
+	// To is the list of recipient email addresses. If empty, the contact
+	// email address from the notification is used.
+	To []string
+
+	// Auth is the optional SMTP authentication mechanism.
+	Auth smtp.Auth
+}
+
+// Send delivers the message to the configured recipients.
+func (s SMTPSender) Send(msg Message) error {
+	recipients := s.To
+	if len(recipients) == 0 && msg.Notification.ContactEmail != "" {
+		recipients = []string{msg.Notification.ContactEmail}
+	}
+
+	if len(recipients) == 0 {
+		return ErrMissingRecipients
+	}
+
+	contentType := "text/plain"
+	switch msg.Format {
+	case FormatHTML:
+		contentType = "text/html"
+	case FormatMarkdown:
+		contentType = "text/markdown"
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "From: %s\r\n", s.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprint(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: %s; charset=UTF-8\r\n", contentType)
+	fmt.Fprint(&buf, "\r\n")
+	fmt.Fprint(&buf, strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.Addr, s.Auth, s.From, recipients, []byte(buf.String())); err != nil {
+		return fmt.Errorf("failed to send notification email via %s: %w", s.Addr, err)
+	}
+
+	return nil
+}
+
+// WebhookSender delivers messages as a JSON encoded Message via HTTP POST
+// request to a generic webhook endpoint.
+type WebhookSender struct {
+	// URL is the webhook endpoint.
+	URL string
+
+	// Headers is an optional collection of additional request headers
+	// (e.g., authorization tokens).
+	Headers map[string]string
+
+	// Client is the optional HTTP client used to submit requests. A client
+	// with DefaultWebhookTimeout is used if not specified.
+	Client *http.Client
+}
+
+// Send delivers the message to the webhook endpoint.
+func (s WebhookSender) Send(msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to prepare webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s returned %s", ErrUnexpectedStatusCode, s.URL, resp.Status)
+	}
+
+	return nil
+}
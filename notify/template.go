@@ -0,0 +1,148 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package notify
+
+import (
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Format is the format of a rendered notification message.
+type Format string
+
+// Supported message formats.
+const (
+	FormatText     Format = "text"
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "markdown"
+)
+
+// Default message templates for each supported format. Templates are
+// executed with a Notification value.
+const (
+	DefaultTextTemplate string = `Notification Type: {{.Type}}
+{{if .IsService}}Service: {{.ServiceDescription}}
+{{end}}Host: {{.HostName}}{{if .HostAddress}} ({{.HostAddress}}){{end}}
+State: {{.State}}
+{{if .DateTime}}Date/Time: {{.DateTime}}
+{{end}}
+{{.Output}}
+{{if .LongOutput}}
+{{.LongOutput}}
+{{end}}{{if .Comment}}
+Comment ({{.Author}}): {{.Comment}}
+{{end}}`
+
+	DefaultHTMLTemplate string = `<html><body>
+<h3>{{.Type}}: {{if .IsService}}{{.HostName}}/{{.ServiceDescription}}{{else}}{{.HostName}}{{end}} is {{.State}}</h3>
+<table>
+<tr><td>Notification Type</td><td>{{.Type}}</td></tr>
+{{if .IsService}}<tr><td>Service</td><td>{{.ServiceDescription}}</td></tr>
+{{end}}<tr><td>Host</td><td>{{.HostName}}{{if .HostAddress}} ({{.HostAddress}}){{end}}</td></tr>
+<tr><td>State</td><td>{{.State}}</td></tr>
+{{if .DateTime}}<tr><td>Date/Time</td><td>{{.DateTime}}</td></tr>
+{{end}}</table>
+<p>{{.Output}}</p>
+{{if .LongOutput}}<pre>{{.LongOutput}}</pre>
+{{end}}{{if .Comment}}<p>Comment ({{.Author}}): {{.Comment}}</p>
+{{end}}</body></html>`
+
+	DefaultMarkdownTemplate string = `### {{.Type}}: {{if .IsService}}{{.HostName}}/{{.ServiceDescription}}{{else}}{{.HostName}}{{end}} is {{.State}}
+
+- **Notification Type**: {{.Type}}
+{{if .IsService}}- **Service**: {{.ServiceDescription}}
+{{end}}- **Host**: {{.HostName}}{{if .HostAddress}} ({{.HostAddress}}){{end}}
+- **State**: {{.State}}
+{{if .DateTime}}- **Date/Time**: {{.DateTime}}
+{{end}}
+{{.Output}}
+{{if .LongOutput}}
+` + "```" + `
+{{.LongOutput}}
+` + "```" + `
+{{end}}{{if .Comment}}
+> Comment ({{.Author}}): {{.Comment}}
+{{end}}`
+)
+
+// ErrUnsupportedFormat indicates that an unsupported message format was
+// specified.
+var ErrUnsupportedFormat = errors.New("unsupported message format")
+
+// Message is a rendered notification ready for delivery by a Sender.
+type Message struct {
+	// Subject is the short summary of the notification.
+	Subject string `json:"subject"`
+
+	// Body is the rendered notification text.
+	Body string `json:"body"`
+
+	// Format is the format of the rendered Body.
+	Format Format `json:"format"`
+
+	// Notification is the notification used to render the message.
+	Notification Notification `json:"notification"`
+}
+
+// Render renders the notification using the default template for the given
+// format.
+func Render(n Notification, format Format) (Message, error) {
+	switch format {
+	case FormatText:
+		return RenderTemplate(n, format, DefaultTextTemplate)
+	case FormatHTML:
+		return RenderTemplate(n, format, DefaultHTMLTemplate)
+	case FormatMarkdown:
+		return RenderTemplate(n, format, DefaultMarkdownTemplate)
+	default:
+		return Message{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// RenderTemplate renders the notification using the given template text.
+// HTML templates are processed using the html/template package so that
+// macro values (e.g., plugin output) are escaped; all other formats are
+// processed using the text/template package.
+func RenderTemplate(n Notification, format Format, tmpl string) (Message, error) {
+	var body strings.Builder
+
+	switch format {
+	case FormatHTML:
+		t, err := htmltemplate.New(string(format)).Parse(tmpl)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to parse %s template: %w", format, err)
+		}
+
+		if err := t.Execute(&body, n); err != nil {
+			return Message{}, fmt.Errorf("failed to render %s template: %w", format, err)
+		}
+
+	case FormatText, FormatMarkdown:
+		t, err := texttemplate.New(string(format)).Parse(tmpl)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to parse %s template: %w", format, err)
+		}
+
+		if err := t.Execute(&body, n); err != nil {
+			return Message{}, fmt.Errorf("failed to render %s template: %w", format, err)
+		}
+
+	default:
+		return Message{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+
+	return Message{
+		Subject:      n.Subject(),
+		Body:         body.String(),
+		Format:       format,
+		Notification: n,
+	}, nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package nsclient provides minimal clients for NSClient++ (NSCP) agents used
+to run checks on Windows hosts from Go plugins.
+
+# OVERVIEW
+
+Windows-focused checks frequently drive an NSClient++ agent rather than
+running locally. NSClient++ exposes checks via its NRPE server (NRPE mode)
+and via the REST API of its web server. This package queries either
+interface and translates the replies into nagios.ExternalResult values so
+that they may be recorded using the nagios.Plugin ImportExternalResult
+method (or evaluated further) like the results of local plugins.
+
+# FEATURES
+
+  - NRPEClient type used to run commands via the NSClient++ NRPE server
+    (NRPE protocol version 2 packets) over TLS or plain TCP
+  - RESTClient type used to run queries via the NSClient++ REST API
+    (/api/v1/queries), translating performance data into
+    nagios.PerformanceData values
+
+# TLS
+
+The Go TLS implementation does not support the anonymous Diffie-Hellman
+ciphers used by the NSClient++ NRPE server by default. Configure the agent
+with a certificate (and optionally client certificate verification) and
+provide a matching *tls.Config (see the transport package TLSConfig type),
+or disable TLS on the agent and pass a nil *tls.Config.
+
+See also:
+
+  - https://nsclient.org/docs/reference/client/NRPEServer/
+  - https://nsclient.org/docs/api/rest/
+*/
+package nsclient
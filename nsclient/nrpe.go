@@ -0,0 +1,238 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nsclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// NRPE protocol version 2 packet details.
+const (
+	nrpePacketVersion2   int16 = 2
+	nrpePacketTypeQuery  int16 = 1
+	nrpePacketTypeResult int16 = 2
+
+	// nrpeBufferSize is the size of the packet buffer holding the command
+	// or result text (including the terminating NUL).
+	nrpeBufferSize int = 1024
+
+	// nrpePacketSize is the size of a version 2 packet: version, type,
+	// CRC32, result code, buffer and two bytes of padding.
+	nrpePacketSize int = 2 + 2 + 4 + 2 + nrpeBufferSize + 2
+
+	// nrpeArgumentSeparator separates the command and arguments of a query.
+	nrpeArgumentSeparator string = "!"
+)
+
+// DefaultNRPETimeout is the timeout of NRPE queries if the context given
+// has no deadline. This matches the default timeout of check_nrpe.
+const DefaultNRPETimeout time.Duration = 10 * time.Second
+
+var (
+	// ErrInvalidNRPEQuery indicates that an NRPE query could not be sent
+	// because the command or its arguments are invalid (e.g., too long).
+	ErrInvalidNRPEQuery = errors.New("invalid NRPE query")
+
+	// ErrInvalidNRPEResponse indicates that an NRPE response could not be
+	// read because it is malformed (e.g., a CRC mismatch).
+	ErrInvalidNRPEResponse = errors.New("invalid NRPE response")
+)
+
+// NRPEClient runs commands via the NRPE server of an NSClient++ agent.
+type NRPEClient struct {
+	address   string
+	tlsConfig *tls.Config
+}
+
+// NRPEOption is a functional option used to configure an NRPEClient value
+// when constructed via NewNRPEClient.
+type NRPEOption func(*NRPEClient)
+
+// WithTLSConfig is an NRPEOption used to encrypt connections to the NRPE
+// server using the given TLS configuration. If not specified, the
+// connection is not encrypted (the agent must have TLS disabled).
+func WithTLSConfig(tlsConfig *tls.Config) NRPEOption {
+	return func(c *NRPEClient) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// NewNRPEClient returns an NRPEClient which queries the NRPE server at the
+// given address (e.g., "win01.example.com:5666"). Default settings are used
+// unless overridden by the given options (e.g., WithTLSConfig).
+func NewNRPEClient(address string, options ...NRPEOption) *NRPEClient {
+	c := NRPEClient{
+		address: address,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return &c
+}
+
+// Query runs the given command (e.g., "check_cpu") with the given arguments
+// (e.g., "warn=load > 80") on the agent and returns the result. The query
+// times out after DefaultNRPETimeout if ctx has no deadline.
+//
+// An error wrapping ErrInvalidNRPEQuery or ErrInvalidNRPEResponse is
+// returned if the query or response is malformed. An error wrapping
+// nagios.ErrInvalidPerformanceData is returned along with the (otherwise
+// complete) result if the performance data could not be parsed.
+func (c *NRPEClient) Query(ctx context.Context, command string, args ...string) (nagios.ExternalResult, error) {
+	result := nagios.ExternalResult{ExitCode: nagios.StateUNKNOWNExitCode}
+
+	query, err := encodeNRPEQuery(command, args...)
+	if err != nil {
+		return result, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultNRPETimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to connect to NRPE server %s: %w", c.address, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return result, fmt.Errorf("failed to send NRPE query: %w", err)
+	}
+
+	response := make([]byte, nrpePacketSize)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return result, fmt.Errorf("failed to read NRPE response: %w", err)
+	}
+
+	exitCode, output, err := decodeNRPEResponse(response)
+	if err != nil {
+		return result, err
+	}
+
+	result.ExitCode = exitCode
+	result.Stdout = output
+	result.Duration = time.Since(start)
+
+	parsed, err := nagios.ParsePluginOutput(output)
+	result.ParsedOutput = parsed
+	if err != nil {
+		return result, fmt.Errorf("failed to parse output of %s: %w", command, err)
+	}
+
+	return result, nil
+}
+
+// dial connects to the NRPE server, using TLS if configured.
+func (c *NRPEClient) dial(ctx context.Context) (net.Conn, error) {
+	if c.tlsConfig == nil {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "tcp", c.address)
+	}
+
+	dialer := tls.Dialer{Config: c.tlsConfig}
+
+	return dialer.DialContext(ctx, "tcp", c.address)
+}
+
+// encodeNRPEQuery encodes the given command and arguments as an NRPE
+// version 2 query packet.
+func encodeNRPEQuery(command string, args ...string) ([]byte, error) {
+	if command == "" || strings.Contains(command, nrpeArgumentSeparator) {
+		return nil, fmt.Errorf("%w: invalid command %q", ErrInvalidNRPEQuery, command)
+	}
+
+	for _, arg := range args {
+		if strings.Contains(arg, nrpeArgumentSeparator) {
+			return nil, fmt.Errorf("%w: argument %q contains %q", ErrInvalidNRPEQuery, arg, nrpeArgumentSeparator)
+		}
+	}
+
+	text := strings.Join(append([]string{command}, args...), nrpeArgumentSeparator)
+	if len(text) >= nrpeBufferSize {
+		return nil, fmt.Errorf("%w: query exceeds %d bytes", ErrInvalidNRPEQuery, nrpeBufferSize-1)
+	}
+
+	return encodeNRPEPacket(nrpePacketTypeQuery, 0, text), nil
+}
+
+// encodeNRPEPacket encodes an NRPE version 2 packet of the given type.
+func encodeNRPEPacket(packetType int16, resultCode int16, text string) []byte {
+	packet := make([]byte, nrpePacketSize)
+
+	binary.BigEndian.PutUint16(packet[0:2], uint16(nrpePacketVersion2))
+	binary.BigEndian.PutUint16(packet[2:4], uint16(packetType))
+	binary.BigEndian.PutUint16(packet[8:10], uint16(resultCode))
+	copy(packet[10:10+nrpeBufferSize-1], text)
+
+	// The CRC is calculated with the CRC field set to zero.
+	binary.BigEndian.PutUint32(packet[4:8], crc32.ChecksumIEEE(packet))
+
+	return packet
+}
+
+// decodeNRPEResponse decodes an NRPE version 2 response packet, returning
+// the result code and output.
+func decodeNRPEResponse(packet []byte) (int, string, error) {
+	if len(packet) != nrpePacketSize {
+		return nagios.StateUNKNOWNExitCode, "", fmt.Errorf(
+			"%w: packet size %d, want %d", ErrInvalidNRPEResponse, len(packet), nrpePacketSize,
+		)
+	}
+
+	version := int16(binary.BigEndian.Uint16(packet[0:2]))
+	packetType := int16(binary.BigEndian.Uint16(packet[2:4]))
+	crc := binary.BigEndian.Uint32(packet[4:8])
+	resultCode := int16(binary.BigEndian.Uint16(packet[8:10]))
+
+	check := make([]byte, len(packet))
+	copy(check, packet)
+	binary.BigEndian.PutUint32(check[4:8], 0)
+
+	switch {
+	case version != nrpePacketVersion2:
+		return nagios.StateUNKNOWNExitCode, "", fmt.Errorf(
+			"%w: unsupported packet version %d", ErrInvalidNRPEResponse, version,
+		)
+	case packetType != nrpePacketTypeResult:
+		return nagios.StateUNKNOWNExitCode, "", fmt.Errorf(
+			"%w: unexpected packet type %d", ErrInvalidNRPEResponse, packetType,
+		)
+	case crc32.ChecksumIEEE(check) != crc:
+		return nagios.StateUNKNOWNExitCode, "", fmt.Errorf("%w: CRC mismatch", ErrInvalidNRPEResponse)
+	}
+
+	buffer := packet[10 : 10+nrpeBufferSize]
+	if i := bytes.IndexByte(buffer, 0); i >= 0 {
+		buffer = buffer[:i]
+	}
+
+	return int(resultCode), string(buffer), nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nsclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// serveNRPE starts a fake NRPE server which records the query text and
+// replies with the given response packet.
+func serveNRPE(t *testing.T, response []byte) (string, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	queries := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		query := make([]byte, nrpePacketSize)
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		text := query[10 : 10+nrpeBufferSize]
+		queries <- string(text[:bytes.IndexByte(text, 0)])
+
+		_, _ = conn.Write(response)
+	}()
+
+	return listener.Addr().String(), queries
+}
+
+// TestNRPEClientQueryTranslatesResponse asserts that the command and
+// arguments are sent as an NRPE query and that the response is translated
+// into an ExternalResult.
+func TestNRPEClientQueryTranslatesResponse(t *testing.T) {
+	t.Parallel()
+
+	response := encodeNRPEPacket(
+		nrpePacketTypeResult,
+		int16(nagios.StateWARNINGExitCode),
+		"WARNING: CPU load is high|'total 5m'=85%;80;90 'total 1m'=91%;80;90",
+	)
+
+	address, queries := serveNRPE(t, response)
+
+	result, err := NewNRPEClient(address).Query(context.Background(), "check_cpu", "warn=load > 80")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	if got, want := <-queries, "check_cpu!warn=load > 80"; got != want {
+		t.Errorf("want query %q, got %q", want, got)
+	}
+
+	if result.ExitCode != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, result.ExitCode)
+	}
+
+	if want := "WARNING: CPU load is high"; result.ServiceOutput != want {
+		t.Errorf("want summary %q, got %q", want, result.ServiceOutput)
+	}
+
+	if len(result.PerfData) != 2 || result.PerfData[0].Label != "total 5m" || result.PerfData[0].Warn != "80" {
+		t.Errorf("unexpected performance data: %+v", result.PerfData)
+	}
+}
+
+// TestNRPEClientQueryRejectsCorruptResponse asserts that a response with
+// a CRC mismatch is rejected.
+func TestNRPEClientQueryRejectsCorruptResponse(t *testing.T) {
+	t.Parallel()
+
+	response := encodeNRPEPacket(nrpePacketTypeResult, int16(nagios.StateOKExitCode), "OK: all good")
+	response[20] ^= 0xFF
+
+	address, _ := serveNRPE(t, response)
+
+	result, err := NewNRPEClient(address).Query(context.Background(), "check_ok")
+	if !errors.Is(err, ErrInvalidNRPEResponse) {
+		t.Errorf("want error %v, got %v", ErrInvalidNRPEResponse, err)
+	}
+
+	if result.ExitCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateUNKNOWNExitCode, result.ExitCode)
+	}
+}
+
+// TestEncodeNRPEQueryRejectsInvalidQueries asserts that queries which
+// cannot be represented in an NRPE packet are rejected.
+func TestEncodeNRPEQueryRejectsInvalidQueries(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		command string
+		args    []string
+	}{
+		"empty command":       {command: ""},
+		"separator in arg":    {command: "check_cpu", args: []string{"a!b"}},
+		"separator in cmd":    {command: "check!cpu"},
+		"exceeds buffer size": {command: "check_cpu", args: []string{strings.Repeat("x", nrpeBufferSize)}},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := encodeNRPEQuery(tt.command, tt.args...); !errors.Is(err, ErrInvalidNRPEQuery) {
+				t.Errorf("want error %v, got %v", ErrInvalidNRPEQuery, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+const (
+	// queriesPath is the path of the query endpoints, relative to the API
+	// base URL.
+	queriesPath string = "/api/v1/queries/"
+
+	// executeSuffix is appended to the query path to run the query.
+	executeSuffix string = "/commands/execute"
+
+	// DefaultRESTUser is the user name used by the NSClient++ web server.
+	DefaultRESTUser string = "admin"
+
+	// passwordHeader is the header used by older NSClient++ releases to
+	// authenticate API requests.
+	passwordHeader string = "password"
+
+	// maxErrorBodySize is the maximum number of bytes of an error response
+	// included in returned errors.
+	maxErrorBodySize int64 = 512
+)
+
+// ErrQueryFailed indicates that the NSClient++ REST API rejected a query.
+var ErrQueryFailed = errors.New("NSClient++ API query failed")
+
+// RESTClient runs queries via the REST API of an NSClient++ agent.
+type RESTClient struct {
+	baseURL    string
+	password   string
+	httpClient *http.Client
+}
+
+// queryResponse is the body of a query execution response.
+type queryResponse struct {
+	Command string          `json:"command"`
+	Lines   []queryLine     `json:"lines"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// queryLine is a single line of a query execution response.
+type queryLine struct {
+	Message string               `json:"message"`
+	Perf    map[string]queryPerf `json:"perf"`
+}
+
+// queryPerf is a single performance data metric of a query execution
+// response.
+type queryPerf struct {
+	Value    *float64 `json:"value"`
+	Unit     string   `json:"unit"`
+	Warning  *float64 `json:"warning"`
+	Critical *float64 `json:"critical"`
+	Minimum  *float64 `json:"minimum"`
+	Maximum  *float64 `json:"maximum"`
+}
+
+// RESTOption is a functional option used to configure a RESTClient value
+// when constructed via NewRESTClient.
+type RESTOption func(*RESTClient)
+
+// WithPassword is a RESTOption used to specify the password of the
+// NSClient++ web server.
+func WithPassword(password string) RESTOption {
+	return func(c *RESTClient) {
+		c.password = password
+	}
+}
+
+// WithHTTPClient is a RESTOption used to specify the HTTP client used to
+// send requests (e.g., one configured with a CA bundle via the transport
+// package). If not specified, http.DefaultClient is used.
+func WithHTTPClient(httpClient *http.Client) RESTOption {
+	return func(c *RESTClient) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// NewRESTClient returns a RESTClient which queries the NSClient++ web
+// server at the given base URL (e.g., "https://win01.example.com:8443").
+// Default settings are used unless overridden by the given options (e.g.,
+// WithPassword).
+func NewRESTClient(baseURL string, options ...RESTOption) *RESTClient {
+	c := RESTClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return &c
+}
+
+// Query runs the given query (e.g., "check_cpu") with the given arguments
+// (e.g., "warning=load > 80", "show-all") on the agent and returns the
+// result. The first line of the reply is used as the one-line summary and
+// the remaining lines as LongServiceOutput.
+//
+// An error wrapping ErrQueryFailed is returned if the API rejects the
+// query.
+func (c *RESTClient) Query(ctx context.Context, command string, args ...string) (nagios.ExternalResult, error) {
+	result := nagios.ExternalResult{ExitCode: nagios.StateUNKNOWNExitCode}
+
+	query := make([]string, 0, len(args))
+	for _, arg := range args {
+		key, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case hasValue:
+			query = append(query, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		default:
+			query = append(query, url.QueryEscape(key))
+		}
+	}
+
+	endpoint := c.baseURL + queriesPath + url.PathEscape(command) + executeSuffix
+	if len(query) > 0 {
+		endpoint += "?" + strings.Join(query, "&")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return result, err
+	}
+
+	req.SetBasicAuth(DefaultRESTUser, c.password)
+	req.Header.Set(passwordHeader, c.password)
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+		return result, fmt.Errorf(
+			"%w: %s: %s",
+			ErrQueryFailed,
+			resp.Status,
+			strings.TrimSpace(string(detail)),
+		)
+	}
+
+	var reply queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return result, fmt.Errorf("failed to decode query response: %w", err)
+	}
+
+	result.Duration = time.Since(start)
+
+	exitCode, err := parseResultCode(reply.Result)
+	if err != nil {
+		return result, fmt.Errorf("%w: %s: %v", ErrQueryFailed, command, err)
+	}
+
+	result.ExitCode = exitCode
+	result.ParsedOutput = reply.parsedOutput()
+
+	return result, nil
+}
+
+// parseResultCode parses the result of a query, which is reported as an
+// exit code or as a state label (e.g., "CRITICAL") depending on the
+// NSClient++ release.
+func parseResultCode(raw json.RawMessage) (int, error) {
+	var code int
+	if err := json.Unmarshal(raw, &code); err == nil {
+		return code, nil
+	}
+
+	var label string
+	if err := json.Unmarshal(raw, &label); err != nil {
+		return nagios.StateUNKNOWNExitCode, fmt.Errorf("invalid result %s", raw)
+	}
+
+	for _, state := range []struct {
+		label    string
+		exitCode int
+	}{
+		{label: nagios.StateOKLabel, exitCode: nagios.StateOKExitCode},
+		{label: nagios.StateWARNINGLabel, exitCode: nagios.StateWARNINGExitCode},
+		{label: nagios.StateCRITICALLabel, exitCode: nagios.StateCRITICALExitCode},
+		{label: nagios.StateUNKNOWNLabel, exitCode: nagios.StateUNKNOWNExitCode},
+	} {
+		if strings.EqualFold(label, state.label) {
+			return state.exitCode, nil
+		}
+	}
+
+	return nagios.StateUNKNOWNExitCode, fmt.Errorf("invalid result %q", label)
+}
+
+// parsedOutput translates the reply lines into plugin output and
+// performance data sorted by label.
+func (r queryResponse) parsedOutput() nagios.ParsedOutput {
+	var parsed nagios.ParsedOutput
+
+	messages := make([]string, 0, len(r.Lines))
+
+	for _, line := range r.Lines {
+		messages = append(messages, line.Message)
+
+		labels := make([]string, 0, len(line.Perf))
+		for label := range line.Perf {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		for _, label := range labels {
+			parsed.PerfData = append(parsed.PerfData, line.Perf[label].perfData(label))
+		}
+	}
+
+	if len(messages) > 0 {
+		parsed.ServiceOutput = messages[0]
+		parsed.LongServiceOutput = strings.Join(messages[1:], "\n")
+	}
+
+	return parsed
+}
+
+// perfData translates the metric into a nagios.PerformanceData value.
+func (qp queryPerf) perfData(label string) nagios.PerformanceData {
+	format := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+
+	value := format(qp.Value)
+	if value == "" {
+		// The actual value couldn't be determined.
+		value = "U"
+	}
+
+	return nagios.PerformanceData{
+		Label:             label,
+		Value:             value,
+		UnitOfMeasurement: qp.Unit,
+		Warn:              format(qp.Warning),
+		Crit:              format(qp.Critical),
+		Min:               format(qp.Minimum),
+		Max:               format(qp.Maximum),
+	}
+}
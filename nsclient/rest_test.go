@@ -0,0 +1,98 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nsclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRESTClientQueryTranslatesResponse asserts that queries are run via
+// the execute endpoint with the given arguments and that the reply is
+// translated into an ExternalResult.
+func TestRESTClientQueryTranslatesResponse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/queries/check_drivesize/commands/execute" ||
+			r.URL.RawQuery != "drive=C%3A&show-all" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+
+		if user, pass, ok := r.BasicAuth(); !ok || user != DefaultRESTUser || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"command":"check_drivesize","lines":[` +
+			`{"message":"CRITICAL: C:\\: 95% used","perf":{"C:\\ used %":{"value":95,"unit":"%","warning":80,"critical":90}}},` +
+			`{"message":"C:\\: 190GB/200GB used","perf":{"C:\\ used":{"value":190.5,"unit":"GB","minimum":0,"maximum":200}}}` +
+			`],"result":"CRITICAL"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewRESTClient(server.URL+"/", WithPassword("secret"), WithHTTPClient(server.Client()))
+
+	result, err := client.Query(context.Background(), "check_drivesize", "drive=C:", "show-all")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	if result.ExitCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateCRITICALExitCode, result.ExitCode)
+	}
+
+	if want := `CRITICAL: C:\: 95% used`; result.ServiceOutput != want {
+		t.Errorf("want summary %q, got %q", want, result.ServiceOutput)
+	}
+
+	if want := `C:\: 190GB/200GB used`; result.LongServiceOutput != want {
+		t.Errorf("want long output %q, got %q", want, result.LongServiceOutput)
+	}
+
+	want := []nagios.PerformanceData{
+		{Label: `C:\ used %`, Value: "95", UnitOfMeasurement: "%", Warn: "80", Crit: "90"},
+		{Label: `C:\ used`, Value: "190.5", UnitOfMeasurement: "GB", Min: "0", Max: "200"},
+	}
+
+	if len(result.PerfData) != len(want) {
+		t.Fatalf("want %d metrics, got %+v", len(want), result.PerfData)
+	}
+
+	for i := range want {
+		if result.PerfData[i] != want[i] {
+			t.Errorf("want metric %+v, got %+v", want[i], result.PerfData[i])
+		}
+	}
+}
+
+// TestRESTClientQueryReportsRejectedQueries asserts that rejected queries
+// are reported as ErrQueryFailed.
+func TestRESTClientQueryReportsRejectedQueries(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "403 You're not allowed", http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	result, err := NewRESTClient(server.URL, WithPassword("wrong"), WithHTTPClient(server.Client())).Query(context.Background(), "check_cpu")
+	if !errors.Is(err, ErrQueryFailed) {
+		t.Errorf("want error %v, got %v", ErrQueryFailed, err)
+	}
+
+	if result.ExitCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateUNKNOWNExitCode, result.ExitCode)
+	}
+}
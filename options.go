@@ -0,0 +1,75 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"io"
+)
+
+// Option is a functional option used to configure a Plugin value when
+// constructed via NewPlugin. Functional options allow new settings to be
+// added over time without changing the constructor signature.
+type Option func(*Plugin)
+
+// WithOutputTarget is an Option used to assign a target for Nagios plugin
+// output. See also SetOutputTarget.
+func WithOutputTarget(w io.Writer) Option {
+	return func(p *Plugin) {
+		p.SetOutputTarget(w)
+	}
+}
+
+// WithBrandingCallback is an Option used to assign a function that is called
+// before application termination to emit branding details at the end of the
+// notification. See also ExitCallBackFunc.
+func WithBrandingCallback(fn ExitCallBackFunc) Option {
+	return func(p *Plugin) {
+		p.BrandingCallback = fn
+	}
+}
+
+// WithThresholdsLabel is an Option used to override the default thresholds
+// label text. See also SetThresholdsLabel.
+func WithThresholdsLabel(label string) Option {
+	return func(p *Plugin) {
+		p.SetThresholdsLabel(label)
+	}
+}
+
+// WithErrorsLabel is an Option used to override the default errors label
+// text. See also SetErrorsLabel.
+func WithErrorsLabel(label string) Option {
+	return func(p *Plugin) {
+		p.SetErrorsLabel(label)
+	}
+}
+
+// WithDetailedInfoLabel is an Option used to override the default detailed
+// info label text. See also SetDetailedInfoLabel.
+func WithDetailedInfoLabel(label string) Option {
+	return func(p *Plugin) {
+		p.SetDetailedInfoLabel(label)
+	}
+}
+
+// WithSkipOSExit is an Option used to indicate that the final os.Exit(x)
+// call should be skipped. See also SkipOSExit.
+func WithSkipOSExit() Option {
+	return func(p *Plugin) {
+		p.SkipOSExit()
+	}
+}
+
+// WithRuntimeMetric is an Option used to emit the plugin runtime as a
+// performance data metric using the given label. Timing starts when the
+// Plugin value is constructed. See also SetRuntimeMetric.
+func WithRuntimeMetric(label string) Option {
+	return func(p *Plugin) {
+		p.SetRuntimeMetric(label)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidPerformanceData indicates that performance data could not
+	// be parsed.
+	ErrInvalidPerformanceData = errors.New("invalid performance data")
+)
+
+// ParsedOutput is the result of parsing plugin output into its component
+// parts.
+type ParsedOutput struct {
+	// ServiceOutput is the first line of output, excluding performance data.
+	ServiceOutput string
+
+	// LongServiceOutput is the output following the first line, excluding
+	// performance data.
+	LongServiceOutput string
+
+	// PerfData is the collection of performance data metrics found in the
+	// output.
+	PerfData []PerformanceData
+}
+
+// ParsePluginOutput parses plugin output in the format described by the
+// Nagios plugin API:
+//
+//	TEXT OUTPUT | OPTIONAL PERFDATA
+//	LONG TEXT LINE 1
+//	LONG TEXT LINE 2
+//	LONG TEXT LINE N | PERFDATA LINE 2
+//	PERFDATA LINE 3
+//
+// Performance data from the first line and from the end of the long output
+// are combined. An error wrapping ErrInvalidPerformanceData is returned if
+// performance data is present but cannot be parsed; the text output is still
+// returned in that case.
+func ParsePluginOutput(output string) (ParsedOutput, error) {
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	output = strings.TrimRight(output, " \t\n")
+
+	firstLine, rest, _ := strings.Cut(output, "\n")
+
+	var parsed ParsedOutput
+	var perfDataText []string
+
+	summary, perfData, found := strings.Cut(firstLine, "|")
+	parsed.ServiceOutput = strings.TrimSpace(summary)
+	if found {
+		perfDataText = append(perfDataText, perfData)
+	}
+
+	if rest != "" {
+		longOutput, longPerfData, found := strings.Cut(rest, "|")
+		parsed.LongServiceOutput = strings.TrimRight(longOutput, " \t\n")
+		if found {
+			perfDataText = append(perfDataText, longPerfData)
+		}
+	}
+
+	var err error
+	parsed.PerfData, err = ParsePerfData(strings.Join(perfDataText, " "))
+
+	return parsed, err
+}
+
+// ParsePerfData parses a space separated list of performance data metrics
+// in the format 'label'=value[UOM];[warn];[crit];[min];[max]. Labels
+// containing spaces must be single quoted; a literal single quote within a
+// quoted label is escaped by doubling it.
+//
+// An error wrapping ErrInvalidPerformanceData is returned for the first
+// metric which cannot be parsed along with the metrics parsed before it.
+func ParsePerfData(perfData string) ([]PerformanceData, error) {
+	var metrics []PerformanceData
+
+	remaining := strings.TrimSpace(perfData)
+	for remaining != "" {
+		label, rest, err := parsePerfDataLabel(remaining)
+		if err != nil {
+			return metrics, err
+		}
+
+		data := rest
+		if i := strings.IndexAny(rest, " \t\n"); i >= 0 {
+			data, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+
+		metric, err := parsePerfDataValues(label, data)
+		if err != nil {
+			return metrics, err
+		}
+
+		metrics = append(metrics, metric)
+		remaining = strings.TrimSpace(rest)
+	}
+
+	return metrics, nil
+}
+
+// parsePerfDataLabel parses the (optionally quoted) label at the start of
+// the given text, returning the label and the text following the equals
+// sign.
+func parsePerfDataLabel(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "'") {
+		label, rest, found := strings.Cut(s, "=")
+		if !found || label == "" || strings.ContainsAny(label, " \t\n") {
+			return "", "", fmt.Errorf("%w: missing label in %q", ErrInvalidPerformanceData, s)
+		}
+
+		return label, rest, nil
+	}
+
+	var label strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			label.WriteByte(s[i])
+			continue
+		}
+
+		// A doubled single quote is a literal single quote.
+		if i+1 < len(s) && s[i+1] == '\'' {
+			label.WriteByte('\'')
+			i++
+			continue
+		}
+
+		if i+1 >= len(s) || s[i+1] != '=' {
+			return "", "", fmt.Errorf("%w: missing value for label %q", ErrInvalidPerformanceData, label.String())
+		}
+
+		return label.String(), s[i+2:], nil
+	}
+
+	return "", "", fmt.Errorf("%w: unterminated label in %q", ErrInvalidPerformanceData, s)
+}
+
+// parsePerfDataValues parses the value[UOM];[warn];[crit];[min];[max]
+// portion of a performance data metric.
+func parsePerfDataValues(label string, data string) (PerformanceData, error) {
+	fields := strings.Split(data, ";")
+	if len(fields) > 5 {
+		return PerformanceData{}, fmt.Errorf(
+			"%w: too many fields for label %q",
+			ErrInvalidPerformanceData,
+			label,
+		)
+	}
+
+	valueText := fields[0]
+	end := strings.IndexFunc(valueText, func(r rune) bool {
+		return !strings.ContainsRune("-+0123456789.eE", r)
+	})
+
+	value, uom := valueText, ""
+	switch {
+	case strings.HasPrefix(valueText, "U"):
+		value, uom = "U", ""
+	case end >= 0:
+		value, uom = valueText[:end], valueText[end:]
+	}
+
+	if value == "" {
+		return PerformanceData{}, fmt.Errorf(
+			"%w: missing value for label %q",
+			ErrInvalidPerformanceData,
+			label,
+		)
+	}
+
+	metric := PerformanceData{
+		Label:             label,
+		Value:             value,
+		UnitOfMeasurement: uom,
+	}
+
+	optional := []*string{&metric.Warn, &metric.Crit, &metric.Min, &metric.Max}
+	for i, field := range fields[1:] {
+		*optional[i] = field
+	}
+
+	return metric, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParsePluginOutput asserts that the one-line summary, long output and
+// performance data from both the first line and the long output are parsed.
+func TestParsePluginOutput(t *testing.T) {
+	t.Parallel()
+
+	output := "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968\n" +
+		"/ 15272 MB (77%);\n" +
+		"/boot 68 MB (69%); | /boot=68MB;88;93;0;98\n" +
+		"'home dir'=69%;~:90;@95:100 time=U\n"
+
+	got, err := nagios.ParsePluginOutput(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := nagios.ParsedOutput{
+		ServiceOutput:     "DISK OK - free space: / 3326 MB (56%);",
+		LongServiceOutput: "/ 15272 MB (77%);\n/boot 68 MB (69%);",
+		PerfData: []nagios.PerformanceData{
+			{Label: "/", Value: "2643", UnitOfMeasurement: "MB", Warn: "5948", Crit: "5958", Min: "0", Max: "5968"},
+			{Label: "/boot", Value: "68", UnitOfMeasurement: "MB", Warn: "88", Crit: "93", Min: "0", Max: "98"},
+			{Label: "home dir", Value: "69", UnitOfMeasurement: "%", Warn: "~:90", Crit: "@95:100"},
+			{Label: "time", Value: "U"},
+		},
+	}
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestParsePerfDataRejectsInvalidMetrics asserts that invalid metrics are
+// reported along with the metrics parsed before them.
+func TestParsePerfDataRejectsInvalidMetrics(t *testing.T) {
+	t.Parallel()
+
+	got, err := nagios.ParsePerfData("load1=0.5;1;2 'unterminated=3")
+	if !errors.Is(err, nagios.ErrInvalidPerformanceData) {
+		t.Fatalf("want error wrapping %v, got %v", nagios.ErrInvalidPerformanceData, err)
+	}
+
+	want := []nagios.PerformanceData{{Label: "load1", Value: "0.5", Warn: "1", Crit: "2"}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/atc0005/go-nagios/transport"
+)
+
+// DefaultBatchSize is the number of results collected by a Batcher before
+// they are flushed if not specified by client code.
+const DefaultBatchSize int = 100
+
+// ErrMissingFlushFunc indicates that a Batcher was created without a
+// function to submit results.
+var ErrMissingFlushFunc = errors.New("missing batch flush function")
+
+// FlushFunc submits a batch of results (e.g., by encoding them with
+// EncodeNRDPXML and posting the payload to an NRDP endpoint).
+type FlushFunc func(results []CheckResult) error
+
+// Batcher collects results and submits them in groups. This reduces the
+// number of submissions (and connections) required for high check volumes.
+// Batcher is safe for concurrent use.
+type Batcher struct {
+	mu      sync.Mutex
+	size    int
+	flush   FlushFunc
+	pending []CheckResult
+}
+
+// NewBatcher creates a Batcher which submits results using the given
+// function once size results have been collected. DefaultBatchSize is used
+// if size is less than 1.
+func NewBatcher(size int, flush FlushFunc) (*Batcher, error) {
+	if flush == nil {
+		return nil, ErrMissingFlushFunc
+	}
+
+	if size < 1 {
+		size = DefaultBatchSize
+	}
+
+	return &Batcher{
+		size:    size,
+		flush:   flush,
+		pending: make([]CheckResult, 0, size),
+	}, nil
+}
+
+// Add adds the given results to the batch and then submits batches of the
+// configured size while enough results are pending. All given results are
+// added before any batch is submitted. If submission fails, the error is
+// returned and every result not yet submitted (including any of the given
+// results) is retained so that it may be retried by a later call to Add or
+// Flush. Results queued by the flush function for a later attempt (an error
+// wrapping transport.ErrPayloadSpooled) are considered submitted.
+func (b *Batcher) Add(results ...CheckResult) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, results...)
+
+	return b.submitLocked(b.size)
+}
+
+// Flush submits all pending results in batches of the configured size. This
+// should be called (e.g., via defer) before the application exits. Results
+// are retained as described for Add if submission fails.
+func (b *Batcher) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.submitLocked(1)
+}
+
+// Len returns the number of pending results.
+func (b *Batcher) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.pending)
+}
+
+// submitLocked submits pending results in batches of the configured size
+// while at least minPending results are pending. Submission stops at the
+// first failed batch; the results of that batch and all later results are
+// retained. The caller must hold the lock.
+func (b *Batcher) submitLocked(minPending int) error {
+	var errs []error
+
+	for len(b.pending) > 0 && len(b.pending) >= minPending {
+		n := min(b.size, len(b.pending))
+
+		if err := b.flush(b.pending[:n:n]); err != nil {
+			errs = append(errs, err)
+
+			if !errors.Is(err, transport.ErrPayloadSpooled) {
+				break
+			}
+		}
+
+		b.pending = append([]CheckResult(nil), b.pending[n:]...)
+	}
+
+	return errors.Join(errs...)
+}
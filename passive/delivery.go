@@ -0,0 +1,169 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/transport"
+)
+
+// DeliveryOption is a functional option used to configure the Sink
+// returned by NewReliableSink.
+type DeliveryOption func(*reliableSink)
+
+// reliableSink submits results via another Sink, queueing them on disk if
+// submission fails and suppressing submission attempts while a circuit
+// breaker is open.
+type reliableSink struct {
+	sink    Sink
+	spool   *transport.Spool
+	breaker *transport.CircuitBreaker
+	plugin  *nagios.Plugin
+}
+
+// WithSpool is a DeliveryOption used to queue results in the given spool
+// if submission fails. Queued results are submitted (oldest first) before
+// new results on the next submission, so that no results are lost while
+// the monitoring system is unavailable (e.g., during maintenance).
+func WithSpool(spool *transport.Spool) DeliveryOption {
+	return func(s *reliableSink) {
+		s.spool = spool
+	}
+}
+
+// WithBreaker is a DeliveryOption used to stop contacting a repeatedly
+// failing monitoring system for a cooldown period (see
+// transport.CircuitBreaker) so that a slow or unreachable endpoint does not
+// cause the plugin to exceed its check timeout. Suppressed submissions fail
+// with an error wrapping transport.ErrCircuitOpen; if a spool is also set
+// (see WithSpool) the results are queued without contacting the endpoint.
+func WithBreaker(breaker *transport.CircuitBreaker) DeliveryOption {
+	return func(s *reliableSink) {
+		s.breaker = breaker
+	}
+}
+
+// WithPlugin is a DeliveryOption used to record suppressed submissions
+// (see WithBreaker) in the output of the given plugin, noting the circuit
+// breaker status in LongServiceOutput. This is intended for plugins which
+// submit passive results (e.g., via an Emitter) before returning their own
+// results. The plugin must not be modified concurrently.
+func WithPlugin(plugin *nagios.Plugin) DeliveryOption {
+	return func(s *reliableSink) {
+		s.plugin = plugin
+	}
+}
+
+// NewReliableSink returns a Sink which submits results via the given sink
+// subject to the given options (see WithSpool and WithBreaker). The Sink is shared by all
+// submission methods (NSCA, NRDP, the external command file and the Icinga
+// 2 API).
+//
+// If results could not be submitted but were queued for a later attempt,
+// an error wrapping transport.ErrPayloadSpooled is returned; Emitter treats
+// such results as accepted.
+func NewReliableSink(sink Sink, options ...DeliveryOption) Sink {
+	s := reliableSink{
+		sink: sink,
+	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s
+}
+
+// Submit submits the given results, queueing them in the spool (if set) if
+// submission fails or is suppressed by the circuit breaker (if set).
+func (s *reliableSink) Submit(ctx context.Context, results []CheckResult) error {
+	err := s.submit(ctx, results)
+
+	if errors.Is(err, transport.ErrCircuitOpen) && s.plugin != nil {
+		s.plugin.WithDetail("Passive result submission suppressed: " + s.breaker.Status())
+	}
+
+	return err
+}
+
+// submit submits the given results via the spool (if set) and circuit
+// breaker (if set).
+func (s *reliableSink) submit(ctx context.Context, results []CheckResult) error {
+	send := func(results []CheckResult) error {
+		if s.breaker == nil {
+			return s.sink.Submit(ctx, results)
+		}
+
+		return s.breaker.Call(func() error {
+			return s.sink.Submit(ctx, results)
+		})
+	}
+
+	if s.spool == nil {
+		return send(results)
+	}
+
+	payload, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	return s.spool.Forward(payload, func(payload []byte) error {
+		var batch []CheckResult
+		if err := json.Unmarshal(payload, &batch); err != nil {
+			// A corrupt entry can never be delivered; drop it rather than
+			// blocking delivery of all later results.
+			return nil
+		}
+
+		return send(batch)
+	})
+}
+
+// ReliableSinkFromURL wraps the given sink as requested by the delivery
+// parameters of the given sink specification (see SinkParamSpool and
+// SinkParamBreaker) using NewReliableSink. The given sink is returned as-is
+// if no delivery parameters are set. This is intended for
+// nagios.SinkFactory implementations of other packages (e.g., the Icinga 2
+// API sink) so that delivery parameters behave the same for all sink
+// schemes.
+func ReliableSinkFromURL(sink Sink, spec *url.URL) (Sink, error) {
+	query := spec.Query()
+
+	var options []DeliveryOption
+
+	if dir := query.Get(SinkParamSpool); dir != "" {
+		spool, err := transport.NewSpool(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid result sink %q: %w", redactSinkURL(spec), err)
+		}
+
+		options = append(options, WithSpool(spool))
+	}
+
+	if path := query.Get(SinkParamBreaker); path != "" {
+		breaker, err := transport.NewCircuitBreaker(transport.WithStateFile(path))
+		if err != nil {
+			return nil, fmt.Errorf("invalid result sink %q: %w", redactSinkURL(spec), err)
+		}
+
+		options = append(options, WithBreaker(breaker))
+	}
+
+	if len(options) == 0 {
+		return sink, nil
+	}
+
+	return NewReliableSink(sink, options...), nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package passive provides common types and helper functions used to submit
+passive check results to a (remote) monitoring system.
+
+# OVERVIEW
+
+In distributed monitoring setups, satellite Nagios instances use obsessive
+compulsive service processor (OCSP) and host processor (OCHP) commands to
+forward check results to a central instance. This package parses the macros passed to those commands and
+encodes the results in the formats accepted by common submission methods
+(NSCA, NRDP and the Nagios external command file). A Batcher type is provided
+to group results into a single submission for high check volumes.
+
+# FEATURES
+
+  - CheckResult type representing a passive check result
+  - Parse OCSP command arguments ($HOSTNAME$, $SERVICEDESC$,
+    $SERVICESTATEID$, $SERVICEOUTPUT$ and optionally $SERVICEPERFDATA$)
+    and OCHP command arguments ($HOSTNAME$, $HOSTSTATEID$, $HOSTOUTPUT$ and
+    optionally $HOSTPERFDATA$)
+  - Encode service and host results for send_nsca, NRDP (XML and JSON) and
+    the external command file (PROCESS_SERVICE_CHECK_RESULT and
+    PROCESS_HOST_CHECK_RESULT)
+  - Write results directly to the Nagios external command file
+  - Batcher type used to submit results in groups of a configurable size
+  - Emitter type used by discovery-style collectors to produce results for
+    many host/service pairs in one run and submit them as a single batch to
+    each configured Sink (external command file, NRDP bulk XML via
+    NRDPSink or the Icinga 2 API via the icinga2 package Client)
+  - NewResultSink adapter used to submit plugin results (see nagios.Sink)
+    as passive check results, and the "cmdfile", "nrdp+https" and "nsca"
+    (send_nsca) result sink schemes for nagios.ParseSink
+  - NewReliableSink wrapper used to queue results on disk (see
+    transport.Spool) while the monitoring system is unavailable and submit
+    them on the next run, and to suppress submissions to a repeatedly
+    failing monitoring system via a circuit breaker (see
+    transport.CircuitBreaker), noting the suppression in plugin output;
+    also available via the "spool" and "breaker" parameters of result sink
+    specifications
+
+See also:
+
+  - https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/4/en/distributed.html
+  - https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/4/en/extcommands.html
+*/
+package passive
@@ -0,0 +1,183 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/transport"
+)
+
+// ErrNoSinks indicates that results were submitted by an Emitter without a
+// Sink to submit them to.
+var ErrNoSinks = errors.New("no result sinks configured")
+
+// Sink submits a batch of results to a monitoring system (e.g., via the
+// external command file, NRDP or the Icinga 2 API).
+type Sink interface {
+	Submit(ctx context.Context, results []CheckResult) error
+}
+
+// SinkFunc is an adapter allowing an ordinary function to be used as a
+// Sink.
+type SinkFunc func(ctx context.Context, results []CheckResult) error
+
+// Submit calls f(ctx, results).
+func (f SinkFunc) Submit(ctx context.Context, results []CheckResult) error {
+	return f(ctx, results)
+}
+
+// CommandFileSink returns a Sink which writes results as external commands
+// to the Nagios external command file at the given path (see
+// WriteCommandFile).
+func CommandFileSink(path string) Sink {
+	return SinkFunc(func(_ context.Context, results []CheckResult) error {
+		return WriteCommandFile(path, time.Now(), results...)
+	})
+}
+
+// Emitter collects results for many host/service pairs during a single
+// program run (e.g., a discovery-style collector checking an entire
+// cluster) and submits them as one batch to each configured Sink. Pending
+// results are held by a Batcher, so submission failures are handled the
+// same way (see Batcher.Add). Emitter is safe for concurrent use.
+type Emitter struct {
+	mu      sync.Mutex
+	sinks   []Sink
+	batcher *Batcher
+
+	// ctx is the context given to Submit, used by the batcher flush
+	// function while mu is held.
+	ctx context.Context
+}
+
+// NewEmitter creates an Emitter which submits results to the given sinks.
+func NewEmitter(sinks ...Sink) *Emitter {
+	e := &Emitter{
+		sinks: sinks,
+	}
+
+	// Results are only submitted by Submit, as one batch.
+	e.batcher = &Batcher{
+		size:  math.MaxInt,
+		flush: e.submit,
+	}
+
+	return e
+}
+
+// Add adds the given results to the batch.
+func (e *Emitter) Add(results ...CheckResult) {
+	// The batch size is never reached, so nothing is submitted here.
+	_ = e.batcher.Add(results...)
+}
+
+// AddService adds a service result with the given state (e.g.,
+// nagios.StateCRITICALExitCode), one-line summary and optional performance
+// data to the batch.
+func (e *Emitter) AddService(host string, service string, exitCode int, summary string, perfData ...nagios.PerformanceData) {
+	e.Add(CheckResult{
+		HostName:           host,
+		ServiceDescription: service,
+		ExitCode:           exitCode,
+		Output:             formatOutput(summary, perfData),
+	})
+}
+
+// AddHost adds a host result with the given state (e.g.,
+// HostStateDOWNExitCode), one-line summary and optional performance data
+// to the batch.
+func (e *Emitter) AddHost(host string, exitCode int, summary string, perfData ...nagios.PerformanceData) {
+	e.Add(CheckResult{
+		HostName: host,
+		ExitCode: exitCode,
+		Output:   formatOutput(summary, perfData),
+	})
+}
+
+// Len returns the number of pending results.
+func (e *Emitter) Len() int {
+	return e.batcher.Len()
+}
+
+// Submit submits all pending results as one batch to each configured sink.
+// Pending results are retained if submission to any sink fails so that
+// they may be retried; sinks which accepted the batch receive the results
+// again in that case. Results queued by a sink for a later attempt (see
+// NewReliableSink) are considered accepted. The returned error joins the
+// errors of all failed sinks. An error wrapping ErrNoSinks is returned if
+// no sinks are configured.
+func (e *Emitter) Submit(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.sinks) == 0 {
+		return ErrNoSinks
+	}
+
+	e.ctx = ctx
+	defer func() { e.ctx = nil }()
+
+	return e.batcher.Flush()
+}
+
+// submit submits the given batch to each configured sink. Errors wrapping
+// transport.ErrPayloadSpooled are only propagated as such if every failed
+// sink queued the results, so that the batcher retains results rejected by
+// any sink.
+func (e *Emitter) submit(results []CheckResult) error {
+	var (
+		failed []int
+		errs   []error
+		retain bool
+	)
+
+	for i, sink := range e.sinks {
+		if err := sink.Submit(e.ctx, results); err != nil {
+			failed = append(failed, i)
+			errs = append(errs, err)
+
+			if !errors.Is(err, transport.ErrPayloadSpooled) {
+				retain = true
+			}
+		}
+	}
+
+	for j, err := range errs {
+		switch {
+		case retain && errors.Is(err, transport.ErrPayloadSpooled):
+			errs[j] = fmt.Errorf("sink %d: %v", failed[j], err)
+		default:
+			errs[j] = fmt.Errorf("sink %d: %w", failed[j], err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// formatOutput formats the given one-line summary and performance data as
+// plugin output.
+func formatOutput(summary string, perfData []nagios.PerformanceData) string {
+	if len(perfData) == 0 {
+		return summary
+	}
+
+	metrics := make([]string, 0, len(perfData))
+	for _, pd := range perfData {
+		metrics = append(metrics, strings.TrimSpace(pd.String()))
+	}
+
+	return joinPerfData(summary, strings.Join(metrics, " "))
+}
@@ -0,0 +1,266 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// NRDP check result types.
+const (
+	nrdpResultTypeHost    string = "host"
+	nrdpResultTypeService string = "service"
+)
+
+// nrdpResultType returns the NRDP check result type for the given result.
+func nrdpResultType(r CheckResult) string {
+	if r.IsHost() {
+		return nrdpResultTypeHost
+	}
+
+	return nrdpResultTypeService
+}
+
+// Characters which may not appear in the host name or service description
+// of a result encoded for line oriented submission formats, as they would
+// inject additional fields or commands.
+const (
+	nscaInvalidFieldChars        string = "\r\n\t"
+	commandFileInvalidFieldChars string = "\r\n;"
+)
+
+// ErrInvalidResultField indicates that the host name or service description
+// of a result contains characters which cannot be represented by the
+// submission format (e.g., a newline or field delimiter).
+var ErrInvalidResultField = errors.New("invalid character in check result field")
+
+// escapeNewlines replaces literal newlines (and carriage returns) with the
+// escaped form expected by line oriented submission formats.
+func escapeNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", `\n`)
+	s = strings.ReplaceAll(s, "\r", `\n`)
+
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+// validateFields returns an error wrapping ErrInvalidResultField if the host
+// name or service description of the given result contain any of the given
+// invalid characters.
+func validateFields(r CheckResult, invalidChars string) error {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{name: "host name", value: r.HostName},
+		{name: "service description", value: r.ServiceDescription},
+	}
+
+	for _, field := range fields {
+		if strings.ContainsAny(field.value, invalidChars) {
+			return fmt.Errorf("%w: %s %q", ErrInvalidResultField, field.name, field.value)
+		}
+	}
+
+	return nil
+}
+
+// EncodeNSCA encodes the given results in the tab delimited format read by
+// send_nsca, one result per line. Host results omit the service field.
+// Newlines in the output are escaped. An error wrapping
+// ErrInvalidResultField is returned if a host name or service description
+// contains a tab or newline.
+//
+//	<host>\t<service>\t<state>\t<output>\n
+//	<host>\t<state>\t<output>\n
+func EncodeNSCA(results ...CheckResult) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, r := range results {
+		if err := validateFields(r, nscaInvalidFieldChars); err != nil {
+			return nil, err
+		}
+
+		if r.IsHost() {
+			fmt.Fprintf(&buf,
+				"%s\t%d\t%s\n",
+				r.HostName,
+				r.ExitCode,
+				escapeNewlines(r.Output),
+			)
+
+			continue
+		}
+
+		fmt.Fprintf(&buf,
+			"%s\t%s\t%d\t%s\n",
+			r.HostName,
+			r.ServiceDescription,
+			r.ExitCode,
+			escapeNewlines(r.Output),
+		)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeCommandFile encodes the given results as Nagios external commands
+// suitable for writing to the external command file, one command per line.
+// Newlines in the output are escaped. An error wrapping
+// ErrInvalidResultField is returned if a host name or service description
+// contains a semicolon or newline.
+//
+//	[<timestamp>] PROCESS_SERVICE_CHECK_RESULT;<host>;<service>;<state>;<output>
+//	[<timestamp>] PROCESS_HOST_CHECK_RESULT;<host>;<state>;<output>
+func EncodeCommandFile(now time.Time, results ...CheckResult) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, r := range results {
+		if err := validateFields(r, commandFileInvalidFieldChars); err != nil {
+			return nil, err
+		}
+
+		if r.IsHost() {
+			fmt.Fprintf(&buf,
+				"[%d] PROCESS_HOST_CHECK_RESULT;%s;%d;%s\n",
+				now.Unix(),
+				r.HostName,
+				r.ExitCode,
+				escapeNewlines(r.Output),
+			)
+
+			continue
+		}
+
+		fmt.Fprintf(&buf,
+			"[%d] PROCESS_SERVICE_CHECK_RESULT;%s;%s;%d;%s\n",
+			now.Unix(),
+			r.HostName,
+			r.ServiceDescription,
+			r.ExitCode,
+			escapeNewlines(r.Output),
+		)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// nrdpXMLCheckResults is the XML document submitted to NRDP.
+type nrdpXMLCheckResults struct {
+	XMLName xml.Name             `xml:"checkresults"`
+	Results []nrdpXMLCheckResult `xml:"checkresult"`
+}
+
+// nrdpXMLCheckResult is a single check result within an NRDP XML document.
+type nrdpXMLCheckResult struct {
+	Type        string `xml:"type,attr"`
+	HostName    string `xml:"hostname"`
+	ServiceName string `xml:"servicename,omitempty"`
+	State       int    `xml:"state"`
+	Output      string `xml:"output"`
+}
+
+// EncodeNRDPXML encodes the given results as an NRDP XML document (the
+// XMLDATA form value).
+func EncodeNRDPXML(results ...CheckResult) ([]byte, error) {
+	doc := nrdpXMLCheckResults{
+		Results: make([]nrdpXMLCheckResult, 0, len(results)),
+	}
+
+	for _, r := range results {
+		doc.Results = append(doc.Results, nrdpXMLCheckResult{
+			Type:        nrdpResultType(r),
+			HostName:    r.HostName,
+			ServiceName: r.ServiceDescription,
+			State:       r.ExitCode,
+			Output:      r.Output,
+		})
+	}
+
+	data, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode NRDP XML payload: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// nrdpJSONCheckResults is the JSON document submitted to NRDP.
+type nrdpJSONCheckResults struct {
+	Results []nrdpJSONCheckResult `json:"checkresults"`
+}
+
+// nrdpJSONCheckResult is a single check result within an NRDP JSON
+// document.
+type nrdpJSONCheckResult struct {
+	CheckResult struct {
+		Type string `json:"type"`
+	} `json:"checkresult"`
+	HostName    string `json:"hostname"`
+	ServiceName string `json:"servicename,omitempty"`
+	State       string `json:"state"`
+	Output      string `json:"output"`
+}
+
+// EncodeNRDPJSON encodes the given results as an NRDP JSON document (the
+// JSONDATA form value).
+func EncodeNRDPJSON(results ...CheckResult) ([]byte, error) {
+	doc := nrdpJSONCheckResults{
+		Results: make([]nrdpJSONCheckResult, 0, len(results)),
+	}
+
+	for _, r := range results {
+		result := nrdpJSONCheckResult{
+			HostName:    r.HostName,
+			ServiceName: r.ServiceDescription,
+			State:       fmt.Sprint(r.ExitCode),
+			Output:      r.Output,
+		}
+		result.CheckResult.Type = nrdpResultType(r)
+
+		doc.Results = append(doc.Results, result)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode NRDP JSON payload: %w", err)
+	}
+
+	return data, nil
+}
+
+// WriteCommandFile writes the given results as external commands to the
+// Nagios external command file (named pipe) at the given path. The file is
+// not created if it does not exist, as this indicates that external commands
+// are disabled or that the path is incorrect.
+func WriteCommandFile(path string, now time.Time, results ...CheckResult) error {
+	payload, err := EncodeCommandFile(now, results...)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open external command file: %w", err)
+	}
+
+	if _, err := f.Write(payload); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write to external command file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close external command file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// nrdpSubmitCommand is the NRDP command used to submit check results.
+	nrdpSubmitCommand string = "submitcheck"
+
+	// maxNRDPResponseSize is the maximum number of bytes of an NRDP response
+	// read.
+	maxNRDPResponseSize int64 = 64 * 1024
+)
+
+// ErrSubmissionFailed indicates that a monitoring system rejected a batch
+// of results.
+var ErrSubmissionFailed = errors.New("check result submission failed")
+
+// NRDPSink submits results in bulk to an NRDP endpoint as an XML document
+// (see EncodeNRDPXML).
+type NRDPSink struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// nrdpResponse is the XML response of an NRDP submission.
+type nrdpResponse struct {
+	Status  int    `xml:"status"`
+	Message string `xml:"message"`
+}
+
+// NRDPOption is a functional option used to configure an NRDPSink value
+// when constructed via NewNRDPSink.
+type NRDPOption func(*NRDPSink)
+
+// WithToken is an NRDPOption used to specify the token used to authenticate
+// submissions.
+func WithToken(token string) NRDPOption {
+	return func(s *NRDPSink) {
+		s.token = token
+	}
+}
+
+// WithHTTPClient is an NRDPOption used to specify the HTTP client used to
+// submit results (e.g., one configured with a CA bundle via the transport
+// package). If not specified, http.DefaultClient is used.
+func WithHTTPClient(httpClient *http.Client) NRDPOption {
+	return func(s *NRDPSink) {
+		if httpClient != nil {
+			s.httpClient = httpClient
+		}
+	}
+}
+
+// NewNRDPSink returns an NRDPSink which submits results to the NRDP
+// endpoint at the given URL (e.g., "https://nagios.example.com/nrdp/").
+// Default settings are used unless overridden by the given options (e.g.,
+// WithToken).
+func NewNRDPSink(endpoint string, options ...NRDPOption) *NRDPSink {
+	s := NRDPSink{
+		url:        endpoint,
+		httpClient: http.DefaultClient,
+	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s
+}
+
+// Submit submits the given results as a single NRDP request. An error
+// wrapping ErrSubmissionFailed is returned if NRDP rejects the request.
+func (s *NRDPSink) Submit(ctx context.Context, results []CheckResult) error {
+	payload, err := EncodeNRDPXML(results...)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"token":   {s.token},
+		"cmd":     {nrdpSubmitCommand},
+		"XMLDATA": {string(payload)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxNRDPResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read NRDP response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s: %s", ErrSubmissionFailed, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var result nrdpResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to decode NRDP response: %w", err)
+	}
+
+	if result.Status != 0 {
+		return fmt.Errorf("%w: NRDP status %d: %s", ErrSubmissionFailed, result.Status, result.Message)
+	}
+
+	return nil
+}
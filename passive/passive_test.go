@@ -0,0 +1,728 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/passive"
+	"github.com/atc0005/go-nagios/transport"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParseOCSPArgs asserts that OCSP command arguments are parsed using
+// either state IDs or state labels.
+func TestParseOCSPArgs(t *testing.T) {
+	t.Parallel()
+
+	want := passive.CheckResult{
+		HostName:           "mail01",
+		ServiceDescription: "SMTP",
+		ExitCode:           nagios.StateCRITICALExitCode,
+		Output:             "CRITICAL: connection refused | time=10.0s;;;",
+	}
+
+	for _, state := range []string{"2", "CRITICAL"} {
+		got, err := passive.ParseOCSPArgs([]string{
+			"mail01", "SMTP", state, "CRITICAL: connection refused", "time=10.0s;;;",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if d := cmp.Diff(want, got); d != "" {
+			t.Errorf("(-want, +got)\n:%s", d)
+		}
+	}
+
+	if _, err := passive.ParseOCSPArgs([]string{"mail01", "SMTP", "7", "output"}); !errors.Is(err, passive.ErrInvalidState) {
+		t.Errorf("want error wrapping %v, got %v", passive.ErrInvalidState, err)
+	}
+}
+
+// TestEncodeServiceResults asserts that service results are encoded in each
+// supported submission format.
+func TestEncodeServiceResults(t *testing.T) {
+	t.Parallel()
+
+	result := passive.CheckResult{
+		HostName:           "mail01",
+		ServiceDescription: "SMTP",
+		ExitCode:           nagios.StateWARNINGExitCode,
+		Output:             "WARNING: slow response\nline two",
+	}
+
+	gotNSCA, err := passive.EncodeNSCA(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantNSCA := "mail01\tSMTP\t1\tWARNING: slow response\\nline two\n"
+	if d := cmp.Diff(wantNSCA, string(gotNSCA)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	wantCmd := "[1600000000] PROCESS_SERVICE_CHECK_RESULT;mail01;SMTP;1;WARNING: slow response\\nline two\n"
+	gotCmd, err := passive.EncodeCommandFile(time.Unix(1600000000, 0), result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := cmp.Diff(wantCmd, string(gotCmd)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	gotXML, err := passive.EncodeNRDPXML(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantXML := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<checkresults><checkresult type="service"><hostname>mail01</hostname>` +
+		`<servicename>SMTP</servicename><state>1</state>` +
+		`<output>WARNING: slow response&#xA;line two</output></checkresult></checkresults>`
+	if d := cmp.Diff(wantXML, string(gotXML)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	gotJSON, err := passive.EncodeNRDPJSON(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantJSON := `{"checkresults":[{"checkresult":{"type":"service"},"hostname":"mail01",` +
+		`"servicename":"SMTP","state":"1","output":"WARNING: slow response\nline two"}]}`
+	if d := cmp.Diff(wantJSON, string(gotJSON)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestEncodeRejectsFieldInjection asserts that host names and service
+// descriptions which would inject additional fields or commands are
+// rejected by line oriented encodings, that carriage returns in output are
+// escaped and that structured encodings retain the values as-is.
+func TestEncodeRejectsFieldInjection(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1600000000, 0)
+
+	encoders := map[string]func(passive.CheckResult) ([]byte, error){
+		"nsca": func(r passive.CheckResult) ([]byte, error) {
+			return passive.EncodeNSCA(r)
+		},
+		"cmdfile": func(r passive.CheckResult) ([]byte, error) {
+			return passive.EncodeCommandFile(now, r)
+		},
+		"nrdp-xml": func(r passive.CheckResult) ([]byte, error) {
+			return passive.EncodeNRDPXML(r)
+		},
+		"nrdp-json": func(r passive.CheckResult) ([]byte, error) {
+			return passive.EncodeNRDPJSON(r)
+		},
+	}
+
+	tests := []struct {
+		name    string
+		result  passive.CheckResult
+		wantErr map[string]bool
+		want    map[string]string
+	}{
+		{
+			name:    "newline in host name",
+			result:  passive.CheckResult{HostName: "web01\nPROCESS_HOST_CHECK_RESULT;db01;0;OK", Output: "OK"},
+			wantErr: map[string]bool{"nsca": true, "cmdfile": true},
+		},
+		{
+			name:    "carriage return in service description",
+			result:  passive.CheckResult{HostName: "web01", ServiceDescription: "disk\r", Output: "OK"},
+			wantErr: map[string]bool{"nsca": true, "cmdfile": true},
+		},
+		{
+			name:    "semicolon in service description",
+			result:  passive.CheckResult{HostName: "web01", ServiceDescription: "disk;0;OK", Output: "OK"},
+			wantErr: map[string]bool{"cmdfile": true},
+			want: map[string]string{
+				"nsca": "web01\tdisk;0;OK\t0\tOK\n",
+			},
+		},
+		{
+			name:    "tab in host name",
+			result:  passive.CheckResult{HostName: "web01\t0", ServiceDescription: "disk", Output: "OK"},
+			wantErr: map[string]bool{"nsca": true},
+			want: map[string]string{
+				"cmdfile": "[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01\t0;disk;0;OK\n",
+			},
+		},
+		{
+			name:   "carriage return in output",
+			result: passive.CheckResult{HostName: "web01", ServiceDescription: "disk", Output: "OK\rline two"},
+			want: map[string]string{
+				"nsca":      "web01\tdisk\t0\tOK\\nline two\n",
+				"cmdfile":   "[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01;disk;0;OK\\nline two\n",
+				"nrdp-json": `{"checkresults":[{"checkresult":{"type":"service"},"hostname":"web01","servicename":"disk","state":"0","output":"OK\rline two"}]}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for name, encode := range encoders {
+			got, err := encode(tt.result)
+
+			switch {
+			case tt.wantErr[name]:
+				if !errors.Is(err, passive.ErrInvalidResultField) {
+					t.Errorf("%s (%s): want error wrapping %v, got %v", tt.name, name, passive.ErrInvalidResultField, err)
+				}
+
+				continue
+			case err != nil:
+				t.Errorf("%s (%s): unexpected error: %v", tt.name, name, err)
+				continue
+			}
+
+			if want, ok := tt.want[name]; ok {
+				if d := cmp.Diff(want, string(got)); d != "" {
+					t.Errorf("%s (%s): (-want, +got)\n:%s", tt.name, name, d)
+				}
+			}
+		}
+	}
+}
+
+// TestBatcherFlushesAtSize asserts that results are submitted once the
+// batch size is reached and that remaining results are submitted by Flush.
+func TestBatcherFlushesAtSize(t *testing.T) {
+	t.Parallel()
+
+	var batches [][]passive.CheckResult
+	batcher, err := passive.NewBatcher(2, func(results []passive.CheckResult) error {
+		batches = append(batches, results)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to create batcher: %v", err)
+	}
+
+	for _, host := range []string{"a", "b", "c"} {
+		if err := batcher.Add(passive.CheckResult{HostName: host, ServiceDescription: "ping"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(batches) != 1 || batcher.Len() != 1 {
+		t.Fatalf("want 1 submitted batch and 1 pending result, got %d and %d", len(batches), batcher.Len())
+	}
+
+	if err := batcher.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(batches) != 2 || len(batches[1]) != 1 || batches[1][0].HostName != "c" {
+		t.Errorf("unexpected batches: %+v", batches)
+	}
+}
+
+// TestBatcherRetainsUnsentResultsOnFailure asserts that results which could
+// not be submitted are retained (and none are lost) when submission fails
+// while adding more results than the batch size.
+func TestBatcherRetainsUnsentResultsOnFailure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		batches [][]passive.CheckResult
+		calls   int
+	)
+
+	failing := true
+	batcher, err := passive.NewBatcher(2, func(results []passive.CheckResult) error {
+		calls++
+
+		// Accept the first batch and reject the next until recovered.
+		if failing && calls > 1 {
+			return errors.New("connection refused")
+		}
+
+		batches = append(batches, results)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to create batcher: %v", err)
+	}
+
+	var results []passive.CheckResult
+	for _, host := range []string{"a", "b", "c", "d", "e"} {
+		results = append(results, passive.CheckResult{HostName: host, ServiceDescription: "ping"})
+	}
+
+	if err := batcher.Add(results...); err == nil {
+		t.Fatal("want error from failing submission")
+	}
+
+	if len(batches) != 1 || batcher.Len() != 3 {
+		t.Fatalf("want 1 submitted batch and 3 pending results, got %d and %d", len(batches), batcher.Len())
+	}
+
+	failing = false
+	if err := batcher.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, batch := range batches {
+		if len(batch) > 2 {
+			t.Errorf("want batches of at most 2 results, got %d", len(batch))
+		}
+
+		for _, r := range batch {
+			got = append(got, r.HostName)
+		}
+	}
+
+	if d := cmp.Diff([]string{"a", "b", "c", "d", "e"}, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestEncodeHostResults asserts that host results are parsed and encoded
+// using the host variants of each supported submission format.
+func TestEncodeHostResults(t *testing.T) {
+	t.Parallel()
+
+	result, err := passive.ParseOCHPArgs([]string{"mail01", "DOWN", "PING CRITICAL - Packet loss = 100%"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.IsHost() || result.ExitCode != passive.HostStateDOWNExitCode {
+		t.Fatalf("unexpected host result: %+v", result)
+	}
+
+	gotNSCA, err := passive.EncodeNSCA(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantNSCA := "mail01\t1\tPING CRITICAL - Packet loss = 100%\n"
+	if d := cmp.Diff(wantNSCA, string(gotNSCA)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	wantCmd := "[1600000000] PROCESS_HOST_CHECK_RESULT;mail01;1;PING CRITICAL - Packet loss = 100%\n"
+	cmdFile := filepath.Join(t.TempDir(), "nagios.cmd")
+	if err := os.WriteFile(cmdFile, nil, 0o600); err != nil {
+		t.Fatalf("failed to create command file: %v", err)
+	}
+	if err := passive.WriteCommandFile(cmdFile, time.Unix(1600000000, 0), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gotCmd, err := os.ReadFile(cmdFile)
+	if err != nil {
+		t.Fatalf("failed to read command file: %v", err)
+	}
+	if d := cmp.Diff(wantCmd, string(gotCmd)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	gotXML, err := passive.EncodeNRDPXML(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(gotXML), `<checkresult type="host"><hostname>mail01</hostname><state>1</state>`) {
+		t.Errorf("unexpected NRDP XML payload: %s", gotXML)
+	}
+
+	gotJSON, err := passive.EncodeNRDPJSON(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(gotJSON), `{"checkresult":{"type":"host"},"hostname":"mail01","state":"1"`) {
+		t.Errorf("unexpected NRDP JSON payload: %s", gotJSON)
+	}
+}
+
+// TestEmitterSubmitsBatchToEachSink asserts that results for many
+// host/service pairs are submitted as a single batch to each sink and
+// retained if a sink fails.
+func TestEmitterSubmitsBatchToEachSink(t *testing.T) {
+	t.Parallel()
+
+	var batches [][]passive.CheckResult
+	recorder := passive.SinkFunc(func(_ context.Context, results []passive.CheckResult) error {
+		batches = append(batches, results)
+		return nil
+	})
+
+	failing := true
+	flaky := passive.SinkFunc(func(context.Context, []passive.CheckResult) error {
+		if failing {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	emitter := passive.NewEmitter(recorder, flaky)
+
+	emitter.AddService("node01", "disk", nagios.StateCRITICALExitCode, "CRITICAL: / 98% used",
+		nagios.PerformanceData{Label: "/", Value: "98", UnitOfMeasurement: "%", Crit: "90"},
+	)
+	emitter.AddService("node02", "disk", nagios.StateOKExitCode, "OK: / 40% used")
+	emitter.AddHost("node03", passive.HostStateDOWNExitCode, "DOWN: no route to host")
+
+	if err := emitter.Submit(context.Background()); err == nil {
+		t.Fatal("want error from failing sink")
+	}
+
+	if emitter.Len() != 3 {
+		t.Errorf("want 3 results retained after failure, got %d", emitter.Len())
+	}
+
+	failing = false
+	if err := emitter.Submit(context.Background()); err != nil {
+		t.Fatalf("failed to submit: %v", err)
+	}
+
+	if emitter.Len() != 0 {
+		t.Errorf("want no pending results after submission, got %d", emitter.Len())
+	}
+
+	want := []passive.CheckResult{
+		{
+			HostName:           "node01",
+			ServiceDescription: "disk",
+			ExitCode:           nagios.StateCRITICALExitCode,
+			Output:             "CRITICAL: / 98% used | '/'=98%;;90;;",
+		},
+		{HostName: "node02", ServiceDescription: "disk", ExitCode: nagios.StateOKExitCode, Output: "OK: / 40% used"},
+		{HostName: "node03", ExitCode: passive.HostStateDOWNExitCode, Output: "DOWN: no route to host"},
+	}
+
+	if len(batches) != 2 {
+		t.Fatalf("want 2 batches submitted, got %d", len(batches))
+	}
+
+	if d := cmp.Diff(want, batches[1]); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestEmitterRequiresSinks asserts that submitting without sinks fails.
+func TestEmitterRequiresSinks(t *testing.T) {
+	t.Parallel()
+
+	if err := passive.NewEmitter().Submit(context.Background()); !errors.Is(err, passive.ErrNoSinks) {
+		t.Errorf("want error %v, got %v", passive.ErrNoSinks, err)
+	}
+}
+
+// TestNRDPSinkSubmitsXMLBatch asserts that results are posted to NRDP as a
+// single XML document and that rejected submissions are reported.
+func TestNRDPSinkSubmitsXMLBatch(t *testing.T) {
+	t.Parallel()
+
+	var submissions []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("cmd") != "submitcheck" {
+			http.Error(w, "bad command", http.StatusBadRequest)
+			return
+		}
+
+		if r.FormValue("token") != "s3cret" {
+			_, _ = w.Write([]byte(`<result><status>-1</status><message>BAD TOKEN</message></result>`))
+			return
+		}
+
+		submissions = append(submissions, r.FormValue("XMLDATA"))
+		_, _ = w.Write([]byte(`<result><status>0</status><message>OK</message></result>`))
+	}))
+	t.Cleanup(server.Close)
+
+	results := []passive.CheckResult{
+		{HostName: "node01", ServiceDescription: "disk", ExitCode: nagios.StateOKExitCode, Output: "OK"},
+		{HostName: "node02", ServiceDescription: "disk", ExitCode: nagios.StateOKExitCode, Output: "OK"},
+	}
+
+	sink := passive.NewNRDPSink(server.URL, passive.WithToken("s3cret"), passive.WithHTTPClient(server.Client()))
+	if err := sink.Submit(context.Background(), results); err != nil {
+		t.Fatalf("failed to submit: %v", err)
+	}
+
+	if len(submissions) != 1 || strings.Count(submissions[0], "<checkresult ") != 2 {
+		t.Errorf("want a single document with 2 results, got %q", submissions)
+	}
+
+	rejected := passive.NewNRDPSink(server.URL, passive.WithToken("wrong"), passive.WithHTTPClient(server.Client()))
+	if err := rejected.Submit(context.Background(), results); !errors.Is(err, passive.ErrSubmissionFailed) {
+		t.Errorf("want error %v, got %v", passive.ErrSubmissionFailed, err)
+	}
+}
+
+// TestResultSinkSubmitsPluginResult asserts that plugin results emitted via
+// a result sink are submitted as passive check results.
+func TestResultSinkSubmitsPluginResult(t *testing.T) {
+	t.Parallel()
+
+	var got []passive.CheckResult
+
+	sink := passive.NewResultSink(
+		passive.SinkFunc(func(_ context.Context, results []passive.CheckResult) error {
+			got = append(got, results...)
+			return nil
+		}),
+		"web01",
+		"disk",
+	)
+
+	err := sink.Emit(nagios.Result{
+		ExitCode:          nagios.StateWARNINGExitCode,
+		ServiceOutput:     "WARNING: / 85% used",
+		LongServiceOutput: "filesystem: ext4",
+		PerfData:          []nagios.PerformanceData{{Label: "/", Value: "85", UnitOfMeasurement: "%"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to emit result: %v", err)
+	}
+
+	want := []passive.CheckResult{{
+		HostName:           "web01",
+		ServiceDescription: "disk",
+		ExitCode:           nagios.StateWARNINGExitCode,
+		Output:             "WARNING: / 85% used | '/'=85%;;;;\nfilesystem: ext4",
+	}}
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestParseSinkCommandFile asserts that command file sink specifications
+// write plugin results to the external command file.
+func TestParseSinkCommandFile(t *testing.T) {
+	t.Parallel()
+
+	// The external command file is created by Nagios.
+	path := filepath.Join(t.TempDir(), "nagios.cmd")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("failed to create command file: %v", err)
+	}
+
+	sink, err := nagios.ParseSink(passive.SinkSchemeCommandFile + "://" + path + "?host=web01&service=disk")
+	if err != nil {
+		t.Fatalf("failed to parse sink: %v", err)
+	}
+
+	if err := sink.Emit(nagios.Result{ExitCode: 2, ServiceOutput: "CRITICAL: down"}); err != nil {
+		t.Fatalf("failed to emit result: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read command file: %v", err)
+	}
+
+	if want := "PROCESS_SERVICE_CHECK_RESULT;web01;disk;2;CRITICAL: down\n"; !strings.HasSuffix(string(got), want) {
+		t.Errorf("want command file to end with %q, got %q", want, got)
+	}
+}
+
+// TestParseSinkRequiresNRDPToken asserts that NRDP sink specifications
+// without a token are rejected.
+func TestParseSinkRequiresNRDPToken(t *testing.T) {
+	t.Parallel()
+
+	if _, err := nagios.ParseSink(passive.SinkSchemeNRDPHTTPS + "://nagios.example.com/nrdp/"); err == nil {
+		t.Error("want error for NRDP sink without token, got nil")
+	}
+}
+
+// TestParseSinkErrorsRedactNRDPToken asserts that the NRDP token is not
+// included in sink specification errors.
+func TestParseSinkErrorsRedactNRDPToken(t *testing.T) {
+	t.Parallel()
+
+	// A spool directory below a regular file cannot be created.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	spec := passive.SinkSchemeNRDPHTTPS + "://nagios.example.com/nrdp/?token=s3cr3t&" +
+		passive.SinkParamSpool + "=" + url.QueryEscape(filepath.Join(file, "spool"))
+
+	_, err := nagios.ParseSink(spec)
+	if err == nil {
+		t.Fatal("want error for invalid spool directory")
+	}
+
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("want error without NRDP token, got %q", err)
+	}
+}
+
+// TestParseSinkSpoolsUndeliveredResults asserts that NRDP sink
+// specifications with a spool directory queue results while the endpoint
+// is unavailable and submit them (oldest first) once it is available.
+func TestParseSinkSpoolsUndeliveredResults(t *testing.T) {
+	t.Parallel()
+
+	var (
+		available   atomic.Bool
+		submissions []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+
+		submissions = append(submissions, r.FormValue("XMLDATA"))
+		_, _ = w.Write([]byte(`<result><status>0</status><message>OK</message></result>`))
+	}))
+	t.Cleanup(server.Close)
+
+	spec := passive.SinkSchemeNRDPHTTP + strings.TrimPrefix(server.URL, "http") +
+		"/nrdp/?token=s3cret&host=web01&service=disk&spool=" + url.QueryEscape(t.TempDir())
+
+	sink, err := nagios.ParseSink(spec)
+	if err != nil {
+		t.Fatalf("failed to parse sink: %v", err)
+	}
+
+	err = sink.Emit(nagios.Result{ExitCode: nagios.StateCRITICALExitCode, ServiceOutput: "CRITICAL: first"})
+	if !errors.Is(err, transport.ErrPayloadSpooled) {
+		t.Fatalf("want error wrapping %v, got %v", transport.ErrPayloadSpooled, err)
+	}
+
+	available.Store(true)
+
+	if err := sink.Emit(nagios.Result{ExitCode: nagios.StateOKExitCode, ServiceOutput: "OK: second"}); err != nil {
+		t.Fatalf("failed to emit result: %v", err)
+	}
+
+	if len(submissions) != 2 ||
+		!strings.Contains(submissions[0], "CRITICAL: first") ||
+		!strings.Contains(submissions[1], "OK: second") {
+		t.Errorf("want queued result submitted before new result, got %q", submissions)
+	}
+}
+
+// TestEmitterTreatsSpooledResultsAsAccepted asserts that results queued by
+// a reliable sink are not retained by the Emitter.
+func TestEmitterTreatsSpooledResultsAsAccepted(t *testing.T) {
+	t.Parallel()
+
+	spool, err := transport.NewSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	failing := passive.SinkFunc(func(context.Context, []passive.CheckResult) error {
+		return errors.New("endpoint unreachable")
+	})
+
+	emitter := passive.NewEmitter(passive.NewReliableSink(failing, passive.WithSpool(spool)))
+	emitter.AddService("web01", "disk", nagios.StateOKExitCode, "OK")
+
+	if err := emitter.Submit(context.Background()); !errors.Is(err, transport.ErrPayloadSpooled) {
+		t.Fatalf("want error wrapping %v, got %v", transport.ErrPayloadSpooled, err)
+	}
+
+	if got := emitter.Len(); got != 0 {
+		t.Errorf("want no pending results, got %d", got)
+	}
+
+	if got, err := spool.Len(); err != nil || got != 1 {
+		t.Errorf("want 1 queued entry, got %d (err: %v)", got, err)
+	}
+}
+
+// TestEmitterRetainsResultsRejectedBySink asserts that results are retained
+// if any sink rejects them, even if another sink queued them for a later
+// attempt.
+func TestEmitterRetainsResultsRejectedBySink(t *testing.T) {
+	t.Parallel()
+
+	spool, err := transport.NewSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	failing := passive.SinkFunc(func(context.Context, []passive.CheckResult) error {
+		return errors.New("endpoint unreachable")
+	})
+
+	emitter := passive.NewEmitter(passive.NewReliableSink(failing, passive.WithSpool(spool)), failing)
+	emitter.AddService("web01", "disk", nagios.StateOKExitCode, "OK")
+
+	if err := emitter.Submit(context.Background()); err == nil {
+		t.Fatal("want error from failing sink")
+	}
+
+	if got := emitter.Len(); got != 1 {
+		t.Errorf("want 1 pending result, got %d", got)
+	}
+}
+
+// TestReliableSinkRecordsSuppressedSubmissions asserts that submissions
+// short-circuited by an open circuit breaker are not attempted and are
+// noted in plugin output.
+func TestReliableSinkRecordsSuppressedSubmissions(t *testing.T) {
+	t.Parallel()
+
+	breaker, err := transport.NewCircuitBreaker(transport.WithFailureThreshold(1))
+	if err != nil {
+		t.Fatalf("failed to create circuit breaker: %v", err)
+	}
+
+	var attempts int
+	failing := passive.SinkFunc(func(context.Context, []passive.CheckResult) error {
+		attempts++
+		return errors.New("endpoint unreachable")
+	})
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin()
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.ServiceOutput = "OK: collected 2 results"
+	plugin.ExitStatusCode = nagios.StateOKExitCode
+
+	sink := passive.NewReliableSink(failing, passive.WithBreaker(breaker), passive.WithPlugin(plugin))
+	results := []passive.CheckResult{{HostName: "web01", ExitCode: nagios.StateOKExitCode, Output: "OK"}}
+
+	if err := sink.Submit(context.Background(), results); err == nil || errors.Is(err, transport.ErrCircuitOpen) {
+		t.Fatalf("want submission failure, got %v", err)
+	}
+
+	if err := sink.Submit(context.Background(), results); !errors.Is(err, transport.ErrCircuitOpen) {
+		t.Fatalf("want error wrapping %v, got %v", transport.ErrCircuitOpen, err)
+	}
+
+	if attempts != 1 {
+		t.Errorf("want 1 submission attempt, got %d", attempts)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+	want := "Passive result submission suppressed: circuit OPEN after 1 consecutive failures"
+	if !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// Host state exit codes used when submitting passive host check results.
+// These correspond to the $HOSTSTATEID$ macro values.
+const (
+	HostStateUPExitCode          int = 0
+	HostStateDOWNExitCode        int = 1
+	HostStateUNREACHABLEExitCode int = 2
+)
+
+// Host state labels corresponding to the $HOSTSTATE$ macro values.
+const (
+	HostStateUPLabel          string = "UP"
+	HostStateDOWNLabel        string = "DOWN"
+	HostStateUNREACHABLELabel string = "UNREACHABLE"
+)
+
+var (
+	// ErrMissingArguments indicates that fewer than the required number of
+	// command arguments were provided.
+	ErrMissingArguments = errors.New("missing command arguments")
+
+	// ErrInvalidState indicates that an unrecognized state value was
+	// provided.
+	ErrInvalidState = errors.New("invalid state")
+)
+
+// CheckResult is a passive check result for submission to a monitoring
+// system. Results with an empty ServiceDescription are host check results.
+type CheckResult struct {
+	// HostName is the name of the host associated with the result.
+	HostName string
+
+	// ServiceDescription is the description of the service associated with
+	// the result. This is empty for host check results.
+	ServiceDescription string
+
+	// ExitCode is the plugin exit status code (e.g.,
+	// nagios.StateCRITICALExitCode) for service results or the host state
+	// (e.g., HostStateDOWNExitCode) for host results.
+	ExitCode int
+
+	// Output is the plugin output. Any performance data is included after a
+	// pipe character.
+	Output string
+}
+
+// ParseOCSPArgs parses obsessive compulsive service processor command
+// arguments provided in the conventional order: host name, service
+// description, state and output, optionally followed by performance data.
+//
+//	command_line $USER1$/ocsp $HOSTNAME$ '$SERVICEDESC$' $SERVICESTATEID$ '$SERVICEOUTPUT$' '$SERVICEPERFDATA$'
+//
+// The state may be given as either a numeric state ID ($SERVICESTATEID$) or
+// a state label ($SERVICESTATE$). The program name should not be included
+// (e.g., pass os.Args[1:]).
+func ParseOCSPArgs(args []string) (CheckResult, error) {
+	if len(args) < 4 {
+		return CheckResult{}, fmt.Errorf(
+			"%w: want host name, service description, state and output, got %d argument(s)",
+			ErrMissingArguments,
+			len(args),
+		)
+	}
+
+	exitCode, err := parseState(args[2])
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	return CheckResult{
+		HostName:           args[0],
+		ServiceDescription: args[1],
+		ExitCode:           exitCode,
+		Output:             joinPerfData(args[3], args[4:]...),
+	}, nil
+}
+
+// ParseOCHPArgs parses obsessive compulsive host processor command
+// arguments provided in the conventional order: host name, state and
+// output, optionally followed by performance data.
+//
+//	command_line $USER1$/ochp $HOSTNAME$ $HOSTSTATEID$ '$HOSTOUTPUT$' '$HOSTPERFDATA$'
+//
+// The state may be given as either a numeric state ID ($HOSTSTATEID$) or a
+// state label ($HOSTSTATE$). The program name should not be included (e.g.,
+// pass os.Args[1:]).
+func ParseOCHPArgs(args []string) (CheckResult, error) {
+	if len(args) < 3 {
+		return CheckResult{}, fmt.Errorf(
+			"%w: want host name, state and output, got %d argument(s)",
+			ErrMissingArguments,
+			len(args),
+		)
+	}
+
+	exitCode, err := parseHostState(args[1])
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	return CheckResult{
+		HostName: args[0],
+		ExitCode: exitCode,
+		Output:   joinPerfData(args[2], args[3:]...),
+	}, nil
+}
+
+// IsHost indicates whether the result is a host check result.
+func (r CheckResult) IsHost() bool {
+	return r.ServiceDescription == ""
+}
+
+// parseState converts the given state ID or state label to a plugin exit
+// status code.
+func parseState(state string) (int, error) {
+	state = strings.TrimSpace(state)
+
+	if id, err := strconv.Atoi(state); err == nil {
+		switch id {
+		case nagios.StateOKExitCode,
+			nagios.StateWARNINGExitCode,
+			nagios.StateCRITICALExitCode,
+			nagios.StateUNKNOWNExitCode:
+			return id, nil
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrInvalidState, state)
+		}
+	}
+
+	switch strings.ToUpper(state) {
+	case nagios.StateOKLabel:
+		return nagios.StateOKExitCode, nil
+	case nagios.StateWARNINGLabel:
+		return nagios.StateWARNINGExitCode, nil
+	case nagios.StateCRITICALLabel:
+		return nagios.StateCRITICALExitCode, nil
+	case nagios.StateUNKNOWNLabel:
+		return nagios.StateUNKNOWNExitCode, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidState, state)
+	}
+}
+
+// parseHostState converts the given host state ID or host state label to a
+// host state exit code.
+func parseHostState(state string) (int, error) {
+	state = strings.TrimSpace(state)
+
+	if id, err := strconv.Atoi(state); err == nil {
+		switch id {
+		case HostStateUPExitCode,
+			HostStateDOWNExitCode,
+			HostStateUNREACHABLEExitCode:
+			return id, nil
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrInvalidState, state)
+		}
+	}
+
+	switch strings.ToUpper(state) {
+	case HostStateUPLabel:
+		return HostStateUPExitCode, nil
+	case HostStateDOWNLabel:
+		return HostStateDOWNExitCode, nil
+	case HostStateUNREACHABLELabel:
+		return HostStateUNREACHABLEExitCode, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidState, state)
+	}
+}
+
+// joinPerfData appends the optional performance data to the given output.
+func joinPerfData(output string, perfData ...string) string {
+	if len(perfData) == 0 || strings.TrimSpace(perfData[0]) == "" {
+		return output
+	}
+
+	return output + " | " + strings.TrimSpace(perfData[0])
+}
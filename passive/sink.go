@@ -0,0 +1,240 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package passive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// Sink URL schemes registered with nagios.ParseSink by this package.
+const (
+	SinkSchemeCommandFile string = "cmdfile"
+	SinkSchemeNRDPHTTP    string = "nrdp+http"
+	SinkSchemeNRDPHTTPS   string = "nrdp+https"
+	SinkSchemeNSCA        string = "nsca"
+)
+
+// Query parameters of sink specifications.
+const (
+	// SinkParamHost is the query parameter naming the host a result
+	// applies to. The local host name is used if not specified.
+	SinkParamHost string = "host"
+
+	// SinkParamService is the query parameter naming the service a result
+	// applies to. Results are submitted as host check results if not
+	// specified.
+	SinkParamService string = "service"
+
+	// SinkParamToken is the query parameter providing the NRDP token.
+	SinkParamToken string = "token"
+
+	// SinkParamConfig is the query parameter providing the send_nsca
+	// configuration file.
+	SinkParamConfig string = "config"
+
+	// SinkParamCommand is the query parameter providing the path of the
+	// send_nsca command.
+	SinkParamCommand string = "command"
+
+	// SinkParamSpool is the query parameter providing the directory used
+	// to queue results which could not be submitted (see WithSpool).
+	SinkParamSpool string = "spool"
+
+	// SinkParamBreaker is the query parameter providing the state file of
+	// the circuit breaker used to suppress submissions to a repeatedly
+	// failing monitoring system (see WithBreaker).
+	SinkParamBreaker string = "breaker"
+)
+
+// redactedSinkParam replaces sensitive sink specification values in error
+// messages, matching the password mask used by url.URL.Redacted.
+const redactedSinkParam string = "xxxxx"
+
+// DefaultSendNSCACommand is the command used by NSCASink if not specified.
+const DefaultSendNSCACommand string = "send_nsca"
+
+// DefaultSinkTimeout is the timeout applied to submissions by Sink values
+// adapted using NewResultSink.
+const DefaultSinkTimeout time.Duration = 30 * time.Second
+
+func init() {
+	nagios.RegisterSinkScheme(SinkSchemeCommandFile, newCommandFileSinkFromURL)
+	nagios.RegisterSinkScheme(SinkSchemeNRDPHTTP, newNRDPSinkFromURL)
+	nagios.RegisterSinkScheme(SinkSchemeNRDPHTTPS, newNRDPSinkFromURL)
+	nagios.RegisterSinkScheme(SinkSchemeNSCA, newNSCASinkFromURL)
+}
+
+// NewResultSink adapts the given Sink for use as a nagios.Sink (see
+// nagios.SetSinks), submitting each plugin result as a passive check result
+// for the given host and service. Results are submitted as host check
+// results if service is empty; the plugin exit code is used as the host
+// state. Each submission times out after DefaultSinkTimeout.
+func NewResultSink(sink Sink, host string, service string) nagios.Sink {
+	return nagios.SinkFunc(func(result nagios.Result) error {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultSinkTimeout)
+		defer cancel()
+
+		return sink.Submit(ctx, []CheckResult{{
+			HostName:           host,
+			ServiceDescription: service,
+			ExitCode:           result.ExitCode,
+			Output:             ResultOutput(result),
+		}})
+	})
+}
+
+// ResultOutput formats the given plugin result as passive check result
+// output: the one-line summary and performance data followed by
+// LongServiceOutput on subsequent lines.
+func ResultOutput(result nagios.Result) string {
+	output := formatOutput(result.ServiceOutput, result.PerfData)
+
+	if long := strings.TrimSpace(result.LongServiceOutput); long != "" {
+		output += "\n" + long
+	}
+
+	return output
+}
+
+// NSCASink returns a Sink which submits results by running the send_nsca
+// command (see DefaultSendNSCACommand) with the given arguments (e.g., "-H",
+// "nagios.example.com", "-c", "/etc/send_nsca.cfg"), writing the results to
+// its standard input (see EncodeNSCA).
+func NSCASink(command string, args ...string) Sink {
+	if command == "" {
+		command = DefaultSendNSCACommand
+	}
+
+	return SinkFunc(func(ctx context.Context, results []CheckResult) error {
+		payload, err := EncodeNSCA(results...)
+		if err != nil {
+			return err
+		}
+
+		cmd := exec.CommandContext(ctx, command, args...)
+		cmd.Stdin = bytes.NewReader(payload)
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s: %v: %s", ErrSubmissionFailed, command, err, strings.TrimSpace(string(output)))
+		}
+
+		return nil
+	})
+}
+
+// redactSinkURL returns the given sink specification with any password and
+// NRDP token (see SinkParamToken) masked so that it may be included in
+// error messages.
+func redactSinkURL(spec *url.URL) string {
+	redacted := *spec
+
+	query := redacted.Query()
+	if query.Has(SinkParamToken) {
+		query.Set(SinkParamToken, redactedSinkParam)
+		redacted.RawQuery = query.Encode()
+	}
+
+	return redacted.Redacted()
+}
+
+// sinkTarget returns the host and service named by the given sink
+// specification.
+func sinkTarget(spec *url.URL) (string, string) {
+	query := spec.Query()
+
+	host := query.Get(SinkParamHost)
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	return host, query.Get(SinkParamService)
+}
+
+// newCommandFileSinkFromURL is the nagios.SinkFactory for command file sink
+// specifications (e.g.,
+// "cmdfile:///usr/local/nagios/var/rw/nagios.cmd?host=web01&service=disk").
+func newCommandFileSinkFromURL(spec *url.URL) (nagios.Sink, error) {
+	if spec.Path == "" {
+		return nil, fmt.Errorf("invalid result sink %q: missing command file path", redactSinkURL(spec))
+	}
+
+	host, service := sinkTarget(spec)
+
+	sink, err := ReliableSinkFromURL(CommandFileSink(spec.Path), spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResultSink(sink, host, service), nil
+}
+
+// newNRDPSinkFromURL is the nagios.SinkFactory for NRDP sink specifications
+// (e.g., "nrdp+https://nagios.example.com/nrdp/?token=secret&host=web01").
+func newNRDPSinkFromURL(spec *url.URL) (nagios.Sink, error) {
+	host, service := sinkTarget(spec)
+
+	query := spec.Query()
+	token := query.Get(SinkParamToken)
+
+	if token == "" {
+		return nil, fmt.Errorf("invalid result sink %q: missing %s parameter", redactSinkURL(spec), SinkParamToken)
+	}
+
+	query.Del(SinkParamToken)
+	query.Del(SinkParamHost)
+	query.Del(SinkParamService)
+	query.Del(SinkParamSpool)
+	query.Del(SinkParamBreaker)
+
+	endpoint := *spec
+	endpoint.Scheme = strings.TrimPrefix(strings.ToLower(spec.Scheme), "nrdp+")
+	endpoint.RawQuery = query.Encode()
+
+	sink, err := ReliableSinkFromURL(NewNRDPSink(endpoint.String(), WithToken(token)), spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResultSink(sink, host, service), nil
+}
+
+// newNSCASinkFromURL is the nagios.SinkFactory for NSCA sink specifications
+// (e.g., "nsca://nagios.example.com:5667?config=/etc/send_nsca.cfg").
+func newNSCASinkFromURL(spec *url.URL) (nagios.Sink, error) {
+	if spec.Hostname() == "" {
+		return nil, fmt.Errorf("invalid result sink %q: missing NSCA server", redactSinkURL(spec))
+	}
+
+	host, service := sinkTarget(spec)
+	query := spec.Query()
+
+	args := []string{"-H", spec.Hostname()}
+	if port := spec.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+
+	if config := query.Get(SinkParamConfig); config != "" {
+		args = append(args, "-c", config)
+	}
+
+	sink, err := ReliableSinkFromURL(NSCASink(query.Get(SinkParamCommand), args...), spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResultSink(sink, host, service), nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Names of the built-in performance data encoders. See also
+// LookupPerfDataEncoder.
+const (
+	PerfDataFormatNagios      string = "nagios"
+	PerfDataFormatJSON        string = "json"
+	PerfDataFormatOpenMetrics string = "openmetrics"
+	PerfDataFormatInflux      string = "influx"
+)
+
+// DefaultInfluxMeasurement is the measurement name used by the built-in
+// Influx line protocol encoder if not specified by client code. See also
+// NewInfluxPerfDataEncoder.
+const DefaultInfluxMeasurement string = "perfdata"
+
+// ErrUnknownPerfDataEncoder indicates that a performance data encoder with
+// the requested name is not registered. See also RegisterPerfDataEncoder.
+var ErrUnknownPerfDataEncoder = errors.New("unknown performance data encoder")
+
+// PerfDataEncoder is implemented by types which write performance data
+// metrics to w in a specific representation (e.g., the classic Nagios
+// format or OpenMetrics). Encoders allow sinks and other consumers to
+// select a representation by configuration only. See also
+// LookupPerfDataEncoder and NewPerfDataSink.
+type PerfDataEncoder interface {
+	EncodePerfData(w io.Writer, perfData []PerformanceData) error
+}
+
+// PerfDataEncoderFunc is an adapter allowing an ordinary function to be
+// used as a PerfDataEncoder.
+type PerfDataEncoderFunc func(w io.Writer, perfData []PerformanceData) error
+
+// EncodePerfData calls f(w, perfData).
+func (f PerfDataEncoderFunc) EncodePerfData(w io.Writer, perfData []PerformanceData) error {
+	return f(w, perfData)
+}
+
+// perfDataEncoders is the registry of performance data encoders by name.
+var perfDataEncoders = struct {
+	mu       sync.RWMutex
+	encoders map[string]PerfDataEncoder
+}{
+	encoders: map[string]PerfDataEncoder{
+		PerfDataFormatNagios:      PerfDataEncoderFunc(EncodeNagiosPerfData),
+		PerfDataFormatJSON:        PerfDataEncoderFunc(EncodePerfDataJSON),
+		PerfDataFormatOpenMetrics: PerfDataEncoderFunc(EncodeOpenMetrics),
+		PerfDataFormatInflux:      NewInfluxPerfDataEncoder(DefaultInfluxMeasurement, nil),
+	},
+}
+
+// RegisterPerfDataEncoder registers the performance data encoder returned
+// by LookupPerfDataEncoder for the given name, replacing any encoder
+// previously registered for the name (including built-in encoders).
+func RegisterPerfDataEncoder(name string, encoder PerfDataEncoder) {
+	perfDataEncoders.mu.Lock()
+	defer perfDataEncoders.mu.Unlock()
+
+	perfDataEncoders.encoders[strings.ToLower(name)] = encoder
+}
+
+// LookupPerfDataEncoder returns the performance data encoder registered for
+// the given name (e.g., "openmetrics"). An error wrapping
+// ErrUnknownPerfDataEncoder is returned if no encoder is registered for the
+// name.
+func LookupPerfDataEncoder(name string) (PerfDataEncoder, error) {
+	perfDataEncoders.mu.RLock()
+	defer perfDataEncoders.mu.RUnlock()
+
+	encoder, ok := perfDataEncoders.encoders[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(perfDataEncoders.encoders))
+		for registered := range perfDataEncoders.encoders {
+			names = append(names, registered)
+		}
+		slices.Sort(names)
+
+		return nil, fmt.Errorf(
+			"%w: %q (registered: %s)",
+			ErrUnknownPerfDataEncoder,
+			name,
+			strings.Join(names, ", "),
+		)
+	}
+
+	return encoder, nil
+}
+
+// NewPerfDataSink returns a Sink which writes the performance data metrics
+// of results to w using the given encoder (e.g., to feed a metrics
+// pipeline alongside another sink).
+func NewPerfDataSink(w io.Writer, encoder PerfDataEncoder) Sink {
+	return SinkFunc(func(result Result) error {
+		return encoder.EncodePerfData(w, result.PerfData)
+	})
+}
+
+// EncodeNagiosPerfData writes the given performance data metrics to w in
+// the classic Nagios format as emitted after the pipe character in plugin
+// output, followed by a newline:
+//
+//	'label'=value[UOM];[warn];[crit];[min];[max] 'label'=...
+func EncodeNagiosPerfData(w io.Writer, perfData []PerformanceData) error {
+	var sb strings.Builder
+
+	for _, pd := range perfData {
+		sb.WriteString(pd.String())
+	}
+
+	_, err := io.WriteString(w, strings.TrimPrefix(sb.String(), " ")+"\n")
+
+	return err
+}
+
+// NewInfluxPerfDataEncoder returns a PerfDataEncoder which writes
+// performance data metrics in the InfluxDB line protocol, one line per
+// metric using the given measurement name (DefaultInfluxMeasurement if
+// empty). Each line is tagged with the metric label, the UOM (if set) and
+// the given tags. The metric value and any numeric warning, critical,
+// minimum and maximum values are written as the value, warn, crit, min and
+// max fields; threshold ranges (e.g., "10:20") are omitted. Metrics with a
+// non-numeric value (e.g., "U") are omitted. Timestamps are not written;
+// the time of receipt is used by the server.
+func NewInfluxPerfDataEncoder(measurement string, tags map[string]string) PerfDataEncoder {
+	if measurement == "" {
+		measurement = DefaultInfluxMeasurement
+	}
+
+	var tagSet strings.Builder
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(&tagSet, ",%s=%s", influxEscapeTag(key), influxEscapeTag(tags[key]))
+	}
+
+	prefix := influxEscapeMeasurement(measurement)
+	extraTags := tagSet.String()
+
+	return PerfDataEncoderFunc(func(w io.Writer, perfData []PerformanceData) error {
+		var sb strings.Builder
+
+		for _, pd := range perfData {
+			value, ok := influxFieldValue(pd.Value)
+			if !ok {
+				continue
+			}
+
+			sb.WriteString(prefix)
+			fmt.Fprintf(&sb, ",label=%s", influxEscapeTag(pd.Label))
+
+			if pd.UnitOfMeasurement != "" {
+				fmt.Fprintf(&sb, ",uom=%s", influxEscapeTag(pd.UnitOfMeasurement))
+			}
+
+			sb.WriteString(extraTags)
+			fmt.Fprintf(&sb, " value=%s", value)
+
+			for _, field := range []struct {
+				key   string
+				value string
+			}{
+				{key: "warn", value: pd.Warn},
+				{key: "crit", value: pd.Crit},
+				{key: "min", value: pd.Min},
+				{key: "max", value: pd.Max},
+			} {
+				if value, ok := influxFieldValue(field.value); ok {
+					fmt.Fprintf(&sb, ",%s=%s", field.key, value)
+				}
+			}
+
+			sb.WriteString("\n")
+		}
+
+		_, err := io.WriteString(w, sb.String())
+
+		return err
+	})
+}
+
+// influxFieldValue converts the given performance data value into an
+// InfluxDB line protocol float field value. False is returned if the value
+// is not a finite number.
+func influxFieldValue(s string) (string, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", false
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64), true
+}
+
+// influxEscapeMeasurement escapes a measurement name as required by the
+// InfluxDB line protocol.
+func influxEscapeMeasurement(s string) string {
+	return strings.NewReplacer(`,`, `\,`, ` `, `\ `).Replace(s)
+}
+
+// influxEscapeTag escapes a tag key or value as required by the InfluxDB
+// line protocol.
+func influxEscapeTag(s string) string {
+	return strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `).Replace(s)
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestBuiltInPerfDataEncoders asserts that each built-in performance data
+// encoder is registered and produces the expected representation.
+func TestBuiltInPerfDataEncoders(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "disk used", Value: "97", UnitOfMeasurement: "%", Warn: "80", Crit: "90", Min: "0", Max: "100"},
+		{Label: "inodes", Value: "U"},
+	}
+
+	tests := map[string]string{
+		nagios.PerfDataFormatNagios:      "'disk used'=97%;80;90;0;100 'inodes'=U;;;;\n",
+		nagios.PerfDataFormatJSON:        `"label":"disk used"`,
+		nagios.PerfDataFormatOpenMetrics: "disk_used 97\n# EOF\n",
+		nagios.PerfDataFormatInflux:      "perfdata,label=disk\\ used,uom=% value=97,warn=80,crit=90,min=0,max=100\n",
+	}
+
+	for name, want := range tests {
+		encoder, err := nagios.LookupPerfDataEncoder(name)
+		if err != nil {
+			t.Fatalf("failed to look up encoder %q: %v", name, err)
+		}
+
+		var sb strings.Builder
+		if err := encoder.EncodePerfData(&sb, perfData); err != nil {
+			t.Fatalf("failed to encode performance data using %q: %v", name, err)
+		}
+
+		if got := sb.String(); !strings.Contains(got, want) {
+			t.Errorf("%s: want output to contain %q, got:\n%s", name, want, got)
+		}
+	}
+}
+
+// TestInfluxPerfDataEncoderTags asserts that the Influx encoder applies the
+// given measurement name and tags and omits non-numeric values.
+func TestInfluxPerfDataEncoderTags(t *testing.T) {
+	t.Parallel()
+
+	encoder := nagios.NewInfluxPerfDataEncoder("check_disk", map[string]string{
+		"service": "disk",
+		"host":    "web01",
+	})
+
+	perfData := []nagios.PerformanceData{
+		{Label: "used", Value: "12.5", Warn: "10:20"},
+		{Label: "free", Value: "U"},
+	}
+
+	var sb strings.Builder
+	if err := encoder.EncodePerfData(&sb, perfData); err != nil {
+		t.Fatalf("failed to encode performance data: %v", err)
+	}
+
+	want := "check_disk,label=used,host=web01,service=disk value=12.5\n"
+	if got := sb.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+// TestRegisterPerfDataEncoder asserts that custom encoders can be
+// registered and that unknown encoder names are rejected.
+func TestRegisterPerfDataEncoder(t *testing.T) {
+	t.Parallel()
+
+	nagios.RegisterPerfDataEncoder("Labels", nagios.PerfDataEncoderFunc(
+		func(w io.Writer, perfData []nagios.PerformanceData) error {
+			for _, pd := range perfData {
+				if _, err := io.WriteString(w, pd.Label+"\n"); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	))
+
+	encoder, err := nagios.LookupPerfDataEncoder("labels")
+	if err != nil {
+		t.Fatalf("failed to look up registered encoder: %v", err)
+	}
+
+	var sb strings.Builder
+
+	sink := nagios.NewPerfDataSink(&sb, encoder)
+	result := nagios.Result{PerfData: []nagios.PerformanceData{{Label: "time", Value: "1"}}}
+
+	if err := sink.Emit(result); err != nil {
+		t.Fatalf("failed to emit result: %v", err)
+	}
+
+	if got, want := sb.String(), "time\n"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if _, err := nagios.LookupPerfDataEncoder("graphite"); !errors.Is(err, nagios.ErrUnknownPerfDataEncoder) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrUnknownPerfDataEncoder, err)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Predicate is a typed condition over collected metrics, built in code
+// using Metric and combined using All, Any and Not. It is an alternative to
+// Condition expressions for plugin authors who prefer code to expressions.
+type Predicate interface {
+	// Test evaluates the predicate against the given metric values and
+	// returns whether it holds along with a human readable explanation of
+	// why (or why not). An error wrapping ErrMissingMetric is returned if a
+	// referenced metric is not present.
+	Test(values map[string]float64) (bool, string, error)
+}
+
+// MetricRef refers to a collected metric by its performance data label and
+// is used to build metric predicates.
+type MetricRef struct {
+	label string
+}
+
+// Metric returns a reference to the metric with the given performance data
+// label (e.g., Metric("used_pct").Above(85)).
+func Metric(label string) MetricRef {
+	return MetricRef{label: label}
+}
+
+// Above returns a predicate which holds if the metric is greater than the
+// given value.
+func (m MetricRef) Above(value float64) Predicate {
+	return metricPredicate{label: m.label, op: "above", value: value, test: func(v float64) bool { return v > value }}
+}
+
+// AtLeast returns a predicate which holds if the metric is greater than or
+// equal to the given value.
+func (m MetricRef) AtLeast(value float64) Predicate {
+	return metricPredicate{label: m.label, op: "at least", value: value, test: func(v float64) bool { return v >= value }}
+}
+
+// Below returns a predicate which holds if the metric is less than the
+// given value.
+func (m MetricRef) Below(value float64) Predicate {
+	return metricPredicate{label: m.label, op: "below", value: value, test: func(v float64) bool { return v < value }}
+}
+
+// AtMost returns a predicate which holds if the metric is less than or
+// equal to the given value.
+func (m MetricRef) AtMost(value float64) Predicate {
+	return metricPredicate{label: m.label, op: "at most", value: value, test: func(v float64) bool { return v <= value }}
+}
+
+// Equal returns a predicate which holds if the metric equals the given
+// value.
+func (m MetricRef) Equal(value float64) Predicate {
+	return metricPredicate{label: m.label, op: "equal to", value: value, test: func(v float64) bool { return v == value }}
+}
+
+// metricPredicate compares a metric against a value.
+type metricPredicate struct {
+	label string
+	op    string
+	value float64
+	test  func(float64) bool
+}
+
+// Test evaluates the comparison, explaining it as (e.g.) "used_pct (88)
+// above 85" or "used_pct (50) not above 85".
+func (mp metricPredicate) Test(values map[string]float64) (bool, string, error) {
+	v, err := conditionOperand{text: mp.label}.resolve(values)
+	if err != nil {
+		return false, "", err
+	}
+
+	holds := mp.test(v)
+
+	op := mp.op
+	if !holds {
+		op = "not " + op
+	}
+
+	return holds, fmt.Sprintf(
+		"%s (%s) %s %s",
+		mp.label,
+		formatRangeBoundary(v),
+		op,
+		formatRangeBoundary(mp.value),
+	), nil
+}
+
+// All returns a predicate which holds if all of the given predicates hold.
+// The explanation lists every predicate if it holds and the first
+// predicate which does not otherwise.
+func All(predicates ...Predicate) Predicate {
+	return combinedPredicate{all: true, predicates: predicates}
+}
+
+// Any returns a predicate which holds if any of the given predicates hold.
+// The explanation lists the predicates which hold if it holds and every
+// predicate otherwise.
+func Any(predicates ...Predicate) Predicate {
+	return combinedPredicate{all: false, predicates: predicates}
+}
+
+// combinedPredicate combines predicates using All or Any semantics.
+type combinedPredicate struct {
+	all        bool
+	predicates []Predicate
+}
+
+// Test evaluates each of the predicates in order, stopping as soon as the
+// result is known when all predicates must hold.
+func (cp combinedPredicate) Test(values map[string]float64) (bool, string, error) {
+	var held, failed []string
+
+	for _, predicate := range cp.predicates {
+		holds, explanation, err := predicate.Test(values)
+		if err != nil {
+			return false, "", err
+		}
+
+		switch {
+		case holds:
+			held = append(held, explanation)
+		case cp.all:
+			return false, explanation, nil
+		default:
+			failed = append(failed, explanation)
+		}
+	}
+
+	if len(held) > 0 {
+		return true, joinExplanations(held, "and"), nil
+	}
+
+	return false, joinExplanations(failed, "and"), nil
+}
+
+// Not returns a predicate which holds if the given predicate does not. The
+// explanation is that of the given predicate.
+func Not(predicate Predicate) Predicate {
+	return notPredicate{predicate: predicate}
+}
+
+// notPredicate negates a predicate.
+type notPredicate struct {
+	predicate Predicate
+}
+
+// Test evaluates the negated predicate.
+func (np notPredicate) Test(values map[string]float64) (bool, string, error) {
+	holds, explanation, err := np.predicate.Test(values)
+	if err != nil {
+		return false, "", err
+	}
+
+	return !holds, explanation, nil
+}
+
+// joinExplanations joins the given explanations using the given
+// conjunction, grouping nested explanations in parentheses.
+func joinExplanations(explanations []string, conjunction string) string {
+	if len(explanations) == 1 {
+		return explanations[0]
+	}
+
+	grouped := make([]string, len(explanations))
+	for i, explanation := range explanations {
+		if strings.Contains(explanation, " and ") {
+			explanation = "(" + explanation + ")"
+		}
+		grouped[i] = explanation
+	}
+
+	return strings.Join(grouped, " "+conjunction+" ")
+}
+
+// EvaluatePredicates evaluates the given critical and warning predicates
+// (in that order; either may be nil) against the collected performance
+// data and returns the matching exit status code. StateOKExitCode is
+// returned if neither predicate holds.
+//
+// An explanation of the predicate which fired is added to
+// LongServiceOutput (e.g., "CRITICAL: used_pct (88) above 85 and
+// inodes_pct (92) above 90"). A predicate which cannot be evaluated (e.g.,
+// referencing a missing metric) is recorded as an error and results in an
+// UNKNOWN state.
+func (p *Plugin) EvaluatePredicates(warning Predicate, critical Predicate) int {
+	values := p.perfDataValues()
+
+	for _, predicate := range []struct {
+		predicate Predicate
+		state     int
+	}{
+		{predicate: critical, state: StateCRITICALExitCode},
+		{predicate: warning, state: StateWARNINGExitCode},
+	} {
+		if predicate.predicate == nil {
+			continue
+		}
+
+		holds, explanation, err := predicate.predicate.Test(values)
+		switch {
+		case err != nil:
+			p.AddError(fmt.Errorf("failed to evaluate %s predicate: %w", strings.ToLower(stateLabel(predicate.state)), err))
+			return StateUNKNOWNExitCode
+		case holds:
+			p.WithDetail(fmt.Sprintf("%s: %s", stateLabel(predicate.state), explanation))
+			return predicate.state
+		}
+	}
+
+	return StateOKExitCode
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestPredicateExplanations asserts that predicates yield both a decision
+// and an explanation of which condition fired.
+func TestPredicateExplanations(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]float64{
+		"used_pct":   88,
+		"inodes_pct": 92,
+		"load1":      4.5,
+	}
+
+	tests := []struct {
+		name            string
+		predicate       nagios.Predicate
+		wantHolds       bool
+		wantExplanation string
+	}{
+		{
+			name:            "all hold",
+			predicate:       nagios.All(nagios.Metric("used_pct").Above(85), nagios.Metric("inodes_pct").AtLeast(90)),
+			wantHolds:       true,
+			wantExplanation: "used_pct (88) above 85 and inodes_pct (92) at least 90",
+		},
+		{
+			name:            "all fail on first",
+			predicate:       nagios.All(nagios.Metric("used_pct").Above(90), nagios.Metric("inodes_pct").Above(90)),
+			wantHolds:       false,
+			wantExplanation: "used_pct (88) not above 90",
+		},
+		{
+			name:            "any lists holding predicates",
+			predicate:       nagios.Any(nagios.Metric("used_pct").Above(90), nagios.Metric("load1").Below(5)),
+			wantHolds:       true,
+			wantExplanation: "load1 (4.5) below 5",
+		},
+		{
+			name:            "not",
+			predicate:       nagios.Not(nagios.Metric("load1").AtMost(4)),
+			wantHolds:       true,
+			wantExplanation: "load1 (4.5) not at most 4",
+		},
+		{
+			name: "nested",
+			predicate: nagios.Any(
+				nagios.All(nagios.Metric("used_pct").Above(85), nagios.Metric("inodes_pct").Above(90)),
+				nagios.Metric("load1").Equal(4.5),
+			),
+			wantHolds:       true,
+			wantExplanation: "(used_pct (88) above 85 and inodes_pct (92) above 90) and load1 (4.5) equal to 4.5",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			holds, explanation, err := tt.predicate.Test(values)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if holds != tt.wantHolds || explanation != tt.wantExplanation {
+				t.Errorf("want (%t, %q), got (%t, %q)", tt.wantHolds, tt.wantExplanation, holds, explanation)
+			}
+		})
+	}
+
+	if _, _, err := nagios.Metric("swap_pct").Above(50).Test(values); !errors.Is(err, nagios.ErrMissingMetric) {
+		t.Errorf("want missing metric error, got %v", err)
+	}
+}
+
+// TestEvaluatePredicates asserts that the predicate which fired determines
+// the state and is explained in LongServiceOutput.
+func TestEvaluatePredicates(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "used_pct", Value: "88", UnitOfMeasurement: "%"},
+		nagios.PerformanceData{Label: "inodes_pct", Value: "92", UnitOfMeasurement: "%"},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state := plugin.EvaluatePredicates(
+		nagios.Metric("used_pct").Above(80),
+		nagios.All(nagios.Metric("used_pct").Above(85), nagios.Metric("inodes_pct").Above(90)),
+	)
+
+	if state != nagios.StateCRITICALExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateCRITICALExitCode, state)
+	}
+
+	plugin.ServiceOutput = "disk usage high"
+	plugin.ReturnCheckResults()
+
+	want := "CRITICAL: used_pct (88) above 85 and inodes_pct (92) above 90"
+	if got := outputBuffer.String(); !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"strconv"
+	"time"
+)
+
+// DefaultUnprivilegedUser is the user privileges are dropped to if no user
+// is specified.
+const DefaultUnprivilegedUser string = "nobody"
+
+var (
+	// ErrPrivilegeDropUnsupported indicates that dropping privileges is not
+	// supported on this platform.
+	ErrPrivilegeDropUnsupported = errors.New("dropping privileges is not supported on this platform")
+
+	// ErrPrivilegeDropFailed indicates that privileges could not be dropped
+	// (or could be regained after dropping them).
+	ErrPrivilegeDropFailed = errors.New("failed to drop privileges")
+)
+
+// RunPrivileged runs the given privileged operation (e.g., opening a raw
+// socket for ICMP probes or reading a protected file) and then permanently
+// drops root privileges to the given user (DefaultUnprivilegedUser if
+// empty) before the rest of the check runs. Privileges are dropped even if
+// the operation fails. The privilege transitions are recorded in the
+// diagnostics section (see EnableDiagnostics) and logged.
+//
+// If the plugin is not running as root the operation is run and privileges
+// are left unchanged. File descriptors (e.g., sockets) opened by the
+// operation remain usable after privileges are dropped.
+//
+// An error wrapping ErrPrivilegeDropFailed is returned if privileges could
+// not be dropped, in which case the plugin should not continue. An error
+// wrapping ErrPrivilegeDropUnsupported is returned on platforms without
+// POSIX user IDs (e.g., Windows).
+func (p *Plugin) RunPrivileged(username string, operation func() error) error {
+	if !privilegeDropSupported {
+		return ErrPrivilegeDropUnsupported
+	}
+
+	if username == "" {
+		username = DefaultUnprivilegedUser
+	}
+
+	p.AddDiagnostic("Privileges at start", currentPrivileges())
+
+	start := time.Now()
+	opErr := operation()
+
+	status := "completed"
+	if opErr != nil {
+		status = fmt.Sprintf("failed (%v)", opErr)
+	}
+	p.AddDiagnostic("Privileged operation", fmt.Sprintf("%s in %v", status, time.Since(start).Round(time.Millisecond)))
+
+	if !runningAsRoot() {
+		p.AddDiagnostic("Privileges dropped", "no (not running as root)")
+		p.Logger().Debug("not running as root, privileges unchanged")
+
+		return opErr
+	}
+
+	uid, gid, err := lookupUnprivilegedUser(username)
+	if err == nil {
+		err = dropPrivileges(uid, gid)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("%w: %s: %v", ErrPrivilegeDropFailed, username, err)
+		p.AddDiagnostic("Privileges dropped", fmt.Sprintf("no (%v)", err))
+		p.Logger().Error("failed to drop privileges", "user", username, "error", err)
+
+		return errors.Join(opErr, err)
+	}
+
+	p.AddDiagnostic("Privileges dropped", fmt.Sprintf("yes (%s)", currentPrivileges()))
+	p.Logger().Info("dropped privileges", "user", username, "uid", uid, "gid", gid)
+
+	return opErr
+}
+
+// lookupUnprivilegedUser returns the user ID and primary group ID of the
+// given user name (or numeric user ID).
+func lookupUnprivilegedUser(username string) (int, int, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		var lookupErr error
+		if u, lookupErr = user.LookupId(username); lookupErr != nil {
+			return 0, 0, err
+		}
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user ID %q: %w", u.Uid, err)
+	}
+
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid group ID %q: %w", u.Gid, err)
+	}
+
+	if uid == 0 {
+		return 0, 0, fmt.Errorf("user %s is root", username)
+	}
+
+	return uid, gid, nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package nagios
+
+// privilegeDropSupported indicates whether dropping privileges is
+// supported on this platform.
+const privilegeDropSupported bool = false
+
+// runningAsRoot is not supported on this platform.
+func runningAsRoot() bool {
+	return false
+}
+
+// currentPrivileges is not supported on this platform.
+func currentPrivileges() string {
+	return "unknown"
+}
+
+// dropPrivileges is not supported on this platform.
+func dropPrivileges(int, int) error {
+	return ErrPrivilegeDropUnsupported
+}
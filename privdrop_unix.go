@@ -0,0 +1,58 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// privilegeDropSupported indicates whether dropping privileges is
+// supported on this platform.
+const privilegeDropSupported bool = true
+
+// runningAsRoot indicates whether the process has root privileges.
+func runningAsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// currentPrivileges describes the user and group IDs of the process.
+func currentPrivileges() string {
+	return fmt.Sprintf(
+		"uid=%d euid=%d gid=%d egid=%d",
+		os.Getuid(), os.Geteuid(), os.Getgid(), os.Getegid(),
+	)
+}
+
+// dropPrivileges permanently changes the user and group IDs of the process
+// (all threads) to the given IDs, clearing supplementary groups, and
+// verifies that root privileges cannot be regained. The group ID is
+// changed first as this is not permitted once the user ID has changed.
+func dropPrivileges(uid int, gid int) error {
+	if err := syscall.Setgroups([]int{gid}); err != nil {
+		return fmt.Errorf("setgroups: %w", err)
+	}
+
+	if err := syscall.Setgid(gid); err != nil {
+		return fmt.Errorf("setgid: %w", err)
+	}
+
+	if err := syscall.Setuid(uid); err != nil {
+		return fmt.Errorf("setuid: %w", err)
+	}
+
+	if err := syscall.Setuid(0); err == nil {
+		return errors.New("root privileges regained after setuid")
+	}
+
+	return nil
+}
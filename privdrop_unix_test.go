@@ -0,0 +1,99 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package nagios_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRunPrivilegedDropsPrivileges asserts that privileges are dropped
+// after the privileged operation and that the transitions are recorded in
+// the diagnostics section. Dropping privileges affects the whole process,
+// so this is done in a child process.
+func TestRunPrivilegedDropsPrivileges(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() != 0 {
+		t.Skip("requires root privileges")
+	}
+
+	if _, err := user.Lookup(nagios.DefaultUnprivilegedUser); err != nil {
+		t.Skipf("user %s not available: %v", nagios.DefaultUnprivilegedUser, err)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcessRunPrivileged$")
+	cmd.Env = append(os.Environ(), "GO_NAGIOS_HELPER_PROCESS=1")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("helper process failed: %v\n%s", err, output)
+	}
+
+	got := string(output)
+
+	for _, want := range []string{
+		"Privileges at start: uid=0 euid=0",
+		"Privileged operation: completed in",
+		"Privileges dropped: yes (uid=",
+		"protected file read as root",
+		"euid after drop is not 0",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestHelperProcessRunPrivileged is run as a child process by
+// TestRunPrivilegedDropsPrivileges.
+func TestHelperProcessRunPrivileged(t *testing.T) {
+	if os.Getenv("GO_NAGIOS_HELPER_PROCESS") != "1" {
+		t.Skip("helper process")
+	}
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithDiagnostics(),
+	)
+
+	err := plugin.RunPrivileged("", func() error {
+		// Only root may read this file.
+		if _, err := os.ReadFile("/etc/shadow"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		plugin.WithDetail("protected file read as root")
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to run privileged operation: %v", err)
+	}
+
+	if os.Geteuid() != 0 {
+		plugin.WithDetail("euid after drop is not 0")
+	}
+
+	plugin.OK("probe complete")
+	plugin.ReturnCheckResults()
+
+	fmt.Print(outputBuffer.String())
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval is the interval between heartbeats written by a
+// Progress reporter if an interval is not specified by client code.
+const DefaultProgressInterval time.Duration = 10 * time.Second
+
+// Progress periodically writes a heartbeat describing the current phase of
+// a long running plugin and the number of targets done, allowing operators
+// to tell a slow check from a hung one:
+//
+//	2026-10-16T10:04:05Z phase=discovery done=37/50 elapsed=2m10s
+//
+// A Progress reporter is created using StartProgress or StartProgressFile
+// and is safe for concurrent use.
+type Progress struct {
+	mu    sync.Mutex
+	phase string
+	done  int
+	total int
+	start time.Time
+
+	write   func(heartbeat string) error
+	cleanup func()
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// StartProgress starts a Progress reporter which writes a heartbeat line to
+// w (e.g., os.Stderr) at the given interval (DefaultProgressInterval if not
+// positive) until stopped. RunChecks updates the number of targets done
+// while the reporter is running.
+func (p *Plugin) StartProgress(w io.Writer, interval time.Duration) *Progress {
+	return p.startProgress(interval, func(heartbeat string) error {
+		_, err := fmt.Fprintln(w, heartbeat)
+		return err
+	}, func() {})
+}
+
+// StartProgressFile starts a Progress reporter which replaces the status
+// file at the given path with the latest heartbeat at the given interval
+// (DefaultProgressInterval if not positive) until stopped. The status file
+// is removed once the reporter is stopped.
+func (p *Plugin) StartProgressFile(path string, interval time.Duration) *Progress {
+	return p.startProgress(interval, func(heartbeat string) error {
+		return writeFileAtomic(path, []byte(heartbeat+"\n"))
+	}, func() {
+		_ = os.Remove(path)
+	})
+}
+
+// startProgress starts a Progress reporter using the given heartbeat writer
+// and cleanup function.
+func (p *Plugin) startProgress(interval time.Duration, write func(string) error, cleanup func()) *Progress {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	pr := Progress{
+		start:   time.Now(),
+		write:   write,
+		cleanup: cleanup,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	p.progress = &pr
+
+	go pr.run(interval, p.Logger())
+
+	return &pr
+}
+
+// SetPhase records the current phase (e.g., "discovery") of the plugin.
+func (pr *Progress) SetPhase(phase string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.phase = phase
+}
+
+// SetTotal records the total number of targets and resets the number of
+// targets done.
+func (pr *Progress) SetTotal(total int) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.total = total
+	pr.done = 0
+}
+
+// Add records that the given number of targets are done.
+func (pr *Progress) Add(done int) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.done += done
+}
+
+// Heartbeat returns the current heartbeat text.
+func (pr *Progress) Heartbeat() string {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	heartbeat := time.Now().UTC().Format(time.RFC3339)
+
+	if pr.phase != "" {
+		heartbeat += " phase=" + pr.phase
+	}
+
+	if pr.total > 0 {
+		heartbeat += fmt.Sprintf(" done=%d/%d", pr.done, pr.total)
+	}
+
+	return heartbeat + " elapsed=" + time.Since(pr.start).Round(time.Second).String()
+}
+
+// Stop stops the reporter, writing a final heartbeat (or removing the status
+// file, see StartProgressFile).
+func (pr *Progress) Stop() {
+	pr.stopOnce.Do(func() {
+		close(pr.stop)
+		<-pr.stopped
+	})
+}
+
+// run writes heartbeats at the given interval until stopped. Write failures
+// are logged using the given logger.
+func (pr *Progress) run(interval time.Duration, logger *slog.Logger) {
+	defer close(pr.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	beat := func() {
+		if err := pr.write(pr.Heartbeat()); err != nil {
+			logger.Warn("failed to write progress heartbeat", "error", err)
+		}
+	}
+
+	beat()
+
+	for {
+		select {
+		case <-ticker.C:
+			beat()
+		case <-pr.stop:
+			beat()
+			pr.cleanup()
+			return
+		}
+	}
+}
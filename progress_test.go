@@ -0,0 +1,103 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// syncBuilder is a strings.Builder safe for concurrent use.
+type syncBuilder struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (sb *syncBuilder) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return sb.b.Write(p)
+}
+
+func (sb *syncBuilder) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return sb.b.String()
+}
+
+// TestProgressWritesHeartbeats asserts that heartbeats include the phase
+// and the number of sub-checks done by RunChecks.
+func TestProgressWritesHeartbeats(t *testing.T) {
+	t.Parallel()
+
+	var heartbeats syncBuilder
+
+	plugin := nagios.Plugin{}
+	progress := plugin.StartProgress(&heartbeats, time.Hour)
+	progress.SetPhase("polling")
+
+	ok := func(context.Context) nagios.CheckResult {
+		return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+	}
+
+	plugin.RunChecks([]nagios.NamedCheck{{Name: "a", Check: ok}, {Name: "b", Check: ok}}, 0, 0)
+
+	if got, want := progress.Heartbeat(), " phase=polling done=2/2 elapsed="; !strings.Contains(got, want) {
+		t.Errorf("want heartbeat to contain %q, got %q", want, got)
+	}
+
+	progress.Stop()
+
+	lines := strings.Split(strings.TrimSpace(heartbeats.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "done=2/2") {
+		t.Errorf("want initial and final heartbeats, got %q", lines)
+	}
+}
+
+// TestProgressFileIsRemovedOnStop asserts that the status file holds the
+// latest heartbeat and is removed once the reporter is stopped.
+func TestProgressFileIsRemovedOnStop(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "check_discovery.status")
+
+	plugin := nagios.Plugin{}
+	progress := plugin.StartProgressFile(path, 10*time.Millisecond)
+	progress.SetPhase("discovery")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(path)
+		if err == nil && strings.Contains(string(data), "phase=discovery") {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("want status file updated, got %q (%v)", data, err)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	progress.Stop()
+
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("want status file removed, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultQuorumLabel is the label used in the one-line summary of a quorum
+// result if not specified by client code.
+const defaultQuorumLabel string = "Cluster"
+
+// QuorumState evaluates the overall state of a group of members using the
+// same semantics as the check_cluster plugin from the Monitoring Plugins
+// project: the number of non-OK members is compared against the given
+// warning and critical threshold ranges. Nil ranges are ignored.
+//
+// For example, with a critical range of "2" (alert if more than 2 members
+// are non-OK), a five member cluster is CRITICAL once three members are not
+// OK.
+func QuorumState(warning *Range, critical *Range, states ...int) int {
+	var nonOK int
+	for _, state := range states {
+		if state != StateOKExitCode {
+			nonOK++
+		}
+	}
+
+	switch {
+	case critical != nil && critical.ShouldAlert(float64(nonOK)):
+		return StateCRITICALExitCode
+	case warning != nil && warning.ShouldAlert(float64(nonOK)):
+		return StateWARNINGExitCode
+	default:
+		return StateOKExitCode
+	}
+}
+
+// Quorum sets the plugin state to the overall state of the given members
+// as evaluated by QuorumState and the one-line summary (ServiceOutput) to a
+// check_cluster style summary of the non-OK members:
+//
+//	Cluster WARNING: 2/5 members CRITICAL
+//	Cluster CRITICAL: 3/5 members non-OK (1 WARNING, 2 CRITICAL)
+//
+// The default "Cluster" label is used if label is empty. The given ranges
+// are recorded for display in the Thresholds section and the state of each
+// member is appended to LongServiceOutput. The receiver is returned to allow
+// chaining further calls.
+func (p *Plugin) Quorum(label string, warning *Range, critical *Range, members ...SubResult) *Plugin {
+	if label == "" {
+		label = defaultQuorumLabel
+	}
+
+	states := make([]int, 0, len(members))
+	for _, member := range members {
+		states = append(states, member.ExitCode)
+	}
+
+	state := QuorumState(warning, critical, states...)
+
+	p.ExitStatusCode = state
+	p.ServiceOutput = fmt.Sprintf(
+		"%s %s: %s",
+		label,
+		stateLabel(state),
+		quorumSummary(members, "members"),
+	)
+
+	p.WarningRange = warning
+	p.CriticalRange = critical
+
+	for _, member := range members {
+		p.WithDetail(p.listItem("%s: %s", member.Name, stateLabel(member.ExitCode)))
+	}
+
+	return p
+}
+
+// quorumSummary summarizes the number of non-OK members by state, referring
+// to the members using the given plural noun (e.g., "members").
+func quorumSummary(members []SubResult, noun string) string {
+	counts := make(map[string]int)
+	for _, member := range members {
+		if member.ExitCode == StateOKExitCode {
+			continue
+		}
+		counts[stateLabel(member.ExitCode)]++
+	}
+
+	return stateCountsSummary(counts, len(members), noun)
+}
+
+// stateCountsSummary summarizes the given number of non-OK members by state
+// label out of the given total, referring to the members using the given
+// plural noun (e.g., "members").
+func stateCountsSummary(counts map[string]int, total int, noun string) string {
+	// Ordered from least to most severe for display purposes.
+	nonOKLabels := []string{
+		StateDEPENDENTLabel,
+		StateUNKNOWNLabel,
+		StateWARNINGLabel,
+		StateCRITICALLabel,
+	}
+
+	var nonOK int
+	for _, count := range counts {
+		nonOK += count
+	}
+
+	switch {
+	case nonOK == 0:
+		return fmt.Sprintf("%d/%d %s %s", total, total, noun, StateOKLabel)
+
+	// Only one non-OK state is present, so name it directly.
+	case len(counts) == 1:
+		for label := range counts {
+			return fmt.Sprintf("%d/%d %s %s", nonOK, total, noun, label)
+		}
+	}
+
+	breakdown := make([]string, 0, len(counts))
+	for _, label := range nonOKLabels {
+		if count := counts[label]; count > 0 {
+			breakdown = append(breakdown, fmt.Sprintf("%d %s", count, label))
+		}
+	}
+
+	return fmt.Sprintf(
+		"%d/%d %s non-OK (%s)",
+		nonOK,
+		total,
+		noun,
+		strings.Join(breakdown, ", "),
+	)
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestQuorumSummaryAndState asserts that the number of non-OK members is
+// evaluated against the threshold ranges and summarized in the
+// check_cluster style.
+func TestQuorumSummaryAndState(t *testing.T) {
+	t.Parallel()
+
+	warning, err := nagios.ParseRange("1")
+	if err != nil {
+		t.Fatalf("failed to parse warning range: %v", err)
+	}
+
+	critical, err := nagios.ParseRange("2")
+	if err != nil {
+		t.Fatalf("failed to parse critical range: %v", err)
+	}
+
+	member := func(name string, exitCode int) nagios.SubResult {
+		return nagios.SubResult{Name: name, ExitCode: exitCode}
+	}
+
+	tests := map[string]struct {
+		members     []nagios.SubResult
+		wantState   int
+		wantSummary string
+	}{
+		"all ok": {
+			members: []nagios.SubResult{
+				member("a", nagios.StateOKExitCode),
+				member("b", nagios.StateOKExitCode),
+			},
+			wantState:   nagios.StateOKExitCode,
+			wantSummary: "Cluster OK: 2/2 members OK",
+		},
+		"two critical": {
+			members: []nagios.SubResult{
+				member("a", nagios.StateOKExitCode),
+				member("b", nagios.StateCRITICALExitCode),
+				member("c", nagios.StateOKExitCode),
+				member("d", nagios.StateCRITICALExitCode),
+				member("e", nagios.StateOKExitCode),
+			},
+			wantState:   nagios.StateWARNINGExitCode,
+			wantSummary: "Cluster WARNING: 2/5 members CRITICAL",
+		},
+		"mixed": {
+			members: []nagios.SubResult{
+				member("a", nagios.StateWARNINGExitCode),
+				member("b", nagios.StateCRITICALExitCode),
+				member("c", nagios.StateOKExitCode),
+				member("d", nagios.StateCRITICALExitCode),
+				member("e", nagios.StateOKExitCode),
+			},
+			wantState:   nagios.StateCRITICALExitCode,
+			wantSummary: "Cluster CRITICAL: 3/5 members non-OK (1 WARNING, 2 CRITICAL)",
+		},
+	}
+
+	for name, tt := range tests {
+		plugin := nagios.Plugin{}
+		plugin.Quorum("", &warning, &critical, tt.members...)
+
+		if plugin.ExitStatusCode != tt.wantState {
+			t.Errorf("%s: want state %d, got %d", name, tt.wantState, plugin.ExitStatusCode)
+		}
+
+		if d := cmp.Diff(tt.wantSummary, plugin.ServiceOutput); d != "" {
+			t.Errorf("%s: (-want, +got)\n:%s", name, d)
+		}
+	}
+}
+
+// TestQuorumOutputIncludesThresholdsAndMembers asserts that the threshold
+// ranges and member states are included in LongServiceOutput.
+func TestQuorumOutputIncludesThresholdsAndMembers(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	critical, err := nagios.ParseRange("1")
+	if err != nil {
+		t.Fatalf("failed to parse critical range: %v", err)
+	}
+
+	plugin.Quorum(
+		"Web pool",
+		nil,
+		&critical,
+		nagios.SubResult{Name: "web01", ExitCode: nagios.StateOKExitCode},
+		nagios.SubResult{Name: "web02", ExitCode: nagios.StateUNKNOWNExitCode},
+	)
+
+	plugin.ReturnCheckResults()
+
+	want := "Web pool OK: 1/2 members UNKNOWN" + nagios.CheckOutputEOL +
+		"**THRESHOLDS**" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"* CRITICAL: 1" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"**DETAILED INFO**" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"* web01: OK" + nagios.CheckOutputEOL +
+		"* web02: UNKNOWN" + nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
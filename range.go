@@ -0,0 +1,305 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Range format special characters. See the Threshold and Ranges section of
+// the plugin development guidelines for details.
+//
+// https://nagios-plugins.org/doc/guidelines.html#THRESHOLDFORMAT
+const (
+	rangeInsidePrefix      string = "@"
+	rangeInfinitySymbol    string = "~"
+	rangeBoundarySeparator string = ":"
+)
+
+// ErrInvalidRange indicates that a threshold range value could not be parsed
+// because it does not follow the expected range format.
+var ErrInvalidRange = errors.New("invalid threshold range")
+
+// Range represents a threshold range as described by the plugin development
+// guidelines. A value "triggers" (alerts on) a range if it falls outside of
+// the inclusive Start and End boundaries, or inside of them if AlertInside
+// is set.
+//
+// Some examples of the range format and the resulting Range values:
+//
+//	10      alert if < 0 or > 10         {Start: 0, End: 10}
+//	10:     alert if < 10                {Start: 10, End: +Inf}
+//	~:10    alert if > 10                {Start: -Inf, End: 10}
+//	10:20   alert if < 10 or > 20        {Start: 10, End: 20}
+//	@10:20  alert if >= 10 and <= 20     {Start: 10, End: 20, AlertInside: true}
+//
+// https://nagios-plugins.org/doc/guidelines.html#THRESHOLDFORMAT
+type Range struct {
+	// Start is the lower boundary of the range. Negative infinity is used
+	// to indicate that there is no lower boundary.
+	Start float64
+
+	// End is the upper boundary of the range. Positive infinity is used to
+	// indicate that there is no upper boundary.
+	End float64
+
+	// AlertInside indicates that an alert should be generated if a value is
+	// inside of the range instead of outside of it.
+	AlertInside bool
+}
+
+// ParseRange parses a threshold range value in the format described by the
+// plugin development guidelines. An error wrapping ErrInvalidRange is
+// returned if the value cannot be parsed.
+func ParseRange(s string) (Range, error) {
+	input := strings.TrimSpace(s)
+
+	var r Range
+
+	if strings.HasPrefix(input, rangeInsidePrefix) {
+		r.AlertInside = true
+		input = strings.TrimPrefix(input, rangeInsidePrefix)
+	}
+
+	if input == "" {
+		return Range{}, fmt.Errorf("%w: empty range value %q", ErrInvalidRange, s)
+	}
+
+	startText, endText, hasSeparator := strings.Cut(input, rangeBoundarySeparator)
+
+	switch {
+	// A single value indicates a range from zero to the given value.
+	case !hasSeparator:
+		end, err := parseRangeBoundary("end", startText, s)
+		if err != nil {
+			return Range{}, err
+		}
+		r.Start, r.End = 0, end
+
+	default:
+		switch startText {
+		case rangeInfinitySymbol:
+			r.Start = math.Inf(-1)
+		case "":
+			// Per the guidelines, the start boundary is assumed to be zero
+			// if not specified.
+			r.Start = 0
+		default:
+			start, err := parseRangeBoundary("start", startText, s)
+			if err != nil {
+				return Range{}, err
+			}
+			r.Start = start
+		}
+
+		switch endText {
+		case "":
+			r.End = math.Inf(1)
+		default:
+			end, err := parseRangeBoundary("end", endText, s)
+			if err != nil {
+				return Range{}, err
+			}
+			r.End = end
+		}
+	}
+
+	if r.Start > r.End {
+		return Range{}, fmt.Errorf(
+			"%w: start %s is greater than end %s in %q",
+			ErrInvalidRange,
+			formatRangeBoundary(r.Start),
+			formatRangeBoundary(r.End),
+			s,
+		)
+	}
+
+	return r, nil
+}
+
+// parseRangeBoundary parses a single range boundary value.
+func parseRangeBoundary(name string, value string, rangeText string) (float64, error) {
+	boundary, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(boundary) || math.IsInf(boundary, 0) {
+		return 0, fmt.Errorf(
+			"%w: %s value %q in %q is not a number",
+			ErrInvalidRange,
+			name,
+			value,
+			rangeText,
+		)
+	}
+
+	return boundary, nil
+}
+
+// formatRangeBoundary formats a range boundary value using the minimal
+// number of digits needed to represent it.
+func formatRangeBoundary(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// String provides the Range in the format described by the plugin
+// development guidelines. The result is suitable for use as a threshold
+// value in performance data or for display purposes.
+func (r Range) String() string {
+	var b strings.Builder
+
+	if r.AlertInside {
+		b.WriteString(rangeInsidePrefix)
+	}
+
+	switch {
+	case math.IsInf(r.Start, -1):
+		b.WriteString(rangeInfinitySymbol + rangeBoundarySeparator)
+	case r.Start == 0 && !math.IsInf(r.End, 1):
+		// Single value (shorthand) form; the zero start is implied.
+	default:
+		b.WriteString(formatRangeBoundary(r.Start) + rangeBoundarySeparator)
+	}
+
+	if !math.IsInf(r.End, 1) {
+		b.WriteString(formatRangeBoundary(r.End))
+	}
+
+	return b.String()
+}
+
+// Describe provides a plain language description of when the range alerts
+// (e.g., "alert if < 10", "alert if > 90", "alert if < 10 or > 20" or
+// "alert if >= 10 and <= 20"). This is intended to clarify range semantics
+// which are easily misread, such as those of ranges with only a lower
+// boundary.
+func (r Range) Describe() string {
+	start := formatRangeBoundary(r.Start)
+	end := formatRangeBoundary(r.End)
+
+	lowerBounded := !math.IsInf(r.Start, -1)
+	upperBounded := !math.IsInf(r.End, 1)
+
+	switch {
+	case r.AlertInside && lowerBounded && upperBounded:
+		return fmt.Sprintf("alert if >= %s and <= %s", start, end)
+	case r.AlertInside && lowerBounded:
+		return fmt.Sprintf("alert if >= %s", start)
+	case r.AlertInside && upperBounded:
+		return fmt.Sprintf("alert if <= %s", end)
+	case r.AlertInside:
+		return "always alert"
+	case lowerBounded && upperBounded:
+		return fmt.Sprintf("alert if < %s or > %s", start, end)
+	case lowerBounded:
+		return fmt.Sprintf("alert if < %s", start)
+	case upperBounded:
+		return fmt.Sprintf("alert if > %s", end)
+	default:
+		return "never alert"
+	}
+}
+
+// ShouldAlert indicates whether the given value triggers an alert for this
+// range.
+func (r Range) ShouldAlert(value float64) bool {
+	inside := value >= r.Start && value <= r.End
+
+	if r.AlertInside {
+		return inside
+	}
+
+	return !inside
+}
+
+// EvaluateThresholds evaluates the given value against the CriticalRange
+// and WarningRange values (in that order) and returns the matching exit
+// status code. StateOKExitCode is returned if neither range is set or if
+// the value does not trigger either range.
+//
+// The same Range values are used to display the thresholds in the
+// THRESHOLDS section of LongServiceOutput, keeping the displayed values and
+// the evaluation logic in sync.
+//
+// If a moving average is enabled (see SetMovingAverage), the thresholds are
+// applied to the moving average instead of the given value. If a threshold
+// window is active (see AddThresholdWindow), its thresholds are used.
+// Recovery thresholds (see SetRecoveryThresholds) are used in place of the
+// thresholds matching the state of the previous run. Range sets (see
+// SetWarningRangeSet) are evaluated in addition to these ranges.
+func (p Plugin) EvaluateThresholds(value float64) int {
+	value, _ = p.MovingAverage(value)
+
+	warning, critical := p.activeRanges(time.Now())
+	warning, critical = p.applyHysteresis(warning, critical)
+
+	switch {
+	case critical != nil && critical.ShouldAlert(value), p.criticalRangeSet.ShouldAlert(value):
+		return StateCRITICALExitCode
+	case warning != nil && warning.ShouldAlert(value), p.warningRangeSet.ShouldAlert(value):
+		return StateWARNINGExitCode
+	default:
+		return StateOKExitCode
+	}
+}
+
+// SetWarningThreshold parses the given threshold range and, if valid, sets
+// both WarningThreshold (for display) and WarningRange (for evaluation). An
+// error wrapping ErrInvalidRange is returned and neither field is modified
+// if the value cannot be parsed.
+func (p *Plugin) SetWarningThreshold(threshold string) error {
+	r, err := ParseRange(threshold)
+	if err != nil {
+		return fmt.Errorf("invalid warning threshold: %w", err)
+	}
+
+	p.setWarningRange(&r, strings.TrimSpace(threshold))
+
+	return nil
+}
+
+// SetCriticalThreshold parses the given threshold range and, if valid, sets
+// both CriticalThreshold (for display) and CriticalRange (for evaluation).
+// An error wrapping ErrInvalidRange is returned and neither field is
+// modified if the value cannot be parsed.
+func (p *Plugin) SetCriticalThreshold(threshold string) error {
+	r, err := ParseRange(threshold)
+	if err != nil {
+		return fmt.Errorf("invalid critical threshold: %w", err)
+	}
+
+	p.setCriticalRange(&r, strings.TrimSpace(threshold))
+
+	return nil
+}
+
+// setWarningRange sets the given warning threshold range for evaluation and
+// the given text for display, clearing any warning range set (see
+// SetWarningRangeSet) and threshold origin (see SetDefaultThresholds).
+// Every warning threshold setter uses this so that no stale values are
+// evaluated or displayed.
+func (p *Plugin) setWarningRange(r *Range, text string) {
+	p.WarningRange = r
+	p.WarningThreshold = text
+	p.warningRangeSet = nil
+	p.warningThresholdOrigin = ""
+}
+
+// setCriticalRange sets the given critical threshold range for evaluation
+// and the given text for display, clearing any critical range set (see
+// SetCriticalRangeSet) and threshold origin (see SetDefaultThresholds).
+// Every critical threshold setter uses this so that no stale values are
+// evaluated or displayed.
+func (p *Plugin) setCriticalRange(r *Range, text string) {
+	p.CriticalRange = r
+	p.CriticalThreshold = text
+	p.criticalRangeSet = nil
+	p.criticalThresholdOrigin = ""
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParseRange asserts that threshold ranges in the guideline format are
+// parsed, evaluated and formatted as expected.
+func TestParseRange(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input       string
+		want        nagios.Range
+		wantString  string
+		alertValues []float64
+		okValues    []float64
+	}{
+		"single value": {
+			input:       "10",
+			want:        nagios.Range{Start: 0, End: 10},
+			wantString:  "10",
+			alertValues: []float64{-1, 11},
+			okValues:    []float64{0, 10},
+		},
+		"start only": {
+			input:       "10:",
+			want:        nagios.Range{Start: 10, End: math.Inf(1)},
+			wantString:  "10:",
+			alertValues: []float64{9.9},
+			okValues:    []float64{10, 1e9},
+		},
+		"negative infinity start": {
+			input:       "~:10",
+			want:        nagios.Range{Start: math.Inf(-1), End: 10},
+			wantString:  "~:10",
+			alertValues: []float64{10.1},
+			okValues:    []float64{-1e9, 10},
+		},
+		"start and end": {
+			input:       "10:20",
+			want:        nagios.Range{Start: 10, End: 20},
+			wantString:  "10:20",
+			alertValues: []float64{9, 21},
+			okValues:    []float64{10, 20},
+		},
+		"alert inside": {
+			input:       "@10:20",
+			want:        nagios.Range{Start: 10, End: 20, AlertInside: true},
+			wantString:  "@10:20",
+			alertValues: []float64{10, 15, 20},
+			okValues:    []float64{9, 21},
+		},
+		"decimal values": {
+			input:       "0.5:1.25",
+			want:        nagios.Range{Start: 0.5, End: 1.25},
+			wantString:  "0.5:1.25",
+			alertValues: []float64{0.4, 1.3},
+			okValues:    []float64{0.5, 1.25},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParseRange(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error parsing %q: %v", tt.input, err)
+			}
+
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+
+			if got.String() != tt.wantString {
+				t.Errorf("want string %q, got %q", tt.wantString, got.String())
+			}
+
+			for _, v := range tt.alertValues {
+				if !got.ShouldAlert(v) {
+					t.Errorf("want alert for value %v with range %q", v, tt.input)
+				}
+			}
+
+			for _, v := range tt.okValues {
+				if got.ShouldAlert(v) {
+					t.Errorf("unexpected alert for value %v with range %q", v, tt.input)
+				}
+			}
+		})
+	}
+}
+
+// TestParseRangeRejectsInvalidInput asserts that invalid threshold ranges
+// are rejected.
+func TestParseRangeRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"", "@", "abc", "10:x", "20:10", "1:2:3"} {
+		if _, err := nagios.ParseRange(input); !errors.Is(err, nagios.ErrInvalidRange) {
+			t.Errorf("%q: want error wrapping %v, got %v", input, nagios.ErrInvalidRange, err)
+		}
+	}
+}
+
+// TestThresholdRangesDriveDisplayAndEvaluation asserts that typed threshold
+// ranges are used for both the THRESHOLDS section and state evaluation.
+func TestThresholdRangesDriveDisplayAndEvaluation(t *testing.T) {
+	t.Parallel()
+
+	warning, err := nagios.ParseRange("80")
+	if err != nil {
+		t.Fatalf("failed to parse warning range: %v", err)
+	}
+
+	critical, err := nagios.ParseRange("90")
+	if err != nil {
+		t.Fatalf("failed to parse critical range: %v", err)
+	}
+
+	plugin := nagios.Plugin{
+		WarningRange:  &warning,
+		CriticalRange: &critical,
+	}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	tests := map[float64]int{
+		50: nagios.StateOKExitCode,
+		85: nagios.StateWARNINGExitCode,
+		95: nagios.StateCRITICALExitCode,
+	}
+
+	for value, want := range tests {
+		if got := plugin.EvaluateThresholds(value); got != want {
+			t.Errorf("value %v: want exit code %d, got %d", value, want, got)
+		}
+	}
+
+	plugin.SetState(plugin.EvaluateThresholds(85))
+	plugin.SetSummary("WARNING: datastore usage 85%")
+	plugin.SetLongServiceOutput("datastore details here")
+
+	// Process exit state, emit output to our output buffer.
+	plugin.ReturnCheckResults()
+
+	want := nagios.CheckOutputEOL +
+		"**THRESHOLDS**" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"* CRITICAL: 90" + nagios.CheckOutputEOL +
+		"* WARNING: 80" + nagios.CheckOutputEOL
+
+	if got := outputBuffer.String(); !strings.Contains(got, want) {
+		t.Errorf("want output containing %q, got %q", want, got)
+	}
+}
+
+// TestSetThresholdsRejectsInvalidSyntax asserts that the threshold setters
+// reject invalid range syntax without modifying existing values.
+func TestSetThresholdsRejectsInvalidSyntax(t *testing.T) {
+	t.Parallel()
+
+	var plugin nagios.Plugin
+
+	if err := plugin.SetWarningThreshold("80"); err != nil {
+		t.Fatalf("failed to set warning threshold: %v", err)
+	}
+
+	if err := plugin.SetCriticalThreshold("@10:5"); !errors.Is(err, nagios.ErrInvalidRange) {
+		t.Errorf("want %v, got %v", nagios.ErrInvalidRange, err)
+	}
+
+	if err := plugin.SetWarningThreshold("eighty"); !errors.Is(err, nagios.ErrInvalidRange) {
+		t.Errorf("want %v, got %v", nagios.ErrInvalidRange, err)
+	}
+
+	if plugin.WarningThreshold != "80" || plugin.WarningRange == nil || plugin.WarningRange.End != 80 {
+		t.Errorf("want warning threshold 80 retained, got %q (%v)", plugin.WarningThreshold, plugin.WarningRange)
+	}
+
+	if plugin.CriticalThreshold != "" || plugin.CriticalRange != nil {
+		t.Errorf("want critical threshold unset, got %q (%v)", plugin.CriticalThreshold, plugin.CriticalRange)
+	}
+
+	if got := plugin.EvaluateThresholds(90); got != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, got)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rangeSetSeparator separates the ranges of a RangeSet.
+const rangeSetSeparator string = ","
+
+// RangeSet is a list of threshold ranges; a value triggers (alerts on) the
+// set if it triggers any of the ranges. This is useful for metrics with a
+// healthy band or several unhealthy bands (e.g., "@~:17.9,@27.1:" alerts if
+// a temperature is below 18°C or above 27°C).
+type RangeSet []Range
+
+// ParseRangeSet parses a comma separated list of threshold ranges (e.g.,
+// "@~:18,@27:"). An error wrapping ErrInvalidRange is returned if any of
+// the ranges cannot be parsed.
+func ParseRangeSet(s string) (RangeSet, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("%w: empty range list %q", ErrInvalidRange, s)
+	}
+
+	parts := strings.Split(s, rangeSetSeparator)
+	set := make(RangeSet, 0, len(parts))
+
+	for _, part := range parts {
+		r, err := ParseRange(part)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, r)
+	}
+
+	return set, nil
+}
+
+// ShouldAlert indicates whether the given value triggers any of the ranges.
+func (rs RangeSet) ShouldAlert(value float64) bool {
+	for _, r := range rs {
+		if r.ShouldAlert(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String provides the ranges as a comma separated list.
+func (rs RangeSet) String() string {
+	ranges := make([]string, len(rs))
+	for i, r := range rs {
+		ranges[i] = r.String()
+	}
+
+	return strings.Join(ranges, rangeSetSeparator)
+}
+
+// Describe provides a plain language description of when the set alerts
+// (e.g., "alert if <= 18 or alert if >= 27").
+func (rs RangeSet) Describe() string {
+	descriptions := make([]string, len(rs))
+	for i, r := range rs {
+		descriptions[i] = r.Describe()
+	}
+
+	return strings.Join(descriptions, " or ")
+}
+
+// SetWarningRangeSet parses the given list of ranges (see ParseRangeSet)
+// and, if valid, uses it in place of WarningRange for evaluation (see
+// EvaluateThresholds) and WarningThreshold for display. An error wrapping
+// ErrInvalidRange is returned and no thresholds are modified if the value
+// cannot be parsed.
+func (p *Plugin) SetWarningRangeSet(ranges string) error {
+	set, err := ParseRangeSet(ranges)
+	if err != nil {
+		return fmt.Errorf("invalid warning threshold: %w", err)
+	}
+
+	p.WarningRange = nil
+	p.WarningThreshold = set.String()
+	p.warningRangeSet = set
+	p.warningThresholdOrigin = ""
+
+	return nil
+}
+
+// SetCriticalRangeSet parses the given list of ranges (see ParseRangeSet)
+// and, if valid, uses it in place of CriticalRange for evaluation (see
+// EvaluateThresholds) and CriticalThreshold for display. An error wrapping
+// ErrInvalidRange is returned and no thresholds are modified if the value
+// cannot be parsed.
+func (p *Plugin) SetCriticalRangeSet(ranges string) error {
+	set, err := ParseRangeSet(ranges)
+	if err != nil {
+		return fmt.Errorf("invalid critical threshold: %w", err)
+	}
+
+	p.CriticalRange = nil
+	p.CriticalThreshold = set.String()
+	p.criticalRangeSet = set
+	p.criticalThresholdOrigin = ""
+
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRangeSetShouldAlert asserts that a value triggers a range set if it
+// triggers any of its ranges.
+func TestRangeSetShouldAlert(t *testing.T) {
+	t.Parallel()
+
+	set, err := nagios.ParseRangeSet("@~:17.9, @27.1:")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for value, want := range map[float64]bool{
+		12:   true,
+		17.9: true,
+		18:   false,
+		22.5: false,
+		27:   false,
+		31:   true,
+	} {
+		if got := set.ShouldAlert(value); got != want {
+			t.Errorf("want %t for %v, got %t", want, value, got)
+		}
+	}
+
+	if got, want := set.String(), "@~:17.9,@27.1:"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if got, want := set.Describe(), "alert if <= 17.9 or alert if >= 27.1"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	for _, s := range []string{"", "10,", "@~:18,high"} {
+		if _, err := nagios.ParseRangeSet(s); !errors.Is(err, nagios.ErrInvalidRange) {
+			t.Errorf("want error for %q, got %v", s, err)
+		}
+	}
+}
+
+// TestSetRangeSets asserts that range sets drive threshold evaluation and
+// display.
+func TestSetRangeSets(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.SetWarningRangeSet("@~:17.9,@27.1:"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := plugin.SetCriticalRangeSet("@~:9.9,@35.1:"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for value, want := range map[float64]int{
+		22: nagios.StateOKExitCode,
+		15: nagios.StateWARNINGExitCode,
+		30: nagios.StateWARNINGExitCode,
+		5:  nagios.StateCRITICALExitCode,
+		40: nagios.StateCRITICALExitCode,
+	} {
+		if got := plugin.EvaluateThresholds(value); got != want {
+			t.Errorf("want state %d for %v, got %d", want, value, got)
+		}
+	}
+
+	plugin.ServiceOutput = "temperature 22°C"
+	plugin.LongServiceOutput = "sensor: intake"
+	plugin.ReturnCheckResults()
+
+	if got, want := outputBuffer.String(), "* WARNING: @~:17.9,@27.1:"; !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
+
+// TestThresholdSettersReplacePreviousThresholds asserts that each threshold
+// setter replaces range sets and threshold origins recorded by setters
+// called earlier, so that stale values are neither evaluated nor
+// displayed.
+func TestThresholdSettersReplacePreviousThresholds(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.SetDefaultThresholds("80", "90"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := plugin.SetWarningRangeSet("@~:17.9,@27.1:"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := plugin.SetCriticalRangeSet("@~:9.9,@35.1:"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Alert once usage of a 100 GB volume exceeds 80% (warning) or 90%
+	// (critical).
+	if err := plugin.SetLimitThresholds(100, nagios.LimitMaximum, "80%", "90%"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for value, want := range map[float64]int{
+		40: nagios.StateOKExitCode,
+		85: nagios.StateWARNINGExitCode,
+		95: nagios.StateCRITICALExitCode,
+	} {
+		if got := plugin.EvaluateThresholds(value); got != want {
+			t.Errorf("limit thresholds: want state %d for %v, got %d", want, value, got)
+		}
+	}
+
+	if err := plugin.SetDefaultThresholds("80", "90"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := plugin.SetLowThresholds("20", "10"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for value, want := range map[float64]int{
+		40: nagios.StateOKExitCode,
+		15: nagios.StateWARNINGExitCode,
+		5:  nagios.StateCRITICALExitCode,
+	} {
+		if got := plugin.EvaluateThresholds(value); got != want {
+			t.Errorf("low thresholds: want state %d for %v, got %d", want, value, got)
+		}
+	}
+
+	plugin.ServiceOutput = "free space 40 GB"
+	plugin.LongServiceOutput = "volume: /data"
+	plugin.ReturnCheckResults()
+
+	if got := outputBuffer.String(); strings.Contains(got, "(default)") {
+		t.Errorf("want output without stale threshold origin, got:\n%s", got)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// checkRateDiagnosticName is the name of the diagnostics entry used to
+// report the sub-check start rate.
+const checkRateDiagnosticName string = "Check rate"
+
+// rateLimiter is a token bucket used to limit the rate at which sub-checks
+// are started.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a rateLimiter allowing rate events per second with
+// bursts of up to burst events. The bucket starts full.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until an event is allowed or ctx is done.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	// Events are allowed one at a time, in the order requested.
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > float64(rl.burst) {
+		rl.tokens = float64(rl.burst)
+	}
+	rl.last = now
+
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return nil
+	}
+
+	delay := time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Return the token reserved for the abandoned event.
+		rl.tokens++
+		return ctx.Err()
+	}
+}
+
+// WithCheckRateLimit is an Option used to limit the rate at which RunChecks
+// starts sub-checks. See also SetCheckRateLimit.
+func WithCheckRateLimit(rate float64, burst int) Option {
+	return func(p *Plugin) {
+		p.SetCheckRateLimit(rate, burst)
+	}
+}
+
+// SetCheckRateLimit limits RunChecks to starting at most rate sub-checks
+// per second, with bursts of up to burst sub-checks, so that plugins
+// probing many devices do not trip intrusion detection thresholds or
+// overload fragile equipment. The configured and effective rates are
+// reported in the diagnostics section (see EnableDiagnostics). A rate which
+// is not positive disables the limit.
+func (p *Plugin) SetCheckRateLimit(rate float64, burst int) {
+	p.checkRateLimit = rate
+	p.checkRateBurst = burst
+}
+
+// checkRateLimiter returns a new rateLimiter for the configured sub-check
+// rate limit or nil if disabled.
+func (p Plugin) checkRateLimiter() *rateLimiter {
+	if p.checkRateLimit <= 0 {
+		return nil
+	}
+
+	return newRateLimiter(p.checkRateLimit, p.checkRateBurst)
+}
+
+// recordCheckRate records the configured and effective sub-check start rates
+// in the diagnostics section.
+func (p *Plugin) recordCheckRate(limiter *rateLimiter, started int, elapsed time.Duration) {
+	effective := 0.0
+	if elapsed > 0 {
+		effective = float64(started) / elapsed.Seconds()
+	}
+
+	p.AddDiagnostic(checkRateDiagnosticName, fmt.Sprintf(
+		"%.1f/s effective (limit %s/s, burst %d)",
+		effective,
+		formatRangeBoundary(limiter.rate),
+		limiter.burst,
+	))
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRunChecksLimitsCheckRate asserts that sub-checks are started no faster
+// than the configured rate limit and that the rate is reported in the
+// diagnostics section.
+func TestRunChecksLimitsCheckRate(t *testing.T) {
+	t.Parallel()
+
+	ok := func(context.Context) nagios.CheckResult {
+		return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+	}
+
+	checks := []nagios.NamedCheck{
+		{Name: "sw01", Check: ok},
+		{Name: "sw02", Check: ok},
+		{Name: "sw03", Check: ok},
+		{Name: "sw04", Check: ok},
+	}
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+	plugin.EnableDiagnostics()
+	plugin.SetCheckRateLimit(20, 2)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	start := time.Now()
+	plugin.RunChecks(checks, 0, 0)
+	elapsed := time.Since(start)
+
+	// Two checks start immediately (burst) and the remaining two at 50ms
+	// intervals.
+	if elapsed < 90*time.Millisecond {
+		t.Errorf("want checks rate limited, all started within %s", elapsed)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+	want := "/s effective (limit 20/s, burst 2)"
+
+	if !strings.Contains(got, "* Check rate: ") || !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
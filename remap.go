@@ -0,0 +1,97 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// stateRemappedNote is appended (in parentheses, followed by the original
+// state label) to the one-line summary of remapped results.
+const stateRemappedNote string = "state remapped from"
+
+// ErrUnknownState indicates that a state label or exit code was not
+// recognized.
+var ErrUnknownState = errors.New("unknown plugin state")
+
+// ParseState returns the exit code of the given state label (e.g.,
+// "critical", case-insensitive) or exit code (e.g., "2"). This is intended
+// for use with command-line flags. An error wrapping ErrUnknownState is
+// returned if the state is not recognized.
+func ParseState(state string) (int, error) {
+	state = strings.TrimSpace(state)
+
+	if exitCode, err := strconv.Atoi(state); err == nil {
+		if !IsValidExitCode(exitCode) {
+			return StateUNKNOWNExitCode, fmt.Errorf("%w: %q", ErrUnknownState, state)
+		}
+
+		return exitCode, nil
+	}
+
+	exitCode := stateExitCode(strings.ToUpper(state))
+	if exitCode < 0 {
+		return StateUNKNOWNExitCode, fmt.Errorf("%w: %q", ErrUnknownState, state)
+	}
+
+	return exitCode, nil
+}
+
+// WithStateRemapping is an Option used to remap the final plugin state. See
+// also SetStateRemapping.
+func WithStateRemapping(mapping StateMapping) Option {
+	return func(p *Plugin) {
+		p.SetStateRemapping(mapping)
+	}
+}
+
+// SetStateRemapping remaps the final plugin state using the given mapping
+// (e.g., UNKNOWN to CRITICAL or WARNING to OK), allowing operators to
+// adjust severity per environment without code changes (see the cmdline
+// package for the related flags). Remapping is applied after all other
+// state adjustments (e.g., escalation by recorded errors or downgrades
+// while in downtime).
+//
+// Remapped results are reported honestly: the leading state label of the
+// one-line summary is replaced and the original state is noted:
+//
+//	CRITICAL: connection timed out (state remapped from UNKNOWN)
+func (p *Plugin) SetStateRemapping(mapping StateMapping) {
+	p.stateRemapping = mapping
+}
+
+// applyStateRemapping remaps the final plugin state as requested via
+// SetStateRemapping and annotates the one-line summary.
+func (p *Plugin) applyStateRemapping() {
+	original := p.ExitStatusCode
+
+	remapped := p.stateRemapping.Apply(original)
+	if remapped == original {
+		return
+	}
+
+	p.ExitStatusCode = remapped
+
+	summary := strings.TrimRight(p.ServiceOutput, trailingWhitespaceCutSet)
+	p.ServiceOutput = fmt.Sprintf(
+		"%s (%s %s)%s",
+		substituteStateLabel(summary, stateLabel(original), stateLabel(remapped)),
+		p.message(MessageStateRemapped, stateRemappedNote),
+		stateLabel(original),
+		p.ServiceOutput[len(summary):],
+	)
+
+	p.Logger().Info(
+		"plugin state remapped",
+		"from", stateLabel(original),
+		"to", stateLabel(remapped),
+	)
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestStateRemappingNotesOriginalState asserts that the final plugin state
+// is remapped and that the original state is noted in the one-line
+// summary.
+func TestStateRemappingNotesOriginalState(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithStateRemapping(nagios.StateMapping{
+			nagios.StateUNKNOWNExitCode: nagios.StateCRITICALExitCode,
+		}),
+	)
+
+	plugin.Unknown("connection timed out")
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	want := "CRITICAL: connection timed out (state remapped from UNKNOWN)"
+	if got := outputBuffer.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("want output to start with %q, got:\n%s", want, got)
+	}
+}
+
+// TestStateRemappingLeavesUnmappedStates asserts that states without a
+// mapping are reported unchanged.
+func TestStateRemappingLeavesUnmappedStates(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithStateRemapping(nagios.StateMapping{
+			nagios.StateWARNINGExitCode: nagios.StateOKExitCode,
+		}),
+	)
+
+	plugin.Critical("disk full")
+	plugin.ReturnCheckResults()
+
+	if got := outputBuffer.String(); strings.Contains(got, "remapped") ||
+		plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want unmodified CRITICAL result, got exit code %d and output:\n%s", plugin.ExitStatusCode, got)
+	}
+}
+
+// TestParseStateAcceptsLabelsAndExitCodes asserts that states are parsed
+// from case-insensitive labels and exit codes.
+func TestParseStateAcceptsLabelsAndExitCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"ok":       nagios.StateOKExitCode,
+		"Warning":  nagios.StateWARNINGExitCode,
+		"CRITICAL": nagios.StateCRITICALExitCode,
+		"3":        nagios.StateUNKNOWNExitCode,
+	}
+
+	for state, want := range tests {
+		got, err := nagios.ParseState(state)
+		if err != nil || got != want {
+			t.Errorf("%s: want %d, got %d (%v)", state, want, got, err)
+		}
+	}
+
+	for _, state := range []string{"", "fatal", "7"} {
+		if _, err := nagios.ParseState(state); !errors.Is(err, nagios.ErrUnknownState) {
+			t.Errorf("%q: want error %v, got %v", state, nagios.ErrUnknownState, err)
+		}
+	}
+}
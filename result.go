@@ -0,0 +1,176 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ResultSchemaVersion is the version of the JSON representation of plugin
+// results produced by this library version. The version is incremented if
+// fields are removed or their meaning changes; new optional fields may be
+// added without incrementing it.
+const ResultSchemaVersion int = 1
+
+// ErrResultSchemaUnsupported indicates that a JSON plugin result uses a
+// schema version newer than the one supported by this library version.
+var ErrResultSchemaUnsupported = errors.New("unsupported result schema version")
+
+// Result is the complete result recorded by a plugin in a form suitable for
+// transport across process boundaries (e.g., from check workers to a parent
+// process which emits the result). Errors are represented as strings.
+type Result struct {
+	// SchemaVersion is the version of the result format. See also
+	// ResultSchemaVersion.
+	SchemaVersion int `json:"schema_version"`
+
+	// State is the state label (e.g., "CRITICAL") of the result.
+	State string `json:"state"`
+
+	// ExitCode is the plugin exit status code of the result.
+	ExitCode int `json:"exit_code"`
+
+	// ServiceOutput is the one-line summary of the result.
+	ServiceOutput string `json:"service_output"`
+
+	// LongServiceOutput is the detailed output of the result.
+	LongServiceOutput string `json:"long_service_output,omitempty"`
+
+	// Errors is the text of the recorded errors.
+	Errors []string `json:"errors,omitempty"`
+
+	// PerfData is the collection of performance data metrics sorted by
+	// label.
+	PerfData []PerformanceData `json:"perfdata,omitempty"`
+
+	// WarningThreshold is the warning threshold (e.g., "80" or "@10:20").
+	WarningThreshold string `json:"warning_threshold,omitempty"`
+
+	// CriticalThreshold is the critical threshold.
+	CriticalThreshold string `json:"critical_threshold,omitempty"`
+
+	// Links is the collection of hyperlinks attached to the result.
+	Links []Link `json:"links,omitempty"`
+}
+
+// Result returns the result currently recorded by the plugin. See also
+// ImportResult.
+func (p *Plugin) Result() Result {
+	result := Result{
+		SchemaVersion:     ResultSchemaVersion,
+		State:             stateLabel(p.ExitStatusCode),
+		ExitCode:          p.ExitStatusCode,
+		ServiceOutput:     p.ServiceOutput,
+		LongServiceOutput: p.LongServiceOutput,
+		PerfData:          p.getSortedPerfData(),
+		WarningThreshold:  p.WarningThreshold,
+		CriticalThreshold: p.CriticalThreshold,
+		Links:             append([]Link(nil), p.links...),
+	}
+
+	if result.WarningThreshold == "" && p.WarningRange != nil {
+		result.WarningThreshold = p.WarningRange.String()
+	}
+
+	if result.CriticalThreshold == "" && p.CriticalRange != nil {
+		result.CriticalThreshold = p.CriticalRange.String()
+	}
+
+	errs := p.Errors
+	if len(errs) == 0 && p.LastError != nil {
+		errs = []error{p.LastError}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	return result
+}
+
+// ImportResult replaces the result recorded by the plugin (state, summary,
+// LongServiceOutput, errors, performance data, thresholds and links) with
+// the given result, allowing a parent process to emit a result recorded by
+// a check worker. Plugin settings (e.g., output target or sections) are
+// retained. Thresholds which are valid ranges also set WarningRange and
+// CriticalRange. The receiver is returned to allow chaining further calls.
+func (p *Plugin) ImportResult(result Result) *Plugin {
+	p.ExitStatusCode = result.ExitCode
+	p.ServiceOutput = result.ServiceOutput
+	p.LongServiceOutput = result.LongServiceOutput
+	p.stateDrivenBy = nil
+	p.LastError = nil
+
+	p.Errors = nil
+	for _, text := range result.Errors {
+		p.Errors = append(p.Errors, errors.New(text))
+	}
+
+	p.perfData = nil
+	if len(result.PerfData) > 0 {
+		// Validation was applied by the plugin which recorded the result.
+		_ = p.AddPerfData(true, result.PerfData...)
+	}
+
+	p.WarningThreshold, p.WarningRange = importThreshold(result.WarningThreshold)
+	p.CriticalThreshold, p.CriticalRange = importThreshold(result.CriticalThreshold)
+
+	p.links = append([]Link(nil), result.Links...)
+
+	return p
+}
+
+// importThreshold returns the display text and, if the threshold is a valid
+// range, the parsed range of the given threshold.
+func importThreshold(threshold string) (string, *Range) {
+	if strings.TrimSpace(threshold) == "" {
+		return "", nil
+	}
+
+	r, err := ParseRange(threshold)
+	if err != nil {
+		return threshold, nil
+	}
+
+	return threshold, &r
+}
+
+// MarshalJSON encodes the result recorded by the plugin as JSON. See also
+// Result.
+func (p *Plugin) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Result())
+}
+
+// UnmarshalJSON decodes a JSON result (as produced by MarshalJSON) and
+// imports it as described by ImportResult. An error wrapping
+// ErrResultSchemaUnsupported is returned if the result uses a newer schema
+// version.
+func (p *Plugin) UnmarshalJSON(data []byte) error {
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	if result.SchemaVersion > ResultSchemaVersion {
+		return fmt.Errorf(
+			"%w: %d (supported: %d)",
+			ErrResultSchemaUnsupported,
+			result.SchemaVersion,
+			ResultSchemaVersion,
+		)
+	}
+
+	p.ImportResult(result)
+
+	return nil
+}
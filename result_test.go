@@ -0,0 +1,93 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestResultJSONRoundTrip asserts that a result marshalled by a check worker
+// and unmarshalled by a parent process is emitted the same way by both.
+func TestResultJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var workerOutput strings.Builder
+
+	worker := nagios.Plugin{}
+	worker.SetOutputTarget(&workerOutput)
+
+	// os.Exit calls break tests
+	worker.SkipOSExit()
+
+	worker.Critical("disk /var is 97% full").
+		WithDetail("mount: /var").
+		WithError(errors.New("inode usage unavailable")).
+		WithPerfData(nagios.PerformanceData{Label: "var_used", Value: "97", UnitOfMeasurement: "%"})
+
+	if err := worker.SetCriticalThreshold("95"); err != nil {
+		t.Fatalf("failed to set critical threshold: %v", err)
+	}
+
+	data, err := json.Marshal(&worker)
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+
+	for _, want := range []string{
+		`"schema_version":1`,
+		`"state":"CRITICAL"`,
+		`"exit_code":2`,
+		`"errors":["inode usage unavailable"]`,
+		`"critical_threshold":"95"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("want JSON to contain %q, got:\n%s", want, data)
+		}
+	}
+
+	var parentOutput strings.Builder
+
+	parent := nagios.Plugin{}
+	parent.SetOutputTarget(&parentOutput)
+
+	// os.Exit calls break tests
+	parent.SkipOSExit()
+
+	if err := json.Unmarshal(data, &parent); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+
+	worker.ReturnCheckResults()
+	parent.ReturnCheckResults()
+
+	if parent.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateCRITICALExitCode, parent.ExitStatusCode)
+	}
+
+	if want, got := workerOutput.String(), parentOutput.String(); got != want {
+		t.Errorf("want re-emitted output:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// TestResultJSONRejectsNewerSchema asserts that results using a newer
+// schema version are rejected.
+func TestResultJSONRejectsNewerSchema(t *testing.T) {
+	t.Parallel()
+
+	var plugin nagios.Plugin
+
+	err := json.Unmarshal([]byte(`{"schema_version":99,"exit_code":0}`), &plugin)
+	if !errors.Is(err, nagios.ErrResultSchemaUnsupported) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrResultSchemaUnsupported, err)
+	}
+}
@@ -0,0 +1,416 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package resultpb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// MaxMessageSize is the maximum size in bytes of a length delimited
+// message read by ReadDelimited.
+const MaxMessageSize int = 16 * 1024 * 1024
+
+// ErrInvalidMessage indicates that a protocol buffer message could not be
+// decoded.
+var ErrInvalidMessage = errors.New("invalid check result message")
+
+// CheckResult fields.
+const (
+	fieldSchemaVersion      int = 1
+	fieldExitCode           int = 2
+	fieldServiceOutput      int = 3
+	fieldLongServiceOutput  int = 4
+	fieldErrors             int = 5
+	fieldPerfData           int = 6
+	fieldWarningThreshold   int = 7
+	fieldCriticalThreshold  int = 8
+	fieldLinks              int = 9
+	fieldHostName           int = 10
+	fieldServiceDescription int = 11
+	fieldCheckTime          int = 12
+)
+
+// PerfData, MetricMetadata, Link and map entry fields.
+const (
+	fieldPerfDataLabel    int = 1
+	fieldPerfDataValue    int = 2
+	fieldPerfDataUOM      int = 3
+	fieldPerfDataWarn     int = 4
+	fieldPerfDataCrit     int = 5
+	fieldPerfDataMin      int = 6
+	fieldPerfDataMax      int = 7
+	fieldPerfDataMetadata int = 8
+
+	fieldMetadataDisplayName int = 1
+	fieldMetadataDescription int = 2
+	fieldMetadataGraphHints  int = 3
+
+	fieldLinkKind int = 1
+	fieldLinkURL  int = 2
+
+	fieldMapKey   int = 1
+	fieldMapValue int = 2
+)
+
+// CheckResult is a check result as transported from (remote) agents to a
+// central forwarder: the complete plugin result along with the host and
+// service it applies to.
+type CheckResult struct {
+	// HostName is the name of the host the result applies to.
+	HostName string
+
+	// ServiceDescription is the description of the service the result
+	// applies to. This is empty for host check results.
+	ServiceDescription string
+
+	// CheckTime is the time the check completed.
+	CheckTime time.Time
+
+	// Result is the plugin result. See also nagios.Plugin Result method.
+	Result nagios.Result
+}
+
+// Marshal encodes the check result as a CheckResult protocol buffer
+// message (see result.proto).
+func Marshal(cr CheckResult) []byte {
+	var e encoder
+
+	r := cr.Result
+
+	e.int64(fieldSchemaVersion, int64(r.SchemaVersion))
+	e.int64(fieldExitCode, int64(r.ExitCode))
+	e.string(fieldServiceOutput, r.ServiceOutput)
+	e.string(fieldLongServiceOutput, r.LongServiceOutput)
+	e.repeatedString(fieldErrors, r.Errors)
+
+	for _, pd := range r.PerfData {
+		pd := pd
+		e.message(fieldPerfData, func(e *encoder) { encodePerfData(e, pd) })
+	}
+
+	e.string(fieldWarningThreshold, r.WarningThreshold)
+	e.string(fieldCriticalThreshold, r.CriticalThreshold)
+
+	for _, link := range r.Links {
+		link := link
+		e.message(fieldLinks, func(e *encoder) {
+			e.string(fieldLinkKind, link.Kind)
+			e.string(fieldLinkURL, link.URL)
+		})
+	}
+
+	e.string(fieldHostName, cr.HostName)
+	e.string(fieldServiceDescription, cr.ServiceDescription)
+
+	if !cr.CheckTime.IsZero() {
+		e.int64(fieldCheckTime, cr.CheckTime.UnixNano())
+	}
+
+	return e.buf
+}
+
+// encodePerfData encodes the fields of a PerfData message.
+func encodePerfData(e *encoder, pd nagios.PerformanceData) {
+	e.string(fieldPerfDataLabel, pd.Label)
+	e.string(fieldPerfDataValue, pd.Value)
+	e.string(fieldPerfDataUOM, pd.UnitOfMeasurement)
+	e.string(fieldPerfDataWarn, pd.Warn)
+	e.string(fieldPerfDataCrit, pd.Crit)
+	e.string(fieldPerfDataMin, pd.Min)
+	e.string(fieldPerfDataMax, pd.Max)
+
+	if pd.Metadata == nil {
+		return
+	}
+
+	metadata := pd.Metadata
+	e.message(fieldPerfDataMetadata, func(e *encoder) {
+		e.string(fieldMetadataDisplayName, metadata.DisplayName)
+		e.string(fieldMetadataDescription, metadata.Description)
+
+		// Map entries are sorted by key for deterministic output.
+		keys := make([]string, 0, len(metadata.GraphHints))
+		for key := range metadata.GraphHints {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value := metadata.GraphHints[key]
+			e.message(fieldMetadataGraphHints, func(e *encoder) {
+				e.string(fieldMapKey, key)
+				e.string(fieldMapValue, value)
+			})
+		}
+	})
+}
+
+// Unmarshal decodes a CheckResult protocol buffer message. Unknown fields
+// are ignored. An error wrapping ErrInvalidMessage is returned if the
+// message is malformed.
+func Unmarshal(data []byte) (CheckResult, error) {
+	var cr CheckResult
+
+	d := decoder{buf: data}
+
+	for {
+		field, wireType, ok, err := d.next()
+		if err != nil {
+			return CheckResult{}, err
+		}
+
+		if !ok {
+			break
+		}
+
+		r := &cr.Result
+
+		switch field {
+		case fieldSchemaVersion:
+			var v int64
+			v, err = d.int64(wireType)
+			r.SchemaVersion = int(v)
+		case fieldExitCode:
+			var v int64
+			v, err = d.int64(wireType)
+			r.ExitCode = int(int32(v))
+		case fieldServiceOutput:
+			r.ServiceOutput, err = d.string(wireType)
+		case fieldLongServiceOutput:
+			r.LongServiceOutput, err = d.string(wireType)
+		case fieldErrors:
+			var s string
+			s, err = d.string(wireType)
+			r.Errors = append(r.Errors, s)
+		case fieldPerfData:
+			var pd nagios.PerformanceData
+			pd, err = decodePerfData(&d, wireType)
+			r.PerfData = append(r.PerfData, pd)
+		case fieldWarningThreshold:
+			r.WarningThreshold, err = d.string(wireType)
+		case fieldCriticalThreshold:
+			r.CriticalThreshold, err = d.string(wireType)
+		case fieldLinks:
+			var link nagios.Link
+			link, err = decodeLink(&d, wireType)
+			r.Links = append(r.Links, link)
+		case fieldHostName:
+			cr.HostName, err = d.string(wireType)
+		case fieldServiceDescription:
+			cr.ServiceDescription, err = d.string(wireType)
+		case fieldCheckTime:
+			var v int64
+			v, err = d.int64(wireType)
+			cr.CheckTime = time.Unix(0, v)
+		default:
+			err = d.skip(wireType)
+		}
+
+		if err != nil {
+			return CheckResult{}, err
+		}
+	}
+
+	cr.Result.State = nagios.ServiceStateFromExitCode(cr.Result.ExitCode).Label
+
+	return cr, nil
+}
+
+// decodePerfData decodes an embedded PerfData message.
+func decodePerfData(d *decoder, wireType int) (nagios.PerformanceData, error) {
+	var pd nagios.PerformanceData
+
+	b, err := d.bytes(wireType)
+	if err != nil {
+		return pd, err
+	}
+
+	embedded := decoder{buf: b}
+
+	for {
+		field, wireType, ok, err := embedded.next()
+		if err != nil || !ok {
+			return pd, err
+		}
+
+		switch field {
+		case fieldPerfDataLabel:
+			pd.Label, err = embedded.string(wireType)
+		case fieldPerfDataValue:
+			pd.Value, err = embedded.string(wireType)
+		case fieldPerfDataUOM:
+			pd.UnitOfMeasurement, err = embedded.string(wireType)
+		case fieldPerfDataWarn:
+			pd.Warn, err = embedded.string(wireType)
+		case fieldPerfDataCrit:
+			pd.Crit, err = embedded.string(wireType)
+		case fieldPerfDataMin:
+			pd.Min, err = embedded.string(wireType)
+		case fieldPerfDataMax:
+			pd.Max, err = embedded.string(wireType)
+		case fieldPerfDataMetadata:
+			var metadata nagios.MetricMetadata
+			metadata, err = decodeMetadata(&embedded, wireType)
+			pd.Metadata = &metadata
+		default:
+			err = embedded.skip(wireType)
+		}
+
+		if err != nil {
+			return pd, err
+		}
+	}
+}
+
+// decodeMetadata decodes an embedded MetricMetadata message.
+func decodeMetadata(d *decoder, wireType int) (nagios.MetricMetadata, error) {
+	var metadata nagios.MetricMetadata
+
+	b, err := d.bytes(wireType)
+	if err != nil {
+		return metadata, err
+	}
+
+	embedded := decoder{buf: b}
+
+	for {
+		field, wireType, ok, err := embedded.next()
+		if err != nil || !ok {
+			return metadata, err
+		}
+
+		switch field {
+		case fieldMetadataDisplayName:
+			metadata.DisplayName, err = embedded.string(wireType)
+		case fieldMetadataDescription:
+			metadata.Description, err = embedded.string(wireType)
+		case fieldMetadataGraphHints:
+			var key, value string
+			key, value, err = decodeMapEntry(&embedded, wireType)
+			if metadata.GraphHints == nil {
+				metadata.GraphHints = make(map[string]string)
+			}
+			metadata.GraphHints[key] = value
+		default:
+			err = embedded.skip(wireType)
+		}
+
+		if err != nil {
+			return metadata, err
+		}
+	}
+}
+
+// decodeMapEntry decodes an embedded map<string, string> entry.
+func decodeMapEntry(d *decoder, wireType int) (string, string, error) {
+	var key, value string
+
+	b, err := d.bytes(wireType)
+	if err != nil {
+		return key, value, err
+	}
+
+	embedded := decoder{buf: b}
+
+	for {
+		field, wireType, ok, err := embedded.next()
+		if err != nil || !ok {
+			return key, value, err
+		}
+
+		switch field {
+		case fieldMapKey:
+			key, err = embedded.string(wireType)
+		case fieldMapValue:
+			value, err = embedded.string(wireType)
+		default:
+			err = embedded.skip(wireType)
+		}
+
+		if err != nil {
+			return key, value, err
+		}
+	}
+}
+
+// decodeLink decodes an embedded Link message.
+func decodeLink(d *decoder, wireType int) (nagios.Link, error) {
+	var link nagios.Link
+
+	b, err := d.bytes(wireType)
+	if err != nil {
+		return link, err
+	}
+
+	embedded := decoder{buf: b}
+
+	for {
+		field, wireType, ok, err := embedded.next()
+		if err != nil || !ok {
+			return link, err
+		}
+
+		switch field {
+		case fieldLinkKind:
+			link.Kind, err = embedded.string(wireType)
+		case fieldLinkURL:
+			link.URL, err = embedded.string(wireType)
+		default:
+			err = embedded.skip(wireType)
+		}
+
+		if err != nil {
+			return link, err
+		}
+	}
+}
+
+// WriteDelimited writes the check result to w as a length delimited
+// message (the message size as a varint followed by the message), allowing
+// a stream of results to be sent over a single connection.
+func WriteDelimited(w io.Writer, cr CheckResult) error {
+	message := Marshal(cr)
+
+	buf := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(message)), uint64(len(message)))
+	buf = append(buf, message...)
+
+	_, err := w.Write(buf)
+
+	return err
+}
+
+// ReadDelimited reads a length delimited check result written by
+// WriteDelimited. io.EOF is returned if no further results are available.
+// An error wrapping ErrInvalidMessage is returned if the message is
+// malformed or larger than MaxMessageSize.
+func ReadDelimited(r *bufio.Reader) (CheckResult, error) {
+	size, err := binary.ReadUvarint(r)
+	switch {
+	case errors.Is(err, io.EOF):
+		return CheckResult{}, io.EOF
+	case err != nil:
+		return CheckResult{}, fmt.Errorf("%w: invalid message size: %v", ErrInvalidMessage, err)
+	case size > uint64(MaxMessageSize):
+		return CheckResult{}, fmt.Errorf("%w: message size %d exceeds %d bytes", ErrInvalidMessage, size, MaxMessageSize)
+	}
+
+	message := make([]byte, size)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return CheckResult{}, fmt.Errorf("%w: truncated message: %v", ErrInvalidMessage, err)
+	}
+
+	return Unmarshal(message)
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package resultpb_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/resultpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+// sampleCheckResult returns a check result using every field of the schema.
+func sampleCheckResult() resultpb.CheckResult {
+	return resultpb.CheckResult{
+		HostName:           "web01",
+		ServiceDescription: "disk /var",
+		CheckTime:          time.Unix(1700000000, 123456789),
+		Result: nagios.Result{
+			SchemaVersion:     nagios.ResultSchemaVersion,
+			State:             nagios.StateCRITICALLabel,
+			ExitCode:          nagios.StateCRITICALExitCode,
+			ServiceOutput:     "CRITICAL: disk /var is 97% full",
+			LongServiceOutput: "mount: /var\nfilesystem: xfs",
+			Errors:            []string{"inode usage unavailable"},
+			PerfData: []nagios.PerformanceData{
+				{Label: "var_free", Value: "3", UnitOfMeasurement: "%", Min: "0", Max: "100"},
+				(nagios.PerformanceData{Label: "var_used", Value: "97", Warn: "80", Crit: "95"}).WithMetadata(
+					nagios.MetricMetadata{
+						DisplayName: "Used space",
+						GraphHints:  map[string]string{nagios.GraphHintType: "gauge"},
+					},
+				),
+			},
+			WarningThreshold:  "80",
+			CriticalThreshold: "95",
+			Links:             []nagios.Link{{Kind: nagios.LinkRunbook, URL: "https://wiki.example.com/disk"}},
+		},
+	}
+}
+
+// TestMarshalRoundTrip asserts that decoding an encoded check result
+// returns an identical check result.
+func TestMarshalRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := sampleCheckResult()
+
+	got, err := resultpb.Unmarshal(resultpb.Marshal(want))
+	if err != nil {
+		t.Fatalf("failed to decode check result: %v", err)
+	}
+
+	if !got.CheckTime.Equal(want.CheckTime) {
+		t.Errorf("want check time %v, got %v", want.CheckTime, got.CheckTime)
+	}
+	got.CheckTime = want.CheckTime
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestMarshalWireFormat asserts that check results are encoded using the
+// protocol buffer wire format with default values omitted.
+func TestMarshalWireFormat(t *testing.T) {
+	t.Parallel()
+
+	got := resultpb.Marshal(resultpb.CheckResult{
+		Result: nagios.Result{
+			SchemaVersion: 1,
+			ExitCode:      nagios.StateWARNINGExitCode,
+			ServiceOutput: "x",
+		},
+	})
+
+	want := []byte{0x08, 0x01, 0x10, 0x01, 0x1a, 0x01, 'x'}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("want encoded message % x, got % x", want, got)
+	}
+}
+
+// TestUnmarshalSkipsUnknownFields asserts that fields added by newer schema
+// versions are ignored.
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	message := append(
+		resultpb.Marshal(resultpb.CheckResult{HostName: "web01"}),
+		// Field 99, varint; field 100, fixed32; field 101, bytes.
+		0x98, 0x06, 0x2a,
+		0xa5, 0x06, 0x01, 0x02, 0x03, 0x04,
+		0xaa, 0x06, 0x02, 'o', 'k',
+	)
+
+	got, err := resultpb.Unmarshal(message)
+	if err != nil {
+		t.Fatalf("failed to decode check result: %v", err)
+	}
+
+	if got.HostName != "web01" {
+		t.Errorf("want host name %q, got %q", "web01", got.HostName)
+	}
+
+	if got.Result.State != nagios.StateOKLabel {
+		t.Errorf("want state %q, got %q", nagios.StateOKLabel, got.Result.State)
+	}
+}
+
+// TestUnmarshalRejectsTruncatedMessage asserts that malformed messages are
+// rejected.
+func TestUnmarshalRejectsTruncatedMessage(t *testing.T) {
+	t.Parallel()
+
+	message := resultpb.Marshal(sampleCheckResult())
+
+	_, err := resultpb.Unmarshal(message[:len(message)-3])
+	if !errors.Is(err, resultpb.ErrInvalidMessage) {
+		t.Errorf("want error wrapping %v, got %v", resultpb.ErrInvalidMessage, err)
+	}
+}
+
+// TestDelimitedStream asserts that a stream of length delimited check
+// results can be read back in order.
+func TestDelimitedStream(t *testing.T) {
+	t.Parallel()
+
+	var stream bytes.Buffer
+
+	first := sampleCheckResult()
+	second := resultpb.CheckResult{HostName: "web02", Result: nagios.Result{ServiceOutput: "OK: up"}}
+
+	for _, cr := range []resultpb.CheckResult{first, second} {
+		if err := resultpb.WriteDelimited(&stream, cr); err != nil {
+			t.Fatalf("failed to write check result: %v", err)
+		}
+	}
+
+	r := bufio.NewReader(&stream)
+
+	for _, want := range []string{"web01", "web02"} {
+		cr, err := resultpb.ReadDelimited(r)
+		if err != nil {
+			t.Fatalf("failed to read check result: %v", err)
+		}
+
+		if cr.HostName != want {
+			t.Errorf("want host name %q, got %q", want, cr.HostName)
+		}
+	}
+
+	if _, err := resultpb.ReadDelimited(r); !errors.Is(err, io.EOF) {
+		t.Errorf("want io.EOF at end of stream, got %v", err)
+	}
+}
+
+// TestRequestRoundTrip asserts that decoding encoded service requests
+// returns identical requests.
+func TestRequestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	execute := resultpb.ExecuteCheckRequest{Name: "disk", Args: []string{"--path", "/var"}}
+
+	gotExecute, err := resultpb.UnmarshalExecuteCheckRequest(resultpb.MarshalExecuteCheckRequest(execute))
+	if err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+
+	if d := cmp.Diff(execute, gotExecute); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	stream := resultpb.StreamResultsRequest{
+		Names:    []string{"disk", "load"},
+		Args:     []string{"-v"},
+		Interval: 30 * time.Second,
+	}
+
+	gotStream, err := resultpb.UnmarshalStreamResultsRequest(resultpb.MarshalStreamResultsRequest(stream))
+	if err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+
+	if d := cmp.Diff(stream, gotStream); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package resultpb provides a protocol buffer schema and codec for check
+results, used to transport results compactly from (remote) Go agents to a
+central forwarder.
+
+# OVERVIEW
+
+The schema is defined in result.proto and mirrors the nagios.Result type
+along with the host, service and time of the check. This package
+implements the encoding by hand, following the protocol buffer wire format
+specification, rather than using code generated from the schema so that
+the module remains free of third-party runtime dependencies.
+
+The encoding is tested against hand-computed encodings only; it has not
+been verified against code generated by protoc. Code generated from the
+schema for other languages is expected to decode the messages, but
+interoperability should be confirmed before relying on it.
+
+# FEATURES
+
+  - CheckResult type pairing a nagios.Result with the host, service and
+    time of the check
+  - Marshal and Unmarshal functions used to encode and decode CheckResult
+    messages; unknown fields are skipped so that newer schema versions can
+    be read
+  - WriteDelimited and ReadDelimited functions used to send a stream of
+    length delimited results over a single connection
+  - ExecuteCheckRequest and StreamResultsRequest messages of the remote
+    check execution service defined in service.proto (see the checkrpc
+    package)
+
+See also:
+
+  - https://protobuf.dev/programming-guides/encoding/
+*/
+package resultpb
@@ -0,0 +1,139 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package resultpb
+
+import (
+	"time"
+)
+
+// ExecuteCheckRequest and StreamResultsRequest fields.
+const (
+	fieldExecuteCheckName int = 1
+	fieldExecuteCheckArgs int = 2
+
+	fieldStreamResultsNames    int = 1
+	fieldStreamResultsArgs     int = 2
+	fieldStreamResultsInterval int = 3
+)
+
+// ExecuteCheckRequest identifies the check run by the ExecuteCheck method
+// of the CheckService (see service.proto).
+type ExecuteCheckRequest struct {
+	// Name is the name the check is registered with.
+	Name string
+
+	// Args are passed to the check.
+	Args []string
+}
+
+// StreamResultsRequest identifies the checks whose results are streamed by
+// the StreamResults method of the CheckService (see service.proto).
+type StreamResultsRequest struct {
+	// Names are the names of the checks to run. All registered checks are
+	// run if empty.
+	Names []string
+
+	// Args are passed to each check.
+	Args []string
+
+	// Interval is the interval at which the checks are repeated. The
+	// checks are run once if zero. The interval is transported with
+	// millisecond precision.
+	Interval time.Duration
+}
+
+// MarshalExecuteCheckRequest encodes the request as an ExecuteCheckRequest
+// protocol buffer message.
+func MarshalExecuteCheckRequest(req ExecuteCheckRequest) []byte {
+	var e encoder
+
+	e.string(fieldExecuteCheckName, req.Name)
+	e.repeatedString(fieldExecuteCheckArgs, req.Args)
+
+	return e.buf
+}
+
+// UnmarshalExecuteCheckRequest decodes an ExecuteCheckRequest protocol
+// buffer message. An error wrapping ErrInvalidMessage is returned if the
+// message is malformed.
+func UnmarshalExecuteCheckRequest(data []byte) (ExecuteCheckRequest, error) {
+	var req ExecuteCheckRequest
+
+	d := decoder{buf: data}
+
+	for {
+		field, wireType, ok, err := d.next()
+		if err != nil || !ok {
+			return req, err
+		}
+
+		switch field {
+		case fieldExecuteCheckName:
+			req.Name, err = d.string(wireType)
+		case fieldExecuteCheckArgs:
+			var arg string
+			arg, err = d.string(wireType)
+			req.Args = append(req.Args, arg)
+		default:
+			err = d.skip(wireType)
+		}
+
+		if err != nil {
+			return ExecuteCheckRequest{}, err
+		}
+	}
+}
+
+// MarshalStreamResultsRequest encodes the request as a StreamResultsRequest
+// protocol buffer message.
+func MarshalStreamResultsRequest(req StreamResultsRequest) []byte {
+	var e encoder
+
+	e.repeatedString(fieldStreamResultsNames, req.Names)
+	e.repeatedString(fieldStreamResultsArgs, req.Args)
+	e.int64(fieldStreamResultsInterval, req.Interval.Milliseconds())
+
+	return e.buf
+}
+
+// UnmarshalStreamResultsRequest decodes a StreamResultsRequest protocol
+// buffer message. An error wrapping ErrInvalidMessage is returned if the
+// message is malformed.
+func UnmarshalStreamResultsRequest(data []byte) (StreamResultsRequest, error) {
+	var req StreamResultsRequest
+
+	d := decoder{buf: data}
+
+	for {
+		field, wireType, ok, err := d.next()
+		if err != nil || !ok {
+			return req, err
+		}
+
+		switch field {
+		case fieldStreamResultsNames:
+			var name string
+			name, err = d.string(wireType)
+			req.Names = append(req.Names, name)
+		case fieldStreamResultsArgs:
+			var arg string
+			arg, err = d.string(wireType)
+			req.Args = append(req.Args, arg)
+		case fieldStreamResultsInterval:
+			var millis int64
+			millis, err = d.int64(wireType)
+			req.Interval = time.Duration(millis) * time.Millisecond
+		default:
+			err = d.skip(wireType)
+		}
+
+		if err != nil {
+			return StreamResultsRequest{}, err
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package resultpb
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  int = 0
+	wireFixed64 int = 1
+	wireBytes   int = 2
+	wireFixed32 int = 5
+)
+
+// encoder appends protocol buffer fields to a buffer. Fields with default
+// (zero) values are omitted as required by proto3.
+type encoder struct {
+	buf []byte
+}
+
+// tag appends the key of the given field.
+func (e *encoder) tag(field int, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+// int64 appends an int32 or int64 field. Negative values are encoded as ten
+// byte varints as required for compatibility.
+func (e *encoder) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+// string appends a string field.
+func (e *encoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+
+	e.bytes(field, []byte(s))
+}
+
+// repeatedString appends a repeated string field. Empty elements are
+// retained.
+func (e *encoder) repeatedString(field int, values []string) {
+	for _, s := range values {
+		e.bytes(field, []byte(s))
+	}
+}
+
+// bytes appends a length delimited field.
+func (e *encoder) bytes(field int, b []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// message appends an embedded message field encoded by fn.
+func (e *encoder) message(field int, fn func(e *encoder)) {
+	var embedded encoder
+	fn(&embedded)
+	e.bytes(field, embedded.buf)
+}
+
+// decoder reads protocol buffer fields from a buffer.
+type decoder struct {
+	buf []byte
+}
+
+// next reads the key of the next field. The ok value is false once the
+// buffer is exhausted.
+func (d *decoder) next() (field int, wireType int, ok bool, err error) {
+	if len(d.buf) == 0 {
+		return 0, 0, false, nil
+	}
+
+	key, err := d.uvarint()
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	field, wireType = int(key>>3), int(key&0x7)
+	if field < 1 {
+		return 0, 0, false, fmt.Errorf("%w: invalid field number %d", ErrInvalidMessage, field)
+	}
+
+	return field, wireType, true, nil
+}
+
+// uvarint reads a varint.
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: invalid varint", ErrInvalidMessage)
+	}
+
+	d.buf = d.buf[n:]
+
+	return v, nil
+}
+
+// int64 reads an int32 or int64 field value.
+func (d *decoder) int64(wireType int) (int64, error) {
+	if wireType != wireVarint {
+		return 0, fmt.Errorf("%w: unexpected wire type %d for integer", ErrInvalidMessage, wireType)
+	}
+
+	v, err := d.uvarint()
+
+	return int64(v), err
+}
+
+// bytes reads a length delimited field value.
+func (d *decoder) bytes(wireType int) ([]byte, error) {
+	if wireType != wireBytes {
+		return nil, fmt.Errorf("%w: unexpected wire type %d for length delimited field", ErrInvalidMessage, wireType)
+	}
+
+	length, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	if length > uint64(len(d.buf)) {
+		return nil, fmt.Errorf("%w: truncated field", ErrInvalidMessage)
+	}
+
+	b := d.buf[:length]
+	d.buf = d.buf[length:]
+
+	return b, nil
+}
+
+// string reads a string field value.
+func (d *decoder) string(wireType int) (string, error) {
+	b, err := d.bytes(wireType)
+
+	return string(b), err
+}
+
+// skip discards the value of an unknown field so that messages produced by
+// newer schema versions can be decoded.
+func (d *decoder) skip(wireType int) error {
+	var size int
+
+	switch wireType {
+	case wireVarint:
+		_, err := d.uvarint()
+		return err
+	case wireBytes:
+		_, err := d.bytes(wireType)
+		return err
+	case wireFixed64:
+		size = 8
+	case wireFixed32:
+		size = 4
+	default:
+		return fmt.Errorf("%w: unsupported wire type %d", ErrInvalidMessage, wireType)
+	}
+
+	if len(d.buf) < size {
+		return fmt.Errorf("%w: truncated field", ErrInvalidMessage)
+	}
+
+	d.buf = d.buf[size:]
+
+	return nil
+}
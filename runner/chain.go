@@ -0,0 +1,237 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package runner
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// Flag names used to enable and configure chain (post-processing) mode.
+const (
+	FlagChainStdin     string = "chain-stdin"
+	FlagChainExitCode  string = "chain-exit-code"
+	FlagChainExec      string = "chain-exec"
+	FlagChainTimeout   string = "chain-timeout"
+	FlagChainThreshold string = "chain-threshold"
+	FlagChainRename    string = "chain-rename"
+)
+
+// chainRenameSeparator separates the original and new labels of a
+// performance data rename (e.g., "/=root").
+const chainRenameSeparator string = "="
+
+var (
+	// ErrInvalidChainConfig indicates that chain mode was requested using
+	// conflicting or incomplete settings.
+	ErrInvalidChainConfig = errors.New("invalid chain mode configuration")
+
+	// ErrInvalidRename indicates that a performance data rename does not
+	// use the expected "old=new" format.
+	ErrInvalidRename = errors.New("invalid performance data rename")
+)
+
+// chainConfig holds the settings used for chain (post-processing) mode.
+type chainConfig struct {
+	// stdin indicates whether the upstream plugin output is read from
+	// the input (see SetInput).
+	stdin bool
+
+	// exitCode is the upstream plugin exit code when reading output from
+	// the input. If negative, the state is taken from the leading state
+	// label of the output.
+	exitCode int
+
+	// exec indicates whether the upstream plugin is executed using the
+	// remaining (non-flag) command-line arguments.
+	exec bool
+
+	// timeout is the timeout for the executed upstream plugin.
+	timeout time.Duration
+
+	// thresholds are the threshold set entries re-evaluated against the
+	// upstream performance data.
+	thresholds []string
+
+	// renames are the performance data label renames ("old=new").
+	renames []string
+}
+
+// registerFlags registers the chain mode flags with the given flag set.
+func (cc *chainConfig) registerFlags(fs *flag.FlagSet) {
+	fs.BoolVar(
+		&cc.stdin,
+		FlagChainStdin,
+		false,
+		"Post-process the output of another plugin read from stdin instead of running the check.",
+	)
+	fs.IntVar(
+		&cc.exitCode,
+		FlagChainExitCode,
+		-1,
+		"Exit code of the plugin whose output is read via --"+FlagChainStdin+
+			" (taken from the state label of the output if not set).",
+	)
+	fs.BoolVar(
+		&cc.exec,
+		FlagChainExec,
+		false,
+		"Post-process the output of the plugin given by the remaining arguments (after --) instead of running the check.",
+	)
+	fs.DurationVar(
+		&cc.timeout,
+		FlagChainTimeout,
+		nagios.DefaultExternalPluginTimeout,
+		"Timeout for the plugin run via --"+FlagChainExec+".",
+	)
+	fs.Func(
+		FlagChainThreshold,
+		"Re-evaluate a metric of the post-processed plugin using `label=warning/critical` ranges (repeatable).",
+		func(value string) error {
+			cc.thresholds = append(cc.thresholds, value)
+			return nil
+		},
+	)
+	fs.Func(
+		FlagChainRename,
+		"Rename a performance data metric of the post-processed plugin using `old=new` (repeatable).",
+		func(value string) error {
+			cc.renames = append(cc.renames, value)
+			return nil
+		},
+	)
+}
+
+// enabled indicates whether chain mode was requested.
+func (cc chainConfig) enabled() bool {
+	return cc.stdin || cc.exec
+}
+
+// run obtains the upstream plugin result from the given input or by
+// executing the given command, applies the requested transformations and
+// records the result.
+func (cc chainConfig) run(plugin *nagios.Plugin, input io.Reader, command []string) error {
+	if cc.stdin && cc.exec {
+		return fmt.Errorf("%w: --%s and --%s are mutually exclusive", ErrInvalidChainConfig, FlagChainStdin, FlagChainExec)
+	}
+
+	thresholds, err := nagios.ParseThresholdSet(cc.thresholds...)
+	if err != nil {
+		return err
+	}
+
+	renames, err := parseRenames(cc.renames)
+	if err != nil {
+		return err
+	}
+
+	var result nagios.ExternalResult
+
+	switch {
+	case cc.exec:
+		if len(command) == 0 {
+			return fmt.Errorf("%w: --%s requires a plugin command", ErrInvalidChainConfig, FlagChainExec)
+		}
+
+		result, err = nagios.RunExternalPlugin(cc.timeout, command[0], command[1:]...)
+
+	default:
+		result, err = readResult(input, cc.exitCode)
+	}
+
+	for i, pd := range result.PerfData {
+		if label, ok := renames[pd.Label]; ok {
+			result.PerfData[i].Label = label
+		}
+	}
+
+	// Upstream thresholds are replaced by the re-evaluated thresholds.
+	for i, pd := range result.PerfData {
+		for _, mt := range thresholds {
+			if strings.EqualFold(pd.Label, mt.Label) {
+				result.PerfData[i].Warn = ""
+				result.PerfData[i].Crit = ""
+			}
+		}
+	}
+
+	plugin.ImportExternalResult(result)
+
+	if err != nil {
+		plugin.AddError(err)
+	}
+
+	// An UNKNOWN upstream state indicates a failure to collect the metrics,
+	// which takes precedence over re-evaluated thresholds.
+	if len(thresholds) == 0 || result.ExitCode == nagios.StateUNKNOWNExitCode {
+		return nil
+	}
+
+	state := plugin.EvaluateThresholdSet(thresholds)
+	plugin.SetState(state)
+
+	summary := result.ServiceOutput
+	if _, rest, err := nagios.ParseServiceState(summary); err == nil {
+		summary = rest
+	}
+	plugin.SetSummary(nagios.ServiceStateFromExitCode(state).Label + ": " + summary)
+
+	return nil
+}
+
+// readResult reads and parses upstream plugin output from the given input.
+// If exitCode is negative the state is taken from the leading state label
+// of the output (UNKNOWN if not found).
+func readResult(input io.Reader, exitCode int) (nagios.ExternalResult, error) {
+	result := nagios.ExternalResult{ExitCode: exitCode}
+
+	output, err := io.ReadAll(input)
+	if err != nil {
+		result.ExitCode = nagios.StateUNKNOWNExitCode
+		return result, fmt.Errorf("failed to read plugin output: %w", err)
+	}
+
+	result.Stdout = string(output)
+
+	if exitCode < 0 {
+		result.ExitCode = nagios.StateUNKNOWNExitCode
+		if state, _, err := nagios.ParseServiceState(result.Stdout); err == nil {
+			result.ExitCode = state.ExitCode
+		}
+	}
+
+	parsed, err := nagios.ParsePluginOutput(result.Stdout)
+	result.ParsedOutput = parsed
+	if err != nil {
+		return result, fmt.Errorf("failed to parse plugin output: %w", err)
+	}
+
+	return result, nil
+}
+
+// parseRenames parses the given "old=new" performance data label renames.
+func parseRenames(renames []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(renames))
+
+	for _, rename := range renames {
+		from, to, ok := strings.Cut(rename, chainRenameSeparator)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRename, rename)
+		}
+
+		parsed[from] = to
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package runner_test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRunChainStdinReevaluatesThresholds asserts that plugin output read
+// from stdin is re-emitted with renamed metrics and the state re-evaluated
+// against new thresholds.
+func TestRunChainStdinReevaluatesThresholds(t *testing.T) {
+	t.Parallel()
+
+	r, outputBuffer := newTestRunner(t,
+		"--chain-stdin",
+		"--chain-rename", "/=root",
+		"--chain-threshold", "root=80/90",
+	)
+
+	r.SetInput(strings.NewReader(
+		"DISK OK - free space: / 3326 MB (8% inode=91%)|/=92%;95;98;0;100\n",
+	))
+
+	var checkRan bool
+	r.Run(func(p *nagios.Plugin) {
+		checkRan = true
+		p.WithDetail("post-processed")
+	})
+
+	if !checkRan {
+		t.Error("want check logic run after the chained result is recorded")
+	}
+
+	if got := r.Plugin().ExitStatusCode; got != nagios.StateCRITICALExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateCRITICALExitCode, got)
+	}
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"CRITICAL: free space: / 3326 MB (8% inode=91%)",
+		"root: 92 triggers critical threshold 90",
+		"post-processed",
+		"'root'=92%;80;90;0;100",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestRunChainStdinUsesGivenExitCode asserts that the exit code given via
+// flag takes precedence over the state label of the output.
+func TestRunChainStdinUsesGivenExitCode(t *testing.T) {
+	t.Parallel()
+
+	r, outputBuffer := newTestRunner(t, "--chain-stdin", "--chain-exit-code", "1")
+	r.SetInput(strings.NewReader("queue depth 120\n"))
+
+	r.Run(func(*nagios.Plugin) {})
+
+	if got := r.Plugin().ExitStatusCode; got != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, got)
+	}
+
+	if !strings.HasPrefix(outputBuffer.String(), "queue depth 120") {
+		t.Errorf("want output passed through, got:\n%s", outputBuffer.String())
+	}
+}
+
+// TestRunChainExecRunsPlugin asserts that the plugin given by the remaining
+// arguments is run and its output re-emitted.
+func TestRunChainExecRunsPlugin(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	r, outputBuffer := newTestRunner(t,
+		"--chain-exec", "--",
+		"sh", "-c", "echo 'WARNING: load high|load1=5.2;4;8'; exit 1",
+	)
+
+	r.Run(func(*nagios.Plugin) {})
+
+	if got := r.Plugin().ExitStatusCode; got != nagios.StateWARNINGExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateWARNINGExitCode, got)
+	}
+
+	if got := outputBuffer.String(); !strings.HasPrefix(got, "WARNING: load high") ||
+		!strings.Contains(got, "'load1'=5.2;4;8") {
+		t.Errorf("want plugin output re-emitted, got:\n%s", got)
+	}
+}
+
+// TestRunChainRejectsConflictingModes asserts that requesting both chain
+// sources results in an UNKNOWN state.
+func TestRunChainRejectsConflictingModes(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newTestRunner(t, "--chain-stdin", "--chain-exec", "--", "true")
+
+	var checkRan bool
+	r.Run(func(*nagios.Plugin) { checkRan = true })
+
+	if checkRan || r.Plugin().ExitStatusCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want UNKNOWN state without running the check, got exit code %d", r.Plugin().ExitStatusCode)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package runner provides a common entry point for Nagios plugins written
+using the nagios package.
+
+# OVERVIEW
+
+A Runner wraps a Plugin value and the check logic provided by client code.
+It is responsible for deferring ReturnCheckResults so that output and exit
+code handling is consistent and for providing run modes shared by all
+plugins built on this library.
+
+# FEATURES
+
+  - Run method which handles deferring ReturnCheckResults on behalf of
+    client code
+  - Static (dummy) mode where the plugin returns a fixed state and output
+    provided via flags or environment variables instead of running its
+    check logic (similar to check_dummy); useful for testing notifications
+    and validating configuration in lab environments
+  - Chain (post-processing) mode where the output of another plugin is
+    read from stdin or obtained by running it, parsed, transformed
+    (performance data renaming, threshold re-evaluation and client code
+    transformations) and re-emitted, enabling generic post-processor
+    plugins
+  - Generated -h (--help) output following the plugin development
+    guidelines layout (version, description, usage, options, threshold
+    format and examples) assembled from the registered flags and the
+    plugin metadata set via SetMetadata
+
+# HOW TO USE
+
+	func main() {
+		plugin := nagios.NewPlugin()
+
+		r := runner.New(plugin)
+		r.SetMetadata(runner.Metadata{
+			Name:        "check_foo",
+			Version:     version,
+			Description: "Checks the foo service.",
+			Thresholds:  true,
+		})
+		r.RegisterFlags(flag.CommandLine)
+		flag.Parse()
+
+		r.Run(func(p *nagios.Plugin) {
+			// check logic here
+		})
+	}
+*/
+package runner
@@ -0,0 +1,242 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package runner
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// helpLineWidth is the maximum width of help output lines, as recommended
+// by the plugin development guidelines.
+const helpLineWidth int = 80
+
+// helpIndent indents option and example descriptions in help output.
+const helpIndent string = "    "
+
+// thresholdFormatHelp explains the threshold range format in help output.
+//
+// https://nagios-plugins.org/doc/guidelines.html#THRESHOLDFORMAT
+var thresholdFormatHelp = []string{
+	"Threshold ranges use the format [@]start:end (start <= end). An alert is",
+	"raised if the value is outside the range (inclusive of endpoints), or",
+	"inside the range if prefixed with @. start is 0 if omitted, ~ means",
+	"negative infinity and an omitted end means positive infinity.",
+	"",
+	"10       alert if < 0 or > 10",
+	"10:      alert if < 10",
+	"~:10     alert if > 10",
+	"10:20    alert if < 10 or > 20",
+	"@10:20   alert if >= 10 and <= 20",
+}
+
+// Metadata describes a plugin for generated help output. See
+// Runner.SetMetadata.
+type Metadata struct {
+	// Name is the plugin name (e.g., "check_cert"). The flag set name is
+	// used if not set.
+	Name string
+
+	// Version is the semantic version of the plugin (e.g., "1.2.3").
+	Version string
+
+	// Description briefly describes what the plugin checks.
+	Description string
+
+	// Usage is the usage synopsis (e.g., "check_cert -H <host> [-w <days>]
+	// [-c <days>]"). A generic synopsis is used if not set.
+	Usage string
+
+	// Thresholds indicates whether the plugin accepts threshold ranges, in
+	// which case the threshold range format is explained.
+	Thresholds bool
+
+	// Examples are example invocations of the plugin.
+	Examples []Example
+}
+
+// Example is an example invocation of a plugin for generated help output.
+type Example struct {
+	// Command is the example command line.
+	Command string
+
+	// Description explains the example.
+	Description string
+}
+
+// SetMetadata sets the plugin details used to generate help output. See
+// also WriteHelp.
+func (r *Runner) SetMetadata(metadata Metadata) {
+	r.metadata = metadata
+}
+
+// WriteHelp writes help output for the plugin to w following the layout
+// recommended by the plugin development guidelines: version details,
+// description, usage, options (the flags of the given flag set), the
+// threshold range format and examples.
+//
+// RegisterFlags sets this as the usage function of the flag set, so help
+// output is emitted for the -h (--help) flag.
+//
+// https://nagios-plugins.org/doc/guidelines.html
+func (r *Runner) WriteHelp(w io.Writer, fs *flag.FlagSet) {
+	name := r.metadata.Name
+	if name == "" {
+		name = fs.Name()
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintln(&b, nagios.ReadVersionInfo(name, r.metadata.Version))
+
+	if r.metadata.Description != "" {
+		fmt.Fprintln(&b)
+		writeWrapped(&b, "", r.metadata.Description)
+	}
+
+	usage := r.metadata.Usage
+	if usage == "" {
+		usage = name + " [options]"
+	}
+
+	fmt.Fprintf(&b, "\nUsage:\n")
+	writeWrapped(&b, " ", usage)
+
+	fmt.Fprintf(&b, "\nOptions:\n")
+	fmt.Fprintf(&b, " -h, --help\n%sPrint detailed help screen\n", helpIndent)
+
+	for _, option := range helpOptions(fs) {
+		fmt.Fprintf(&b, " %s\n", option.names)
+		writeWrapped(&b, helpIndent, option.usage)
+	}
+
+	if r.metadata.Thresholds {
+		fmt.Fprintf(&b, "\nThresholds:\n")
+		for _, line := range thresholdFormatHelp {
+			fmt.Fprintln(&b, strings.TrimRight(" "+line, " "))
+		}
+	}
+
+	if len(r.metadata.Examples) > 0 {
+		fmt.Fprintf(&b, "\nExamples:\n")
+		for _, example := range r.metadata.Examples {
+			fmt.Fprintf(&b, " %s\n", example.Command)
+			if example.Description != "" {
+				writeWrapped(&b, helpIndent, example.Description)
+			}
+		}
+	}
+
+	fmt.Fprint(w, b.String())
+}
+
+// helpOption is a single entry of the options listed in help output.
+type helpOption struct {
+	// names is the list of flag names (e.g., "-V, --version") including a
+	// value placeholder if applicable.
+	names string
+
+	// usage is the flag usage text including the default value.
+	usage string
+}
+
+// helpOptions collects the options listed in help output. Flags sharing a
+// value (e.g., -V and --version) are listed as a single entry using the
+// usage text of the longest name.
+func helpOptions(fs *flag.FlagSet) []helpOption {
+	var (
+		options []helpOption
+		groups  [][]*flag.Flag
+		index   = make(map[uintptr]int)
+	)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		v := reflect.ValueOf(f.Value)
+		if v.Kind() == reflect.Pointer {
+			if i, ok := index[v.Pointer()]; ok {
+				groups[i] = append(groups[i], f)
+				return
+			}
+			index[v.Pointer()] = len(groups)
+		}
+
+		groups = append(groups, []*flag.Flag{f})
+	})
+
+	for _, group := range groups {
+		names := make([]string, 0, len(group))
+		primary := group[0]
+
+		for _, f := range group {
+			if len(f.Name) > len(primary.Name) {
+				primary = f
+			}
+		}
+
+		placeholder, usage := flag.UnquoteUsage(primary)
+
+		for _, f := range group {
+			switch {
+			case len(f.Name) == 1:
+				names = append(names, "-"+f.Name)
+			default:
+				names = append(names, "--"+f.Name)
+			}
+		}
+
+		// List shorthand names first.
+		sort.SliceStable(names, func(i, j int) bool {
+			return len(names[i]) < len(names[j])
+		})
+
+		option := helpOption{names: strings.Join(names, ", "), usage: usage}
+		switch {
+		case placeholder == "":
+		case len(primary.Name) == 1:
+			option.names += " " + strings.ToUpper(placeholder)
+		default:
+			option.names += "=" + strings.ToUpper(placeholder)
+		}
+
+		switch primary.DefValue {
+		case "", "false", "0", "[]":
+		default:
+			option.usage += fmt.Sprintf(" (default: %s)", primary.DefValue)
+		}
+
+		options = append(options, option)
+	}
+
+	return options
+}
+
+// writeWrapped writes the given text wrapped at helpLineWidth columns with
+// each line prefixed by indent.
+func writeWrapped(w io.Writer, indent string, text string) {
+	line := indent
+
+	for _, word := range strings.Fields(text) {
+		if len(line) > len(indent) && len(line)+1+len(word) > helpLineWidth {
+			fmt.Fprintln(w, line)
+			line = indent
+		}
+
+		if len(line) > len(indent) {
+			line += " "
+		}
+		line += word
+	}
+
+	fmt.Fprintln(w, line)
+}
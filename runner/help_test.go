@@ -0,0 +1,84 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package runner_test
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/runner"
+)
+
+// TestHelpFlagWritesGuidelineLayout asserts that the -h flag emits help
+// output assembled from the registered metadata and flags in the layout
+// recommended by the plugin development guidelines.
+func TestHelpFlagWritesGuidelineLayout(t *testing.T) {
+	t.Parallel()
+
+	r := runner.New(&nagios.Plugin{})
+	r.SetMetadata(runner.Metadata{
+		Name:        "check_cert",
+		Version:     "1.2.3",
+		Description: "Checks the expiration of TLS certificates.",
+		Usage:       "check_cert -H <host> [-w <days>] [-c <days>]",
+		Thresholds:  true,
+		Examples: []runner.Example{
+			{
+				Command:     "check_cert -H www.example.com -w 30: -c 7:",
+				Description: "Warn if the certificate expires within 30 days.",
+			},
+		},
+	})
+
+	var help strings.Builder
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&help)
+
+	var host string
+	fs.StringVar(&host, "H", "", "Host `name` or IP address")
+	fs.StringVar(&host, "hostname", "", "Host `name` or IP address")
+
+	var warning string
+	fs.StringVar(&warning, "w", "30:", "Warning threshold `range` in days")
+
+	r.RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("want %v, got %v", flag.ErrHelp, err)
+	}
+
+	got := help.String()
+
+	for _, want := range []string{
+		"check_cert v1.2.3 (",
+		"\nChecks the expiration of TLS certificates.\n",
+		"\nUsage:\n check_cert -H <host> [-w <days>] [-c <days>]\n",
+		"\nOptions:\n -h, --help\n    Print detailed help screen\n",
+		"\n -H, --hostname=NAME\n    Host name or IP address\n",
+		"\n -w RANGE\n    Warning threshold range in days (default: 30:)\n",
+		"\n --static-state=STRING\n",
+		"\nThresholds:\n Threshold ranges use the format [@]start:end",
+		"\n @10:20   alert if >= 10 and <= 20\n",
+		"\nExamples:\n check_cert -H www.example.com -w 30: -c 7:\n" +
+			"    Warn if the certificate expires within 30 days.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) > 80 {
+			t.Errorf("want lines of at most 80 characters, got %q", line)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package runner
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// CheckFunc is the check logic provided by client code. Results are recorded
+// using the given Plugin value.
+type CheckFunc func(p *nagios.Plugin)
+
+// Runner runs the check logic for a plugin and handles returning the check
+// results.
+type Runner struct {
+	// plugin is the Plugin value used to record and return check results.
+	plugin *nagios.Plugin
+
+	// static holds the settings used for static (dummy) mode.
+	static staticConfig
+
+	// metadata describes the plugin for generated help output.
+	metadata Metadata
+
+	// chain holds the settings used for chain (post-processing) mode.
+	chain chainConfig
+
+	// flagSet is the flag set the Runner flags were registered with. The
+	// remaining (non-flag) arguments are used as the plugin command in
+	// chain mode.
+	flagSet *flag.FlagSet
+
+	// input is the source of plugin output in chain mode. Defaults to
+	// os.Stdin.
+	input io.Reader
+}
+
+// New creates a Runner for the given Plugin value.
+func New(plugin *nagios.Plugin) *Runner {
+	return &Runner{
+		plugin: plugin,
+		input:  os.Stdin,
+	}
+}
+
+// Plugin returns the Plugin value used by the Runner.
+func (r *Runner) Plugin() *nagios.Plugin {
+	return r.plugin
+}
+
+// RegisterFlags registers the flags for run modes provided by the Runner
+// with the given flag set and sets generated help output (see WriteHelp) as
+// the usage function of the flag set. This should be called before the flag
+// set is parsed.
+func (r *Runner) RegisterFlags(fs *flag.FlagSet) {
+	r.static.registerFlags(fs)
+	r.chain.registerFlags(fs)
+	r.flagSet = fs
+
+	fs.Usage = func() {
+		r.WriteHelp(fs.Output(), fs)
+	}
+}
+
+// SetInput sets the source of plugin output read in chain mode (see Run).
+// The default is os.Stdin.
+func (r *Runner) SetInput(input io.Reader) {
+	r.input = input
+}
+
+// Run runs the given check logic and returns the check results. The
+// ReturnCheckResults method is deferred by Run, so client code should not
+// also defer it. Run does not return unless os.Exit calls have been
+// disabled via SkipOSExit.
+//
+// If static mode is enabled the check logic is skipped and the configured
+// state and output are returned instead.
+//
+// If chain (post-processing) mode is enabled the output of another plugin
+// is read from stdin (--chain-stdin) or obtained by running the plugin
+// given by the remaining command-line arguments (--chain-exec). The parsed
+// result is recorded after renaming performance data metrics
+// (--chain-rename) and re-evaluating thresholds (--chain-threshold). The
+// check logic is then run to apply any further transformations before the
+// result is re-emitted. State remapping is available via the cmdline
+// package.
+func (r *Runner) Run(check CheckFunc) {
+	defer r.plugin.ReturnCheckResults()
+
+	enabled, err := r.static.resolve()
+	switch {
+	case err != nil:
+		r.plugin.UnknownWithError("invalid static mode configuration", err)
+		return
+
+	case enabled:
+		r.static.apply(r.plugin)
+		return
+	}
+
+	if r.chain.enabled() {
+		var command []string
+		if r.flagSet != nil {
+			command = r.flagSet.Args()
+		}
+
+		if err := r.chain.run(r.plugin, r.input, command); err != nil {
+			r.plugin.UnknownWithError("invalid chain mode configuration", err)
+			return
+		}
+	}
+
+	check(r.plugin)
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package runner_test
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/atc0005/go-nagios/runner"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestRunner creates a Runner with flags parsed from the given arguments.
+func newTestRunner(t *testing.T, args ...string) (*runner.Runner, *strings.Builder) {
+	t.Helper()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	r := runner.New(&plugin)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	r.RegisterFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	return r, &outputBuffer
+}
+
+// TestRunStaticModeSkipsCheck asserts that static mode returns the fixed
+// state and output without running the check logic.
+func TestRunStaticModeSkipsCheck(t *testing.T) {
+	t.Parallel()
+
+	r, outputBuffer := newTestRunner(t, "--static-state", "critical", "--static-output", "notification test")
+
+	var checkRan bool
+	r.Run(func(*nagios.Plugin) { checkRan = true })
+
+	if checkRan {
+		t.Error("want check logic skipped in static mode")
+	}
+
+	if got := r.Plugin().ExitStatusCode; got != nagios.StateCRITICALExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateCRITICALExitCode, got)
+	}
+
+	want := "CRITICAL: notification test"
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestRunStaticModeFromEnv asserts that static mode may be enabled via
+// environment variables.
+func TestRunStaticModeFromEnv(t *testing.T) {
+	t.Setenv(runner.EnvStaticState, "1")
+
+	r, outputBuffer := newTestRunner(t)
+	r.Run(func(p *nagios.Plugin) { p.OK("check ran") })
+
+	want := "WARNING"
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestRunWithoutStaticModeRunsCheck asserts that the check logic is run
+// when static mode is not enabled and that invalid static states are
+// reported as UNKNOWN.
+func TestRunWithoutStaticModeRunsCheck(t *testing.T) {
+	t.Parallel()
+
+	r, outputBuffer := newTestRunner(t)
+	r.Run(func(p *nagios.Plugin) { p.OK("check ran") })
+
+	want := "OK: check ran"
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	r, _ = newTestRunner(t, "--static-state", "sideways")
+	r.Run(func(p *nagios.Plugin) { p.OK("check ran") })
+
+	if got := r.Plugin().ExitStatusCode; got != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateUNKNOWNExitCode, got)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package runner
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// Environment variables used to enable static (dummy) mode. Flag values take
+// precedence over environment variables.
+const (
+	EnvStaticState  string = "NAGIOS_PLUGIN_STATIC_STATE"
+	EnvStaticOutput string = "NAGIOS_PLUGIN_STATIC_OUTPUT"
+)
+
+// Flag names used to enable static (dummy) mode.
+const (
+	FlagStaticState  string = "static-state"
+	FlagStaticOutput string = "static-output"
+)
+
+// ErrInvalidStaticState indicates that an unrecognized state was specified
+// for static mode.
+var ErrInvalidStaticState = errors.New("invalid static mode state")
+
+// staticConfig holds the settings used for static (dummy) mode.
+type staticConfig struct {
+	// state is the state label or exit code provided via flag.
+	state string
+
+	// output is the output text provided via flag.
+	output string
+
+	// exitCode is the resolved static mode exit code.
+	exitCode int
+
+	// resolvedOutput is the resolved static mode output text.
+	resolvedOutput string
+}
+
+// registerFlags registers the static mode flags with the given flag set.
+func (sc *staticConfig) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&sc.state,
+		FlagStaticState,
+		"",
+		"Return this fixed state (OK, WARNING, CRITICAL, UNKNOWN or 0-3) without running the check.",
+	)
+	fs.StringVar(
+		&sc.output,
+		FlagStaticOutput,
+		"",
+		"Output text to return with the fixed state set via --"+FlagStaticState+".",
+	)
+}
+
+// resolve determines whether static mode is enabled via flags or
+// environment variables and validates the requested state.
+func (sc *staticConfig) resolve() (bool, error) {
+	state := sc.state
+	if state == "" {
+		state = os.Getenv(EnvStaticState)
+	}
+
+	if state == "" {
+		return false, nil
+	}
+
+	output := sc.output
+	if output == "" {
+		output = os.Getenv(EnvStaticOutput)
+	}
+
+	exitCode, err := parseStaticState(state)
+	if err != nil {
+		return false, err
+	}
+
+	sc.exitCode = exitCode
+	sc.resolvedOutput = output
+
+	return true, nil
+}
+
+// apply records the static mode state and output.
+func (sc staticConfig) apply(plugin *nagios.Plugin) {
+	label := nagios.ServiceStateFromExitCode(sc.exitCode).Label
+
+	plugin.SetState(sc.exitCode)
+
+	switch {
+	case sc.resolvedOutput != "":
+		plugin.SetSummary(label + ": " + sc.resolvedOutput)
+	default:
+		plugin.SetSummary(label)
+	}
+}
+
+// parseStaticState converts the given state label or exit code to a plugin
+// exit code.
+func parseStaticState(state string) (int, error) {
+	state = strings.TrimSpace(state)
+
+	if exitCode, err := strconv.Atoi(state); err == nil {
+		switch exitCode {
+		case nagios.StateOKExitCode,
+			nagios.StateWARNINGExitCode,
+			nagios.StateCRITICALExitCode,
+			nagios.StateUNKNOWNExitCode:
+			return exitCode, nil
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrInvalidStaticState, state)
+		}
+	}
+
+	switch strings.ToUpper(state) {
+	case nagios.StateOKLabel:
+		return nagios.StateOKExitCode, nil
+	case nagios.StateWARNINGLabel:
+		return nagios.StateWARNINGExitCode, nil
+	case nagios.StateCRITICALLabel:
+		return nagios.StateCRITICALExitCode, nil
+	case nagios.StateUNKNOWNLabel:
+		return nagios.StateUNKNOWNExitCode, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidStaticState, state)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ASCIIMode controls how non-ASCII characters in plugin output are handled.
+type ASCIIMode int
+
+// Supported ASCIIMode values.
+const (
+	// ASCIIModeDisabled emits non-ASCII characters as-is. This is the
+	// default.
+	ASCIIModeDisabled ASCIIMode = iota
+
+	// ASCIIModeStrip removes non-ASCII characters from plugin output.
+	ASCIIModeStrip
+
+	// ASCIIModeTransliterate replaces non-ASCII characters with an ASCII
+	// approximation where known (e.g., "é" becomes "e", "…" becomes "...")
+	// and with asciiReplacementChar otherwise.
+	ASCIIModeTransliterate
+)
+
+// asciiReplacementChar is substituted for non-ASCII characters without a
+// known ASCII approximation when transliterating.
+const asciiReplacementChar string = "?"
+
+// ansiEscapeRegex matches ANSI terminal escape sequences (e.g., colors
+// emitted by external commands) which are removed along with other control
+// characters.
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// asciiTransliterations is the collection of ASCII approximations for
+// commonly encountered non-ASCII characters.
+var asciiTransliterations = map[rune]string{
+	// Punctuation and symbols.
+	' ': " ",   // no-break space
+	'¦': "/",   // broken bar (see DefaultPipeReplacement)
+	'©': "(c)", // copyright
+	'«': "<<",  // left guillemet
+	'®': "(R)", // registered
+	'°': "deg", // degree
+	'±': "+/-", // plus-minus
+	'µ': "u",   // micro
+	'·': ".",   // middle dot
+	'»': ">>",  // right guillemet
+	'×': "x",   // multiplication
+	'÷': "/",   // division
+	'‐': "-",   // hyphen
+	'–': "-",   // en dash
+	'—': "--",  // em dash
+	'‘': "'",   // left single quote
+	'’': "'",   // right single quote
+	'“': `"`,   // left double quote
+	'”': `"`,   // right double quote
+	'•': "*",   // bullet
+	'…': "...", // ellipsis
+	'←': "<-",  // leftwards arrow
+	'↑': "^",   // upwards arrow
+	'→': "->",  // rightwards arrow
+	'↓': "v",   // downwards arrow
+	'≤': "<=",  // less-than or equal
+	'≥': ">=",  // greater-than or equal
+	'✓': "v",   // check mark
+	'✗': "x",   // ballot x
+
+	// Letters.
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE",
+	'Ç': "C", 'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I",
+	'Î': "I", 'Ï': "I", 'Ð': "D", 'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O",
+	'Õ': "O", 'Ö': "O", 'Ø': "O", 'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U",
+	'Ý': "Y", 'Þ': "TH", 'ß': "ss", 'à': "a", 'á': "a", 'â': "a", 'ã': "a",
+	'ä': "a", 'å': "a", 'æ': "ae", 'ç': "c", 'è': "e", 'é': "e", 'ê': "e",
+	'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ù': "u",
+	'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'þ': "th", 'ÿ': "y", 'Ł': "L",
+	'ł': "l", 'Œ': "OE", 'œ': "oe", 'Š': "S", 'š': "s", 'Ž': "Z", 'ž': "z",
+}
+
+// WithASCIIOutput is an Option used to restrict plugin output to ASCII
+// characters. See also SetASCIIOutput.
+func WithASCIIOutput(mode ASCIIMode) Option {
+	return func(p *Plugin) {
+		p.SetASCIIOutput(mode)
+	}
+}
+
+// SetASCIIOutput restricts plugin output to ASCII characters using the
+// given mode. This is intended for use with transports (e.g., some NRPE or
+// NSClient++ versions) and older Nagios releases which mangle UTF-8,
+// producing garbage in notifications.
+func (p *Plugin) SetASCIIOutput(mode ASCIIMode) {
+	p.asciiMode = mode
+}
+
+// sanitizeOutput applies character transformations to the given plugin
+// output. Control characters (and ANSI escape sequences) are always removed
+// as they can corrupt notification channels; these are commonly found in
+// panic and error content. Newlines and tabs are retained. Non-ASCII
+// characters are handled as requested via SetASCIIOutput.
+func (p Plugin) sanitizeOutput(pluginOutput string) string {
+	pluginOutput = stripControlChars(pluginOutput)
+
+	if p.asciiMode == ASCIIModeDisabled {
+		return pluginOutput
+	}
+
+	return toASCII(pluginOutput, p.asciiMode)
+}
+
+// stripControlChars removes ANSI escape sequences and control characters
+// other than newlines and tabs from the given text. Carriage returns are
+// also removed; CRLF line endings are applied afterwards if requested (see
+// SetEOL).
+func stripControlChars(s string) string {
+	if strings.IndexFunc(s, isStrippedControlChar) < 0 {
+		return s
+	}
+
+	s = ansiEscapeRegex.ReplaceAllString(s, "")
+
+	return strings.Map(func(r rune) rune {
+		if isStrippedControlChar(r) {
+			return -1
+		}
+
+		return r
+	}, s)
+}
+
+// isStrippedControlChar indicates whether the given character is a control
+// character which should be removed from plugin output.
+func isStrippedControlChar(r rune) bool {
+	return unicode.IsControl(r) && r != '\n' && r != '\t'
+}
+
+// toASCII converts the given text to ASCII using the given mode.
+func toASCII(s string, mode ASCIIMode) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		switch {
+		case r <= unicode.MaxASCII:
+			b.WriteRune(r)
+
+		case mode == ASCIIModeTransliterate:
+			if replacement, ok := asciiTransliterations[r]; ok {
+				b.WriteString(replacement)
+				continue
+			}
+
+			if unicode.IsSpace(r) {
+				b.WriteString(" ")
+				continue
+			}
+
+			b.WriteString(asciiReplacementChar)
+		}
+	}
+
+	return b.String()
+}
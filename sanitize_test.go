@@ -0,0 +1,77 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestSetASCIIOutput asserts that non-ASCII and control characters are
+// stripped or transliterated as requested.
+func TestSetASCIIOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := map[nagios.ASCIIMode]string{
+		nagios.ASCIIModeDisabled:      "OK: Café “prod” – 21°C ✓ 日本",
+		nagios.ASCIIModeStrip:         "OK: Caf prod  21C  ",
+		nagios.ASCIIModeTransliterate: `OK: Cafe "prod" - 21degC v ??`,
+	}
+
+	for mode, want := range tests {
+		plugin := nagios.Plugin{}
+		plugin.SetASCIIOutput(mode)
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetSummary("OK: Café “prod” – 21°C ✓\x07 日本")
+		plugin.ReturnCheckResults()
+
+		if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+			t.Errorf("mode %d: (-want, +got)\n:%s", mode, d)
+		}
+	}
+}
+
+// TestControlCharactersStrippedFromOutput asserts that control characters
+// and ANSI escape sequences in error content are removed while EOLs are
+// retained.
+func TestControlCharactersStrippedFromOutput(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetSummary("CRITICAL: command failed\r")
+	plugin.SetState(nagios.StateCRITICALExitCode)
+	plugin.AddError(errors.New("\x1b[31mfatal:\x1b[0m bad\x00 input\x07\r\nretry later"))
+	plugin.ReturnCheckResults()
+
+	want := "CRITICAL: command failed" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"**ERRORS**" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"* fatal: bad input\nretry later" + nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
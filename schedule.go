@@ -0,0 +1,304 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// scheduleAnyDay matches every day of the week in a schedule.
+	scheduleAnyDay string = "*"
+
+	// scheduleDayListSeparator separates days (or ranges of days) in a
+	// schedule.
+	scheduleDayListSeparator string = ","
+
+	// scheduleRangeSeparator separates the start and end of a range of days
+	// or times in a schedule.
+	scheduleRangeSeparator string = "-"
+
+	// scheduleDayEnd is the end of the day, in time since midnight.
+	scheduleDayEnd time.Duration = 24 * time.Hour
+)
+
+// ErrInvalidSchedule indicates that a schedule specification could not be
+// parsed.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// scheduleDays is the collection of recognized day names.
+var scheduleDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule is a recurring weekly time window (e.g., business hours).
+type Schedule struct {
+	spec  string
+	days  [7]bool
+	start time.Duration
+	end   time.Duration
+}
+
+// ParseSchedule parses a schedule specification consisting of days, a time
+// range or both, separated by whitespace. Days are given as a comma
+// separated list of day names or ranges of day names (e.g., "Mon-Fri" or
+// "Sat,Sun") or "*" for every day. The time range uses 24 hour HH:MM times
+// with an exclusive end (e.g., "08:00-18:00"); "24:00" may be used as the
+// end of the day. A time range which ends before it starts spans midnight
+// (e.g., "22:00-06:00"). Omitted days match every day and an omitted time
+// range matches the whole day.
+//
+// Examples: "Mon-Fri 08:00-18:00", "Sat,Sun", "* 22:00-06:00".
+//
+// An error wrapping ErrInvalidSchedule is returned if the specification
+// cannot be parsed.
+func ParseSchedule(spec string) (Schedule, error) {
+	s := Schedule{
+		spec: strings.TrimSpace(spec),
+		end:  scheduleDayEnd,
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Schedule{}, fmt.Errorf("%w: %q", ErrInvalidSchedule, spec)
+	}
+
+	daysField := scheduleAnyDay
+	timesField := ""
+
+	switch {
+	case len(fields) == 2:
+		daysField, timesField = fields[0], fields[1]
+	case strings.Contains(fields[0], ":"):
+		timesField = fields[0]
+	default:
+		daysField = fields[0]
+	}
+
+	if err := s.parseDays(daysField); err != nil {
+		return Schedule{}, fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, spec, err)
+	}
+
+	if timesField != "" {
+		if err := s.parseTimes(timesField); err != nil {
+			return Schedule{}, fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, spec, err)
+		}
+	}
+
+	return s, nil
+}
+
+// parseDays parses the days portion of a schedule specification.
+func (s *Schedule) parseDays(field string) error {
+	if field == scheduleAnyDay {
+		for i := range s.days {
+			s.days[i] = true
+		}
+
+		return nil
+	}
+
+	for _, item := range strings.Split(field, scheduleDayListSeparator) {
+		first, last, isRange := strings.Cut(item, scheduleRangeSeparator)
+		if !isRange {
+			last = first
+		}
+
+		from, ok := scheduleDays[strings.ToLower(first)]
+		if !ok {
+			return fmt.Errorf("unknown day %q", first)
+		}
+
+		to, ok := scheduleDays[strings.ToLower(last)]
+		if !ok {
+			return fmt.Errorf("unknown day %q", last)
+		}
+
+		// Day ranges may wrap around the end of the week (e.g., "Fri-Mon").
+		for day := from; ; day = (day + 1) % 7 {
+			s.days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseTimes parses the time range portion of a schedule specification.
+func (s *Schedule) parseTimes(field string) error {
+	first, last, ok := strings.Cut(field, scheduleRangeSeparator)
+	if !ok {
+		return fmt.Errorf("time range %q missing %q separator", field, scheduleRangeSeparator)
+	}
+
+	start, err := parseClockTime(first)
+	if err != nil {
+		return err
+	}
+
+	end, err := parseClockTime(last)
+	if err != nil {
+		return err
+	}
+
+	if start == scheduleDayEnd || start == end {
+		return fmt.Errorf("empty time range %q", field)
+	}
+
+	s.start, s.end = start, end
+
+	return nil
+}
+
+// parseClockTime parses the given HH:MM time as time since midnight.
+func parseClockTime(value string) (time.Duration, error) {
+	hh, mm, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("time %q not in HH:MM format", value)
+	}
+
+	hours, err := strconv.Atoi(hh)
+	if err != nil {
+		return 0, fmt.Errorf("time %q not in HH:MM format", value)
+	}
+
+	minutes, err := strconv.Atoi(mm)
+	if err != nil {
+		return 0, fmt.Errorf("time %q not in HH:MM format", value)
+	}
+
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+
+	if hours < 0 || minutes < 0 || minutes > 59 || d > scheduleDayEnd {
+		return 0, fmt.Errorf("time %q out of range", value)
+	}
+
+	return d, nil
+}
+
+// String provides the schedule specification.
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// Contains indicates whether the given time falls within the schedule. The
+// time is evaluated in its own location. For time ranges spanning midnight,
+// the portion after midnight belongs to the day on which the range starts.
+func (s Schedule) Contains(t time.Time) bool {
+	sinceMidnight := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	if s.start < s.end {
+		return s.days[today] && sinceMidnight >= s.start && sinceMidnight < s.end
+	}
+
+	return (s.days[today] && sinceMidnight >= s.start) ||
+		(s.days[yesterday] && sinceMidnight < s.end)
+}
+
+// thresholdWindow is a set of thresholds which apply during a schedule.
+type thresholdWindow struct {
+	schedule      Schedule
+	warning       string
+	warningRange  *Range
+	critical      string
+	criticalRange *Range
+}
+
+// AddThresholdWindow registers warning and critical thresholds which apply
+// instead of the default thresholds (see SetWarningThreshold and
+// SetCriticalThreshold) while the current time falls within the given
+// schedule (see ParseSchedule). This allows a single service definition to
+// use different thresholds for business hours and nights or weekends.
+// Windows are consulted in the order registered and the first matching
+// window is used. An empty threshold leaves the default threshold of that
+// level in effect.
+//
+// The active thresholds are used by EvaluateThresholds and are displayed
+// (along with the matching schedule) in the THRESHOLDS section.
+//
+// An error wrapping ErrInvalidSchedule or ErrInvalidRange is returned if the
+// schedule or either threshold cannot be parsed.
+func (p *Plugin) AddThresholdWindow(schedule string, warning string, critical string) error {
+	s, err := ParseSchedule(schedule)
+	if err != nil {
+		return err
+	}
+
+	window := thresholdWindow{schedule: s}
+
+	if strings.TrimSpace(warning) != "" {
+		r, err := ParseRange(warning)
+		if err != nil {
+			return fmt.Errorf("invalid warning threshold: %w", err)
+		}
+
+		window.warning = strings.TrimSpace(warning)
+		window.warningRange = &r
+	}
+
+	if strings.TrimSpace(critical) != "" {
+		r, err := ParseRange(critical)
+		if err != nil {
+			return fmt.Errorf("invalid critical threshold: %w", err)
+		}
+
+		window.critical = strings.TrimSpace(critical)
+		window.criticalRange = &r
+	}
+
+	p.thresholdWindows = append(p.thresholdWindows, window)
+
+	return nil
+}
+
+// activeThresholdWindow returns the first threshold window containing the
+// given time or nil if none match.
+func (p Plugin) activeThresholdWindow(now time.Time) *thresholdWindow {
+	for i := range p.thresholdWindows {
+		if p.thresholdWindows[i].schedule.Contains(now) {
+			return &p.thresholdWindows[i]
+		}
+	}
+
+	return nil
+}
+
+// activeRanges returns the warning and critical threshold ranges in effect
+// at the given time.
+func (p Plugin) activeRanges(now time.Time) (*Range, *Range) {
+	warning, critical := p.WarningRange, p.CriticalRange
+
+	if window := p.activeThresholdWindow(now); window != nil {
+		if window.warningRange != nil {
+			warning = window.warningRange
+		}
+
+		if window.criticalRange != nil {
+			critical = window.criticalRange
+		}
+	}
+
+	return warning, critical
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestScheduleContains asserts that schedules match the expected days and
+// times, including time ranges spanning midnight.
+func TestScheduleContains(t *testing.T) {
+	t.Parallel()
+
+	// 2024-01-01 is a Monday.
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		spec string
+		time time.Time
+		want bool
+	}{
+		{spec: "Mon-Fri 08:00-18:00", time: at(1, 8, 0), want: true},
+		{spec: "Mon-Fri 08:00-18:00", time: at(1, 18, 0), want: false},
+		{spec: "Mon-Fri 08:00-18:00", time: at(6, 12, 0), want: false},
+		{spec: "Sat,Sun", time: at(7, 23, 59), want: true},
+		{spec: "Sat,Sun", time: at(8, 0, 0), want: false},
+		{spec: "Fri-Mon", time: at(7, 12, 0), want: true},
+		{spec: "* 22:00-06:00", time: at(3, 23, 0), want: true},
+		{spec: "* 22:00-06:00", time: at(3, 5, 59), want: true},
+		{spec: "* 22:00-06:00", time: at(3, 12, 0), want: false},
+		// The early hours of Saturday belong to Friday night.
+		{spec: "Fri 22:00-06:00", time: at(6, 3, 0), want: true},
+		{spec: "Fri 22:00-06:00", time: at(5, 3, 0), want: false},
+		{spec: "18:00-24:00", time: at(2, 23, 30), want: true},
+	}
+
+	for _, tt := range tests {
+		schedule, err := nagios.ParseSchedule(tt.spec)
+		if err != nil {
+			t.Fatalf("failed to parse schedule %q: %v", tt.spec, err)
+		}
+
+		if got := schedule.Contains(tt.time); got != tt.want {
+			t.Errorf("%q contains %s: want %t, got %t", tt.spec, tt.time.Format(time.RFC1123), tt.want, got)
+		}
+	}
+
+	for _, spec := range []string{"", "Mon-Fri 08:00", "Someday", "Mon 25:00-26:00", "* 08:00-08:00"} {
+		if _, err := nagios.ParseSchedule(spec); !errors.Is(err, nagios.ErrInvalidSchedule) {
+			t.Errorf("%q: want error wrapping %v, got %v", spec, nagios.ErrInvalidSchedule, err)
+		}
+	}
+}
+
+// TestThresholdWindowOverridesDefaultThresholds asserts that the thresholds
+// of an active window are used for evaluation and display.
+func TestThresholdWindowOverridesDefaultThresholds(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.SetWarningThreshold("80"); err != nil {
+		t.Fatalf("failed to set warning threshold: %v", err)
+	}
+
+	if err := plugin.SetCriticalThreshold("90"); err != nil {
+		t.Fatalf("failed to set critical threshold: %v", err)
+	}
+
+	// This window is always active; only the warning threshold differs.
+	if err := plugin.AddThresholdWindow("*", "50", ""); err != nil {
+		t.Fatalf("failed to add threshold window: %v", err)
+	}
+
+	if got := plugin.EvaluateThresholds(60); got != nagios.StateWARNINGExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateWARNINGExitCode, got)
+	}
+
+	if got := plugin.EvaluateThresholds(95); got != nagios.StateCRITICALExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateCRITICALExitCode, got)
+	}
+
+	plugin.ServiceOutput = "usage evaluated"
+	plugin.LongServiceOutput = "details"
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{"* CRITICAL: 90 \n", "* WARNING: 50 (*) \n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if err := plugin.AddThresholdWindow("Mon-Fri", "high", ""); !errors.Is(err, nagios.ErrInvalidRange) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrInvalidRange, err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 )
 
 // handleServiceOutputSection is a wrapper around the logic used to process
@@ -45,25 +46,37 @@ func (p Plugin) handleErrorsSection(w io.Writer) {
 	if !p.isErrorsHidden() {
 
 		fmt.Fprintf(w,
-			"%s%s**%s**%s%s",
+			"%s%s%s%s%s",
 			CheckOutputEOL,
 			CheckOutputEOL,
-			p.getErrorsLabelText(),
+			p.sectionLabel(p.getErrorsLabelText()),
 			CheckOutputEOL,
 			CheckOutputEOL,
 		)
 
 		if p.LastError != nil {
-			fmt.Fprintf(w, "* %v%s", p.LastError, CheckOutputEOL)
+			fmt.Fprintf(w, "%s%s", p.listItem("%v", p.LastError), CheckOutputEOL)
 		}
 
 		// Process any non-nil errors in the collection.
 		for _, err := range p.Errors {
 			if err != nil {
-				fmt.Fprintf(w, "* %v%s", err, CheckOutputEOL)
+				fmt.Fprintf(w, "%s%s", p.listItem("%v", err), CheckOutputEOL)
 			}
 		}
 
+		// Note which error (if any) determined the final plugin state.
+		if p.stateDrivenBy != nil {
+			fmt.Fprintf(w,
+				"%s%s: %s (%v)%s",
+				CheckOutputEOL,
+				p.message(MessageStateDrivenBy, stateDrivenByLabel),
+				stateLabel(p.stateDrivenBy.ExitCode),
+				p.stateDrivenBy,
+				CheckOutputEOL,
+			)
+		}
+
 	}
 
 }
@@ -81,27 +94,25 @@ func (p Plugin) handleThresholdsSection(w io.Writer) {
 		if !p.isThresholdsSectionHidden() {
 
 			fmt.Fprintf(w,
-				"%s**%s**%s%s",
+				"%s%s%s%s",
 				CheckOutputEOL,
-				p.getThresholdsLabelText(),
+				p.sectionLabel(p.getThresholdsLabelText()),
 				CheckOutputEOL,
 				CheckOutputEOL,
 			)
 
-			if p.CriticalThreshold != "" {
+			if critical := p.getCriticalThresholdText(); critical != "" {
 				fmt.Fprintf(w,
-					"* %s: %v%s",
-					StateCRITICALLabel,
-					p.CriticalThreshold,
+					"%s%s",
+					p.listItem("%s: %v", StateCRITICALLabel, critical),
 					CheckOutputEOL,
 				)
 			}
 
-			if p.WarningThreshold != "" {
+			if warning := p.getWarningThresholdText(); warning != "" {
 				fmt.Fprintf(w,
-					"* %s: %v%s",
-					StateWARNINGLabel,
-					p.WarningThreshold,
+					"%s%s",
+					p.listItem("%s: %v", StateWARNINGLabel, warning),
 					CheckOutputEOL,
 				)
 			}
@@ -130,9 +141,9 @@ func (p Plugin) handleLongServiceOutput(w io.Writer) {
 	switch {
 	case !p.isThresholdsSectionHidden() || !p.isErrorsHidden():
 		fmt.Fprintf(w,
-			"%s**%s**%s",
+			"%s%s%s",
 			CheckOutputEOL,
-			p.getDetailedInfoLabelText(),
+			p.sectionLabel(p.getDetailedInfoLabelText()),
 			CheckOutputEOL,
 		)
 	default:
@@ -192,7 +203,8 @@ func (p *Plugin) handlePerformanceData(w io.Writer) {
 // isThresholdsSectionHidden indicates whether the Thresholds section should
 // be omitted from output.
 func (p Plugin) isThresholdsSectionHidden() bool {
-	if p.hideThresholdsSection || (p.WarningThreshold == "" && p.CriticalThreshold == "") {
+	if p.hideThresholdsSection ||
+		(p.getWarningThresholdText() == "" && p.getCriticalThresholdText() == "") {
 		return true
 	}
 	return false
@@ -208,35 +220,74 @@ func (p Plugin) isErrorsHidden() bool {
 }
 
 // getThresholdsLabelText retrieves the custom thresholds label text if set,
-// otherwise returns the default value.
+// otherwise returns the localized (see SetMessageCatalog) or default value.
 func (p Plugin) getThresholdsLabelText() string {
 	switch {
 	case p.thresholdsLabel != "":
 		return p.thresholdsLabel
 	default:
-		return defaultThresholdsLabel
+		return p.message(MessageThresholdsLabel, defaultThresholdsLabel)
+	}
+}
+
+// getCriticalThresholdText retrieves the critical threshold display text for
+// the active threshold window (if any), otherwise the critical threshold
+// display text if set, otherwise falls back to the critical threshold range
+// (if set). The origin of the threshold is included if known.
+func (p Plugin) getCriticalThresholdText() string {
+	if window := p.activeThresholdWindow(time.Now()); window != nil && window.critical != "" {
+		return fmt.Sprintf("%s (%s)", window.critical, window.schedule)
+	}
+
+	switch {
+	case p.CriticalThreshold != "":
+		return withThresholdOrigin(p.CriticalThreshold, p.criticalThresholdOrigin)
+	case p.CriticalRange != nil:
+		return withThresholdOrigin(p.CriticalRange.String(), p.criticalThresholdOrigin)
+	default:
+		return ""
+	}
+}
+
+// getWarningThresholdText retrieves the warning threshold display text for
+// the active threshold window (if any), otherwise the warning threshold
+// display text if set, otherwise falls back to the warning threshold range
+// (if set). The origin of the threshold is included if known.
+func (p Plugin) getWarningThresholdText() string {
+	if window := p.activeThresholdWindow(time.Now()); window != nil && window.warning != "" {
+		return fmt.Sprintf("%s (%s)", window.warning, window.schedule)
+	}
+
+	switch {
+	case p.WarningThreshold != "":
+		return withThresholdOrigin(p.WarningThreshold, p.warningThresholdOrigin)
+	case p.WarningRange != nil:
+		return withThresholdOrigin(p.WarningRange.String(), p.warningThresholdOrigin)
+	default:
+		return ""
 	}
 }
 
 // getErrorsLabelText retrieves the custom errors label text if set, otherwise
-// returns the default value.
+// returns the localized (see SetMessageCatalog) or default value.
 func (p Plugin) getErrorsLabelText() string {
 	switch {
 	case p.errorsLabel != "":
 		return p.errorsLabel
 	default:
-		return defaultErrorsLabel
+		return p.message(MessageErrorsLabel, defaultErrorsLabel)
 	}
 }
 
-// getErrorsLabelText retrieves the custom detailed info label text if set,
-// otherwise returns the default value.
+// getDetailedInfoLabelText retrieves the custom detailed info label text if
+// set, otherwise returns the localized (see SetMessageCatalog) or default
+// value.
 func (p Plugin) getDetailedInfoLabelText() string {
 	switch {
 	case p.detailedInfoLabel != "":
 		return p.detailedInfoLabel
 	default:
-		return defaultDetailedInfoLabel
+		return p.message(MessageDetailedInfoLabel, defaultDetailedInfoLabel)
 	}
 }
 
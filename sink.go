@@ -0,0 +1,291 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink URL schemes supported by ParseSink without further registration.
+const (
+	SinkSchemeStdout       string = "stdout"
+	SinkSchemeWebhookHTTP  string = "webhook+http"
+	SinkSchemeWebhookHTTPS string = "webhook+https"
+)
+
+// DefaultWebhookSinkTimeout is the timeout applied to webhook sink requests
+// if an HTTP client is not provided.
+const DefaultWebhookSinkTimeout time.Duration = 30 * time.Second
+
+// maxSinkErrorBodySize is the maximum number of bytes of an error response
+// included in returned errors.
+const maxSinkErrorBodySize int64 = 512
+
+var (
+	// ErrSinkFailed indicates that a Sink failed to accept a result.
+	ErrSinkFailed = errors.New("result sink failed")
+
+	// ErrUnknownSinkScheme indicates that a sink specification uses a URL
+	// scheme which is not registered. See also RegisterSinkScheme.
+	ErrUnknownSinkScheme = errors.New("unknown result sink scheme")
+)
+
+// Sink receives the final result of a plugin run. Sinks allow the same
+// check code to run in active mode (plugin output, see NewOutputSink) or
+// passive mode (e.g., NRDP or the Icinga 2 API) by configuration only. See
+// also SetSinks and ParseSink.
+type Sink interface {
+	Emit(result Result) error
+}
+
+// SinkFunc is an adapter allowing an ordinary function to be used as a
+// Sink.
+type SinkFunc func(result Result) error
+
+// Emit calls f(result).
+func (f SinkFunc) Emit(result Result) error {
+	return f(result)
+}
+
+// SinkFactory constructs a Sink from a parsed sink specification. See also
+// RegisterSinkScheme.
+type SinkFactory func(spec *url.URL) (Sink, error)
+
+// sinkSchemes is the registry of sink factories by URL scheme.
+var sinkSchemes = struct {
+	mu        sync.RWMutex
+	factories map[string]SinkFactory
+}{
+	factories: map[string]SinkFactory{
+		SinkSchemeStdout: func(*url.URL) (Sink, error) {
+			return NewOutputSink(os.Stdout), nil
+		},
+		SinkSchemeWebhookHTTP:  newWebhookSinkFromURL,
+		SinkSchemeWebhookHTTPS: newWebhookSinkFromURL,
+	},
+}
+
+// RegisterSinkScheme registers the factory used by ParseSink for sink
+// specifications using the given URL scheme, replacing any factory
+// previously registered for the scheme. The passive and icinga2 packages
+// register their schemes (e.g., "nrdp+https") when imported.
+func RegisterSinkScheme(scheme string, factory SinkFactory) {
+	sinkSchemes.mu.Lock()
+	defer sinkSchemes.mu.Unlock()
+
+	sinkSchemes.factories[strings.ToLower(scheme)] = factory
+}
+
+// ParseSink constructs a Sink from the given specification, a URL whose
+// scheme selects the sink type (e.g., "stdout:" or
+// "webhook+https://hooks.example.com/results"). An error wrapping
+// ErrUnknownSinkScheme is returned if no factory is registered for the
+// scheme.
+func ParseSink(spec string) (Sink, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result sink %q: %w", spec, err)
+	}
+
+	sinkSchemes.mu.RLock()
+	factory, ok := sinkSchemes.factories[strings.ToLower(u.Scheme)]
+	sinkSchemes.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: %q (registered: %s)",
+			ErrUnknownSinkScheme,
+			u.Scheme,
+			strings.Join(registeredSinkSchemes(), ", "),
+		)
+	}
+
+	return factory(u)
+}
+
+// registeredSinkSchemes returns the registered sink schemes, sorted.
+func registeredSinkSchemes() []string {
+	sinkSchemes.mu.RLock()
+	defer sinkSchemes.mu.RUnlock()
+
+	schemes := make([]string, 0, len(sinkSchemes.factories))
+	for scheme := range sinkSchemes.factories {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// outputSink is the Sink returned by NewOutputSink.
+type outputSink struct {
+	w io.Writer
+}
+
+// NewOutputSink returns a Sink which writes results to w in the standard
+// plugin output format. When used as a sink of a plugin (see SetSinks) the
+// output rendered by that plugin is written as-is, so all plugin output
+// settings (e.g., EOL, ASCII output, output spill, strict mode size limits,
+// output filters and code page) apply. Results emitted by calling Emit
+// directly are rendered using default settings (one-line summary,
+// LongServiceOutput with the Errors and Thresholds sections, and
+// performance data).
+func NewOutputSink(w io.Writer) Sink {
+	return &outputSink{w: w}
+}
+
+// Emit writes the given result to the output using default settings.
+func (s *outputSink) Emit(result Result) error {
+	var output strings.Builder
+
+	var plugin Plugin
+	plugin.ImportResult(result).renderOutput(&output)
+
+	return writeOutput(s.w, output.String())
+}
+
+// WebhookSinkOption is a functional option used to configure the Sink
+// returned by NewWebhookSink.
+type WebhookSinkOption func(*webhookSink)
+
+// webhookSink is the Sink returned by NewWebhookSink.
+type webhookSink struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// WithWebhookHTTPClient is a WebhookSinkOption used to specify the HTTP
+// client used to submit results (e.g., one configured with a CA bundle via
+// the transport package). If not specified, a client with
+// DefaultWebhookSinkTimeout is used.
+func WithWebhookHTTPClient(httpClient *http.Client) WebhookSinkOption {
+	return func(s *webhookSink) {
+		if httpClient != nil {
+			s.httpClient = httpClient
+		}
+	}
+}
+
+// NewWebhookSink returns a Sink which submits results as JSON (see Result)
+// to the given URL using HTTP POST requests. Default settings are used
+// unless overridden by the given options (e.g., WithWebhookHTTPClient). An
+// error wrapping ErrSinkFailed is returned for responses with a non-2xx
+// status.
+func NewWebhookSink(endpoint string, options ...WebhookSinkOption) Sink {
+	s := webhookSink{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: DefaultWebhookSinkTimeout},
+	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s
+}
+
+// Emit submits the given result to the webhook endpoint.
+func (s *webhookSink) Emit(result Result) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to prepare webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSinkFailed, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxSinkErrorBodySize))
+
+		return fmt.Errorf(
+			"%w: webhook returned %s: %s",
+			ErrSinkFailed,
+			resp.Status,
+			strings.TrimSpace(string(detail)),
+		)
+	}
+
+	return nil
+}
+
+// newWebhookSinkFromURL is the SinkFactory for webhook sink specifications
+// (e.g., "webhook+https://hooks.example.com/results").
+func newWebhookSinkFromURL(spec *url.URL) (Sink, error) {
+	endpoint := *spec
+	endpoint.Scheme = strings.TrimPrefix(strings.ToLower(spec.Scheme), "webhook+")
+
+	return NewWebhookSink(endpoint.String()), nil
+}
+
+// WithSinks is an Option used to emit the final result to the given sinks.
+// See also SetSinks.
+func WithSinks(sinks ...Sink) Option {
+	return func(p *Plugin) {
+		p.SetSinks(sinks...)
+	}
+}
+
+// SetSinks causes ReturnCheckResults to emit the final result to each of
+// the given sinks instead of writing plugin output to the output target
+// (see SetOutputTarget). Include NewOutputSink(os.Stdout) to also emit
+// plugin output. The plugin exit code still reflects the plugin state.
+// Sink failures are reported as described for emit failures (see
+// SetOutputFailureTrace).
+func (p *Plugin) SetSinks(sinks ...Sink) {
+	p.sinks = sinks
+}
+
+// emitToSinks emits the given final result to each configured sink. The
+// given rendered plugin output is written as-is to output sinks (see
+// NewOutputSink). Whether any output sink was written to is returned along
+// with the errors of all failed sinks.
+func (p *Plugin) emitToSinks(result Result, pluginOutput string) (bool, error) {
+	var (
+		errs        []error
+		wroteOutput bool
+	)
+
+	for _, sink := range p.sinks {
+		var err error
+
+		switch sink := sink.(type) {
+		case *outputSink:
+			wroteOutput = true
+			err = writeOutput(sink.w, pluginOutput)
+		default:
+			err = sink.Emit(result)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return wroteOutput, errors.Join(errs...)
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestSinksReplaceOutputTarget asserts that the final result is emitted to
+// configured sinks instead of the output target.
+func TestSinksReplaceOutputTarget(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+	var sinkBuffer strings.Builder
+	var emitted []nagios.Result
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+	plugin.SetSinks(
+		nagios.SinkFunc(func(result nagios.Result) error {
+			emitted = append(emitted, result)
+			return nil
+		}),
+		nagios.NewOutputSink(&sinkBuffer),
+	)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Warning("queue backlog").
+		WithDetail("consumer: billing").
+		WithPerfData(nagios.PerformanceData{Label: "queued", Value: "120"})
+	plugin.ReturnCheckResults()
+
+	if outputBuffer.Len() != 0 {
+		t.Errorf("want no output written to output target, got:\n%s", outputBuffer.String())
+	}
+
+	if len(emitted) != 1 || emitted[0].ExitCode != nagios.StateWARNINGExitCode {
+		t.Fatalf("want one WARNING result emitted, got %+v", emitted)
+	}
+
+	got := sinkBuffer.String()
+	for _, want := range []string{"WARNING: queue backlog", "consumer: billing", "'queued'=120"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestParseSinkWebhook asserts that webhook sink specifications submit
+// results as JSON to the given endpoint.
+func TestParseSinkWebhook(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan nagios.Result, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result nagios.Result
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Path != "/results" {
+			http.Error(w, "unexpected path", http.StatusNotFound)
+			return
+		}
+
+		received <- result
+	}))
+	defer server.Close()
+
+	sink, err := nagios.ParseSink(strings.Replace(server.URL, "http://", "webhook+http://", 1) + "/results")
+	if err != nil {
+		t.Fatalf("failed to parse sink: %v", err)
+	}
+
+	if err := sink.Emit(nagios.Result{ExitCode: 2, State: "CRITICAL", ServiceOutput: "CRITICAL: down"}); err != nil {
+		t.Fatalf("failed to emit result: %v", err)
+	}
+
+	if got := <-received; got.ServiceOutput != "CRITICAL: down" {
+		t.Errorf("want summary %q, got %q", "CRITICAL: down", got.ServiceOutput)
+	}
+}
+
+// TestParseSinkUnknownScheme asserts that unregistered sink schemes are
+// rejected.
+func TestParseSinkUnknownScheme(t *testing.T) {
+	t.Parallel()
+
+	_, err := nagios.ParseSink("carrier-pigeon://loft")
+	if !errors.Is(err, nagios.ErrUnknownSinkScheme) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrUnknownSinkScheme, err)
+	}
+}
+
+// TestOutputSinkUsesPluginSettings asserts that output sinks of a plugin
+// write the output rendered using the plugin output settings.
+func TestOutputSinkUsesPluginSettings(t *testing.T) {
+	t.Parallel()
+
+	var sinkBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithASCIIOutput(nagios.ASCIIModeTransliterate),
+		nagios.WithOutputFilter(nagios.RedactFilter("hunter2")),
+		nagios.WithSinks(nagios.NewOutputSink(&sinkBuffer)),
+	)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Critical("café login failed for password hunter2")
+	plugin.ReturnCheckResults()
+
+	got := sinkBuffer.String()
+
+	if want := "CRITICAL: cafe login failed for password REDACTED"; !strings.Contains(got, want) {
+		t.Errorf("want output to contain %q, got:\n%s", want, got)
+	}
+}
+
+// TestOutputTraceSkippedWithoutOutputSink asserts that output is not
+// recorded to the trace file if results are only emitted to sinks which do
+// not write plugin output.
+func TestOutputTraceSkippedWithoutOutputSink(t *testing.T) {
+	t.Parallel()
+
+	tracePath := filepath.Join(t.TempDir(), "output.trace")
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTrace(tracePath, 0, 0),
+		nagios.WithSinks(nagios.SinkFunc(func(nagios.Result) error { return nil })),
+	)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.OK("all good")
+	plugin.ReturnCheckResults()
+
+	if _, err := os.Stat(tracePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want no trace file, got %v", err)
+	}
+}
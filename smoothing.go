@@ -0,0 +1,93 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+// WithMovingAverage is an Option used to evaluate thresholds against a moving
+// average. See also SetMovingAverage.
+func WithMovingAverage(label string, samples int) Option {
+	return func(p *Plugin) {
+		p.SetMovingAverage(label, samples)
+	}
+}
+
+// SetMovingAverage causes EvaluateThresholds to apply the warning and
+// critical thresholds to the moving average of the given value and the
+// values of the performance data metric with the given label persisted by
+// the most recent runs, up to the given number of samples in total. This
+// smooths noisy metrics (e.g., load average) without changing the plugin's
+// collection logic.
+//
+// This requires a state store (see SetStateStore) and that the plugin emits
+// the performance data metric with the given label on each run. The
+// instantaneous value is used until samples have been persisted, if the
+// number of samples is less than 2 or if persisted state cannot be read.
+func (p *Plugin) SetMovingAverage(label string, samples int) {
+	p.movingAverageLabel = label
+	p.movingAverageSamples = samples
+}
+
+// MovingAverage returns the moving average of the given value and the
+// persisted values of the performance data metric selected via
+// SetMovingAverage, along with the number of samples used. The given value
+// and a sample count of 1 are returned if a moving average is not enabled or
+// no values have been persisted.
+func (p Plugin) MovingAverage(value float64) (float64, int) {
+	if p.movingAverageLabel == "" || p.movingAverageSamples < 2 || p.stateStore == nil {
+		return value, 1
+	}
+
+	state, err := p.stateStore.Load()
+	if err != nil {
+		p.Logger().Warn(
+			"failed to load persisted samples; using instantaneous value",
+			"label", p.movingAverageLabel,
+			"error", err,
+		)
+
+		return value, 1
+	}
+
+	samples := state.Samples[p.movingAverageLabel]
+	if limit := p.movingAverageSamples - 1; len(samples) > limit {
+		samples = samples[len(samples)-limit:]
+	}
+
+	sum := value
+	for _, sample := range samples {
+		sum += sample
+	}
+
+	count := len(samples) + 1
+
+	return sum / float64(count), count
+}
+
+// recordMovingAverageSample appends the current value of the performance
+// data metric selected via SetMovingAverage to the given persisted state,
+// retaining only the samples needed for the moving average.
+func (p *Plugin) recordMovingAverageSample(state *PersistedState, values map[string]float64) {
+	if p.movingAverageLabel == "" || p.movingAverageSamples < 2 {
+		return
+	}
+
+	value, ok := values[p.movingAverageLabel]
+	if !ok {
+		return
+	}
+
+	if state.Samples == nil {
+		state.Samples = make(map[string][]float64)
+	}
+
+	samples := append(state.Samples[p.movingAverageLabel], value)
+	if len(samples) > p.movingAverageSamples {
+		samples = samples[len(samples)-p.movingAverageSamples:]
+	}
+
+	state.Samples[p.movingAverageLabel] = samples
+}
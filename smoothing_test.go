@@ -0,0 +1,77 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"io"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestEvaluateThresholdsUsesMovingAverage asserts that thresholds are
+// applied to the moving average of persisted samples when enabled.
+func TestEvaluateThresholdsUsesMovingAverage(t *testing.T) {
+	t.Parallel()
+
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	run := func(load float64) int {
+		plugin := nagios.Plugin{}
+		plugin.SetOutputTarget(io.Discard)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetStateFile(stateFile)
+		plugin.SetMovingAverage("load1", 3)
+
+		if err := plugin.SetWarningThreshold("5"); err != nil {
+			t.Fatalf("failed to set warning threshold: %v", err)
+		}
+
+		state := plugin.EvaluateThresholds(load)
+
+		plugin.ExitStatusCode = state
+		plugin.ServiceOutput = "load evaluated"
+
+		if err := plugin.AddPerfData(false, nagios.PerformanceData{
+			Label: "load1",
+			Value: strconv.FormatFloat(load, 'f', -1, 64),
+		}); err != nil {
+			t.Fatalf("failed to add performance data: %v", err)
+		}
+
+		plugin.ReturnCheckResults()
+
+		return state
+	}
+
+	tests := []struct {
+		load float64
+		want int
+	}{
+		{load: 1, want: nagios.StateOKExitCode},
+		{load: 1, want: nagios.StateOKExitCode},
+		{load: 1, want: nagios.StateOKExitCode},
+		// (1 + 1 + 10) / 3 = 4
+		{load: 10, want: nagios.StateOKExitCode},
+		// (1 + 10 + 10) / 3 = 7
+		{load: 10, want: nagios.StateWARNINGExitCode},
+		// (10 + 10 + 1) / 3 = 7
+		{load: 1, want: nagios.StateWARNINGExitCode},
+	}
+
+	for i, tt := range tests {
+		if got := run(tt.load); got != tt.want {
+			t.Errorf("run %d (load %v): want state %d, got %d", i, tt.load, tt.want, got)
+		}
+	}
+}
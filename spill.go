@@ -0,0 +1,178 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultSpillFileRetention is the number of spill files retained for a
+// plugin if not specified by client code. See also SetOutputSpill.
+const DefaultSpillFileRetention int = 10
+
+// Spill file naming and marker text.
+const (
+	spillFileExtension string = ".txt"
+	spillMarkerFormat  string = "[output truncated, full report: %s]"
+	spillMarkerNoFile  string = "[output truncated]"
+)
+
+// WithOutputSpill is an Option used to truncate oversize output while
+// retaining the full report in a spill file. See also SetOutputSpill.
+func WithOutputSpill(dir string, keep int) Option {
+	return func(p *Plugin) {
+		p.SetOutputSpill(dir, keep)
+	}
+}
+
+// SetOutputSpill truncates LongServiceOutput if plugin output exceeds the
+// maximum output size (see SetMaxOutputSize) and appends a marker noting
+// where the complete report was written:
+//
+//	[output truncated, full report: /var/tmp/mycheck-1234.txt]
+//
+// The complete report is written to a spill file in the given directory
+// named after the plugin executable and process ID. The most recent keep
+// spill files of the plugin are retained (DefaultSpillFileRetention if
+// keep is less than 1); older spill files are removed.
+//
+// Output is truncated before strict mode enforces the maximum output size
+// (see EnableStrictMode).
+func (p *Plugin) SetOutputSpill(dir string, keep int) {
+	if keep < 1 {
+		keep = DefaultSpillFileRetention
+	}
+
+	p.spillDir = dir
+	p.spillRetention = keep
+}
+
+// truncateOutput truncates LongServiceOutput and renders the given output
+// again if the output exceeds the maximum output size and a spill directory
+// is set. The complete output is written to a spill file first.
+func (p *Plugin) truncateOutput(output *strings.Builder) {
+	maxSize := p.getMaxOutputSize()
+
+	if p.spillDir == "" || output.Len() <= maxSize || p.LongServiceOutput == "" {
+		return
+	}
+
+	marker := spillMarkerNoFile
+
+	path, err := p.writeSpillFile(output.String())
+	switch {
+	case err != nil:
+		p.Logger().Warn("failed to write spill file", "error", err)
+	default:
+		marker = fmt.Sprintf(spillMarkerFormat, path)
+	}
+
+	p.Logger().Debug("truncating oversize output", "bytes", output.Len(), "limit", maxSize)
+
+	long := p.LongServiceOutput
+
+	for output.Len() > maxSize && long != "" {
+		keep := len(long) - (output.Len() - maxSize) - len(marker) - len(CheckOutputEOL)
+		long = truncateUTF8(long, keep)
+
+		p.LongServiceOutput = marker
+		if long != "" {
+			p.LongServiceOutput = long + CheckOutputEOL + marker
+		}
+
+		output.Reset()
+		p.renderOutput(output)
+	}
+}
+
+// truncateUTF8 returns the longest prefix of s no longer than n bytes which
+// does not split a multi-byte character.
+func truncateUTF8(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+// writeSpillFile writes the given output to a new spill file, removes
+// spill files beyond the retention limit and returns the path of the spill
+// file.
+func (p *Plugin) writeSpillFile(output string) (string, error) {
+	prefix := filepath.Base(os.Args[0]) + "-"
+
+	path := filepath.Join(
+		p.spillDir,
+		fmt.Sprintf("%s%d%s", prefix, os.Getpid(), spillFileExtension),
+	)
+
+	if err := writeFileAtomic(path, []byte(output)); err != nil {
+		return "", fmt.Errorf("failed to write spill file %s: %w", path, err)
+	}
+
+	if err := rotateSpillFiles(p.spillDir, prefix, p.spillRetention); err != nil {
+		p.Logger().Warn("failed to rotate spill files", "error", err)
+	}
+
+	return path, nil
+}
+
+// rotateSpillFiles removes all but the most recent keep spill files with
+// the given name prefix from dir.
+func rotateSpillFiles(dir string, prefix string, keep int) error {
+	paths, err := filepath.Glob(filepath.Join(dir, prefix+"*"+spillFileExtension))
+	if err != nil {
+		return err
+	}
+
+	if len(paths) <= keep {
+		return nil
+	}
+
+	type spillFile struct {
+		path    string
+		modTime int64
+	}
+
+	files := make([]spillFile, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, spillFile{path: path, modTime: info.ModTime().UnixNano()})
+	}
+
+	// Most recent first.
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime > files[j].modTime
+	})
+
+	var errs []error
+	for i := keep; i < len(files); i++ {
+		if err := os.Remove(files[i].path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
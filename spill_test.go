@@ -0,0 +1,137 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestOutputSpillTruncatesOversizeOutput asserts that oversize output is
+// truncated with a marker pointing to a spill file holding the complete
+// output and that old spill files are rotated.
+func TestOutputSpillTruncatesOversizeOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	prefix := filepath.Base(os.Args[0]) + "-"
+
+	// Spill files left behind by earlier runs.
+	for i := 1; i <= 3; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%s%d.txt", prefix, i))
+		if err := os.WriteFile(path, []byte("old report"), 0o600); err != nil {
+			t.Fatalf("failed to create spill file: %v", err)
+		}
+
+		modTime := time.Now().Add(-time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("failed to set spill file time: %v", err)
+		}
+	}
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithOutputSpill(dir, 2),
+	)
+
+	plugin.SetMaxOutputSize(300)
+
+	plugin.ServiceOutput = "WARNING: 40 of 200 volumes degraded"
+	plugin.ExitStatusCode = nagios.StateWARNINGExitCode
+
+	for i := 1; i <= 40; i++ {
+		plugin.WithDetail(fmt.Sprintf("* vol%03d: degraded", i))
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+	spillPath := filepath.Join(dir, fmt.Sprintf("%s%d.txt", prefix, os.Getpid()))
+
+	if len(got) > 300 {
+		t.Errorf("want output of at most 300 bytes, got %d bytes:\n%s", len(got), got)
+	}
+
+	for _, want := range []string{
+		"WARNING: 40 of 200 volumes degraded",
+		"* vol001: degraded",
+		"[output truncated, full report: " + spillPath + "]",
+		" | 'time'=",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	report, err := os.ReadFile(spillPath)
+	if err != nil {
+		t.Fatalf("failed to read spill file: %v", err)
+	}
+
+	if !strings.Contains(string(report), "* vol040: degraded") {
+		t.Errorf("want complete output in spill file, got:\n%s", report)
+	}
+
+	remaining, err := filepath.Glob(filepath.Join(dir, prefix+"*.txt"))
+	if err != nil {
+		t.Fatalf("failed to list spill files: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, prefix+"1.txt"),
+		spillPath,
+	}
+
+	if len(remaining) != len(want) {
+		t.Fatalf("want spill files %v retained, got %v", want, remaining)
+	}
+
+	for _, path := range want {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("want spill file %s retained: %v", path, err)
+		}
+	}
+}
+
+// TestOutputSpillIgnoresOutputWithinLimit asserts that output within the
+// maximum output size is emitted as-is.
+func TestOutputSpillIgnoresOutputWithinLimit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputTarget(&outputBuffer),
+		nagios.WithSkipOSExit(),
+		nagios.WithOutputSpill(dir, 0),
+	)
+
+	plugin.ServiceOutput = "OK: 200 volumes healthy"
+	plugin.WithDetail("* vol001: healthy")
+
+	plugin.ReturnCheckResults()
+
+	if got := outputBuffer.String(); strings.Contains(got, "output truncated") {
+		t.Errorf("want output without truncation marker, got:\n%s", got)
+	}
+
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("want no spill files, got %v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// stateDrivenByLabel is the text emitted prior to the error responsible for
+// the final plugin state.
+const stateDrivenByLabel string = "State driven by"
+
+// StateError is an error which carries the plugin state (exit code) that it
+// should cause. When recorded via AddError, the final plugin state is
+// computed as the most severe of the state set by client code and the states
+// of all recorded StateError values. If a StateError determines the final
+// state, a short attribution line is included in the Errors section so that
+// it is immediately clear which failure caused the notification.
+type StateError struct {
+	// ExitCode is the plugin exit status code associated with the error.
+	ExitCode int
+
+	// Err is the underlying error.
+	Err error
+}
+
+// NewStateError wraps the given error with the plugin state (exit code) that
+// it should cause.
+//
+//	plugin.AddError(nagios.NewStateError(nagios.StateWARNINGExitCode, err))
+func NewStateError(exitCode int, err error) error {
+	return &StateError{
+		ExitCode: exitCode,
+		Err:      err,
+	}
+}
+
+// Error provides the text of the underlying error.
+func (se *StateError) Error() string {
+	if se.Err == nil {
+		return fmt.Sprintf("%s state requested without error details", stateLabel(se.ExitCode))
+	}
+
+	return se.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (se *StateError) Unwrap() error {
+	return se.Err
+}
+
+// stateLabel returns the state label associated with the given exit code.
+// The UNKNOWN state label is returned for unrecognized exit codes.
+func stateLabel(exitCode int) string {
+	switch exitCode {
+	case StateOKExitCode:
+		return StateOKLabel
+	case StateWARNINGExitCode:
+		return StateWARNINGLabel
+	case StateCRITICALExitCode:
+		return StateCRITICALLabel
+	case StateDEPENDENTExitCode:
+		return StateDEPENDENTLabel
+	default:
+		return StateUNKNOWNLabel
+	}
+}
+
+// stateSeverity ranks the given exit code by severity. The ordering matches
+// the max_state_alt function from the Monitoring Plugins project: CRITICAL >
+// WARNING > UNKNOWN > DEPENDENT > OK. Unrecognized exit codes are ranked
+// the same as UNKNOWN.
+func stateSeverity(exitCode int) int {
+	switch exitCode {
+	case StateOKExitCode:
+		return 0
+	case StateDEPENDENTExitCode:
+		return 1
+	case StateWARNINGExitCode:
+		return 3
+	case StateCRITICALExitCode:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// worstExitCode returns the most severe of the given exit codes.
+// StateOKExitCode is returned if no exit codes are provided.
+func worstExitCode(exitCodes ...int) int {
+	worst := StateOKExitCode
+	for _, exitCode := range exitCodes {
+		if stateSeverity(exitCode) > stateSeverity(worst) {
+			worst = exitCode
+		}
+	}
+
+	return worst
+}
+
+// ErrInvalidExitCode indicates that the plugin exit code was set to a value
+// outside of the range of valid Nagios plugin exit codes (e.g., an errno or
+// HTTP status code).
+var ErrInvalidExitCode = errors.New("invalid plugin exit code")
+
+// IsValidExitCode indicates whether the given exit code is a valid Nagios
+// plugin exit code (OK, WARNING, CRITICAL, UNKNOWN or DEPENDENT).
+func IsValidExitCode(exitCode int) bool {
+	return exitCode >= StateOKExitCode && exitCode <= StateDEPENDENTExitCode
+}
+
+// sanitizeExitCode translates an invalid plugin exit code into UNKNOWN,
+// recording an error explaining the translation. Nagios treats unrecognized
+// exit codes as UNKNOWN anyway, but without an explanation the actual
+// problem (usually a bug in client code) is hidden.
+func (p *Plugin) sanitizeExitCode() {
+	if IsValidExitCode(p.ExitStatusCode) {
+		return
+	}
+
+	err := fmt.Errorf(
+		"%w: %d is outside of the range %d-%d; translated to %s",
+		ErrInvalidExitCode,
+		p.ExitStatusCode,
+		StateOKExitCode,
+		StateDEPENDENTExitCode,
+		StateUNKNOWNLabel,
+	)
+
+	p.Logger().Warn("invalid plugin exit code", "exit_code", p.ExitStatusCode)
+
+	p.AddError(err)
+	p.ExitStatusCode = StateUNKNOWNExitCode
+}
+
+// applyErrorStates computes the final plugin state from the state set by
+// client code and any recorded StateError values. If a StateError is
+// responsible for the final state it is recorded for attribution purposes
+// and any state label at the start of ServiceOutput (e.g., "OK: ...") is
+// replaced so that the summary matches the escalated state.
+func (p *Plugin) applyErrorStates() {
+	var drivenBy *StateError
+
+	for _, err := range p.Errors {
+		var stateErr *StateError
+		if !errors.As(err, &stateErr) {
+			continue
+		}
+
+		if drivenBy == nil || stateSeverity(stateErr.ExitCode) > stateSeverity(drivenBy.ExitCode) {
+			drivenBy = stateErr
+		}
+	}
+
+	if drivenBy == nil ||
+		drivenBy.ExitCode == StateOKExitCode ||
+		stateSeverity(drivenBy.ExitCode) < stateSeverity(p.ExitStatusCode) {
+		return
+	}
+
+	p.Logger().Debug(
+		"plugin state escalated by recorded error",
+		"from", stateLabel(p.ExitStatusCode),
+		"to", stateLabel(drivenBy.ExitCode),
+		"error", drivenBy,
+	)
+
+	p.ExitStatusCode = drivenBy.ExitCode
+	p.stateDrivenBy = drivenBy
+
+	if start, end := findStateLabel(p.ServiceOutput); start >= 0 {
+		p.ServiceOutput = p.ServiceOutput[:start] + stateLabel(p.ExitStatusCode) + p.ServiceOutput[end:]
+	}
+}
+
+// ErrServiceStateLabelNotFound indicates that a recognized state label could
+// not be found at the start of the given plugin output.
+var ErrServiceStateLabelNotFound = errors.New("service state label not found")
+
+// ServiceStateFromExitCode returns the ServiceState associated with the
+// given exit code. Unrecognized exit codes are reported as UNKNOWN, matching
+// how Nagios treats them.
+func ServiceStateFromExitCode(exitCode int) ServiceState {
+	label := stateLabel(exitCode)
+
+	return ServiceState{
+		Label:    label,
+		ExitCode: stateExitCode(label),
+	}
+}
+
+// String provides the state label, suitable for use as a prefix to plugin
+// output (e.g., "OK").
+func (ss ServiceState) String() string {
+	return ss.Label
+}
+
+// serviceStateLabelRegex matches a leading state label, optionally preceded
+// by a single word service name (e.g., "DISK OK - ...") and followed by a
+// colon, a dash separator or the end of the line. A separator is required so
+// that ordinary words in the output are not mistaken for a state label.
+var serviceStateLabelRegex = regexp.MustCompile(
+	`^(?:\S+\s+)?(OK|WARNING|CRITICAL|UNKNOWN|DEPENDENT)(?::\s*|\s+-\s+|\s*-?\s*$)`,
+)
+
+// ParseServiceState extracts the leading state label from the first line of
+// plugin output, returning the matching ServiceState along with the
+// remaining text. Both "LABEL: text" and "LABEL - text" forms are
+// recognized, as is an optional leading service name (e.g., "DISK OK -
+// free space: / 3326 MB"). Label matching is case-sensitive as state labels
+// are always emitted in upper case.
+//
+// This is useful for wrapper plugins and for validating that a summary
+// matches the plugin exit code. Any performance data present in the line is
+// returned as part of the remaining text.
+//
+// An error wrapping ErrServiceStateLabelNotFound is returned if a state
+// label is not found.
+func ParseServiceState(firstLine string) (ServiceState, string, error) {
+	line := firstLine
+
+	// Only the first line is considered.
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+
+	line = strings.TrimSpace(line)
+
+	match := serviceStateLabelRegex.FindStringSubmatchIndex(line)
+	if match == nil {
+		return ServiceState{}, "", fmt.Errorf("%w: %q", ErrServiceStateLabelNotFound, firstLine)
+	}
+
+	label := line[match[2]:match[3]]
+	rest := line[match[1]:]
+
+	return ServiceStateFromExitCode(stateExitCode(label)), rest, nil
+}
+
+// bareStateLabelRegex matches a state label at the very start of plugin
+// output followed by a colon or whitespace (e.g., "WARNING disk usage
+// high"). This form is only recognized at the start of the output as the
+// label is otherwise indistinguishable from an ordinary word.
+var bareStateLabelRegex = regexp.MustCompile(`^(OK|WARNING|CRITICAL|UNKNOWN|DEPENDENT)(?::|\s)`)
+
+// findStateLabel returns the start and end offsets of the leading state
+// label within the first line of the given plugin output, or -1 for both if
+// a state label is not found. The forms recognized by ParseServiceState are
+// supported along with a bare label followed by a space (e.g., "WARNING
+// disk usage high").
+func findStateLabel(pluginOutput string) (int, int) {
+	line := pluginOutput
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+
+	trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+	offset := len(line) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+
+	for _, re := range []*regexp.Regexp{serviceStateLabelRegex, bareStateLabelRegex} {
+		if match := re.FindStringSubmatchIndex(trimmed); match != nil {
+			return offset + match[2], offset + match[3]
+		}
+	}
+
+	return -1, -1
+}
+
+// stateExitCode returns the exit code associated with the given state label
+// or -1 if the label is not recognized.
+func stateExitCode(label string) int {
+	switch label {
+	case StateOKLabel:
+		return StateOKExitCode
+	case StateWARNINGLabel:
+		return StateWARNINGExitCode
+	case StateCRITICALLabel:
+		return StateCRITICALExitCode
+	case StateUNKNOWNLabel:
+		return StateUNKNOWNExitCode
+	case StateDEPENDENTLabel:
+		return StateDEPENDENTExitCode
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestStateErrorsDetermineFinalStateWithAttribution asserts that the most
+// severe recorded StateError determines the final plugin state and is
+// named in the Errors section.
+func TestStateErrorsDetermineFinalStateWithAttribution(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetSummary("problems detected with mail server")
+
+	plugin.AddError(
+		nagios.NewStateError(nagios.StateWARNINGExitCode, errors.New("certificate expires in 20 days")),
+		errors.New("plain error without state"),
+		nagios.NewStateError(nagios.StateCRITICALExitCode, errors.New("SMTP port not responding")),
+		nagios.NewStateError(nagios.StateUNKNOWNExitCode, errors.New("failed to parse banner")),
+	)
+
+	// Process exit state, emit output to our output buffer.
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf(
+			"want exit status code %d, got %d",
+			nagios.StateCRITICALExitCode,
+			plugin.ExitStatusCode,
+		)
+	}
+
+	want := "problems detected with mail server" +
+		nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"**ERRORS**" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"* certificate expires in 20 days" + nagios.CheckOutputEOL +
+		"* plain error without state" + nagios.CheckOutputEOL +
+		"* SMTP port not responding" + nagios.CheckOutputEOL +
+		"* failed to parse banner" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"State driven by: CRITICAL (SMTP port not responding)" + nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestStateErrorsDoNotLowerClientState asserts that a StateError less
+// severe than the state set by client code does not change the final state
+// and is not named as responsible for it.
+func TestStateErrorsDoNotLowerClientState(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.Critical("mail queue exceeds 500 messages")
+	plugin.AddError(nagios.NewStateError(nagios.StateWARNINGExitCode, errors.New("slow DNS lookup")))
+
+	// Process exit state, emit output to our output buffer.
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf(
+			"want exit status code %d, got %d",
+			nagios.StateCRITICALExitCode,
+			plugin.ExitStatusCode,
+		)
+	}
+
+	if got := outputBuffer.String(); strings.Contains(got, "State driven by") {
+		t.Errorf("unexpected state attribution in output: %q", got)
+	}
+}
+
+// TestStateErrorsRewriteSummaryStateLabel asserts that the state label at
+// the start of the summary is replaced when a StateError escalates the
+// plugin state, so that the summary matches the exit code.
+func TestStateErrorsRewriteSummaryStateLabel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		summary string
+		want    string
+	}{
+		{summary: "OK: fine", want: "CRITICAL: fine"},
+		{summary: "DISK OK - free space: / 3326 MB", want: "DISK CRITICAL - free space: / 3326 MB"},
+		{summary: "OK all checks passed", want: "CRITICAL all checks passed"},
+		{summary: "all checks passed", want: "all checks passed"},
+	}
+
+	for _, tt := range tests {
+		plugin := nagios.NewPlugin()
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.ServiceOutput = tt.summary
+		plugin.ExitStatusCode = nagios.StateOKExitCode
+		plugin.AddError(nagios.NewStateError(nagios.StateCRITICALExitCode, errors.New("boom")))
+
+		plugin.ReturnCheckResults()
+
+		if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+			t.Errorf("%q: want exit status code %d, got %d", tt.summary, nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+		}
+
+		got := outputBuffer.String()
+
+		if !strings.HasPrefix(got, tt.want+nagios.CheckOutputEOL) {
+			t.Errorf("want output to start with %q, got:\n%s", tt.want, got)
+		}
+
+		if want := "State driven by: CRITICAL (boom)"; !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestStateErrorInvalidExitCodeTranslated asserts that an out of range exit
+// code carried by a StateError is translated into UNKNOWN instead of being
+// used as the process exit code.
+func TestStateErrorInvalidExitCodeTranslated(t *testing.T) {
+	t.Parallel()
+
+	for _, exitCode := range []int{42, -1} {
+		plugin := nagios.NewPlugin()
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.ServiceOutput = "OK: fine"
+		plugin.ExitStatusCode = nagios.StateOKExitCode
+		plugin.AddError(nagios.NewStateError(exitCode, errors.New("boom")))
+
+		plugin.ReturnCheckResults()
+
+		if plugin.ExitStatusCode != nagios.StateUNKNOWNExitCode {
+			t.Errorf("%d: want exit status code %d, got %d", exitCode, nagios.StateUNKNOWNExitCode, plugin.ExitStatusCode)
+		}
+
+		if !errors.Is(errors.Join(plugin.Errors...), nagios.ErrInvalidExitCode) {
+			t.Errorf("%d: want error wrapping %v, got %v", exitCode, nagios.ErrInvalidExitCode, plugin.Errors)
+		}
+
+		got := outputBuffer.String()
+
+		if want := "UNKNOWN: fine"; !strings.HasPrefix(got, want) {
+			t.Errorf("want output to start with %q, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestParseServiceState asserts that leading state labels are extracted from
+// plugin output.
+func TestParseServiceState(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input    string
+		want     nagios.ServiceState
+		wantRest string
+	}{
+		"colon separator": {
+			input:    "OK: Datastore usage is 0.01%",
+			want:     nagios.ServiceState{Label: nagios.StateOKLabel, ExitCode: nagios.StateOKExitCode},
+			wantRest: "Datastore usage is 0.01%",
+		},
+		"dash separator with service name": {
+			input:    "DISK CRITICAL - free space: / 3326 MB (5%);",
+			want:     nagios.ServiceState{Label: nagios.StateCRITICALLabel, ExitCode: nagios.StateCRITICALExitCode},
+			wantRest: "free space: / 3326 MB (5%);",
+		},
+		"label only": {
+			input:    "WARNING",
+			want:     nagios.ServiceState{Label: nagios.StateWARNINGLabel, ExitCode: nagios.StateWARNINGExitCode},
+			wantRest: "",
+		},
+		"multiple lines": {
+			input:    "UNKNOWN: timeout  after 10s | 'time'=10s\nsecond line",
+			want:     nagios.ServiceState{Label: nagios.StateUNKNOWNLabel, ExitCode: nagios.StateUNKNOWNExitCode},
+			wantRest: "timeout  after 10s | 'time'=10s",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, rest, err := nagios.ParseServiceState(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+
+			if rest != tt.wantRest {
+				t.Errorf("want remaining text %q, got %q", tt.wantRest, rest)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "all OK here", "ok: lowercase label", "OKAY: not a label"} {
+		if _, _, err := nagios.ParseServiceState(input); !errors.Is(err, nagios.ErrServiceStateLabelNotFound) {
+			t.Errorf("%q: want error wrapping %v, got %v", input, nagios.ErrServiceStateLabelNotFound, err)
+		}
+	}
+}
+
+// TestServiceStateFromExitCode asserts that exit codes map to the expected
+// ServiceState values.
+func TestServiceStateFromExitCode(t *testing.T) {
+	t.Parallel()
+
+	if got := nagios.ServiceStateFromExitCode(nagios.StateWARNINGExitCode).String(); got != nagios.StateWARNINGLabel {
+		t.Errorf("want %q, got %q", nagios.StateWARNINGLabel, got)
+	}
+
+	want := nagios.ServiceState{Label: nagios.StateUNKNOWNLabel, ExitCode: nagios.StateUNKNOWNExitCode}
+	if d := cmp.Diff(want, nagios.ServiceStateFromExitCode(42)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestInvalidExitCodeTranslatedToUnknown asserts that exit codes outside of
+// the valid range are translated to UNKNOWN with an explanatory error.
+func TestInvalidExitCodeTranslatedToUnknown(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetSummary("request failed")
+	plugin.SetState(404)
+
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateUNKNOWNExitCode, plugin.ExitStatusCode)
+	}
+
+	if len(plugin.Errors) != 1 || !errors.Is(plugin.Errors[0], nagios.ErrInvalidExitCode) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrInvalidExitCode, plugin.Errors)
+	}
+
+	if got := outputBuffer.String(); !strings.Contains(got, "404 is outside of the range 0-4") {
+		t.Errorf("want explanatory note in output, got %q", got)
+	}
+
+	for exitCode, want := range map[int]bool{-1: false, 0: true, 4: true, 5: false} {
+		if got := nagios.IsValidExitCode(exitCode); got != want {
+			t.Errorf("IsValidExitCode(%d): want %t, got %t", exitCode, want, got)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/atc0005/go-nagios/internal/filelock"
+)
+
+// Settings for the JSON state store.
+const (
+	// DefaultStateLockTimeout is the maximum time spent waiting to acquire
+	// the state file lock if not specified by client code.
+	DefaultStateLockTimeout time.Duration = 5 * time.Second
+
+	// staleStateLockAge is the age at which an existing lock file is
+	// assumed to have been left behind by a process which exited without
+	// releasing it (only used on platforms without flock support).
+	staleStateLockAge time.Duration = time.Minute
+
+	// stateLockFileSuffix is appended to the state file path to form the
+	// path of the lock file. A separate lock file is used so that the lock
+	// is retained while the state file is replaced via rename.
+	stateLockFileSuffix string = ".lock"
+
+	// stateSchemaVersion is the current version of the persisted state
+	// format.
+	stateSchemaVersion int = 1
+
+	// stateSchemaVersionField is the name of the schema version field in the
+	// JSON state file.
+	stateSchemaVersionField string = "schema_version"
+)
+
+var (
+	// ErrStateLockTimeout indicates that the state file lock could not be
+	// acquired before the lock timeout expired.
+	ErrStateLockTimeout = errors.New("timeout acquiring state file lock")
+
+	// ErrStateSchemaUnsupported indicates that the state file uses a schema
+	// version newer than the one supported by this library version.
+	ErrStateSchemaUnsupported = errors.New("unsupported state file schema version")
+
+	// ErrStateMigrationFailed indicates that a state file schema migration
+	// failed.
+	ErrStateMigrationFailed = errors.New("state file schema migration failed")
+)
+
+// PersistedState is the plugin state retained between plugin runs.
+type PersistedState struct {
+	// SchemaVersion is the version of the persisted state format.
+	SchemaVersion int `json:"schema_version"`
+
+	// LastState is the final state (exit code) of the most recent run. This
+	// is nil if the plugin has not run before.
+	LastState *int `json:"last_state,omitempty"`
+
+	// Transitions is the collection of recorded state transitions, oldest
+	// first.
+	Transitions []StateTransition `json:"transitions,omitempty"`
+
+	// LastValues is the collection of numeric performance data values
+	// emitted by the most recent run, indexed by label.
+	LastValues map[string]float64 `json:"last_values,omitempty"`
+
+	// Samples is the collection of recent performance data values retained
+	// for moving average evaluation, indexed by label, oldest first.
+	Samples map[string][]float64 `json:"samples,omitempty"`
+}
+
+// StateStore persists plugin state between plugin runs. Implementations
+// must be safe for use by concurrent runs of the same plugin (e.g., via file
+// locking).
+type StateStore interface {
+	// Load returns the persisted state. An empty state is returned if no
+	// state has been persisted.
+	Load() (PersistedState, error)
+
+	// Update loads the persisted state, calls fn to modify it and persists
+	// the result. The state is not persisted if fn returns an error.
+	// Concurrent updates are serialized.
+	Update(fn func(state *PersistedState) error) error
+}
+
+// StateMigration upgrades a decoded JSON state document from one schema
+// version to the next. Top-level fields may be added, removed or rewritten
+// as needed; the schema version field is updated automatically.
+type StateMigration func(document map[string]json.RawMessage) error
+
+// JSONStateStore is a StateStore which persists state as a JSON document in
+// a local file. Updates are serialized between processes using an advisory
+// lock on a companion lock file and written atomically by renaming a
+// temporary file into place, so concurrent scheduled runs of the same
+// plugin cannot corrupt state.
+//
+// The document includes a schema version. Documents written by older
+// library versions are upgraded on load using registered migrations (see
+// WithStateMigration).
+type JSONStateStore struct {
+	path        string
+	lockTimeout time.Duration
+	migrations  map[int]StateMigration
+}
+
+// JSONStateStoreOption is a functional option used to configure a
+// JSONStateStore.
+type JSONStateStoreOption func(*JSONStateStore)
+
+// WithStateLockTimeout is a JSONStateStoreOption used to override the
+// maximum time spent waiting to acquire the state file lock.
+func WithStateLockTimeout(timeout time.Duration) JSONStateStoreOption {
+	return func(s *JSONStateStore) {
+		if timeout > 0 {
+			s.lockTimeout = timeout
+		}
+	}
+}
+
+// WithStateMigration is a JSONStateStoreOption used to register a migration
+// which upgrades a state document from the given schema version to the next
+// version. Registering a migration for a version replaces any existing
+// migration for that version.
+func WithStateMigration(fromVersion int, migration StateMigration) JSONStateStoreOption {
+	return func(s *JSONStateStore) {
+		s.migrations[fromVersion] = migration
+	}
+}
+
+// NewJSONStateStore returns a JSONStateStore which persists state in the
+// file at the given path.
+func NewJSONStateStore(path string, options ...JSONStateStoreOption) *JSONStateStore {
+	s := JSONStateStore{
+		path:        path,
+		lockTimeout: DefaultStateLockTimeout,
+		migrations: map[int]StateMigration{
+			// State files written prior to schema versioning use the same
+			// layout as version 1.
+			0: func(map[string]json.RawMessage) error { return nil },
+		},
+	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s
+}
+
+// Path returns the path of the state file.
+func (s *JSONStateStore) Path() string {
+	return s.path
+}
+
+// Load returns the persisted state. An empty state is returned if the state
+// file does not exist.
+func (s *JSONStateStore) Load() (PersistedState, error) {
+	return s.read()
+}
+
+// Update loads the persisted state, calls fn to modify it and atomically
+// writes the result while holding the state file lock.
+func (s *JSONStateStore) Update(fn func(state *PersistedState) error) error {
+	lock, err := acquireStateLock(s.path+stateLockFileSuffix, s.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	state, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&state); err != nil {
+		return err
+	}
+
+	return s.write(state)
+}
+
+// read reads and migrates the state file.
+func (s *JSONStateStore) read() (PersistedState, error) {
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return PersistedState{SchemaVersion: stateSchemaVersion}, nil
+	case err != nil:
+		return PersistedState{}, err
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return PersistedState{}, fmt.Errorf("failed to decode state file: %w", err)
+	}
+
+	if err := s.migrate(document); err != nil {
+		return PersistedState{}, err
+	}
+
+	migrated, err := json.Marshal(document)
+	if err != nil {
+		return PersistedState{}, fmt.Errorf("failed to decode state file: %w", err)
+	}
+
+	var state PersistedState
+	if err := json.Unmarshal(migrated, &state); err != nil {
+		return PersistedState{}, fmt.Errorf("failed to decode state file: %w", err)
+	}
+
+	return state, nil
+}
+
+// migrate upgrades the given state document to the current schema version.
+func (s *JSONStateStore) migrate(document map[string]json.RawMessage) error {
+	var version int
+	if raw, ok := document[stateSchemaVersionField]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return fmt.Errorf("failed to decode state file schema version: %w", err)
+		}
+	}
+
+	if version > stateSchemaVersion {
+		return fmt.Errorf(
+			"%w: %d (latest supported version is %d)",
+			ErrStateSchemaUnsupported,
+			version,
+			stateSchemaVersion,
+		)
+	}
+
+	for ; version < stateSchemaVersion; version++ {
+		migration, ok := s.migrations[version]
+		if !ok {
+			return fmt.Errorf("%w: no migration registered for version %d", ErrStateMigrationFailed, version)
+		}
+
+		if err := migration(document); err != nil {
+			return fmt.Errorf("%w: version %d: %v", ErrStateMigrationFailed, version, err)
+		}
+	}
+
+	document[stateSchemaVersionField] = json.RawMessage(fmt.Sprint(version))
+
+	return nil
+}
+
+// write atomically writes the state file.
+func (s *JSONStateStore) write(state PersistedState) error {
+	state.SchemaVersion = stateSchemaVersion
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state file: %w", err)
+	}
+
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic writes data to a temporary file which is then renamed into
+// place so that readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// acquireStateLock acquires an exclusive lock on the given lock file,
+// retrying until the timeout expires.
+func acquireStateLock(path string, timeout time.Duration) (*filelock.Lock, error) {
+	lock, err := filelock.Acquire(path, timeout, staleStateLockAge)
+	switch {
+	case errors.Is(err, filelock.ErrTimeout):
+		return nil, fmt.Errorf("%w: %s", ErrStateLockTimeout, path)
+	case err != nil:
+		return nil, fmt.Errorf("failed to lock state file: %w", err)
+	}
+
+	return lock, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestJSONStateStoreSerializesConcurrentUpdates asserts that concurrent
+// updates do not lose or corrupt state.
+func TestJSONStateStoreSerializesConcurrentUpdates(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	const updates = 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// A separate store value per update mimics separate plugin runs.
+			store := nagios.NewJSONStateStore(path)
+
+			err := store.Update(func(state *nagios.PersistedState) error {
+				state.Transitions = append(state.Transitions, nagios.StateTransition{To: i})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("update %d failed: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	state, err := nagios.NewJSONStateStore(path).Load()
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+
+	if len(state.Transitions) != updates {
+		t.Errorf("want %d transitions, got %d", updates, len(state.Transitions))
+	}
+}
+
+// TestJSONStateStoreMigratesOlderSchema asserts that state files written
+// with an older schema version are migrated on load and that newer schema
+// versions are rejected.
+func TestJSONStateStoreMigratesOlderSchema(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	legacy := filepath.Join(dir, "legacy.json")
+	if err := os.WriteFile(legacy, []byte(`{"state": 2}`), 0o600); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	store := nagios.NewJSONStateStore(legacy, nagios.WithStateMigration(0,
+		func(document map[string]json.RawMessage) error {
+			document["last_state"] = document["state"]
+			delete(document, "state")
+			return nil
+		},
+	))
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+
+	if state.SchemaVersion != 1 || state.LastState == nil || *state.LastState != nagios.StateCRITICALExitCode {
+		t.Errorf("unexpected migrated state: %+v", state)
+	}
+
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"schema_version": 99}`), 0o600); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	if _, err := nagios.NewJSONStateStore(future).Load(); !errors.Is(err, nagios.ErrStateSchemaUnsupported) {
+		t.Errorf("want %v, got %v", nagios.ErrStateSchemaUnsupported, err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Label suffixes for summary statistics performance data metrics.
+const (
+	statsMinSuffix        string = "_min"
+	statsMaxSuffix        string = "_max"
+	statsAvgSuffix        string = "_avg"
+	statsPercentilePrefix string = "_p"
+)
+
+var (
+	// ErrNoSamplesProvided indicates that summary statistics were requested
+	// for an empty collection of samples.
+	ErrNoSamplesProvided = errors.New("no samples provided")
+
+	// ErrInvalidPercentile indicates that a requested percentile is outside
+	// of the range (0, 100].
+	ErrInvalidPercentile = errors.New("invalid percentile")
+)
+
+// Percentile is a computed percentile value for a collection of samples.
+type Percentile struct {
+	// Rank is the requested percentile (e.g., 95 or 99.9).
+	Rank float64
+
+	// Value is the sample value at the requested percentile.
+	Value float64
+}
+
+// SummaryStatistics summarizes a collection of samples gathered during a
+// plugin run (e.g., multiple latency probes).
+type SummaryStatistics struct {
+	// Count is the number of samples.
+	Count int
+
+	// Min is the smallest sample value.
+	Min float64
+
+	// Max is the largest sample value.
+	Max float64
+
+	// Avg is the arithmetic mean of the sample values.
+	Avg float64
+
+	// Percentiles is the collection of requested percentiles in the order
+	// requested.
+	Percentiles []Percentile
+}
+
+// Summarize computes summary statistics for the given samples along with
+// any requested percentiles (e.g., 50, 95, 99). Percentiles are computed
+// using the nearest-rank method so that each reported percentile is an
+// observed sample value.
+func Summarize(samples []float64, percentiles ...float64) (SummaryStatistics, error) {
+	if len(samples) == 0 {
+		return SummaryStatistics{}, ErrNoSamplesProvided
+	}
+
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, sample := range sorted {
+		sum += sample
+	}
+
+	stats := SummaryStatistics{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Avg:   sum / float64(len(sorted)),
+	}
+
+	for _, rank := range percentiles {
+		if rank <= 0 || rank > 100 || math.IsNaN(rank) {
+			return SummaryStatistics{}, fmt.Errorf("%w: %v", ErrInvalidPercentile, rank)
+		}
+
+		index := int(math.Ceil(rank/100*float64(len(sorted)))) - 1
+		if index < 0 {
+			index = 0
+		}
+
+		stats.Percentiles = append(stats.Percentiles, Percentile{
+			Rank:  rank,
+			Value: sorted[index],
+		})
+	}
+
+	return stats, nil
+}
+
+// PerfData returns the summary statistics as a consistent family of
+// performance data metrics using the given label as a prefix (e.g.,
+// rtt_min, rtt_max, rtt_avg, rtt_p95). Percentiles with a fractional rank
+// use an underscore in place of the decimal point (e.g., rtt_p99_9).
+func (s SummaryStatistics) PerfData(label string, uom string) []PerformanceData {
+	metric := func(suffix string, value float64) PerformanceData {
+		return PerformanceData{
+			Label:             label + suffix,
+			Value:             formatRangeBoundary(value),
+			UnitOfMeasurement: uom,
+		}
+	}
+
+	perfData := []PerformanceData{
+		metric(statsMinSuffix, s.Min),
+		metric(statsMaxSuffix, s.Max),
+		metric(statsAvgSuffix, s.Avg),
+	}
+
+	for _, percentile := range s.Percentiles {
+		suffix := statsPercentilePrefix + strings.ReplaceAll(formatRangeBoundary(percentile.Rank), ".", "_")
+		perfData = append(perfData, metric(suffix, percentile.Value))
+	}
+
+	return perfData
+}
+
+// AddSummaryPerfData computes summary statistics for the given samples and
+// adds them to the performance data collection as a family of metrics
+// sharing the given label prefix. See Summarize and SummaryStatistics.PerfData
+// for details.
+func (p *Plugin) AddSummaryPerfData(label string, uom string, samples []float64, percentiles ...float64) error {
+	stats, err := Summarize(samples, percentiles...)
+	if err != nil {
+		return err
+	}
+
+	return p.AddPerfData(false, stats.PerfData(label, uom)...)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestSummarizeComputesStatistics asserts that min, max, average and
+// nearest-rank percentiles are computed as expected.
+func TestSummarizeComputesStatistics(t *testing.T) {
+	t.Parallel()
+
+	samples := []float64{15, 20, 35, 40, 50}
+
+	got, err := nagios.Summarize(samples, 40, 50, 100)
+	if err != nil {
+		t.Fatalf("failed to summarize samples: %v", err)
+	}
+
+	want := nagios.SummaryStatistics{
+		Count: 5,
+		Min:   15,
+		Max:   50,
+		Avg:   32,
+		Percentiles: []nagios.Percentile{
+			{Rank: 40, Value: 20},
+			{Rank: 50, Value: 35},
+			{Rank: 100, Value: 50},
+		},
+	}
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestSummarizeRejectsInvalidInput asserts that empty sample collections
+// and out of range percentiles are rejected.
+func TestSummarizeRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := nagios.Summarize(nil); !errors.Is(err, nagios.ErrNoSamplesProvided) {
+		t.Errorf("want %v, got %v", nagios.ErrNoSamplesProvided, err)
+	}
+
+	for _, rank := range []float64{0, -5, 101} {
+		if _, err := nagios.Summarize([]float64{1}, rank); !errors.Is(err, nagios.ErrInvalidPercentile) {
+			t.Errorf("percentile %v: want %v, got %v", rank, nagios.ErrInvalidPercentile, err)
+		}
+	}
+}
+
+// TestAddSummaryPerfDataEmitsMetricFamily asserts that summary statistics
+// are emitted as a consistently named family of performance data metrics.
+func TestAddSummaryPerfDataEmitsMetricFamily(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetSummary("probes complete")
+
+	err := plugin.AddSummaryPerfData("rtt", "ms", []float64{10, 12.5, 30}, 95, 99.9)
+	if err != nil {
+		t.Fatalf("failed to add summary perfdata: %v", err)
+	}
+
+	plugin.ReturnCheckResults()
+
+	want := "probes complete" +
+		" | 'rtt_avg'=17.5ms;;;; 'rtt_max'=30ms;;;; 'rtt_min'=10ms;;;;" +
+		" 'rtt_p95'=30ms;;;; 'rtt_p99_9'=30ms;;;;" + nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ResultStream consumes sub-check results incrementally as they are sent by
+// collectors, allowing very large jobs (e.g., thousands of devices) to be
+// summarized without holding all intermediate results in memory.
+//
+// A ResultStream is created using StreamResults and must not be copied.
+type ResultStream struct {
+	plugin    *Plugin
+	results   chan NamedCheckResult
+	done      chan struct{}
+	closeOnce sync.Once
+
+	counts map[string]int
+	state  int
+}
+
+// StreamResults returns a ResultStream bound to the plugin, buffering up to
+// the given number of results. Collectors send results using the channel
+// returned by Results; once all collectors are done, Close is called to
+// set the plugin state and one-line summary.
+//
+// Each result is consumed as described by RunChecks except that only
+// non-OK results are added to LongServiceOutput; OK results are only
+// counted. The plugin should not be modified until Close returns.
+func (p *Plugin) StreamResults(buffer int) *ResultStream {
+	if buffer < 0 {
+		buffer = 0
+	}
+
+	s := ResultStream{
+		plugin:  p,
+		results: make(chan NamedCheckResult, buffer),
+		done:    make(chan struct{}),
+		counts:  make(map[string]int),
+		state:   StateOKExitCode,
+	}
+
+	go s.consume()
+
+	return &s
+}
+
+// Results returns the channel used by collectors to send results. The
+// channel is closed by Close and must not be closed by collectors.
+func (s *ResultStream) Results() chan<- NamedCheckResult {
+	return s.results
+}
+
+// Close stops accepting results, waits for all sent results to be consumed
+// and then sets the plugin state to the most severe result state and the
+// one-line summary (ServiceOutput) to the number of results in each state:
+//
+//	CRITICAL: 2 critical, 1 warning, 4997 ok
+//
+// Close should be called once all collectors are done sending results.
+func (s *ResultStream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.results)
+		<-s.done
+
+		s.plugin.ExitStatusCode = s.state
+		s.plugin.ServiceOutput = fmt.Sprintf(
+			"%s: %s",
+			stateLabel(s.state),
+			stateCountsText(s.counts),
+		)
+	})
+}
+
+// consume records results as they are received until the results channel
+// is closed.
+func (s *ResultStream) consume() {
+	defer close(s.done)
+
+	for result := range s.results {
+		s.state = worstExitCode(s.state, result.ExitCode)
+		s.counts[stateLabel(result.ExitCode)]++
+
+		s.plugin.recordCheckResult(result, false)
+	}
+}
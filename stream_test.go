@@ -0,0 +1,74 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestStreamResultsSummarizesIncrementally asserts that results sent by
+// concurrent collectors are consumed and rolled up once the stream is
+// closed.
+func TestStreamResultsSummarizesIncrementally(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	stream := plugin.StreamResults(10)
+
+	var wg sync.WaitGroup
+	for collector := 0; collector < 4; collector++ {
+		wg.Add(1)
+		go func(collector int) {
+			defer wg.Done()
+
+			for i := 0; i < 25; i++ {
+				result := nagios.NamedCheckResult{Name: fmt.Sprintf("dev%d-%d", collector, i)}
+				if collector == 3 && i < 2 {
+					result.ExitCode = nagios.StateCRITICALExitCode
+					result.Summary = "unreachable"
+				}
+				stream.Results() <- result
+			}
+		}(collector)
+	}
+
+	wg.Wait()
+	stream.Close()
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want rollup state %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	if want := "CRITICAL: 2 critical, 98 ok"; plugin.ServiceOutput != want {
+		t.Errorf("want summary %q, got %q", want, plugin.ServiceOutput)
+	}
+
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	if !strings.Contains(got, "* dev3-0: CRITICAL - unreachable") {
+		t.Errorf("want non-OK result detail, got:\n%s", got)
+	}
+
+	if strings.Contains(got, "dev0-0") {
+		t.Errorf("want OK results omitted from details, got:\n%s", got)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxOutputSize is the maximum size in bytes of plugin output
+// enforced in strict mode if not specified by client code. This matches the
+// MAX_PLUGIN_OUTPUT_LENGTH value used by Nagios Core; output beyond this
+// length is silently truncated.
+const DefaultMaxOutputSize int = 8192
+
+var (
+	// ErrServiceOutputStateMismatch indicates that ServiceOutput begins with
+	// a state label which does not match the plugin exit code (e.g.,
+	// "OK: ..." with a CRITICAL exit code).
+	ErrServiceOutputStateMismatch = errors.New("service output state label does not match exit code")
+
+	// ErrOutputTooLarge indicates that plugin output exceeds the maximum
+	// output size.
+	ErrOutputTooLarge = errors.New("plugin output exceeds maximum size")
+
+	// ErrStrictValidationFailed indicates that plugin output failed
+	// validation in strict mode and was replaced with an UNKNOWN result.
+	ErrStrictValidationFailed = errors.New("plugin output failed strict validation")
+)
+
+// WithStrictMode is an Option used to enable strict mode. See also
+// EnableStrictMode.
+func WithStrictMode() Option {
+	return func(p *Plugin) {
+		p.EnableStrictMode()
+	}
+}
+
+// EnableStrictMode converts any output validation failure into an UNKNOWN
+// result which explains the failure. Validation includes invalid
+// performance data, output exceeding the maximum size (see
+// SetMaxOutputSize), a ServiceOutput state label which does not match the
+// exit code and the ServiceOutput checks applied by ValidateServiceOutput
+// and ValidateOutputText. This is intended for teams which prefer loud
+// failures over silently malformed check output.
+//
+// Without strict mode validation failures are logged (see SetLogger) and
+// output is emitted as-is.
+func (p *Plugin) EnableStrictMode() {
+	p.strictMode = true
+}
+
+// SetMaxOutputSize overrides the maximum size in bytes of plugin output
+// enforced in strict mode (and used to truncate output, see
+// SetOutputSpill). DefaultMaxOutputSize is used if size is less than 1.
+func (p *Plugin) SetMaxOutputSize(size int) {
+	p.maxOutputSize = size
+}
+
+// getMaxOutputSize retrieves the maximum output size if set, otherwise
+// returns the default value.
+func (p Plugin) getMaxOutputSize() int {
+	if p.maxOutputSize < 1 {
+		return DefaultMaxOutputSize
+	}
+
+	return p.maxOutputSize
+}
+
+// ValidateStateLabel asserts that a state label at the start of
+// ServiceOutput (e.g., "WARNING: ...", "WARNING disk usage high" or "DISK
+// WARNING - ...") matches the plugin exit code. A ServiceOutput value which
+// does not begin with a state label is permitted. An error wrapping
+// ErrServiceOutputStateMismatch is returned if the labels do not match. See
+// also ParseServiceState.
+func (p Plugin) ValidateStateLabel() error {
+	start, end := findStateLabel(p.ServiceOutput)
+	if start < 0 {
+		return nil
+	}
+
+	if label, want := p.ServiceOutput[start:end], stateLabel(p.ExitStatusCode); label != want {
+		return fmt.Errorf(
+			"%w: label %s, exit code %d (%s)",
+			ErrServiceOutputStateMismatch,
+			label,
+			p.ExitStatusCode,
+			want,
+		)
+	}
+
+	return nil
+}
+
+// validatePerfData removes invalid performance data metrics from the
+// collection and returns the validation failures.
+func (p *Plugin) validatePerfData() []error {
+	var errs []error
+
+	p.warnPerfDataLabels()
+
+	for key, pd := range p.perfData {
+		if err := pd.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("performance data %q: %w", pd.Label, err))
+
+			if p.strictMode {
+				delete(p.perfData, key)
+			}
+		}
+	}
+
+	return errs
+}
+
+// applyStrictMode replaces the plugin result with an UNKNOWN result
+// explaining the given validation failures.
+func (p *Plugin) applyStrictMode(errs []error) {
+	p.ExitStatusCode = StateUNKNOWNExitCode
+	p.ServiceOutput = fmt.Sprintf(
+		"%s: %s (%d problem(s) found); see ERRORS section for details",
+		StateUNKNOWNLabel,
+		ErrStrictValidationFailed,
+		len(errs),
+	)
+
+	p.AddError(errs...)
+
+	p.Logger().Debug("strict mode converted result to UNKNOWN", "problems", len(errs))
+}
+
+// enforceOutputSize replaces the plugin result with a minimal UNKNOWN result
+// if strict mode is enabled and the given output size exceeds the maximum
+// output size. The return value indicates whether the result was replaced
+// and output should be rendered again.
+func (p *Plugin) enforceOutputSize(size int) bool {
+	maxSize := p.getMaxOutputSize()
+
+	if size <= maxSize {
+		return false
+	}
+
+	err := fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrOutputTooLarge, size, maxSize)
+
+	if !p.strictMode {
+		p.Logger().Warn("invalid plugin output", "error", err)
+		return false
+	}
+
+	// Discard the content responsible for the oversize output, retaining
+	// only the explanation.
+	p.LongServiceOutput = ""
+	p.Errors = nil
+	p.perfData = nil
+	p.diagnosticsEnabled = false
+
+	p.applyStrictMode([]error{err})
+
+	return true
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestValidateStateLabelDetectsMismatch asserts that a ServiceOutput state
+// label which does not match the exit code is detected.
+func TestValidateStateLabelDetectsMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		serviceOutput string
+		exitCode      int
+		want          error
+	}{
+		{serviceOutput: "OK: all good", exitCode: nagios.StateOKExitCode},
+		{serviceOutput: "no label here", exitCode: nagios.StateCRITICALExitCode},
+		{serviceOutput: "OKAY then", exitCode: nagios.StateCRITICALExitCode},
+		{serviceOutput: "OK: all good", exitCode: nagios.StateCRITICALExitCode, want: nagios.ErrServiceOutputStateMismatch},
+		{serviceOutput: "WARNING disk usage high", exitCode: nagios.StateOKExitCode, want: nagios.ErrServiceOutputStateMismatch},
+		{serviceOutput: "DISK WARNING - usage high", exitCode: nagios.StateOKExitCode, want: nagios.ErrServiceOutputStateMismatch},
+		{serviceOutput: "WARNING disk usage high", exitCode: nagios.StateWARNINGExitCode},
+	}
+
+	for _, tt := range tests {
+		plugin := nagios.Plugin{
+			ServiceOutput:  tt.serviceOutput,
+			ExitStatusCode: tt.exitCode,
+		}
+
+		if got := plugin.ValidateStateLabel(); !errors.Is(got, tt.want) {
+			t.Errorf("%q (%d): want %v, got %v", tt.serviceOutput, tt.exitCode, tt.want, got)
+		}
+	}
+}
+
+// TestStrictModeConvertsValidationFailuresToUnknown asserts that validation
+// failures in strict mode result in an UNKNOWN result with an explanation.
+func TestStrictModeConvertsValidationFailuresToUnknown(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+	plugin.EnableStrictMode()
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetState(nagios.StateCRITICALExitCode)
+	plugin.SetSummary("OK: everything is fine")
+
+	if err := plugin.AddPerfData(true, nagios.PerformanceData{Label: "missing_value"}); err != nil {
+		t.Fatalf("failed to add perfdata: %v", err)
+	}
+
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateUNKNOWNExitCode, plugin.ExitStatusCode)
+	}
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"UNKNOWN: " + nagios.ErrStrictValidationFailed.Error() + " (2 problem(s) found)",
+		nagios.ErrServiceOutputStateMismatch.Error(),
+		nagios.ErrPerformanceDataMissingValue.Error(),
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output containing %q, got %q", want, got)
+		}
+	}
+
+	if strings.Contains(got, "'missing_value'=") {
+		t.Errorf("want invalid perfdata omitted, got %q", got)
+	}
+}
+
+// TestStrictModeRejectsOversizeOutput asserts that output exceeding the
+// maximum size is replaced in strict mode.
+func TestStrictModeRejectsOversizeOutput(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+	plugin.EnableStrictMode()
+	plugin.SetMaxOutputSize(100)
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetSummary("OK: large output follows")
+	plugin.SetLongServiceOutput(strings.Repeat("x", 200))
+
+	plugin.ReturnCheckResults()
+
+	if plugin.ExitStatusCode != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want exit code %d, got %d", nagios.StateUNKNOWNExitCode, plugin.ExitStatusCode)
+	}
+
+	got := outputBuffer.String()
+
+	if !strings.Contains(got, nagios.ErrOutputTooLarge.Error()) || strings.Contains(got, "xxx") {
+		t.Errorf("want oversize output replaced with explanation, got %q", got)
+	}
+}
+
+// TestStrictModeOversizeOutputPersistsEmittedState asserts that the state
+// recorded in the state store and run history matches the UNKNOWN state
+// emitted for oversize output in strict mode.
+func TestStrictModeOversizeOutputPersistsEmittedState(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stateStore := nagios.NewJSONStateStore(filepath.Join(dir, "state.json"))
+	historyStore := nagios.NewJSONLinesHistoryStore(filepath.Join(dir, "history.jsonl"))
+
+	plugin := nagios.Plugin{}
+	plugin.EnableStrictMode()
+	plugin.SetMaxOutputSize(100)
+	plugin.SetStateStore(stateStore)
+	plugin.SetHistoryStore(historyStore)
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.SetSummary("OK: large output follows")
+	plugin.SetLongServiceOutput(strings.Repeat("x", 200))
+
+	plugin.ReturnCheckResults()
+
+	state, err := stateStore.Load()
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+
+	if state.LastState == nil || *state.LastState != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want persisted state %d, got %v", nagios.StateUNKNOWNExitCode, state.LastState)
+	}
+
+	records, err := historyStore.Records(time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+
+	if len(records) != 1 || records[0].State != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want 1 history record with state %d, got %+v", nagios.StateUNKNOWNExitCode, records)
+	}
+}
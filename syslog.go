@@ -0,0 +1,196 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrSyslogUnsupported indicates that syslog is not supported on the
+	// current platform.
+	ErrSyslogUnsupported = errors.New("syslog is not supported on this platform")
+
+	// ErrSyslogInvalidFacility indicates that an unrecognized syslog
+	// facility name was specified.
+	ErrSyslogInvalidFacility = errors.New("invalid syslog facility")
+
+	// ErrSyslogInvalidSeverity indicates that an unrecognized syslog
+	// severity name was specified.
+	ErrSyslogInvalidSeverity = errors.New("invalid syslog severity")
+)
+
+// Default syslog settings used if not specified by client code.
+const (
+	DefaultSyslogFacility string = "user"
+)
+
+// syslogFacilities maps syslog facility names to their codes (RFC 5424).
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// syslogSeverities maps syslog severity names to their codes (RFC 5424).
+var syslogSeverities = map[string]int{
+	"emerg":   0,
+	"alert":   1,
+	"crit":    2,
+	"err":     3,
+	"warning": 4,
+	"notice":  5,
+	"info":    6,
+	"debug":   7,
+}
+
+// defaultSyslogSeverities maps plugin states to the syslog severity names
+// used if not specified by client code.
+func defaultSyslogSeverities() map[int]string {
+	return map[int]string{
+		StateOKExitCode:        "info",
+		StateWARNINGExitCode:   "warning",
+		StateCRITICALExitCode:  "crit",
+		StateUNKNOWNExitCode:   "err",
+		StateDEPENDENTExitCode: "notice",
+	}
+}
+
+// SyslogOptions configures mirroring of check results to syslog.
+type SyslogOptions struct {
+	// Network and Address specify a remote syslog server (e.g., "udp" and
+	// "syslog.example.com:514"). If empty, the local syslog server is used.
+	Network string
+	Address string
+
+	// Tag is the syslog tag (program name). If empty, the name of the
+	// running executable is used.
+	Tag string
+
+	// Facility is the syslog facility name (e.g., "daemon", "local0"). If
+	// empty, DefaultSyslogFacility is used.
+	Facility string
+
+	// Severities maps plugin states (exit codes) to syslog severity names
+	// (e.g., "info", "warning", "crit"). Entries override the default
+	// mapping of OK to info, WARNING to warning, CRITICAL to crit, UNKNOWN
+	// to err and DEPENDENT to notice.
+	Severities map[int]string
+}
+
+// syslogConfig is the validated form of SyslogOptions.
+type syslogConfig struct {
+	network    string
+	address    string
+	tag        string
+	facility   int
+	severities map[int]int
+}
+
+// EnableSyslog mirrors the final state and one-line summary of the plugin to
+// syslog after check results are emitted, providing an audit trail that is
+// independent of the monitoring system. Failure to write to syslog does not
+// affect plugin output; the failure is logged instead (see SetLogger).
+//
+// An error is returned if the options are invalid or if syslog is not
+// supported on the current platform.
+func (p *Plugin) EnableSyslog(options SyslogOptions) error {
+	cfg, err := newSyslogConfig(options)
+	if err != nil {
+		return err
+	}
+
+	if !syslogSupported {
+		return ErrSyslogUnsupported
+	}
+
+	p.addEmitHook(func(p *Plugin, result Result) {
+		severity, ok := cfg.severities[p.ExitStatusCode]
+		if !ok {
+			severity = cfg.severities[StateUNKNOWNExitCode]
+		}
+
+		if err := writeSyslog(cfg, severity, syslogMessage(result)); err != nil {
+			p.Logger().Warn("failed to write check result to syslog", "error", err)
+		}
+	})
+
+	return nil
+}
+
+// newSyslogConfig validates the given options and applies default values.
+func newSyslogConfig(options SyslogOptions) (syslogConfig, error) {
+	cfg := syslogConfig{
+		network:    options.Network,
+		address:    options.Address,
+		tag:        options.Tag,
+		severities: make(map[int]int),
+	}
+
+	if cfg.tag == "" {
+		cfg.tag = filepath.Base(os.Args[0])
+	}
+
+	facilityName := options.Facility
+	if facilityName == "" {
+		facilityName = DefaultSyslogFacility
+	}
+
+	facility, ok := syslogFacilities[strings.ToLower(facilityName)]
+	if !ok {
+		return syslogConfig{}, fmt.Errorf("%w: %q", ErrSyslogInvalidFacility, facilityName)
+	}
+	cfg.facility = facility
+
+	severityNames := defaultSyslogSeverities()
+	for state, name := range options.Severities {
+		severityNames[state] = name
+	}
+
+	for state, name := range severityNames {
+		severity, ok := syslogSeverities[strings.ToLower(name)]
+		if !ok {
+			return syslogConfig{}, fmt.Errorf("%w: %q", ErrSyslogInvalidSeverity, name)
+		}
+		cfg.severities[state] = severity
+	}
+
+	return cfg, nil
+}
+
+// syslogMessage formats the final plugin state and one-line summary for
+// syslog.
+func syslogMessage(result Result) string {
+	return fmt.Sprintf(
+		"state=%s exit_code=%d summary=%q",
+		result.State,
+		result.ExitCode,
+		strings.TrimSpace(result.ServiceOutput),
+	)
+}
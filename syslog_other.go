@@ -0,0 +1,18 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build windows || plan9
+
+package nagios
+
+// syslogSupported indicates whether syslog is supported on this platform.
+const syslogSupported bool = false
+
+// writeSyslog is not supported on this platform.
+func writeSyslog(syslogConfig, int, string) error {
+	return ErrSyslogUnsupported
+}
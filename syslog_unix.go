@@ -0,0 +1,34 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build !windows && !plan9
+
+package nagios
+
+import (
+	"log/syslog"
+)
+
+// syslogSupported indicates whether syslog is supported on this platform.
+const syslogSupported bool = true
+
+// writeSyslog writes the message to syslog using the given severity.
+func writeSyslog(cfg syslogConfig, severity int, msg string) error {
+	priority := syslog.Priority(cfg.facility<<3 | severity)
+
+	w, err := syslog.Dial(cfg.network, cfg.address, priority, cfg.tag)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write([]byte(msg)); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build !windows && !plan9
+
+package nagios_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestEnableSyslogMirrorsResult asserts that the final state and summary
+// are written to syslog using the facility and severity mapped from the
+// plugin state.
+func TestEnableSyslogMirrorsResult(t *testing.T) {
+	t.Parallel()
+
+	// Unix socket paths are limited in length, so avoid t.TempDir.
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("failed to listen on syslog socket: %v", err)
+	}
+	defer conn.Close()
+
+	plugin := nagios.Plugin{}
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	err = plugin.EnableSyslog(nagios.SyslogOptions{
+		Network:  "unixgram",
+		Address:  socketPath,
+		Tag:      "check_test",
+		Facility: "local0",
+	})
+	if err != nil {
+		t.Fatalf("failed to enable syslog: %v", err)
+	}
+
+	plugin.Critical("disk full")
+	plugin.ReturnCheckResults()
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read syslog message: %v", err)
+	}
+	got := string(buf[:n])
+
+	// local0 (16) * 8 + crit (2) = 130
+	for _, want := range []string{"<130>", "check_test", `state=CRITICAL exit_code=2 summary="CRITICAL: disk full"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want syslog message containing %q, got %q", want, got)
+		}
+	}
+}
+
+// TestOutputFiltersAppliedToAllDestinations asserts that registered output
+// filters are applied to the results passed to sinks, after emit hooks and
+// syslog so that redacted values are not leaked.
+func TestOutputFiltersAppliedToAllDestinations(t *testing.T) {
+	t.Parallel()
+
+	// Unix socket paths are limited in length, so avoid t.TempDir.
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("failed to listen on syslog socket: %v", err)
+	}
+	defer conn.Close()
+
+	const secret = "hunter2"
+
+	var (
+		outputBuffer strings.Builder
+		sinkResult   nagios.Result
+		hookResult   nagios.Result
+		hookOutput   string
+	)
+
+	plugin := nagios.NewPlugin(
+		nagios.WithOutputFilter(nagios.RedactFilter(secret)),
+		nagios.WithSinks(
+			nagios.NewOutputSink(&outputBuffer),
+			nagios.SinkFunc(func(result nagios.Result) error {
+				sinkResult = result
+				return nil
+			}),
+		),
+		nagios.WithAfterEmitHook(func(result nagios.Result, pluginOutput string) {
+			hookResult = result
+			hookOutput = pluginOutput
+		}),
+	)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	err = plugin.EnableSyslog(nagios.SyslogOptions{
+		Network: "unixgram",
+		Address: socketPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to enable syslog: %v", err)
+	}
+
+	plugin.Critical("login failed with password " + secret)
+	plugin.LongServiceOutput = "password: " + secret
+	plugin.AddError(errors.New("auth error for password " + secret))
+	plugin.ReturnCheckResults()
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read syslog message: %v", err)
+	}
+
+	destinations := map[string]string{
+		"output":           outputBuffer.String(),
+		"syslog":           string(buf[:n]),
+		"sink":             fmt.Sprintf("%+v", sinkResult),
+		"after emit hook":  fmt.Sprintf("%+v", hookResult),
+		"hook output text": hookOutput,
+	}
+
+	for name, got := range destinations {
+		if strings.Contains(got, secret) {
+			t.Errorf("want %s without %q, got:\n%s", name, secret, got)
+		}
+
+		if !strings.Contains(got, "REDACTED") {
+			t.Errorf("want %s to contain %q, got:\n%s", name, "REDACTED", got)
+		}
+	}
+}
+
+// TestEnableSyslogRejectsInvalidOptions asserts that invalid facility and
+// severity names are rejected.
+func TestEnableSyslogRejectsInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	err := plugin.EnableSyslog(nagios.SyslogOptions{Facility: "local9"})
+	if !errors.Is(err, nagios.ErrSyslogInvalidFacility) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrSyslogInvalidFacility, err)
+	}
+
+	err = plugin.EnableSyslog(nagios.SyslogOptions{
+		Severities: map[int]string{nagios.StateWARNINGExitCode: "loud"},
+	})
+	if !errors.Is(err, nagios.ErrSyslogInvalidSeverity) {
+		t.Errorf("want error wrapping %v, got %v", nagios.ErrSyslogInvalidSeverity, err)
+	}
+}
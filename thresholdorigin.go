@@ -0,0 +1,99 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Common threshold origins displayed in the THRESHOLDS section. Client code
+// may use any other description (e.g., "--warning flag" or
+// "/etc/nagios/check_disk.conf").
+const (
+	// ThresholdOriginDefault indicates a plugin-declared default threshold.
+	ThresholdOriginDefault string = "default"
+
+	// ThresholdOriginFlag indicates a threshold given via a command-line
+	// flag.
+	ThresholdOriginFlag string = "flag"
+
+	// ThresholdOriginConfig indicates a threshold given via a configuration
+	// file.
+	ThresholdOriginConfig string = "config"
+)
+
+// SetDefaultThresholds sets plugin-declared default warning and critical
+// thresholds (either may be empty). A default threshold does not replace a
+// threshold previously set using OverrideThresholds, so defaults and user
+// overrides may be applied in any order. The effective thresholds are
+// displayed in the THRESHOLDS section along with their origin.
+//
+// An error wrapping ErrInvalidRange is returned and no thresholds are
+// modified if either value cannot be parsed.
+func (p *Plugin) SetDefaultThresholds(warning string, critical string) error {
+	return p.setThresholdsWithOrigin(ThresholdOriginDefault, warning, critical, false)
+}
+
+// OverrideThresholds sets warning and critical thresholds given by the user
+// (e.g., via flags or a configuration file), replacing any plugin-declared
+// defaults (see SetDefaultThresholds). An empty value leaves the
+// corresponding threshold unchanged. The origin (e.g., ThresholdOriginFlag)
+// is displayed alongside the effective thresholds in the THRESHOLDS
+// section.
+//
+// An error wrapping ErrInvalidRange is returned and no thresholds are
+// modified if either value cannot be parsed.
+func (p *Plugin) OverrideThresholds(origin string, warning string, critical string) error {
+	return p.setThresholdsWithOrigin(origin, warning, critical, true)
+}
+
+// setThresholdsWithOrigin sets the given (non-empty) thresholds and records
+// their origin. Unless override is set, thresholds set by an override are
+// left unchanged.
+func (p *Plugin) setThresholdsWithOrigin(origin string, warning string, critical string, override bool) error {
+	for _, threshold := range []struct {
+		name string
+		text string
+	}{
+		{name: "warning", text: warning},
+		{name: "critical", text: critical},
+	} {
+		if strings.TrimSpace(threshold.text) == "" {
+			continue
+		}
+
+		if _, err := ParseRange(threshold.text); err != nil {
+			return fmt.Errorf("invalid %s threshold: %w", threshold.name, err)
+		}
+	}
+
+	if strings.TrimSpace(warning) != "" && (override || !p.warningThresholdOverridden) {
+		_ = p.SetWarningThreshold(warning)
+		p.warningThresholdOrigin = origin
+		p.warningThresholdOverridden = override
+	}
+
+	if strings.TrimSpace(critical) != "" && (override || !p.criticalThresholdOverridden) {
+		_ = p.SetCriticalThreshold(critical)
+		p.criticalThresholdOrigin = origin
+		p.criticalThresholdOverridden = override
+	}
+
+	return nil
+}
+
+// withThresholdOrigin appends the given origin (if any) to the threshold
+// display text.
+func withThresholdOrigin(text string, origin string) string {
+	if text == "" || origin == "" {
+		return text
+	}
+
+	return fmt.Sprintf("%s (%s)", text, origin)
+}
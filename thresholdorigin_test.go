@@ -0,0 +1,62 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestThresholdOverridesReplaceDefaults asserts that user overrides take
+// precedence over default thresholds regardless of the order applied and
+// that the origin of each effective threshold is displayed.
+func TestThresholdOverridesReplaceDefaults(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.OverrideThresholds(nagios.ThresholdOriginFlag, "", "95"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := plugin.SetDefaultThresholds("80", "90"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plugin.CriticalRange == nil || plugin.CriticalRange.End != 95 {
+		t.Errorf("want critical override retained, got %v", plugin.CriticalRange)
+	}
+
+	if err := plugin.OverrideThresholds(nagios.ThresholdOriginConfig, "high", ""); !errors.Is(err, nagios.ErrInvalidRange) {
+		t.Errorf("want invalid range error, got %v", err)
+	}
+
+	plugin.ServiceOutput = "disk usage OK"
+	plugin.LongServiceOutput = "all volumes checked"
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"* CRITICAL: 95 (flag)",
+		"* WARNING: 80 (default)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
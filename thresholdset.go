@@ -0,0 +1,215 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Threshold set format special characters.
+const (
+	thresholdSetLabelSeparator string = "="
+	thresholdSetRangeSeparator string = "/"
+)
+
+var (
+	// ErrInvalidThresholdSet indicates that a threshold set entry could not
+	// be parsed because it does not follow the expected format.
+	ErrInvalidThresholdSet = errors.New("invalid threshold set entry")
+
+	// ErrMissingMetric indicates that a metric referenced by a threshold set
+	// was not found in the collected performance data.
+	ErrMissingMetric = errors.New("metric not found in performance data")
+)
+
+// MetricThresholds is the collection of threshold ranges for a single
+// performance data metric.
+type MetricThresholds struct {
+	// Label is the performance data label of the metric.
+	Label string
+
+	// Warning is the optional warning threshold range.
+	Warning *Range
+
+	// Critical is the optional critical threshold range.
+	Critical *Range
+
+	// warningText and criticalText are the threshold ranges as given when
+	// they include units, which are normalized against the unit of
+	// measurement of the metric when evaluated.
+	warningText  string
+	criticalText string
+}
+
+// ThresholdSet is a collection of per-metric threshold ranges evaluated
+// against collected performance data in one call (see
+// EvaluateThresholdSet), matching how multi-metric plugins such as
+// check_load behave.
+type ThresholdSet []MetricThresholds
+
+// ParseThresholdSet parses the given threshold set entries. Each entry has
+// the form "label=warning/critical" where either range may be omitted
+// (e.g., "load1=5/10", "load15=3:/6:" or "swap=/90"). Range boundaries may
+// include a unit (e.g., "latency=500ms/2s"); see NormalizeRange. An error
+// wrapping ErrInvalidThresholdSet, ErrInvalidRange or ErrIncompatibleUnit
+// is returned if an entry cannot be parsed.
+func ParseThresholdSet(entries ...string) (ThresholdSet, error) {
+	set := make(ThresholdSet, 0, len(entries))
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		i := strings.LastIndex(entry, thresholdSetLabelSeparator)
+		if i <= 0 {
+			return nil, fmt.Errorf("%w: %q: missing label", ErrInvalidThresholdSet, entry)
+		}
+
+		label := strings.TrimSpace(entry[:i])
+		ranges := strings.Split(entry[i+1:], thresholdSetRangeSeparator)
+
+		if len(ranges) != 2 {
+			return nil, fmt.Errorf("%w: %q: expected warning/critical ranges", ErrInvalidThresholdSet, entry)
+		}
+
+		mt := MetricThresholds{Label: label}
+
+		for _, field := range []struct {
+			name  string
+			text  string
+			value **Range
+			units *string
+		}{
+			{name: "warning", text: ranges[0], value: &mt.Warning, units: &mt.warningText},
+			{name: "critical", text: ranges[1], value: &mt.Critical, units: &mt.criticalText},
+		} {
+			if strings.TrimSpace(field.text) == "" {
+				continue
+			}
+
+			// Ranges with units are validated using their own unit until
+			// the unit of the metric is known.
+			unit := rangeUnit(field.text)
+
+			r, err := NormalizeRange(field.text, unit)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s threshold for %s: %w", field.name, label, err)
+			}
+			*field.value = &r
+
+			if unit != "" {
+				*field.units = strings.TrimSpace(field.text)
+			}
+		}
+
+		set = append(set, mt)
+	}
+
+	return set, nil
+}
+
+// EvaluateThresholdSet evaluates the collected performance data against
+// the given threshold set and returns the most severe resulting exit status
+// code. The critical range of each metric is evaluated before the warning
+// range.
+//
+// Each triggered threshold is described in LongServiceOutput and recorded
+// as a ThresholdBreach (see BreachSummary). The threshold ranges are
+// applied to the Warn and Crit fields of the matching performance data
+// (unless already set). A metric missing from the
+// performance data (or with a non-numeric value) is recorded as an error
+// wrapping ErrMissingMetric and results in an UNKNOWN state.
+func (p *Plugin) EvaluateThresholdSet(set ThresholdSet) int {
+	states := make([]int, 0, len(set))
+
+	for _, mt := range set {
+		key := strings.ToLower(mt.Label)
+
+		pd, ok := p.perfData[key]
+		value, numeric := parsePerfDataValue(pd.Value)
+		if !ok || !numeric {
+			p.AddError(fmt.Errorf("%w: %s", ErrMissingMetric, mt.Label))
+			states = append(states, StateUNKNOWNExitCode)
+			continue
+		}
+
+		mt, err := mt.normalize(metricUnit(pd))
+		if err != nil {
+			p.AddError(fmt.Errorf("invalid thresholds for %s: %w", mt.Label, err))
+			states = append(states, StateUNKNOWNExitCode)
+			continue
+		}
+
+		if mt.Warning != nil && pd.Warn == "" {
+			pd.Warn = mt.Warning.String()
+		}
+
+		if mt.Critical != nil && pd.Crit == "" {
+			pd.Crit = mt.Critical.String()
+		}
+
+		p.perfData[key] = pd
+
+		var triggered *Range
+		var state int
+
+		switch {
+		case mt.Critical != nil && mt.Critical.ShouldAlert(value):
+			triggered, state = mt.Critical, StateCRITICALExitCode
+		case mt.Warning != nil && mt.Warning.ShouldAlert(value):
+			triggered, state = mt.Warning, StateWARNINGExitCode
+		default:
+			continue
+		}
+
+		states = append(states, state)
+
+		p.thresholdBreaches = append(p.thresholdBreaches, ThresholdBreach{
+			Label:             pd.Label,
+			Value:             value,
+			UnitOfMeasurement: metricUnit(pd),
+			State:             state,
+			Range:             *triggered,
+		})
+
+		p.WithDetail(fmt.Sprintf(
+			"%s: %s triggers %s threshold %s",
+			pd.Label,
+			formatRangeBoundary(value),
+			strings.ToLower(stateLabel(state)),
+			triggered,
+		))
+	}
+
+	return worstExitCode(states...)
+}
+
+// normalize returns the thresholds with any ranges given with units
+// converted to the given unit of measurement of the metric.
+func (mt MetricThresholds) normalize(uom string) (MetricThresholds, error) {
+	for _, field := range []struct {
+		text  string
+		value **Range
+	}{
+		{text: mt.warningText, value: &mt.Warning},
+		{text: mt.criticalText, value: &mt.Critical},
+	} {
+		if field.text == "" {
+			continue
+		}
+
+		r, err := NormalizeRange(field.text, uom)
+		if err != nil {
+			return mt, err
+		}
+		*field.value = &r
+	}
+
+	return mt, nil
+}
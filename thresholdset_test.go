@@ -0,0 +1,92 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestParseThresholdSet asserts that threshold set entries are parsed and
+// that invalid entries are rejected.
+func TestParseThresholdSet(t *testing.T) {
+	t.Parallel()
+
+	set, err := nagios.ParseThresholdSet("load1=5/10", "load15=3:/6:", "swap=/90")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(set) != 3 || set[2].Warning != nil || set[2].Critical == nil || set[1].Warning.String() != "3:" {
+		t.Errorf("unexpected threshold set: %+v", set)
+	}
+
+	for _, entry := range []string{"5/10", "load1=5", "load1=high/10"} {
+		if _, err := nagios.ParseThresholdSet(entry); !errors.Is(err, nagios.ErrInvalidThresholdSet) && !errors.Is(err, nagios.ErrInvalidRange) {
+			t.Errorf("want error for %q, got %v", entry, err)
+		}
+	}
+}
+
+// TestEvaluateThresholdSet asserts that each metric is evaluated against
+// its own thresholds and that the most severe state is returned.
+func TestEvaluateThresholdSet(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "load1", Value: "12.5"},
+		nagios.PerformanceData{Label: "load5", Value: "4"},
+		nagios.PerformanceData{Label: "load15", Value: "2"},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	set, err := nagios.ParseThresholdSet("load1=15/20", "load5=3/6", "load15=3/6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := plugin.EvaluateThresholdSet(set); got != nagios.StateWARNINGExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateWARNINGExitCode, got)
+	}
+
+	missing, _ := nagios.ParseThresholdSet("load30=1/2")
+	if got := plugin.EvaluateThresholdSet(missing); got != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want state %d for missing metric, got %d", nagios.StateUNKNOWNExitCode, got)
+	}
+
+	if len(plugin.Errors) != 1 || !errors.Is(plugin.Errors[0], nagios.ErrMissingMetric) {
+		t.Errorf("want missing metric error, got %v", plugin.Errors)
+	}
+
+	plugin.ServiceOutput = "load average: 12.50, 4.00, 2.00"
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"load5: 4 triggers warning threshold 3",
+		"'load1'=12.5;15;20;;",
+		"'load5'=4;3;6;;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"time"
+)
+
+// partialResultsLabel prefixes the counts of the one-line summary of
+// partial results.
+const partialResultsLabel string = "partial results"
+
+// WithTimeout is an Option used to set the overall plugin timeout. See also
+// SetTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Plugin) {
+		p.SetTimeout(timeout)
+	}
+}
+
+// SetTimeout sets the overall plugin timeout, measured from the start of the
+// plugin (construction via NewPlugin, or this call for Plugin values
+// constructed manually). The timeout should be shorter than the service
+// check timeout used by Nagios so that results can be emitted before the
+// plugin is killed. RunChecks does not run sub-checks beyond the plugin
+// deadline; see also EnablePartialResults.
+func (p *Plugin) SetTimeout(timeout time.Duration) {
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+
+	p.deadline = p.start.Add(timeout)
+}
+
+// Deadline returns the time at which the overall plugin timeout expires.
+// The ok value is false if no plugin timeout is set.
+func (p Plugin) Deadline() (deadline time.Time, ok bool) {
+	return p.deadline, !p.deadline.IsZero()
+}
+
+// TimeRemaining returns the time remaining before the overall plugin timeout
+// expires (zero once expired), allowing collectors to adapt (e.g., skip
+// optional expensive steps or reduce sample counts) as the deadline
+// approaches. The ok value is false if no plugin timeout is set.
+func (p Plugin) TimeRemaining() (remaining time.Duration, ok bool) {
+	deadline, ok := p.Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	if remaining = time.Until(deadline); remaining < 0 {
+		remaining = 0
+	}
+
+	return remaining, true
+}
+
+// WithPartialResults is an Option used to report the results gathered so
+// far when the overall timeout expires. See also EnablePartialResults.
+func WithPartialResults(minState int) Option {
+	return func(p *Plugin) {
+		p.EnablePartialResults(minState)
+	}
+}
+
+// EnablePartialResults causes RunChecks to report the results gathered so
+// far if the overall (or plugin) timeout expires before all sub-checks
+// complete, instead of reporting each unfinished sub-check as timed out.
+// The plugin state is set to the most severe state of the completed
+// sub-checks, but no less severe than minState (e.g., WARNING), and the
+// one-line summary notes the partial results:
+//
+//	WARNING: partial results: 37/50 checks completed, 37 ok
+//
+// Unfinished sub-checks are still listed in LongServiceOutput.
+func (p *Plugin) EnablePartialResults(minState int) {
+	p.partialResultsState = &minState
+}
+
+// recordPartialCheckResults records the given sub-check results as
+// described by EnablePartialResults. Sub-checks which are not done are
+// excluded from the plugin state and summary.
+func (p *Plugin) recordPartialCheckResults(results []NamedCheckResult, done []bool) {
+	states := []int{*p.partialResultsState}
+	counts := make(map[string]int)
+
+	var completed int
+	for i, result := range results {
+		if done[i] {
+			completed++
+			states = append(states, result.ExitCode)
+			counts[stateLabel(result.ExitCode)]++
+		}
+	}
+
+	for _, result := range sortedBySeverity(results) {
+		p.recordCheckResult(result, true)
+	}
+
+	state := worstExitCode(states...)
+
+	p.ExitStatusCode = state
+	p.ServiceOutput = fmt.Sprintf(
+		"%s: %s: %d/%d %s completed, %s",
+		stateLabel(state),
+		p.message(MessagePartialResults, partialResultsLabel),
+		completed,
+		len(results),
+		checksNoun,
+		stateCountsText(counts),
+	)
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestRunChecksReportsPartialResults asserts that sub-checks which do not
+// complete before the plugin timeout are excluded from the rollup when
+// partial results are enabled.
+func TestRunChecksReportsPartialResults(t *testing.T) {
+	t.Parallel()
+
+	stuck := make(chan struct{})
+	t.Cleanup(func() { close(stuck) })
+
+	checks := []nagios.NamedCheck{
+		{Name: "nas01", Check: func(context.Context) nagios.CheckResult {
+			return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+		}},
+		{Name: "nas02", Check: func(context.Context) nagios.CheckResult {
+			return nagios.CheckResult{ExitCode: nagios.StateCRITICALExitCode, Summary: "volume degraded"}
+		}},
+		{Name: "nas03", Check: func(context.Context) nagios.CheckResult {
+			<-stuck
+			return nagios.CheckResult{ExitCode: nagios.StateOKExitCode}
+		}},
+	}
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+	plugin.SetTimeout(50 * time.Millisecond)
+	plugin.EnablePartialResults(nagios.StateWARNINGExitCode)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if _, ok := plugin.Deadline(); !ok {
+		t.Fatal("want plugin deadline set")
+	}
+
+	plugin.RunChecks(checks, 0, 0)
+
+	if plugin.ExitStatusCode != nagios.StateCRITICALExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateCRITICALExitCode, plugin.ExitStatusCode)
+	}
+
+	want := "CRITICAL: partial results: 2/3 checks completed, 1 critical, 1 ok"
+	if plugin.ServiceOutput != want {
+		t.Errorf("want summary %q, got %q", want, plugin.ServiceOutput)
+	}
+
+	plugin.ReturnCheckResults()
+
+	if got := outputBuffer.String(); !strings.Contains(got, "* nas03: UNKNOWN - check did not complete before timeout") {
+		t.Errorf("want unfinished sub-check listed, got:\n%s", got)
+	}
+}
+
+// TestTimeRemaining asserts that the time remaining is tied to the plugin
+// timeout.
+func TestTimeRemaining(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+
+	if _, ok := plugin.TimeRemaining(); ok {
+		t.Error("want no time remaining reported without a plugin timeout")
+	}
+
+	plugin.SetTimeout(time.Minute)
+
+	remaining, ok := plugin.TimeRemaining()
+	if !ok || remaining <= 50*time.Second || remaining > time.Minute {
+		t.Errorf("want roughly 1m remaining, got %s (%t)", remaining, ok)
+	}
+
+	expired := nagios.Plugin{}
+	expired.SetTimeout(-time.Second)
+
+	if remaining, _ := expired.TimeRemaining(); remaining != 0 {
+		t.Errorf("want no time remaining once expired, got %s", remaining)
+	}
+}
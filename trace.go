@@ -0,0 +1,141 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Default settings for the output trace file.
+const (
+	// DefaultOutputTraceMaxSize is the size in bytes at which the output
+	// trace file is rotated if not specified by client code.
+	DefaultOutputTraceMaxSize int64 = 1024 * 1024
+
+	// DefaultOutputTraceMaxBackups is the number of rotated output trace
+	// files retained if not specified by client code.
+	DefaultOutputTraceMaxBackups int = 3
+)
+
+// outputTrace holds the settings for recording emitted plugin output.
+type outputTrace struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	// failuresOnly indicates that output is only recorded if emitting it
+	// fails. See also SetOutputFailureTrace.
+	failuresOnly bool
+}
+
+// WithOutputTrace is an Option used to record every final emission of
+// plugin output to a rotating trace file. See also SetOutputTrace.
+func WithOutputTrace(path string, maxSize int64, maxBackups int) Option {
+	return func(p *Plugin) {
+		p.SetOutputTrace(path, maxSize, maxBackups)
+	}
+}
+
+// SetOutputTrace enables recording every final emission of plugin output
+// to the trace file at the given path. Each record consists of a header
+// line with the timestamp, exit code and output length followed by the
+// byte-exact output. This is useful when debugging cases where the web UI
+// shows something different from what the plugin emitted.
+//
+// The trace file is rotated once it would exceed maxSize bytes, retaining up
+// to maxBackups older files (path.1, path.2, ...). Default values are used
+// if maxSize or maxBackups are less than 1. Failure to write the trace file
+// does not affect plugin output; the failure is logged instead (see
+// SetLogger).
+//
+// If sinks are configured (see SetSinks), output is only recorded if it
+// is written to an output sink (see NewOutputSink).
+func (p *Plugin) SetOutputTrace(path string, maxSize int64, maxBackups int) {
+	if maxSize < 1 {
+		maxSize = DefaultOutputTraceMaxSize
+	}
+
+	if maxBackups < 1 {
+		maxBackups = DefaultOutputTraceMaxBackups
+	}
+
+	p.outputTrace = &outputTrace{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+}
+
+// traceOutput records the given plugin output to the output trace file if
+// enabled, noting the error from emitting the output (if any).
+func (p *Plugin) traceOutput(pluginOutput string, emitErr error) {
+	if p.outputTrace == nil || (p.outputTrace.failuresOnly && emitErr == nil) {
+		return
+	}
+
+	var emitError string
+	if emitErr != nil {
+		emitError = fmt.Sprintf(" emit_error=%q", emitErr.Error())
+	}
+
+	record := fmt.Sprintf(
+		"=== %s exit_code=%d bytes=%d%s ===\n%s\n",
+		time.Now().Format(time.RFC3339Nano),
+		p.ExitStatusCode,
+		len(pluginOutput),
+		emitError,
+		pluginOutput,
+	)
+
+	if err := p.outputTrace.write(record); err != nil {
+		p.Logger().Warn("failed to write output trace", "path", p.outputTrace.path, "error", err)
+	}
+}
+
+// write appends the record to the trace file, rotating the file first if
+// needed.
+func (ot *outputTrace) write(record string) error {
+	if info, err := os.Stat(ot.path); err == nil && info.Size()+int64(len(record)) > ot.maxSize {
+		if err := ot.rotate(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(ot.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open output trace file: %w", err)
+	}
+
+	if _, err := f.WriteString(record); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write output trace file: %w", err)
+	}
+
+	return f.Close()
+}
+
+// rotate shifts existing trace files (path -> path.1 -> path.2 ...),
+// discarding the oldest.
+func (ot *outputTrace) rotate() error {
+	for i := ot.maxBackups - 1; i >= 1; i-- {
+		older := fmt.Sprintf("%s.%d", ot.path, i)
+		if _, err := os.Stat(older); err == nil {
+			if err := os.Rename(older, fmt.Sprintf("%s.%d", ot.path, i+1)); err != nil {
+				return fmt.Errorf("failed to rotate output trace file: %w", err)
+			}
+		}
+	}
+
+	if err := os.Rename(ot.path, ot.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate output trace file: %w", err)
+	}
+
+	return nil
+}
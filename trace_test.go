@@ -0,0 +1,71 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestOutputTraceRecordsAndRotates asserts that emitted output is recorded
+// byte-for-byte to the trace file and that the file is rotated once it
+// exceeds the maximum size.
+func TestOutputTraceRecordsAndRotates(t *testing.T) {
+	t.Parallel()
+
+	tracePath := filepath.Join(t.TempDir(), "output.trace")
+
+	run := func(summary string) string {
+		var outputBuffer strings.Builder
+
+		plugin := nagios.Plugin{}
+		plugin.SetOutputTarget(&outputBuffer)
+		plugin.SetOutputTrace(tracePath, 150, 2)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.Critical(summary).WithDetail("line two")
+		plugin.ReturnCheckResults()
+
+		return outputBuffer.String()
+	}
+
+	emitted := run("first run")
+
+	got, err := os.ReadFile(tracePath)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+
+	if !strings.Contains(string(got), "exit_code=2") || !strings.Contains(string(got), "\n"+emitted+"\n") {
+		t.Errorf("want trace record with exit code and exact output %q, got %q", emitted, got)
+	}
+
+	run("second run")
+	run("third run")
+
+	for _, name := range []string{"output.trace", "output.trace.1", "output.trace.2"} {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(tracePath), name)); err != nil {
+			t.Errorf("want trace file %s: %v", name, err)
+		}
+	}
+
+	latest, err := os.ReadFile(tracePath)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+
+	if !strings.Contains(string(latest), "third run") || strings.Contains(string(latest), "first run") {
+		t.Errorf("want only the latest record after rotation, got %q", latest)
+	}
+}
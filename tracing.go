@@ -0,0 +1,148 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+// Span attribute keys recorded on the root span for a plugin run. The
+// state, exit code and summary are also recorded on the child span of each
+// sub-check run by RunChecks, and the error text on the child span of each
+// failed sub-check or Group task.
+const (
+	SpanAttrState          string = "nagios.state"
+	SpanAttrExitCode       string = "nagios.exit_code"
+	SpanAttrSummary        string = "nagios.summary"
+	SpanAttrErrors         string = "nagios.errors"
+	SpanAttrError          string = "nagios.error"
+	SpanAttrPerfDataPrefix string = "nagios.perfdata."
+)
+
+// rootSpanName is the name of the span covering the full plugin run.
+const rootSpanName string = "check"
+
+// Span is a unit of work within a plugin run. This is a minimal interface
+// which is intended to be implemented by a small adapter around a tracing
+// library such as OpenTelemetry; this keeps the library free of tracing
+// dependencies while allowing client code to export spans via OTLP.
+type Span interface {
+	// SetAttribute records a key/value attribute on the span.
+	SetAttribute(key string, value interface{})
+
+	// End marks the span as complete.
+	End()
+}
+
+// Tracer starts spans. The parent span is nil when starting the root span
+// for a plugin run. Adapters are responsible for propagating the parent
+// (e.g., via a context.Context stored in their Span implementation).
+// Implementations must be safe for concurrent use; child spans are started
+// concurrently for sub-checks and Group tasks.
+//
+// This library does not export spans itself. Exporting spans (e.g., via
+// OpenTelemetry and OTLP) requires an adapter provided by client code so
+// that the library remains free of tracing dependencies.
+//
+// An OpenTelemetry adapter might look like:
+//
+//	type otelTracer struct{ tracer trace.Tracer }
+//
+//	type otelSpan struct {
+//		ctx  context.Context
+//		span trace.Span
+//	}
+//
+//	func (t otelTracer) StartSpan(parent nagios.Span, name string) nagios.Span {
+//		ctx := context.Background()
+//		if p, ok := parent.(otelSpan); ok {
+//			ctx = p.ctx
+//		}
+//		ctx, span := t.tracer.Start(ctx, name)
+//		return otelSpan{ctx: ctx, span: span}
+//	}
+type Tracer interface {
+	StartSpan(parent Span, name string) Span
+}
+
+// noopSpan is used when tracing is not enabled.
+type noopSpan struct{}
+
+// SetAttribute discards the attribute.
+func (noopSpan) SetAttribute(string, interface{}) {}
+
+// End does nothing.
+func (noopSpan) End() {}
+
+// WithTracer is an Option used to enable tracing of the plugin run. See
+// also SetTracer.
+func WithTracer(tracer Tracer) Option {
+	return func(p *Plugin) {
+		p.SetTracer(tracer)
+	}
+}
+
+// SetTracer enables tracing of the plugin run. A root span is started
+// immediately and ended by ReturnCheckResults after recording the final
+// state, one-line summary, number of errors and performance data values as
+// attributes. A child span is recorded for each sub-check run by RunChecks
+// and each task run by a Group (see NewGroup); child spans for other
+// collectors may be started using StartSpan.
+func (p *Plugin) SetTracer(tracer Tracer) {
+	p.tracer = tracer
+	p.rootSpan = nil
+
+	if tracer != nil {
+		p.rootSpan = tracer.StartSpan(nil, rootSpanName)
+	}
+}
+
+// StartSpan starts a child span of the root span for the plugin run (e.g.,
+// for a sub-check or collector). The caller is responsible for ending the
+// span. If tracing is not enabled a no-op span is returned.
+//
+//	span := plugin.StartSpan("query database")
+//	defer span.End()
+func (p *Plugin) StartSpan(name string) Span {
+	if p.tracer == nil {
+		return noopSpan{}
+	}
+
+	return p.tracer.StartSpan(p.rootSpan, name)
+}
+
+// endRootSpan records the plugin results on the root span using the
+// summary from the given (filtered) result and ends it.
+func (p *Plugin) endRootSpan(result Result) {
+	if p.rootSpan == nil {
+		return
+	}
+
+	p.rootSpan.SetAttribute(SpanAttrState, stateLabel(p.ExitStatusCode))
+	p.rootSpan.SetAttribute(SpanAttrExitCode, p.ExitStatusCode)
+	p.rootSpan.SetAttribute(SpanAttrSummary, result.ServiceOutput)
+	p.rootSpan.SetAttribute(SpanAttrErrors, len(p.Errors))
+
+	for _, pd := range p.getSortedPerfData() {
+		p.rootSpan.SetAttribute(SpanAttrPerfDataPrefix+pd.Label, pd.Value+pd.UnitOfMeasurement)
+	}
+
+	p.rootSpan.End()
+	p.rootSpan = nil
+}
+
+// endCheckSpan records the result of a sub-check on the given span and ends
+// it. Output filters (see AddOutputFilter) are applied to the summary and
+// error text.
+func (p *Plugin) endCheckSpan(span Span, result NamedCheckResult) {
+	span.SetAttribute(SpanAttrState, stateLabel(result.ExitCode))
+	span.SetAttribute(SpanAttrExitCode, result.ExitCode)
+	span.SetAttribute(SpanAttrSummary, p.filterText(result.Summary))
+
+	if result.Err != nil {
+		span.SetAttribute(SpanAttrError, p.filterText(result.Err.Error()))
+	}
+
+	span.End()
+}
@@ -0,0 +1,177 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// recordedSpan is a span recorded by recordingTracer.
+type recordedSpan struct {
+	Name       string
+	Parent     string
+	Attributes map[string]interface{}
+	Ended      bool
+}
+
+// SetAttribute records the attribute.
+func (rs *recordedSpan) SetAttribute(key string, value interface{}) {
+	rs.Attributes[key] = value
+}
+
+// End marks the span as ended.
+func (rs *recordedSpan) End() {
+	rs.Ended = true
+}
+
+// recordingTracer records all started spans.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+// StartSpan records and returns a new span.
+func (rt *recordingTracer) StartSpan(parent nagios.Span, name string) nagios.Span {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	span := &recordedSpan{Name: name, Attributes: map[string]interface{}{}}
+	if p, ok := parent.(*recordedSpan); ok {
+		span.Parent = p.Name
+	}
+
+	rt.spans = append(rt.spans, span)
+
+	return span
+}
+
+// TestTracerRecordsRootAndChildSpans asserts that a root span records the
+// final plugin results and that child spans are parented to it.
+func TestTracerRecordsRootAndChildSpans(t *testing.T) {
+	t.Parallel()
+
+	tracer := &recordingTracer{}
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(nagios.WithTracer(tracer), nagios.WithOutputTarget(&outputBuffer))
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	span := plugin.StartSpan("query database")
+	span.End()
+
+	plugin.Warning("slow query").
+		WithPerfData(nagios.PerformanceData{Label: "query_time", Value: "1.5", UnitOfMeasurement: "s"})
+	plugin.ReturnCheckResults()
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("want 2 spans, got %d", len(tracer.spans))
+	}
+
+	root, child := tracer.spans[0], tracer.spans[1]
+
+	if !root.Ended || !child.Ended || child.Parent != root.Name {
+		t.Errorf("unexpected spans: root=%+v, child=%+v", root, child)
+	}
+
+	want := map[string]interface{}{
+		nagios.SpanAttrState:                         nagios.StateWARNINGLabel,
+		nagios.SpanAttrExitCode:                      nagios.StateWARNINGExitCode,
+		nagios.SpanAttrSummary:                       "WARNING: slow query",
+		nagios.SpanAttrErrors:                        0,
+		nagios.SpanAttrPerfDataPrefix + "query_time": "1.5s",
+	}
+
+	// The default time metric value varies between runs.
+	delete(root.Attributes, nagios.SpanAttrPerfDataPrefix+"time")
+
+	if d := cmp.Diff(want, root.Attributes); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestTracerRecordsSubCheckAndGroupSpans asserts that a child span is
+// recorded for each sub-check run by RunChecks and each Group task.
+func TestTracerRecordsSubCheckAndGroupSpans(t *testing.T) {
+	t.Parallel()
+
+	tracer := &recordingTracer{}
+
+	var outputBuffer strings.Builder
+	plugin := nagios.NewPlugin(nagios.WithTracer(tracer), nagios.WithOutputTarget(&outputBuffer))
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.RunChecks([]nagios.NamedCheck{
+		{
+			Name: "web01",
+			Check: func(context.Context) nagios.CheckResult {
+				return nagios.CheckResult{ExitCode: nagios.StateOKExitCode, Summary: "up"}
+			},
+		},
+		{
+			Name: "web02",
+			Check: func(context.Context) nagios.CheckResult {
+				return nagios.CheckResult{ExitCode: nagios.StateCRITICALExitCode, Summary: "down", Err: errors.New("refused")}
+			},
+		},
+	}, 0, 0)
+
+	group := plugin.NewGroup()
+	group.Go("collect", func() error { return errors.New("timeout") })
+	_ = group.Wait()
+
+	plugin.ReturnCheckResults()
+
+	got := make(map[string]*recordedSpan)
+	for _, span := range tracer.spans {
+		got[span.Name] = span
+	}
+
+	want := map[string]map[string]interface{}{
+		"web01": {
+			nagios.SpanAttrState:    nagios.StateOKLabel,
+			nagios.SpanAttrExitCode: nagios.StateOKExitCode,
+			nagios.SpanAttrSummary:  "up",
+		},
+		"web02": {
+			nagios.SpanAttrState:    nagios.StateCRITICALLabel,
+			nagios.SpanAttrExitCode: nagios.StateCRITICALExitCode,
+			nagios.SpanAttrSummary:  "down",
+			nagios.SpanAttrError:    "refused",
+		},
+		"collect": {
+			nagios.SpanAttrError: "timeout",
+		},
+	}
+
+	for name, attributes := range want {
+		span, ok := got[name]
+		if !ok {
+			t.Errorf("want span %q, got none", name)
+			continue
+		}
+
+		if !span.Ended || span.Parent != tracer.spans[0].Name {
+			t.Errorf("unexpected span: %+v", span)
+		}
+
+		if d := cmp.Diff(attributes, span.Attributes); d != "" {
+			t.Errorf("%s: (-want, +got)\n:%s", name, d)
+		}
+	}
+}
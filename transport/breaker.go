@@ -0,0 +1,291 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Default circuit breaker settings used if not specified by client code.
+const (
+	DefaultBreakerFailureThreshold int           = 3
+	DefaultBreakerCooldown         time.Duration = 5 * time.Minute
+)
+
+// ErrCircuitOpen indicates that a submission attempt was suppressed because
+// the circuit breaker is open after repeated failures. Client code will
+// likely wish to record this error in plugin output so that the suppression
+// is visible.
+var ErrCircuitOpen = errors.New("circuit breaker open; submission suppressed")
+
+// breakerState is the persisted state of a CircuitBreaker.
+type breakerState struct {
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	OpenedAt            time.Time `json:"opened_at,omitempty"`
+	Suppressed          int       `json:"suppressed"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// CircuitBreaker wraps submission attempts to a remote endpoint. After a
+// number of consecutive failures the breaker "trips" (opens) and further
+// attempts fail immediately with ErrCircuitOpen until a cooldown period has
+// elapsed. After the cooldown a single trial attempt is permitted; success
+// closes the breaker, failure reopens it for another cooldown period.
+//
+// Because plugins are short-lived processes, breaker state may be persisted
+// to a file so that a slow or unreachable endpoint is not retried on every
+// scheduled run, preventing plugins from exceeding their check timeout.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	// statePath is the optional path to the file used to persist breaker
+	// state between runs. If empty, state is held in memory only.
+	statePath string
+
+	// failureThreshold is the number of consecutive failures required to
+	// trip the breaker.
+	failureThreshold int
+
+	// cooldown is the amount of time the breaker remains open before a
+	// trial attempt is permitted.
+	cooldown time.Duration
+
+	// state is the in-memory copy of the breaker state.
+	state breakerState
+
+	// now returns the current time. Overridden by tests.
+	now func() time.Time
+}
+
+// BreakerOption is a functional option used to configure a CircuitBreaker
+// value when constructed via NewCircuitBreaker.
+type BreakerOption func(*CircuitBreaker)
+
+// WithStateFile is a BreakerOption used to persist breaker state to the
+// specified file so that it is retained between plugin runs. If not
+// specified, breaker state is held in memory only.
+func WithStateFile(path string) BreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.statePath = path
+	}
+}
+
+// WithFailureThreshold is a BreakerOption used to specify the number of
+// consecutive failures required to trip the breaker. Values less than or
+// equal to zero are ignored.
+func WithFailureThreshold(failures int) BreakerOption {
+	return func(cb *CircuitBreaker) {
+		if failures > 0 {
+			cb.failureThreshold = failures
+		}
+	}
+}
+
+// WithCooldown is a BreakerOption used to specify how long the breaker
+// remains open before a trial attempt is permitted. Values less than or
+// equal to zero are ignored.
+func WithCooldown(cooldown time.Duration) BreakerOption {
+	return func(cb *CircuitBreaker) {
+		if cooldown > 0 {
+			cb.cooldown = cooldown
+		}
+	}
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker. Default settings are used
+// unless overridden by the given options. If a state file is specified,
+// previously persisted breaker state is loaded from it.
+func NewCircuitBreaker(options ...BreakerOption) (*CircuitBreaker, error) {
+	cb := CircuitBreaker{
+		failureThreshold: DefaultBreakerFailureThreshold,
+		cooldown:         DefaultBreakerCooldown,
+		now:              time.Now,
+	}
+
+	for _, option := range options {
+		option(&cb)
+	}
+
+	if err := cb.load(); err != nil {
+		return nil, err
+	}
+
+	return &cb, nil
+}
+
+// Wrap returns a SendFunc which delivers payloads using the given send
+// function, subject to the state of the circuit breaker. The returned
+// function is suitable for use with Spool.Forward so that payloads are
+// queued without contacting the endpoint while the breaker is open.
+func (cb *CircuitBreaker) Wrap(send SendFunc) SendFunc {
+	return func(payload []byte) error {
+		return cb.Call(func() error { return send(payload) })
+	}
+}
+
+// Call invokes fn unless the breaker is open, in which case an error
+// wrapping ErrCircuitOpen is returned without invoking fn. The outcome of fn
+// is recorded and persisted.
+func (cb *CircuitBreaker) Call(fn func() error) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+
+	if cb.isOpen(now) {
+		cb.state.Suppressed++
+		if err := cb.save(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf(
+			"%w: %d consecutive failures (last: %s); retry after %s",
+			ErrCircuitOpen,
+			cb.state.ConsecutiveFailures,
+			cb.state.LastError,
+			cb.state.OpenedAt.Add(cb.cooldown).Format(time.RFC3339),
+		)
+	}
+
+	callErr := fn()
+
+	switch {
+	case callErr == nil:
+		cb.state = breakerState{}
+
+	default:
+		cb.state.ConsecutiveFailures++
+		cb.state.LastError = callErr.Error()
+
+		// Trip the breaker (or reopen it after a failed trial attempt).
+		if cb.state.ConsecutiveFailures >= cb.failureThreshold {
+			cb.state.OpenedAt = now
+		}
+	}
+
+	if err := cb.save(); err != nil {
+		if callErr != nil {
+			return fmt.Errorf("%v (additionally failed to save breaker state: %w)", callErr, err)
+		}
+		return err
+	}
+
+	return callErr
+}
+
+// IsOpen indicates whether the breaker is currently open and suppressing
+// submission attempts.
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.isOpen(cb.now())
+}
+
+// Status provides a one-line summary of the breaker state suitable for
+// inclusion in plugin output (e.g., LongServiceOutput).
+func (cb *CircuitBreaker) Status() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+
+	switch {
+	case cb.isOpen(now):
+		return fmt.Sprintf(
+			"circuit OPEN after %d consecutive failures; %d submission(s) suppressed; next attempt after %s",
+			cb.state.ConsecutiveFailures,
+			cb.state.Suppressed,
+			cb.state.OpenedAt.Add(cb.cooldown).Format(time.RFC3339),
+		)
+	case cb.state.ConsecutiveFailures > 0:
+		return fmt.Sprintf(
+			"circuit closed; %d of %d permitted consecutive failures recorded",
+			cb.state.ConsecutiveFailures,
+			cb.failureThreshold,
+		)
+	default:
+		return "circuit closed"
+	}
+}
+
+// isOpen indicates whether the breaker is open at the given time. The
+// caller is responsible for holding the lock.
+func (cb *CircuitBreaker) isOpen(now time.Time) bool {
+	if cb.state.ConsecutiveFailures < cb.failureThreshold || cb.state.OpenedAt.IsZero() {
+		return false
+	}
+
+	return now.Before(cb.state.OpenedAt.Add(cb.cooldown))
+}
+
+// load retrieves persisted breaker state, if available.
+func (cb *CircuitBreaker) load() error {
+	if cb.statePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(cb.statePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to read breaker state %q: %w", cb.statePath, err)
+	}
+
+	if err := json.Unmarshal(data, &cb.state); err != nil {
+		// Corrupt state should not block submissions; start fresh.
+		cb.state = breakerState{}
+	}
+
+	return nil
+}
+
+// save persists breaker state, if a state file was specified. The caller is
+// responsible for holding the lock.
+func (cb *CircuitBreaker) save() error {
+	if cb.statePath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(cb.state)
+	if err != nil {
+		return fmt.Errorf("failed to encode breaker state: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(cb.statePath), filepath.Base(cb.statePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save breaker state: %w", err)
+	}
+
+	tmpName := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to save breaker state: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to save breaker state: %w", err)
+	}
+
+	if err := os.Rename(tmpName, cb.statePath); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to save breaker state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package transport
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCircuitBreakerTripsAndRecovers asserts that the breaker opens after
+// the configured number of failures, suppresses calls during the cooldown
+// and closes again after a successful trial attempt. Breaker state is
+// persisted between instances as it would be between plugin runs.
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	statePath := filepath.Join(t.TempDir(), "breaker.json")
+	now := time.Date(2022, 12, 15, 8, 0, 0, 0, time.UTC)
+
+	newBreaker := func() *CircuitBreaker {
+		cb, err := NewCircuitBreaker(
+			WithStateFile(statePath),
+			WithFailureThreshold(2),
+			WithCooldown(time.Minute),
+		)
+		if err != nil {
+			t.Fatalf("failed to create breaker: %v", err)
+		}
+		cb.now = func() time.Time { return now }
+		return cb
+	}
+
+	errTimeout := errors.New("endpoint timed out")
+
+	var calls int
+	failing := func() error { calls++; return errTimeout }
+	working := func() error { calls++; return nil }
+
+	for i := 0; i < 2; i++ {
+		if err := newBreaker().Call(failing); !errors.Is(err, errTimeout) {
+			t.Fatalf("want error %v, got %v", errTimeout, err)
+		}
+	}
+
+	cb := newBreaker()
+	if !cb.IsOpen() {
+		t.Fatal("want open breaker after repeated failures")
+	}
+
+	if err := cb.Call(working); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("want error wrapping %v, got %v", ErrCircuitOpen, err)
+	}
+
+	if calls != 2 {
+		t.Errorf("want 2 calls to reach the endpoint, got %d", calls)
+	}
+
+	// After the cooldown a trial attempt is permitted.
+	now = now.Add(2 * time.Minute)
+
+	if err := newBreaker().Call(working); err != nil {
+		t.Errorf("unexpected error for trial attempt: %v", err)
+	}
+
+	if newBreaker().IsOpen() {
+		t.Error("want closed breaker after successful trial attempt")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+/*
+Package transport provides shared building blocks used when submitting check
+results to a remote monitoring system (e.g., NSCA, NRDP, Icinga2 API).
+
+# OVERVIEW
+
+Plugins and passive submitters often run on hosts with unreliable access to
+the monitoring core. The types in this package are intended to be shared by
+all network based submission methods so that each one does not need to grow
+its own (incompatible) implementation of the same behavior.
+
+# FEATURES
+
+  - Spool type providing a persistent, on-disk queue used to hold results
+    when a remote endpoint is unreachable; queued results are forwarded
+    (oldest first) on the next run, with a lock file ensuring that
+    concurrent runs deliver each queued result only once
+  - TLSConfig type providing a unified set of TLS options (CA bundle, client
+    certificate, minimum version, certificate verification) for all network
+    based clients
+  - ProxyConfig type and NewHTTPClient helper used to route HTTP based
+    submissions through HTTP, HTTPS or (authenticated) SOCKS5 proxies
+  - CircuitBreaker type used to stop contacting a repeatedly failing
+    endpoint for a cooldown period (state is persisted between runs); see
+    the passive package WithBreaker option
+*/
+package transport
@@ -0,0 +1,182 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package transport_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/atc0005/go-nagios/transport"
+)
+
+// fakeSOCKS5Proxy is a minimal SOCKS5 proxy which requires username/password
+// authentication and supports the CONNECT command.
+func fakeSOCKS5Proxy(t *testing.T, username string, password string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake proxy: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn, username, password)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveSOCKS5(conn net.Conn, username string, password string) {
+	defer conn.Close()
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	if _, err := io.CopyN(io.Discard, conn, int64(header[1])); err != nil {
+		return
+	}
+	_, _ = conn.Write([]byte{0x05, 0x02})
+
+	readField := func() string {
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return ""
+		}
+		field := make([]byte, length[0])
+		_, _ = io.ReadFull(conn, field)
+		return string(field)
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		return
+	}
+	if readField() != username || readField() != password {
+		_, _ = conn.Write([]byte{0x01, 0x01})
+		return
+	}
+	_, _ = conn.Write([]byte{0x01, 0x00})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+
+	var host string
+	switch request[3] {
+	case 0x01:
+		ip := make([]byte, 4)
+		_, _ = io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 0x03:
+		host = readField()
+	default:
+		return
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		_, _ = conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+
+	_, _ = conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+
+	go func() { _, _ = io.Copy(target, conn) }()
+	_, _ = io.Copy(conn, target)
+}
+
+// TestNewHTTPClientUsesAuthenticatedSOCKS5Proxy asserts that requests are
+// routed through an authenticated SOCKS5 proxy.
+func TestNewHTTPClientUsesAuthenticatedSOCKS5Proxy(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "via proxy")
+	}))
+	t.Cleanup(server.Close)
+
+	proxyAddr := fakeSOCKS5Proxy(t, "agent", "s3cret")
+
+	client, err := transport.NewHTTPClient(
+		transport.TLSConfig{},
+		transport.ProxyConfig{URL: "socks5://agent:s3cret@" + proxyAddr},
+		0,
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request via proxy failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if got := string(body); got != "via proxy" {
+		t.Errorf("want %q, got %q", "via proxy", got)
+	}
+}
+
+// TestNewHTTPClientRejectsBadSOCKS5Credentials asserts that rejected proxy
+// credentials surface as a handshake failure.
+func TestNewHTTPClientRejectsBadSOCKS5Credentials(t *testing.T) {
+	t.Parallel()
+
+	proxyAddr := fakeSOCKS5Proxy(t, "agent", "s3cret")
+
+	client, err := transport.NewHTTPClient(
+		transport.TLSConfig{},
+		transport.ProxyConfig{URL: "socks5://agent:wrong@" + proxyAddr},
+		0,
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.Get("http://127.0.0.1:1/")
+	if !errors.Is(err, transport.ErrSOCKS5HandshakeFailed) {
+		t.Errorf("want error wrapping %v, got %v", transport.ErrSOCKS5HandshakeFailed, err)
+	}
+}
+
+// TestProxyConfigRejectsUnsupportedScheme asserts that unknown proxy schemes
+// are rejected.
+func TestProxyConfigRejectsUnsupportedScheme(t *testing.T) {
+	t.Parallel()
+
+	_, err := transport.NewHTTPClient(
+		transport.TLSConfig{},
+		transport.ProxyConfig{URL: "ftp://proxy.example.com"},
+		0,
+	)
+	if !errors.Is(err, transport.ErrProxyUnsupportedScheme) {
+		t.Errorf("want error wrapping %v, got %v", transport.ErrProxyUnsupportedScheme, err)
+	}
+}
@@ -0,0 +1,396 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atc0005/go-nagios/internal/filelock"
+)
+
+// Spool file naming details. Entries are named using a fixed width
+// timestamp so that a lexical sort of the directory listing also provides
+// the order in which entries were queued.
+const (
+	spoolEntryPrefix    string = "result-"
+	spoolEntrySuffix    string = ".spool"
+	spoolEntryTmpSuffix string = ".tmp"
+
+	// spoolLockFileName is the name of the lock file within the spool
+	// directory used to serialize delivery of queued entries across
+	// processes.
+	spoolLockFileName string = ".lock"
+)
+
+// Spool locking settings.
+const (
+	// DefaultSpoolLockTimeout is the maximum time spent waiting to acquire
+	// the spool lock if not specified by client code.
+	DefaultSpoolLockTimeout time.Duration = 5 * time.Second
+
+	// staleSpoolLockAge is the age at which an existing lock file is
+	// assumed to have been left behind by a process which exited without
+	// releasing it (only used on platforms without flock support). This
+	// exceeds the time a drain is expected to take.
+	staleSpoolLockAge time.Duration = 10 * time.Minute
+)
+
+// Permissions applied to spool content. Queued results may contain
+// sensitive details, so access is limited to the owner.
+const (
+	spoolDirPerms  os.FileMode = 0700
+	spoolFilePerms os.FileMode = 0600
+)
+
+// Sentinel error collection. Exported for potential use by client code to
+// detect & handle specific error scenarios.
+var (
+	// ErrPayloadSpooled indicates that a payload could not be submitted and
+	// was instead queued on disk for a later delivery attempt. Client code
+	// will likely wish to note this in plugin output, but not treat the
+	// submission as lost.
+	ErrPayloadSpooled = errors.New("payload queued in local spool for later delivery")
+
+	// ErrSpoolDirMissing indicates that a spool directory was not specified.
+	ErrSpoolDirMissing = errors.New("spool directory not specified")
+
+	// ErrSpoolFull indicates that the spool has reached the maximum number of
+	// queued entries and a new entry could not be queued.
+	ErrSpoolFull = errors.New("spool is full")
+
+	// ErrSpoolLockTimeout indicates that the spool lock could not be
+	// acquired before the lock timeout expired (e.g., another process is
+	// draining the spool).
+	ErrSpoolLockTimeout = errors.New("timeout acquiring spool lock")
+)
+
+// SendFunc is a function that attempts to deliver a payload to a remote
+// endpoint. A non-nil error indicates that the delivery attempt failed and
+// that the payload should remain queued.
+type SendFunc func(payload []byte) error
+
+// Spool is a persistent, directory backed queue of payloads which could not
+// be delivered to a remote endpoint. Spool is intended to be shared by all
+// passive submission methods so that results are not lost while the
+// monitoring core is unavailable (e.g., during maintenance).
+type Spool struct {
+	// mu guards access to spool content by the current process. Delivery
+	// of queued entries by separate processes is serialized using a lock
+	// file within the spool directory; entries are queued using unique
+	// names and atomic renames so that queueing does not require the lock.
+	mu sync.Mutex
+
+	// dir is the path to the directory used to store queued entries.
+	dir string
+
+	// maxEntries is the maximum number of entries permitted in the spool.
+	// A zero value indicates no limit.
+	maxEntries int
+
+	// lockTimeout is the maximum time spent waiting to acquire the spool
+	// lock.
+	lockTimeout time.Duration
+}
+
+// SpoolOption is a functional option used to configure a Spool value when
+// constructed via NewSpool.
+type SpoolOption func(*Spool)
+
+// WithMaxEntries is a SpoolOption used to limit the number of queued
+// entries. New payloads are rejected once the spool holds this many entries.
+// A value of zero (the default) indicates no limit.
+func WithMaxEntries(maxEntries int) SpoolOption {
+	return func(s *Spool) {
+		s.maxEntries = maxEntries
+	}
+}
+
+// WithLockTimeout is a SpoolOption used to specify the maximum time spent
+// waiting to acquire the spool lock held while queued entries are
+// delivered. Values less than or equal to zero are ignored.
+func WithLockTimeout(timeout time.Duration) SpoolOption {
+	return func(s *Spool) {
+		if timeout > 0 {
+			s.lockTimeout = timeout
+		}
+	}
+}
+
+// NewSpool creates a new Spool using the specified directory to store
+// queued payloads. The directory is created if it does not already exist.
+func NewSpool(dir string, options ...SpoolOption) (*Spool, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, ErrSpoolDirMissing
+	}
+
+	if err := os.MkdirAll(dir, spoolDirPerms); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory %q: %w", dir, err)
+	}
+
+	s := Spool{
+		dir:         dir,
+		lockTimeout: DefaultSpoolLockTimeout,
+	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s, nil
+}
+
+// Dir returns the path to the directory used to store queued entries.
+func (s *Spool) Dir() string {
+	return s.dir
+}
+
+// Len returns the number of entries currently queued.
+func (s *Spool) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
+
+// Enqueue writes the given payload to the spool. The entry is written to a
+// temporary file first and then renamed into place so that a partially
+// written entry is never forwarded. Queueing does not wait for the spool
+// lock.
+func (s *Spool) Enqueue(payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.enqueue(payload)
+}
+
+// Drain attempts to deliver all queued entries, oldest first, using the
+// provided send function. Successfully delivered entries are removed from
+// the spool. Draining stops at the first failed delivery attempt so that
+// ordering is preserved; the number of delivered entries is returned along
+// with the error from the failed attempt.
+//
+// The spool lock is held while draining so that entries are delivered only
+// once when several processes (e.g., concurrent plugin runs) share the
+// spool. An error wrapping ErrSpoolLockTimeout is returned if the lock
+// could not be acquired.
+func (s *Spool) Drain(send SendFunc) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Release()
+
+	return s.drain(send)
+}
+
+// Forward delivers the given payload after first draining any previously
+// queued entries. If the spool cannot be drained or the payload cannot be
+// delivered, the payload is queued for a later attempt and an error wrapping
+// ErrPayloadSpooled is returned.
+//
+// The spool lock is held while delivering so that queued entries are
+// delivered only once when several processes share the spool. If the lock
+// could not be acquired the payload is queued behind the existing entries.
+//
+// An error not wrapping ErrPayloadSpooled indicates that the payload could
+// not be delivered OR queued and has been lost.
+func (s *Spool) Forward(payload []byte, send SendFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sendErr := s.forward(payload, send)
+	if sendErr == nil {
+		return nil
+	}
+
+	if err := s.enqueue(payload); err != nil {
+		return fmt.Errorf(
+			"failed to deliver payload (%v) and failed to queue it: %w",
+			sendErr,
+			err,
+		)
+	}
+
+	return fmt.Errorf("%w: %w", ErrPayloadSpooled, sendErr)
+}
+
+// forward delivers the given payload after first draining any previously
+// queued entries while holding the spool lock. The caller is responsible
+// for holding the mutex and for queueing the payload if an error is
+// returned.
+func (s *Spool) forward(payload []byte, send SendFunc) error {
+	lock, err := s.lock()
+	if err != nil {
+		// Another process is delivering queued entries; preserve ordering
+		// by queueing this payload for that (or a later) run.
+		return err
+	}
+	defer lock.Release()
+
+	if _, err := s.drain(send); err != nil {
+		// The endpoint is still unreachable; preserve ordering by queueing
+		// this payload behind the existing entries.
+		return err
+	}
+
+	return send(payload)
+}
+
+// enqueue writes the given payload to the spool. The caller is responsible
+// for holding the lock.
+func (s *Spool) enqueue(payload []byte) error {
+	if s.maxEntries > 0 {
+		entries, err := s.entries()
+		if err != nil {
+			return err
+		}
+
+		if len(entries) >= s.maxEntries {
+			return fmt.Errorf(
+				"%w: %d of %d entries used",
+				ErrSpoolFull,
+				len(entries),
+				s.maxEntries,
+			)
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(
+		s.dir,
+		fmt.Sprintf("%s%d-*%s", spoolEntryPrefix, time.Now().UnixNano(), spoolEntryTmpSuffix),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create spool entry: %w", err)
+	}
+
+	tmpName := tmpFile.Name()
+
+	// Remove the temporary file if we fail to move it into place.
+	cleanup := func() {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpName)
+	}
+
+	if err := tmpFile.Chmod(spoolFilePerms); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to set spool entry permissions: %w", err)
+	}
+
+	if _, err := tmpFile.Write(payload); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to write spool entry: %w", err)
+	}
+
+	if err := tmpFile.Sync(); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to sync spool entry: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to close spool entry: %w", err)
+	}
+
+	finalName := strings.TrimSuffix(tmpName, spoolEntryTmpSuffix) + spoolEntrySuffix
+	if err := os.Rename(tmpName, finalName); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to finalize spool entry: %w", err)
+	}
+
+	return nil
+}
+
+// drain attempts to deliver all queued entries, oldest first. The caller is
+// responsible for holding the mutex and the spool lock.
+func (s *Spool) drain(send SendFunc) (int, error) {
+	entries, err := s.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var delivered int
+	for _, entry := range entries {
+		payload, err := os.ReadFile(entry)
+		if err != nil {
+			// The entry may have been removed by client code since we
+			// retrieved the listing.
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return delivered, fmt.Errorf("failed to read spool entry %q: %w", entry, err)
+		}
+
+		if err := send(payload); err != nil {
+			return delivered, err
+		}
+
+		if err := os.Remove(entry); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return delivered, fmt.Errorf("failed to remove delivered spool entry %q: %w", entry, err)
+		}
+
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// lock acquires the spool lock, retrying until the lock timeout expires.
+func (s *Spool) lock() (*filelock.Lock, error) {
+	path := filepath.Join(s.dir, spoolLockFileName)
+
+	lock, err := filelock.Acquire(path, s.lockTimeout, staleSpoolLockAge)
+	switch {
+	case errors.Is(err, filelock.ErrTimeout):
+		return nil, fmt.Errorf("%w: %s", ErrSpoolLockTimeout, path)
+	case err != nil:
+		return nil, fmt.Errorf("failed to lock spool: %w", err)
+	}
+
+	return lock, nil
+}
+
+// entries returns the sorted paths of all queued (finalized) entries.
+func (s *Spool) entries() ([]string, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory %q: %w", s.dir, err)
+	}
+
+	entries := make([]string, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+
+		if dirEntry.IsDir() ||
+			!strings.HasPrefix(name, spoolEntryPrefix) ||
+			!strings.HasSuffix(name, spoolEntrySuffix) {
+			continue
+		}
+
+		entries = append(entries, filepath.Join(s.dir, name))
+	}
+
+	sort.Strings(entries)
+
+	return entries, nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package transport_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios/transport"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestSpoolForwardQueuesWhenEndpointUnreachable asserts that payloads are
+// queued when delivery fails and forwarded in their original order once the
+// endpoint is reachable again.
+func TestSpoolForwardQueuesWhenEndpointUnreachable(t *testing.T) {
+	t.Parallel()
+
+	spool, err := transport.NewSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	errUnreachable := errors.New("endpoint unreachable")
+	failingSend := func([]byte) error { return errUnreachable }
+
+	for _, payload := range []string{"first", "second"} {
+		err := spool.Forward([]byte(payload), failingSend)
+		if !errors.Is(err, transport.ErrPayloadSpooled) {
+			t.Fatalf("want error wrapping %v, got %v", transport.ErrPayloadSpooled, err)
+		}
+	}
+
+	if got, err := spool.Len(); err != nil || got != 2 {
+		t.Fatalf("want 2 queued entries, got %d (err: %v)", got, err)
+	}
+
+	var delivered []string
+	workingSend := func(payload []byte) error {
+		delivered = append(delivered, string(payload))
+		return nil
+	}
+
+	if err := spool.Forward([]byte("third"), workingSend); err != nil {
+		t.Fatalf("unexpected error forwarding payload: %v", err)
+	}
+
+	want := []string{"first", "second", "third"}
+	if d := cmp.Diff(want, delivered); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if got, err := spool.Len(); err != nil || got != 0 {
+		t.Errorf("want empty spool, got %d entries (err: %v)", got, err)
+	}
+}
+
+// TestSpoolDrainStopsAtFirstFailure asserts that draining stops at the first
+// failed delivery so that queued entries remain in order.
+func TestSpoolDrainStopsAtFirstFailure(t *testing.T) {
+	t.Parallel()
+
+	spool, err := transport.NewSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	for _, payload := range []string{"first", "second", "third"} {
+		if err := spool.Enqueue([]byte(payload)); err != nil {
+			t.Fatalf("failed to enqueue payload: %v", err)
+		}
+	}
+
+	errUnreachable := errors.New("endpoint unreachable")
+	send := func(payload []byte) error {
+		if string(payload) == "second" {
+			return errUnreachable
+		}
+		return nil
+	}
+
+	delivered, err := spool.Drain(send)
+	if !errors.Is(err, errUnreachable) {
+		t.Errorf("want error %v, got %v", errUnreachable, err)
+	}
+
+	if delivered != 1 {
+		t.Errorf("want 1 delivered entry, got %d", delivered)
+	}
+
+	if got, err := spool.Len(); err != nil || got != 2 {
+		t.Errorf("want 2 queued entries, got %d (err: %v)", got, err)
+	}
+}
+
+// TestSpoolRejectsEntriesWhenFull asserts that the configured entry limit is
+// enforced.
+func TestSpoolRejectsEntriesWhenFull(t *testing.T) {
+	t.Parallel()
+
+	spool, err := transport.NewSpool(t.TempDir(), transport.WithMaxEntries(1))
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	if err := spool.Enqueue([]byte("first")); err != nil {
+		t.Fatalf("failed to enqueue payload: %v", err)
+	}
+
+	if err := spool.Enqueue([]byte("second")); !errors.Is(err, transport.ErrSpoolFull) {
+		t.Errorf("want error wrapping %v, got %v", transport.ErrSpoolFull, err)
+	}
+}
+
+// TestSpoolConcurrentDrainDeliversOnce asserts that entries are delivered
+// only once when separate Spool values (e.g., concurrent plugin runs) drain
+// the same spool directory at the same time.
+func TestSpoolConcurrentDrainDeliversOnce(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	spools := make([]*transport.Spool, 2)
+	for i := range spools {
+		spool, err := transport.NewSpool(dir, transport.WithLockTimeout(10*time.Second))
+		if err != nil {
+			t.Fatalf("failed to create spool: %v", err)
+		}
+		spools[i] = spool
+	}
+
+	const entries = 20
+	for i := 0; i < entries; i++ {
+		if err := spools[0].Enqueue([]byte(fmt.Sprintf("entry-%02d", i))); err != nil {
+			t.Fatalf("failed to queue entry: %v", err)
+		}
+	}
+
+	var (
+		mu        sync.Mutex
+		delivered = make(map[string]int)
+		wg        sync.WaitGroup
+	)
+
+	send := func(payload []byte) error {
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		delivered[string(payload)]++
+
+		return nil
+	}
+
+	for _, spool := range spools {
+		wg.Add(1)
+		go func(spool *transport.Spool) {
+			defer wg.Done()
+
+			if _, err := spool.Drain(send); err != nil {
+				t.Errorf("failed to drain spool: %v", err)
+			}
+		}(spool)
+	}
+
+	wg.Wait()
+
+	if len(delivered) != entries {
+		t.Errorf("want %d delivered entries, got %d", entries, len(delivered))
+	}
+
+	for payload, count := range delivered {
+		if count != 1 {
+			t.Errorf("want entry %q delivered once, got %d deliveries", payload, count)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// DefaultTLSMinVersion is the minimum TLS version used if not specified by
+// client code.
+const DefaultTLSMinVersion uint16 = tls.VersionTLS12
+
+var (
+	// ErrTLSCertKeyMismatch indicates that only one of a client certificate
+	// or client key was specified; both are required for client certificate
+	// authentication.
+	ErrTLSCertKeyMismatch = errors.New("client certificate and key must be specified together")
+
+	// ErrTLSNoCACertsFound indicates that a CA bundle was specified, but did
+	// not contain any usable PEM encoded certificates.
+	ErrTLSNoCACertsFound = errors.New("no PEM encoded certificates found in CA bundle")
+
+	// ErrTLSUnsupportedVersion indicates that an unknown or unsupported TLS
+	// version was specified.
+	ErrTLSUnsupportedVersion = errors.New("unsupported TLS version")
+)
+
+// TLSConfig is the unified set of TLS options shared by all network based
+// clients (e.g., NRPE, NRDP, Icinga2 API). Client code is expected to
+// populate this value from flags or configuration files and then use the
+// Config method to obtain a *tls.Config for use by a specific client.
+//
+// The zero value is usable and results in verification of the remote
+// certificate using the system CA pool with a minimum TLS version of
+// DefaultTLSMinVersion.
+type TLSConfig struct {
+	// CAFile is an optional path to a PEM encoded CA bundle used to verify
+	// the remote certificate. If not specified, the system CA pool is used.
+	CAFile string
+
+	// CertFile is an optional path to a PEM encoded client certificate.
+	// Must be specified along with KeyFile.
+	CertFile string
+
+	// KeyFile is an optional path to a PEM encoded client key. Must be
+	// specified along with CertFile.
+	KeyFile string
+
+	// ServerName is an optional value used to override the hostname used to
+	// verify the remote certificate.
+	ServerName string
+
+	// MinVersion is the minimum TLS version accepted. If not specified,
+	// DefaultTLSMinVersion is used. See also ParseTLSVersion.
+	MinVersion uint16
+
+	// InsecureSkipVerify disables verification of the remote certificate
+	// chain and hostname. This should only be used for testing purposes; a
+	// warning is logged each time a configuration using this setting is
+	// generated.
+	InsecureSkipVerify bool
+}
+
+// Config generates a *tls.Config from the specified options. An error is
+// returned if the specified CA bundle or client certificate cannot be
+// loaded.
+func (c TLSConfig) Config() (*tls.Config, error) {
+	minVersion := c.MinVersion
+	if minVersion == 0 {
+		minVersion = DefaultTLSMinVersion
+	}
+
+	// #nosec G402; InsecureSkipVerify is an explicit opt-in and is loudly
+	// reported below.
+	tlsConfig := tls.Config{
+		MinVersion:         minVersion,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.InsecureSkipVerify {
+		log.Printf(
+			"WARNING: TLS certificate verification is DISABLED (server name %q);"+
+				" connections are vulnerable to interception",
+			c.ServerName,
+		)
+	}
+
+	if c.CAFile != "" {
+		pemData, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %q: %w", c.CAFile, err)
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("%w: %s", ErrTLSNoCACertsFound, c.CAFile)
+		}
+
+		tlsConfig.RootCAs = certPool
+	}
+
+	switch {
+	case c.CertFile != "" && c.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to load client certificate %q / key %q: %w",
+				c.CertFile,
+				c.KeyFile,
+				err,
+			)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+
+	case c.CertFile != "" || c.KeyFile != "":
+		return nil, ErrTLSCertKeyMismatch
+	}
+
+	return &tlsConfig, nil
+}
+
+// ParseTLSVersion converts a human readable TLS version (e.g., "1.2",
+// "TLS1.3") to the value expected by the TLSConfig MinVersion field.
+func ParseTLSVersion(version string) (uint16, error) {
+	normalized := strings.ToLower(strings.TrimSpace(version))
+	normalized = strings.TrimPrefix(normalized, "tls")
+	normalized = strings.TrimPrefix(normalized, "v")
+
+	switch normalized {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrTLSUnsupportedVersion, version)
+	}
+}
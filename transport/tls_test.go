@@ -0,0 +1,91 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package transport_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/atc0005/go-nagios/transport"
+)
+
+// TestTLSConfigDefaults asserts that the zero value produces a usable
+// configuration with the default minimum TLS version.
+func TestTLSConfigDefaults(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := transport.TLSConfig{}.Config()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.MinVersion != transport.DefaultTLSMinVersion {
+		t.Errorf("want min version %x, got %x", transport.DefaultTLSMinVersion, cfg.MinVersion)
+	}
+
+	if cfg.InsecureSkipVerify {
+		t.Error("certificate verification unexpectedly disabled")
+	}
+}
+
+// TestTLSConfigRejectsIncompleteClientCert asserts that specifying only one
+// of a client certificate or key is rejected.
+func TestTLSConfigRejectsIncompleteClientCert(t *testing.T) {
+	t.Parallel()
+
+	_, err := transport.TLSConfig{CertFile: "client.pem"}.Config()
+	if !errors.Is(err, transport.ErrTLSCertKeyMismatch) {
+		t.Errorf("want error %v, got %v", transport.ErrTLSCertKeyMismatch, err)
+	}
+}
+
+// TestTLSConfigRejectsEmptyCABundle asserts that a CA bundle without any
+// certificates is rejected.
+func TestTLSConfigRejectsEmptyCABundle(t *testing.T) {
+	t.Parallel()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	_, err := transport.TLSConfig{CAFile: caFile}.Config()
+	if !errors.Is(err, transport.ErrTLSNoCACertsFound) {
+		t.Errorf("want error %v, got %v", transport.ErrTLSNoCACertsFound, err)
+	}
+}
+
+// TestParseTLSVersion asserts that supported version formats are recognized.
+func TestParseTLSVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]uint16{
+		"1.2":     tls.VersionTLS12,
+		"TLS1.3":  tls.VersionTLS13,
+		"tlsv1.1": tls.VersionTLS11,
+	}
+
+	for input, want := range tests {
+		got, err := transport.ParseTLSVersion(input)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", input, err)
+			continue
+		}
+
+		if got != want {
+			t.Errorf("%q: want %x, got %x", input, want, got)
+		}
+	}
+
+	if _, err := transport.ParseTLSVersion("2.0"); !errors.Is(err, transport.ErrTLSUnsupportedVersion) {
+		t.Errorf("want error %v, got %v", transport.ErrTLSUnsupportedVersion, err)
+	}
+}
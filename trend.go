@@ -0,0 +1,146 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"math"
+)
+
+const (
+	// trendsLabel is the text emitted prior to the list of trend
+	// annotations.
+	trendsLabel string = "Trends since last run"
+
+	// deltaLabelSuffix is appended to a performance data label to form the
+	// label of the associated delta metric.
+	deltaLabelSuffix string = "_delta"
+
+	// deltaPrecision is the precision that deltas are rounded to in order to
+	// hide floating point noise (e.g., 4.5 - 3.3 = 1.2000000000000002).
+	deltaPrecision float64 = 1e9
+)
+
+// Trend indicators used to annotate performance data values.
+const (
+	TrendUp   string = "↑"
+	TrendDown string = "↓"
+	TrendFlat string = "→"
+)
+
+// WithPerfDataTrends is an Option used to annotate performance data values
+// with the trend since the last run. See also ShowPerfDataTrends.
+func WithPerfDataTrends() Option {
+	return func(p *Plugin) {
+		p.ShowPerfDataTrends()
+	}
+}
+
+// ShowPerfDataTrends includes a list of numeric performance data values
+// annotated with the trend and delta since the last run (e.g., "load1: 4.5
+// ↑ (+1.2)") in LongServiceOutput. Metrics which were not recorded by the
+// last run are omitted. This requires a state store (see SetStateStore).
+func (p *Plugin) ShowPerfDataTrends() {
+	p.showPerfDataTrends = true
+}
+
+// WithPerfDataDeltas is an Option used to emit delta performance data
+// metrics. See also EmitPerfDataDeltas.
+func WithPerfDataDeltas() Option {
+	return func(p *Plugin) {
+		p.EmitPerfDataDeltas()
+	}
+}
+
+// EmitPerfDataDeltas emits an additional performance data metric for each
+// numeric performance data value recorded by the last run, labeled with a
+// "_delta" suffix (e.g., "load1_delta") and holding the change since the
+// last run. This requires a state store (see SetStateStore).
+func (p *Plugin) EmitPerfDataDeltas() {
+	p.emitPerfDataDeltas = true
+}
+
+// applyTrends annotates the current performance data values with the trend
+// since the given previous values as requested via ShowPerfDataTrends and
+// EmitPerfDataDeltas.
+func (p *Plugin) applyTrends(current map[string]float64, previous map[string]float64) {
+	if !p.showPerfDataTrends && !p.emitPerfDataDeltas {
+		return
+	}
+
+	var annotations []string
+	var deltas []PerformanceData
+
+	for _, pd := range p.getSortedPerfData() {
+		value, ok := current[pd.Label]
+		if !ok {
+			continue
+		}
+
+		last, ok := previous[pd.Label]
+		if !ok {
+			continue
+		}
+
+		delta := math.Round((value-last)*deltaPrecision) / deltaPrecision
+
+		annotations = append(annotations, p.listItem(
+			"%s: %s%s %s (%s)",
+			pd.Label,
+			formatRangeBoundary(value),
+			pd.UnitOfMeasurement,
+			trendIndicator(delta),
+			formatDelta(delta),
+		))
+
+		uom := pd.UnitOfMeasurement
+		if uom == "c" {
+			// The change in a counter is not itself a counter.
+			uom = ""
+		}
+
+		deltas = append(deltas, PerformanceData{
+			Label:             pd.Label + deltaLabelSuffix,
+			Value:             formatRangeBoundary(delta),
+			UnitOfMeasurement: uom,
+		})
+	}
+
+	if p.showPerfDataTrends && len(annotations) > 0 {
+		p.WithDetail(trendsLabel + ":")
+		for _, annotation := range annotations {
+			p.WithDetail(annotation)
+		}
+	}
+
+	if p.emitPerfDataDeltas && len(deltas) > 0 {
+		if err := p.AddPerfData(false, deltas...); err != nil {
+			p.Logger().Warn("failed to record delta metrics", "error", err)
+		}
+	}
+}
+
+// trendIndicator returns the trend indicator for the given delta.
+func trendIndicator(delta float64) string {
+	switch {
+	case delta > 0:
+		return TrendUp
+	case delta < 0:
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
+// formatDelta formats the given delta with an explicit sign.
+func formatDelta(delta float64) string {
+	if delta > 0 {
+		return "+" + formatRangeBoundary(delta)
+	}
+
+	return formatRangeBoundary(delta)
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestPerfDataTrendsAnnotateChangesSinceLastRun asserts that performance
+// data values are annotated with the trend since the last run and that delta
+// metrics are emitted.
+func TestPerfDataTrendsAnnotateChangesSinceLastRun(t *testing.T) {
+	t.Parallel()
+
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	run := func(load string, users string) string {
+		plugin := nagios.Plugin{}
+
+		var outputBuffer strings.Builder
+		plugin.SetOutputTarget(&outputBuffer)
+
+		// os.Exit calls break tests
+		plugin.SkipOSExit()
+
+		plugin.SetStateFile(stateFile)
+		plugin.ShowPerfDataTrends()
+		plugin.EmitPerfDataDeltas()
+
+		plugin.ServiceOutput = "OK: load acceptable"
+
+		if err := plugin.AddPerfData(false,
+			nagios.PerformanceData{Label: "load1", Value: load},
+			nagios.PerformanceData{Label: "users", Value: users},
+		); err != nil {
+			t.Fatalf("failed to add performance data: %v", err)
+		}
+
+		plugin.ReturnCheckResults()
+
+		return outputBuffer.String()
+	}
+
+	if got := run("3.3", "5"); strings.Contains(got, "Trends since last run") {
+		t.Errorf("want no trends on first run, got %q", got)
+	}
+
+	got := run("4.5", "5")
+
+	for _, want := range []string{
+		"Trends since last run:",
+		"* load1: 4.5 ↑ (+1.2)",
+		"* users: 5 → (0)",
+		"'load1_delta'=1.2;;;;",
+		"'users_delta'=0;;;;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if got := run("2", "5"); !strings.Contains(got, "* load1: 2 ↓ (-2.5)") {
+		t.Errorf("want downward trend reported, got:\n%s", got)
+	}
+}
@@ -10,8 +10,11 @@ package nagios
 
 import (
 	"fmt"
+	"runtime/debug"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -322,3 +325,111 @@ func addTestTimeMetric(t *testing.T, p *Plugin) PerformanceData {
 
 	return runtimeMetric
 }
+
+// TestFormatAge asserts that durations are formatted using the two most
+// significant units.
+func TestFormatAge(t *testing.T) {
+	t.Parallel()
+
+	tests := map[time.Duration]string{
+		52 * time.Hour:                 "2d4h",
+		3*time.Hour + 12*time.Minute:   "3h12m",
+		5*time.Minute + 30*time.Second: "5m30s",
+		42 * time.Second:               "42s",
+	}
+
+	for d, want := range tests {
+		if got := formatAge(d); got != want {
+			t.Errorf("formatAge(%v): want %q, got %q", d, want, got)
+		}
+	}
+}
+
+// TestCommandLineRedactsSensitiveFlags asserts that the values of sensitive
+// flags are redacted and that arguments are quoted as needed.
+func TestCommandLineRedactsSensitiveFlags(t *testing.T) {
+	t.Parallel()
+
+	args := []string{
+		"/usr/lib/nagios/plugins/check_db",
+		"--host", "db01",
+		"--password", "hunter2",
+		"--api-token=abc123",
+		"--query", "SELECT 1",
+	}
+
+	want := "/usr/lib/nagios/plugins/check_db --host db01 --password REDACTED " +
+		"--api-token=REDACTED --query 'SELECT 1'"
+
+	if got := commandLine(args); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+// TestGatherHostContextDetectsContainersAndHypervisors asserts that host
+// details are gathered from well-known files.
+func TestGatherHostContextDetectsContainersAndHypervisors(t *testing.T) {
+	t.Parallel()
+
+	root := fstest.MapFS{
+		"etc/os-release":                {Data: []byte("NAME=\"Ubuntu\"\nPRETTY_NAME=\"Ubuntu 22.04.3 LTS\"\n")},
+		"proc/sys/kernel/osrelease":     {Data: []byte("5.15.0-91-generic\n")},
+		"proc/1/cgroup":                 {Data: []byte("0::/kubepods/besteffort/pod1234\n")},
+		"sys/class/dmi/id/sys_vendor":   {Data: []byte("QEMU\n")},
+		"sys/class/dmi/id/product_name": {Data: []byte("Standard PC (Q35 + ICH9, 2009)\n")},
+	}
+
+	got := gatherHostContext(root, "")
+
+	want := HostContext{
+		OS:             "Ubuntu 22.04.3 LTS",
+		Arch:           got.Arch,
+		Kernel:         "5.15.0-91-generic",
+		Container:      "kubernetes",
+		Virtualization: "qemu",
+	}
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	bare := gatherHostContext(fstest.MapFS{
+		"proc/cpuinfo": {Data: []byte("processor\t: 0\nflags\t\t: fpu vme hypervisor lahf_lm\n")},
+	}, "podman")
+
+	if bare.Container != "podman" || bare.Virtualization != "unknown hypervisor" {
+		t.Errorf("want podman container on unknown hypervisor, got %+v", bare)
+	}
+}
+
+// TestVersionInfoReadsBuildInfo asserts that VCS details are read from the
+// build information and that the main module version is used if no version
+// is given.
+func TestVersionInfoReadsBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	buildInfo := &debug.BuildInfo{
+		GoVersion: "go1.21.5",
+		Main:      debug.Module{Path: "example.com/check_foo", Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "0123456789abcdef0123456789abcdef01234567"},
+			{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	want := "check_foo v1.4.0 (go1.21.5, git 0123456789ab, 2024-01-02T03:04:05Z, modified)"
+	if got := versionInfo("check_foo", "", buildInfo).String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	buildInfo.Main.Version = develVersion
+	if got := versionInfo("check_foo", "", buildInfo).Version; got != "" {
+		t.Errorf("want no version for local builds, got %q", got)
+	}
+
+	if got := versionInfo("check_foo", "v2.0.1", buildInfo).Version; got != "2.0.1" {
+		t.Errorf("want given version to take precedence, got %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// unitPrecision is the number of significant digits retained when
+// converting values between units, avoiding floating point noise (e.g.,
+// 0.1s as 100.00000000000001ms).
+const unitPrecision int = 12
+
+// ErrIncompatibleUnit indicates that a threshold uses a unit which cannot
+// be converted to the unit of measurement of the metric it applies to
+// (e.g., "500ms" for a metric measured in bytes).
+var ErrIncompatibleUnit = errors.New("incompatible threshold unit")
+
+// unitScale describes a unit of measurement as a multiple of the base unit
+// of its dimension.
+type unitScale struct {
+	dimension string
+	factor    float64
+}
+
+// unitScales is the collection of units supported in threshold values.
+// Decimal byte units (e.g., KB) use powers of 1000 and binary byte units
+// (e.g., KiB) use powers of 1024.
+var unitScales = map[string]unitScale{
+	"ns":  {dimension: "time", factor: 1e-9},
+	"us":  {dimension: "time", factor: 1e-6},
+	"ms":  {dimension: "time", factor: 1e-3},
+	"s":   {dimension: "time", factor: 1},
+	"min": {dimension: "time", factor: 60},
+	"h":   {dimension: "time", factor: 3600},
+
+	"B":   {dimension: "bytes", factor: 1},
+	"KB":  {dimension: "bytes", factor: 1e3},
+	"MB":  {dimension: "bytes", factor: 1e6},
+	"GB":  {dimension: "bytes", factor: 1e9},
+	"TB":  {dimension: "bytes", factor: 1e12},
+	"KiB": {dimension: "bytes", factor: 1 << 10},
+	"MiB": {dimension: "bytes", factor: 1 << 20},
+	"GiB": {dimension: "bytes", factor: 1 << 30},
+	"TiB": {dimension: "bytes", factor: 1 << 40},
+
+	"%": {dimension: "percent", factor: 1},
+}
+
+// NormalizeRange parses a threshold range whose boundaries may include a
+// unit (e.g., "500ms", "1GiB:2GiB" or "@80%:") and converts the boundaries
+// to the given unit of measurement (e.g., "s" or "B") of the metric the
+// range applies to. Boundaries without a unit are assumed to use the unit
+// of the metric.
+//
+// An error wrapping ErrIncompatibleUnit is returned if a boundary uses an
+// unknown unit or one which cannot be converted to the unit of the metric
+// (e.g., "500ms" for a metric measured in bytes). An error wrapping
+// ErrInvalidRange is returned if the range cannot otherwise be parsed.
+func NormalizeRange(s string, uom string) (Range, error) {
+	input := strings.TrimSpace(s)
+
+	prefix := ""
+	if strings.HasPrefix(input, rangeInsidePrefix) {
+		prefix = rangeInsidePrefix
+		input = strings.TrimPrefix(input, rangeInsidePrefix)
+	}
+
+	boundaries := strings.Split(input, rangeBoundarySeparator)
+	for i, boundary := range boundaries {
+		unit, ok := numericUnit(boundary)
+		if !ok || unit == "" {
+			continue
+		}
+
+		number := strings.TrimSuffix(strings.TrimSpace(boundary), unit)
+
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w: value %q in %q is not a number", ErrInvalidRange, boundary, s)
+		}
+
+		converted, err := convertUnit(value, unit, uom)
+		if err != nil {
+			return Range{}, fmt.Errorf("threshold %q: %w", s, err)
+		}
+
+		boundaries[i] = formatRangeBoundary(converted)
+	}
+
+	r, err := ParseRange(prefix + strings.Join(boundaries, rangeBoundarySeparator))
+	if err != nil {
+		return Range{}, fmt.Errorf("%w: %q", ErrInvalidRange, s)
+	}
+
+	return r, nil
+}
+
+// convertUnit converts the given value from one unit to another.
+func convertUnit(value float64, from string, to string) (float64, error) {
+	if from == to {
+		return value, nil
+	}
+
+	fromScale, ok := unitScales[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit %q", ErrIncompatibleUnit, from)
+	}
+
+	if to == "" {
+		return 0, fmt.Errorf("%w: unit %q given for a metric without a unit of measurement", ErrIncompatibleUnit, from)
+	}
+
+	toScale, ok := unitScales[to]
+	if !ok || toScale.dimension != fromScale.dimension {
+		return 0, fmt.Errorf("%w: unit %q cannot be converted to %q", ErrIncompatibleUnit, from, to)
+	}
+
+	converted := value * fromScale.factor / toScale.factor
+
+	// Discard floating point noise introduced by the conversion.
+	converted, _ = strconv.ParseFloat(strconv.FormatFloat(converted, 'g', unitPrecision, 64), 64)
+
+	return converted, nil
+}
+
+// metricUnit returns the unit of measurement of the given performance data
+// metric, taken from the UnitOfMeasurement field or from a unit suffix on
+// the Value field.
+func metricUnit(pd PerformanceData) string {
+	if pd.UnitOfMeasurement != "" {
+		return pd.UnitOfMeasurement
+	}
+
+	unit, _ := numericUnit(pd.Value)
+
+	return unit
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestNormalizeRange asserts that threshold boundaries given with units are
+// converted to the unit of measurement of the metric.
+func TestNormalizeRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		threshold string
+		uom       string
+		want      string
+	}{
+		{threshold: "500ms", uom: "s", want: "0.5"},
+		{threshold: "0.1s", uom: "ms", want: "100"},
+		{threshold: "2GiB", uom: "B", want: "2147483648"},
+		{threshold: "1GB:2GB", uom: "MB", want: "1000:2000"},
+		{threshold: "@80%:", uom: "%", want: "@80:"},
+		{threshold: "~:1s", uom: "ms", want: "~:1000"},
+		{threshold: "10", uom: "ms", want: "10"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.threshold, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := nagios.NormalizeRange(tt.threshold, tt.uom)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := r.String(); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		threshold string
+		uom       string
+	}{
+		{threshold: "500ms", uom: "B"},
+		{threshold: "80%", uom: "s"},
+		{threshold: "5parsecs", uom: "s"},
+		{threshold: "500ms", uom: ""},
+	} {
+		if _, err := nagios.NormalizeRange(tt.threshold, tt.uom); !errors.Is(err, nagios.ErrIncompatibleUnit) {
+			t.Errorf("want incompatible unit error for %q against %q, got %v", tt.threshold, tt.uom, err)
+		}
+	}
+}
+
+// TestEvaluateThresholdSetNormalizesUnits asserts that threshold set ranges
+// with units are normalized against the unit of measurement of each metric.
+func TestEvaluateThresholdSetNormalizesUnits(t *testing.T) {
+	t.Parallel()
+
+	var outputBuffer strings.Builder
+
+	plugin := nagios.Plugin{}
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	if err := plugin.AddPerfData(false,
+		nagios.PerformanceData{Label: "latency", Value: "0.75", UnitOfMeasurement: "s"},
+		nagios.PerformanceData{Label: "heap", Value: "1073741824", UnitOfMeasurement: "B"},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	set, err := nagios.ParseThresholdSet("latency=500ms/2s", "heap=2GiB/3GiB")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := plugin.EvaluateThresholdSet(set); got != nagios.StateWARNINGExitCode {
+		t.Errorf("want state %d, got %d", nagios.StateWARNINGExitCode, got)
+	}
+
+	mismatch, err := nagios.ParseThresholdSet("heap=500ms/1s")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := plugin.EvaluateThresholdSet(mismatch); got != nagios.StateUNKNOWNExitCode {
+		t.Errorf("want state %d for unit mismatch, got %d", nagios.StateUNKNOWNExitCode, got)
+	}
+
+	if len(plugin.Errors) != 1 || !errors.Is(plugin.Errors[0], nagios.ErrIncompatibleUnit) {
+		t.Errorf("want incompatible unit error, got %v", plugin.Errors)
+	}
+
+	plugin.ServiceOutput = "service responding slowly"
+	plugin.ReturnCheckResults()
+
+	got := outputBuffer.String()
+
+	for _, want := range []string{
+		"latency: 0.75 triggers warning threshold 0.5",
+		"'latency'=0.75s;0.5;2;;",
+		"'heap'=1073741824B;2147483648;3221225472;;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
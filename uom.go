@@ -0,0 +1,126 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// perfDataNumericChars is the set of characters permitted in a performance
+// data numeric value; any characters following these are treated as a unit
+// of measurement.
+const perfDataNumericChars string = "-0123456789."
+
+// validateUOM asserts that the Value, Min and Max fields share the same unit
+// of measurement and that the Warn and Crit fields use the same unit of
+// measurement as each other and as the Value field. The unit of measurement
+// for the Value field is taken from the UnitOfMeasurement field, or from a
+// unit suffix on the Value field itself if UnitOfMeasurement is not set.
+// Fields without a unit suffix are assumed to use the same unit of
+// measurement as the Value field.
+func (pd PerformanceData) validateUOM() error {
+	valueUnit := pd.UnitOfMeasurement
+	if suffix, ok := numericUnit(pd.Value); ok && suffix != "" {
+		if valueUnit != "" && suffix != valueUnit {
+			return fmt.Errorf(
+				"%w: value %q for label %q uses unit %q, expected %q",
+				ErrPerformanceDataUOMMismatch,
+				pd.Value,
+				pd.Label,
+				suffix,
+				valueUnit,
+			)
+		}
+		valueUnit = suffix
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{name: "min", value: pd.Min},
+		{name: "max", value: pd.Max},
+	} {
+		if unit, ok := numericUnit(field.value); ok && unit != "" && unit != valueUnit {
+			return fmt.Errorf(
+				"%w: %s %q for label %q uses unit %q, expected %q",
+				ErrPerformanceDataUOMMismatch,
+				field.name,
+				field.value,
+				pd.Label,
+				unit,
+				valueUnit,
+			)
+		}
+	}
+
+	warnUnit := rangeUnit(pd.Warn)
+	critUnit := rangeUnit(pd.Crit)
+
+	switch {
+	case warnUnit != "" && critUnit != "" && warnUnit != critUnit:
+		return fmt.Errorf(
+			"%w: warn %q uses unit %q while crit %q uses unit %q for label %q",
+			ErrPerformanceDataThresholdUOMMismatch,
+			pd.Warn,
+			warnUnit,
+			pd.Crit,
+			critUnit,
+			pd.Label,
+		)
+
+	case warnUnit != "" && warnUnit != valueUnit:
+		return fmt.Errorf(
+			"%w: warn %q for label %q uses unit %q, expected %q",
+			ErrPerformanceDataThresholdUOMMismatch,
+			pd.Warn,
+			pd.Label,
+			warnUnit,
+			valueUnit,
+		)
+
+	case critUnit != "" && critUnit != valueUnit:
+		return fmt.Errorf(
+			"%w: crit %q for label %q uses unit %q, expected %q",
+			ErrPerformanceDataThresholdUOMMismatch,
+			pd.Crit,
+			pd.Label,
+			critUnit,
+			valueUnit,
+		)
+	}
+
+	return nil
+}
+
+// numericUnit returns any unit of measurement suffix following the numeric
+// portion of the given value. The boolean return value is false if the value
+// does not begin with a numeric portion (e.g., an empty or "U" value), in
+// which case no unit of measurement can be determined.
+func numericUnit(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	unit := strings.TrimLeft(value, perfDataNumericChars)
+
+	return unit, len(unit) < len(value)
+}
+
+// rangeUnit returns the unit of measurement used by the boundaries of the
+// given threshold range. If the boundaries use different units, the first
+// unit found is returned.
+func rangeUnit(rangeText string) string {
+	input := strings.TrimPrefix(strings.TrimSpace(rangeText), rangeInsidePrefix)
+
+	for _, boundary := range strings.Split(input, rangeBoundarySeparator) {
+		if unit, ok := numericUnit(boundary); ok && unit != "" {
+			return unit
+		}
+	}
+
+	return ""
+}
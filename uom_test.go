@@ -0,0 +1,59 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestPerformanceDataValidateUOMConsistency asserts that inconsistent units
+// of measurement across performance data fields are flagged with specific
+// errors.
+func TestPerformanceDataValidateUOMConsistency(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		perfData nagios.PerformanceData
+		want     error
+	}{
+		"consistent": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "512", UnitOfMeasurement: "MB", Warn: "800MB", Crit: "900", Min: "0", Max: "1024MB"},
+		},
+		"unit on value only": {
+			perfData: nagios.PerformanceData{Label: "time", Value: "874ms", Warn: "1000ms", Crit: "2000ms"},
+		},
+		"unknown value": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "U", UnitOfMeasurement: "MB", Crit: "900MB"},
+		},
+		"value differs from UOM": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "512MB", UnitOfMeasurement: "%"},
+			want:     nagios.ErrPerformanceDataUOMMismatch,
+		},
+		"max differs from value": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "512", UnitOfMeasurement: "MB", Max: "1GB"},
+			want:     nagios.ErrPerformanceDataUOMMismatch,
+		},
+		"crit differs from value": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "512", UnitOfMeasurement: "MB", Crit: "95%"},
+			want:     nagios.ErrPerformanceDataThresholdUOMMismatch,
+		},
+		"warn differs from crit": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "512", Warn: "@10s:20s", Crit: "~:30ms"},
+			want:     nagios.ErrPerformanceDataThresholdUOMMismatch,
+		},
+	}
+
+	for name, tt := range tests {
+		if got := tt.perfData.Validate(); !errors.Is(got, tt.want) {
+			t.Errorf("%s: want %v, got %v", name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"strings"
+)
+
+// perfDataSeparator is the character Nagios uses to separate plugin output
+// text from performance data.
+const perfDataSeparator string = "|"
+
+// DefaultPipeReplacement is the text substituted for pipe characters in
+// ServiceOutput and LongServiceOutput if pipe replacement is enabled without
+// specifying a replacement.
+const DefaultPipeReplacement string = "¦"
+
+// trailingWhitespaceCutSet is the set of characters permitted at the end of
+// ServiceOutput. Client code commonly ends ServiceOutput with CheckOutputEOL.
+const trailingWhitespaceCutSet string = " \t\r\n"
+
+// ErrServiceOutputMultiline indicates that ServiceOutput contains embedded
+// newlines. Nagios treats the first line of plugin output as ServiceOutput
+// and performance data; any additional lines are treated as
+// LongServiceOutput, silently breaking parsing and perfdata placement.
+var ErrServiceOutputMultiline = errors.New("service output contains embedded newlines")
+
+// ErrOutputContainsPipe indicates that ServiceOutput or LongServiceOutput
+// contains a pipe character. Nagios interprets the remainder of the line
+// following a pipe as performance data.
+var ErrOutputContainsPipe = errors.New("output text contains pipe character")
+
+// WithServiceOutputFolding is an Option used to fold embedded newlines in
+// ServiceOutput into spaces. See also FoldServiceOutput.
+func WithServiceOutputFolding() Option {
+	return func(p *Plugin) {
+		p.FoldServiceOutput()
+	}
+}
+
+// FoldServiceOutput indicates that embedded newlines in ServiceOutput should
+// be folded (along with any surrounding whitespace) into a single space
+// before output is emitted. Trailing whitespace (e.g., CheckOutputEOL) is
+// retained.
+func (p *Plugin) FoldServiceOutput() {
+	p.foldServiceOutput = true
+}
+
+// WithPipeReplacement is an Option used to substitute the given text for
+// pipe characters in ServiceOutput and LongServiceOutput. See also
+// SetPipeReplacement.
+func WithPipeReplacement(replacement string) Option {
+	return func(p *Plugin) {
+		p.SetPipeReplacement(replacement)
+	}
+}
+
+// SetPipeReplacement substitutes the given text for any pipe characters in
+// ServiceOutput and LongServiceOutput before output is emitted. This
+// prevents Nagios from interpreting the remainder of a line containing a
+// stray pipe (e.g., from command output or an error message) as performance
+// data. DefaultPipeReplacement is used if replacement is empty or itself
+// contains a pipe character.
+func (p *Plugin) SetPipeReplacement(replacement string) {
+	if replacement == "" || strings.Contains(replacement, perfDataSeparator) {
+		replacement = DefaultPipeReplacement
+	}
+
+	p.pipeReplacement = replacement
+}
+
+// EscapePipes substitutes the given text for any pipe characters in s.
+// DefaultPipeReplacement is used if replacement is empty or itself contains
+// a pipe character.
+func EscapePipes(s string, replacement string) string {
+	if replacement == "" || strings.Contains(replacement, perfDataSeparator) {
+		replacement = DefaultPipeReplacement
+	}
+
+	return strings.ReplaceAll(s, perfDataSeparator, replacement)
+}
+
+// ValidateOutputText asserts that ServiceOutput and LongServiceOutput do not
+// contain pipe characters. ErrOutputContainsPipe is returned if a pipe
+// character is found.
+func (p Plugin) ValidateOutputText() error {
+	if strings.Contains(p.ServiceOutput, perfDataSeparator) ||
+		strings.Contains(p.LongServiceOutput, perfDataSeparator) {
+		return ErrOutputContainsPipe
+	}
+
+	return nil
+}
+
+// ValidateServiceOutput asserts that ServiceOutput is a single line. Trailing
+// whitespace (e.g., CheckOutputEOL) is permitted. ErrServiceOutputMultiline
+// is returned if embedded newlines are found.
+func (p Plugin) ValidateServiceOutput() error {
+	trimmed := strings.TrimRight(p.ServiceOutput, trailingWhitespaceCutSet)
+
+	if strings.ContainsAny(trimmed, "\r\n") {
+		return ErrServiceOutputMultiline
+	}
+
+	return nil
+}
+
+// validateOutput applies any requested fixes to plugin output and logs
+// validation failures prior to emitting output. If strict mode is enabled,
+// validation failures replace the plugin result with an UNKNOWN result.
+func (p *Plugin) validateOutput() {
+	if p.foldServiceOutput {
+		p.ServiceOutput = foldNewlines(p.ServiceOutput)
+	}
+
+	if p.pipeReplacement != "" {
+		p.ServiceOutput = EscapePipes(p.ServiceOutput, p.pipeReplacement)
+		p.LongServiceOutput = EscapePipes(p.LongServiceOutput, p.pipeReplacement)
+	}
+
+	errs := p.validatePerfData()
+
+	for _, validate := range []func() error{
+		p.ValidateServiceOutput,
+		p.ValidateOutputText,
+		p.ValidateStateLabel,
+	} {
+		if err := validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, err := range errs {
+		p.Logger().Warn("invalid plugin output", "error", err)
+	}
+
+	if p.strictMode && len(errs) > 0 {
+		p.applyStrictMode(errs)
+	}
+}
+
+// foldNewlines replaces embedded newlines (and surrounding whitespace) in
+// the given text with a single space, retaining any trailing whitespace.
+func foldNewlines(s string) string {
+	trimmed := strings.TrimRight(s, trailingWhitespaceCutSet)
+	trailing := s[len(trimmed):]
+
+	if !strings.ContainsAny(trimmed, "\r\n") {
+		return s
+	}
+
+	lines := strings.FieldsFunc(trimmed, func(r rune) bool {
+		return r == '\r' || r == '\n'
+	})
+
+	folded := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			folded = append(folded, line)
+		}
+	}
+
+	return strings.Join(folded, " ") + trailing
+}
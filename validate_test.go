@@ -0,0 +1,115 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestValidateServiceOutputRejectsEmbeddedNewlines asserts that embedded
+// newlines are rejected while trailing newlines are permitted.
+func TestValidateServiceOutputRejectsEmbeddedNewlines(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]error{
+		"OK: all good":                         nil,
+		"OK: all good" + nagios.CheckOutputEOL: nil,
+		"OK: all good\nsecond line":            nagios.ErrServiceOutputMultiline,
+		"OK: all good\r\nsecond line \n":       nagios.ErrServiceOutputMultiline,
+	}
+
+	for serviceOutput, want := range tests {
+		plugin := nagios.Plugin{ServiceOutput: serviceOutput}
+
+		if got := plugin.ValidateServiceOutput(); !errors.Is(got, want) {
+			t.Errorf("%q: want %v, got %v", serviceOutput, want, got)
+		}
+	}
+}
+
+// TestFoldServiceOutputFoldsEmbeddedNewlines asserts that embedded newlines
+// are folded into spaces when requested.
+func TestFoldServiceOutputFoldsEmbeddedNewlines(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+	plugin.FoldServiceOutput()
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.ServiceOutput = "OK: first line\n  second line\r\n\nthird line" + nagios.CheckOutputEOL
+	plugin.ReturnCheckResults()
+
+	want := "OK: first line second line third line"
+
+	if d := cmp.Diff(want, strings.TrimRight(outputBuffer.String(), " \n")); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestSetPipeReplacementEscapesOutputText asserts that pipe characters in
+// ServiceOutput and LongServiceOutput are replaced while the performance
+// data separator is retained.
+func TestSetPipeReplacementEscapesOutputText(t *testing.T) {
+	t.Parallel()
+
+	plugin := nagios.Plugin{}
+	plugin.SetPipeReplacement("/")
+
+	var outputBuffer strings.Builder
+	plugin.SetOutputTarget(&outputBuffer)
+
+	// os.Exit calls break tests
+	plugin.SkipOSExit()
+
+	plugin.ServiceOutput = "OK: grep foo | wc -l returned 1"
+	plugin.LongServiceOutput = "cmd: a|b"
+
+	if err := plugin.AddPerfData(false, nagios.PerformanceData{Label: "lines", Value: "1"}); err != nil {
+		t.Fatalf("failed to add perfdata: %v", err)
+	}
+
+	if err := plugin.ValidateOutputText(); !errors.Is(err, nagios.ErrOutputContainsPipe) {
+		t.Errorf("want %v, got %v", nagios.ErrOutputContainsPipe, err)
+	}
+
+	plugin.ReturnCheckResults()
+
+	want := "OK: grep foo / wc -l returned 1" + nagios.CheckOutputEOL +
+		nagios.CheckOutputEOL +
+		"cmd: a/b" + nagios.CheckOutputEOL +
+		" | 'lines'=1;;;;" + nagios.CheckOutputEOL
+
+	if d := cmp.Diff(want, outputBuffer.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestEscapePipesUsesDefaultReplacement asserts that the default replacement
+// is used when an unusable replacement is given.
+func TestEscapePipesUsesDefaultReplacement(t *testing.T) {
+	t.Parallel()
+
+	for _, replacement := range []string{"", "||"} {
+		got := nagios.EscapePipes("a|b", replacement)
+		want := "a" + nagios.DefaultPipeReplacement + "b"
+
+		if got != want {
+			t.Errorf("replacement %q: want %q, got %q", replacement, want, got)
+		}
+	}
+}
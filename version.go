@@ -0,0 +1,139 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// unknownVersion is reported if the plugin version is not known.
+const unknownVersion string = "unknown"
+
+// develVersion is the main module version reported by debug.ReadBuildInfo
+// for builds from a local checkout.
+const develVersion string = "(devel)"
+
+// shortRevisionLength is the number of characters of the VCS revision
+// included in version details.
+const shortRevisionLength int = 12
+
+// VersionInfo describes the plugin version and the build details embedded
+// by the Go toolchain. See ReadVersionInfo.
+type VersionInfo struct {
+	// Name is the plugin name (e.g., "check_cert").
+	Name string
+
+	// Version is the semantic version of the plugin (e.g., "1.2.3").
+	Version string
+
+	// GoVersion is the version of the Go toolchain used to build the
+	// plugin.
+	GoVersion string
+
+	// VCS is the version control system used (e.g., "git"), if known.
+	VCS string
+
+	// Revision is the VCS revision the plugin was built from, if known.
+	Revision string
+
+	// Time is the time of the VCS revision, if known.
+	Time time.Time
+
+	// Modified indicates whether the plugin was built from a working tree
+	// with uncommitted changes.
+	Modified bool
+}
+
+// ReadVersionInfo returns the version details of the plugin with the given
+// name and semantic version (e.g., set at build time via -ldflags "-X").
+// VCS details are read from the build information embedded by the Go
+// toolchain (see debug.ReadBuildInfo). If version is empty the main module
+// version is used if available (e.g., for plugins installed via "go
+// install").
+func ReadVersionInfo(name string, version string) VersionInfo {
+	// The build information is nil if not available.
+	buildInfo, _ := debug.ReadBuildInfo()
+
+	return versionInfo(name, version, buildInfo)
+}
+
+// versionInfo returns the version details of the plugin with the given name
+// and version using the given (optional) build information.
+func versionInfo(name string, version string, buildInfo *debug.BuildInfo) VersionInfo {
+	info := VersionInfo{
+		Name:      name,
+		Version:   strings.TrimPrefix(version, "v"),
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo == nil {
+		return info
+	}
+
+	if buildInfo.GoVersion != "" {
+		info.GoVersion = buildInfo.GoVersion
+	}
+
+	if info.Version == "" && buildInfo.Main.Version != develVersion {
+		info.Version = strings.TrimPrefix(buildInfo.Main.Version, "v")
+	}
+
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs":
+			info.VCS = setting.Value
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.Time, _ = time.Parse(time.RFC3339, setting.Value)
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
+
+// String formats the version details as recommended by the plugin
+// development guidelines for the -V (--version) flag: the plugin name and
+// version followed by build details.
+//
+//	check_cert v1.2.3 (go1.21.5, git 0123456789ab, 2024-01-02T03:04:05Z, modified)
+//
+// https://nagios-plugins.org/doc/guidelines.html
+func (v VersionInfo) String() string {
+	version := unknownVersion
+	if v.Version != "" {
+		version = "v" + v.Version
+	}
+
+	details := []string{v.GoVersion}
+
+	if v.Revision != "" {
+		revision := v.Revision
+		if len(revision) > shortRevisionLength {
+			revision = revision[:shortRevisionLength]
+		}
+
+		details = append(details, strings.TrimSpace(v.VCS+" "+revision))
+	}
+
+	if !v.Time.IsZero() {
+		details = append(details, v.Time.UTC().Format(time.RFC3339))
+	}
+
+	if v.Modified {
+		details = append(details, "modified")
+	}
+
+	return fmt.Sprintf("%s %s (%s)", v.Name, version, strings.Join(details, ", "))
+}
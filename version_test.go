@@ -0,0 +1,66 @@
+// Copyright 2020 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestVersionInfoStringUsesGuidelineFormat asserts that version details
+// are formatted as the plugin name and version followed by build details.
+func TestVersionInfoStringUsesGuidelineFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		info nagios.VersionInfo
+		want string
+	}{
+		"full details": {
+			info: nagios.VersionInfo{
+				Name:      "check_cert",
+				Version:   "1.2.3",
+				GoVersion: "go1.21.5",
+				VCS:       "git",
+				Revision:  "0123456789abcdef",
+				Time:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			want: "check_cert v1.2.3 (go1.21.5, git 0123456789ab, 2024-01-02T03:04:05Z)",
+		},
+		"unknown version": {
+			info: nagios.VersionInfo{Name: "check_cert", GoVersion: "go1.21.5", Modified: true},
+			want: "check_cert unknown (go1.21.5, modified)",
+		},
+	}
+
+	for name, tt := range tests {
+		if got := tt.info.String(); got != tt.want {
+			t.Errorf("%s: want %q, got %q", name, tt.want, got)
+		}
+	}
+}
+
+// TestReadVersionInfoUsesGivenVersion asserts that the given version is
+// normalized and the Go version is reported.
+func TestReadVersionInfoUsesGivenVersion(t *testing.T) {
+	t.Parallel()
+
+	got := nagios.ReadVersionInfo("check_cert", "v1.2.3").String()
+
+	if want := "check_cert v1.2.3 ("; !strings.HasPrefix(got, want) {
+		t.Errorf("want prefix %q, got %q", want, got)
+	}
+
+	if !strings.Contains(got, runtime.Version()) {
+		t.Errorf("want Go version %q, got %q", runtime.Version(), got)
+	}
+}